@@ -29,6 +29,7 @@ const accountCacheKey = "accountCache"
 const navigateCacheKey = "navigateCache"
 const resumeNodeIDKey = "resumeNode"
 const resumeTenantIDKey = "resumeTenant"
+const resumeTabsKey = "resumeTabs"
 
 func handleCommandAndArgs() {
 
@@ -73,6 +74,17 @@ func createRootCmd() *cobra.Command {
 			storage.PutCache(resumeTenantIDKey, tenantID)           //nolint: errcheck
 		}
 	}()
+	go func() {
+		defer errorhandling.RecoveryWithCleanup()
+
+		tabsChangedChannel := eventing.SubscribeToTopic("list.tabschanged")
+		for {
+			tabsState := (<-tabsChangedChannel).(views.ListTabsState)
+			if tabsStateJSON, err := json.Marshal(tabsState); err == nil {
+				storage.PutCache(resumeTabsKey, string(tabsStateJSON)) //nolint: errcheck
+			}
+		}
+	}()
 
 	cmd := &cobra.Command{
 		Use:   "azbrowse",
@@ -113,6 +125,13 @@ func createRootCmd() *cobra.Command {
 				settings.TenantID = currentTenantID
 				settings.NavigateToID = nodeID
 				settings.ShouldRender = false
+
+				// Restore the tabs if there were multiple tabs open
+				var tabsState views.ListTabsState
+				if tabsStateJSON, err := storage.GetCache(resumeTabsKey); err == nil && json.Unmarshal([]byte(tabsStateJSON), &tabsState) == nil && len(tabsState.NodeIDs) > 1 {
+					settings.NavigateToTabIDs = tabsState.NodeIDs
+					settings.CurrentTab = tabsState.CurrentTab
+				}
 			}
 
 			if fuzzerDurationMinutes > 0 {
@@ -111,10 +111,16 @@ func run(settings *config.Settings) {
 
 	// Start a go routine to handling automated naviging to an item via the
 	// `--navigate` command
-	if settings.NavigateToID != "" {
+	if len(settings.NavigateToTabIDs) > 0 {
+		automation.RestoreTabs(list, settings.NavigateToTabIDs, settings.CurrentTab)
+	} else if settings.NavigateToID != "" {
 		automation.NavigateTo(list, settings.NavigateToID)
 	}
 
+	// Start a go routine to handle links between nodes, e.g. from an activity log
+	// event to the resource
+	automation.HandleNavigateToEvents(list)
+
 	if settings.FuzzerEnabled {
 		automation.StartAutomatedFuzzer(list, settings, g)
 	}
@@ -246,6 +252,11 @@ func setupViewsAndKeybindings(ctx context.Context, g *gocui.Gui, settings *confi
 	listUpdateCommand := keybindings.NewListUpdateHandler(list, status, ctx, content, g)
 	listDebugCopyItemDataCommand := keybindings.NewListDebugCopyItemDataHandler(list, status)
 	listSortCommand := keybindings.NewListSortHandler(list)
+	listWatchCommand := keybindings.NewListWatchHandler(list)
+	listNewTabCommand := keybindings.NewListNewTabHandler(list)
+	listNextTabCommand := keybindings.NewListNextTabHandler(list)
+	listPreviousTabCommand := keybindings.NewListPreviousTabHandler(list)
+	listCloseTabCommand := keybindings.NewListCloseTabHandler(list)
 
 	itemCopyItemIDCommand := keybindings.NewItemCopyItemIDHandler(content, status)
 
@@ -259,6 +270,11 @@ func setupViewsAndKeybindings(ctx context.Context, g *gocui.Gui, settings *confi
 		itemCopyItemIDCommand,
 		toggleDemoModeCommand,
 		listSortCommand,
+		listWatchCommand,
+		listNewTabCommand,
+		listNextTabCommand,
+		listPreviousTabCommand,
+		listCloseTabCommand,
 	}
 	if settings.EnableTracing {
 		commands = append(commands, listDebugCopyItemDataCommand)
@@ -310,6 +326,11 @@ func setupViewsAndKeybindings(ctx context.Context, g *gocui.Gui, settings *confi
 	keybindings.AddHandler(commandPanelAzureSearchQueryCommand)
 	keybindings.AddHandler(itemCopyItemIDCommand)
 	keybindings.AddHandler(listSortCommand)
+	keybindings.AddHandler(listWatchCommand)
+	keybindings.AddHandler(listNewTabCommand)
+	keybindings.AddHandler(listNextTabCommand)
+	keybindings.AddHandler(listPreviousTabCommand)
+	keybindings.AddHandler(listCloseTabCommand)
 	if settings.EnableTracing {
 		keybindings.AddHandler(listDebugCopyItemDataCommand)
 	}
@@ -0,0 +1,93 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+
+	"github.com/lawrencegripper/azbrowse/pkg/swagger"
+)
+
+// armSummaryFilename is the snapshot of the generated ARM resource types that diff compares against by default
+const armSummaryFilename = "./internal/pkg/expanders/swagger-armspecs.summary.json"
+
+// runDiff compares the ARM resource types from two swagger-specs folders (or the snapshot written with the current
+// generated output and a swagger-specs folder) and writes a report of the changes. This is intended to help review
+// spec refreshes as the diff of the generated code is too large to be useful
+//
+//	go run ./cmd/swagger-codegen diff [--old <specs folder>] [--snapshot <file>] [--new <specs folder>] [--format markdown|json] [--output <file>]
+func runDiff(args []string) {
+	flags := flag.NewFlagSet("diff", flag.ExitOnError)
+	oldSpecsFolder := flags.String("old", "", "swagger-specs folder to compare from (defaults to the snapshot of the current generated code)")
+	snapshotFile := flags.String("snapshot", armSummaryFilename, "snapshot written by swagger-codegen to compare from when --old isn't set")
+	newSpecsFolder := flags.String("new", "swagger-specs", "swagger-specs folder to compare to")
+	format := flags.String("format", "markdown", "output format: markdown or json")
+	output := flags.String("output", "", "file to write the report to (defaults to stdout)")
+	_ = flags.Parse(args) // ExitOnError
+
+	if *format != "markdown" && *format != "json" {
+		fmt.Fprintf(os.Stderr, "Unsupported format %q (expected markdown or json)\n", *format)
+		os.Exit(1)
+	}
+
+	progressWriter = os.Stderr
+	var oldSummaries []swagger.ResourceTypeSummary
+	if *oldSpecsFolder == "" {
+		oldSummaries = loadSummaries(*snapshotFile)
+	} else {
+		oldSummaries = swagger.GetResourceTypeSummaries(loadARMResourceTypes(*oldSpecsFolder))
+	}
+	newSummaries := swagger.GetResourceTypeSummaries(loadARMResourceTypes(*newSpecsFolder))
+
+	diff := swagger.DiffResourceTypeSummaries(oldSummaries, newSummaries)
+	report := diff.Markdown()
+	if *format == "json" {
+		var err error
+		if report, err = diff.JSON(); err != nil {
+			panic(err)
+		}
+	}
+
+	if *output == "" {
+		fmt.Print(report)
+		return
+	}
+	if err := ioutil.WriteFile(*output, []byte(report), 0644); err != nil {
+		panic(fmt.Errorf("Error writing report: %s", err))
+	}
+	fmt.Fprintf(os.Stderr, "Report written to %s\n", *output)
+}
+
+func loadARMResourceTypes(specsFolder string) []swagger.ResourceType {
+	fmt.Fprintf(os.Stderr, "Loading ARM specs from %s\n", specsFolder)
+	paths := loadARMSwagger(getARMConfig(), specsFolder)
+	fmt.Fprintln(os.Stderr)
+	return swagger.ConvertToSwaggerResourceTypes(paths)
+}
+
+func loadSummaries(filename string) []swagger.ResourceTypeSummary {
+	fmt.Fprintf(os.Stderr, "Loading ARM resource types snapshot from %s\n", filename)
+	buf, err := ioutil.ReadFile(filename)
+	if err != nil {
+		panic(fmt.Errorf("Error reading snapshot (run swagger-codegen to create it): %s", err))
+	}
+	var summaries []swagger.ResourceTypeSummary
+	if err := json.Unmarshal(buf, &summaries); err != nil {
+		panic(fmt.Errorf("Error parsing snapshot: %s", err))
+	}
+	return summaries
+}
+
+// writeSummaries writes the snapshot of the resource types that diff compares against
+func writeSummaries(paths []*swagger.Path, filename string) {
+	summaries := swagger.GetResourceTypeSummaries(swagger.ConvertToSwaggerResourceTypes(paths))
+	buf, err := json.MarshalIndent(summaries, "", "  ")
+	if err != nil {
+		panic(fmt.Errorf("Error creating snapshot: %s", err))
+	}
+	if err := ioutil.WriteFile(filename, append(buf, '\n'), 0644); err != nil {
+		panic(fmt.Errorf("Error writing snapshot: %s", err))
+	}
+}
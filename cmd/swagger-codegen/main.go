@@ -7,11 +7,11 @@ import (
 	"io/ioutil"
 	"log"
 	"os"
+	"path/filepath"
 	"strings"
 	"text/template"
 
 	"github.com/go-openapi/loads"
-	"github.com/go-openapi/spec"
 	"github.com/lawrencegripper/azbrowse/pkg/swagger"
 )
 
@@ -38,12 +38,18 @@ import (
 
 func main() {
 
+	if len(os.Args) > 1 && os.Args[1] == "diff" {
+		runDiff(os.Args[2:])
+		return
+	}
+
 	fmt.Println("*******************************************")
 	fmt.Println("  Processing ARM Specs ")
 	fmt.Println("*******************************************")
 	config := getARMConfig()
-	paths := loadARMSwagger(config)
+	paths := loadARMSwagger(config, "swagger-specs")
 	writeOutput(paths, config, "./internal/pkg/expanders/swagger-armspecs.generated.go", "SwaggerAPISetARMResources")
+	writeSummaries(paths, armSummaryFilename)
 	fmt.Println()
 
 	fmt.Println("*******************************************")
@@ -69,15 +75,18 @@ type APISet struct {
 	InputFiles []string `json:"input_files"`
 }
 
+// progressWriter is where print writes progress (diff mode uses stderr to keep the report on stdout clean)
+var progressWriter io.Writer = os.Stdout
+
 func print(s string) {
 	if os.Getenv("VERBOSE") == "true" {
-		fmt.Print(s)
+		fmt.Fprint(progressWriter, s)
 	} else {
-		fmt.Print(".")
+		fmt.Fprint(progressWriter, ".")
 	}
 }
 
-func loadARMSwagger(config *swagger.Config) []*swagger.Path {
+func loadARMSwagger(config *swagger.Config, specsFolder string) []*swagger.Path {
 	var paths []*swagger.Path
 	processAPISet := func(apiSetFolder string) bool {
 		apiSetPath := fmt.Sprintf("%s/api-set.json", apiSetFolder)
@@ -111,14 +120,14 @@ func loadARMSwagger(config *swagger.Config) []*swagger.Path {
 		return false
 	}
 
-	resourceProviderFileInfos, err := ioutil.ReadDir("swagger-specs")
+	resourceProviderFileInfos, err := ioutil.ReadDir(specsFolder)
 	if err != nil {
 		panic(err)
 	}
 	for _, resourceProviderFileInfo := range resourceProviderFileInfos {
 		if resourceProviderFileInfo.IsDir() && resourceProviderFileInfo.Name() != "common-types" {
 			print(fmt.Sprintf("Processing resource provider folder: %s\n", resourceProviderFileInfo.Name()))
-			resourceProviderFolderPath := fmt.Sprintf("swagger-specs/%s/resource-manager", resourceProviderFileInfo.Name())
+			resourceProviderFolderPath := fmt.Sprintf("%s/%s/resource-manager", specsFolder, resourceProviderFileInfo.Name())
 			resourceTypeFileInfos, err := ioutil.ReadDir(resourceProviderFolderPath)
 			_ = resourceTypeFileInfos
 			if err != nil {
@@ -342,20 +351,24 @@ func loadDatabricksDataPlaneSpecs(config *swagger.Config) []*swagger.Path {
 }
 
 func loadDoc(path string) *loads.Document {
-
-	document, err := loads.Spec(path)
-	if err != nil {
-		log.Panicf("Error opening Swagger: %s", err)
-	}
-
-	document, err = document.Expanded(&spec.ExpandOptions{RelativeBase: path})
+	document, err := swagger.LoadDoc(path)
 	if err != nil {
-		log.Panicf("Error expanding Swagger: %s", err)
+		log.Panic(err)
 	}
-
 	return document
 }
 func writeOutput(paths []*swagger.Path, config *swagger.Config, filename string, structName string) {
+	schemaTable := swagger.NewSchemaTable()
+	addSchemasToTable(schemaTable, paths)
+
+	// descriptions are written to a separate file that is embedded and only loaded when needed
+	descriptionsFilename := ""
+	if len(schemaTable.Descriptions) > 0 {
+		descriptionsPath := strings.TrimSuffix(filename, ".generated.go") + ".descriptions.json"
+		writeDescriptions(schemaTable.Descriptions, descriptionsPath)
+		descriptionsFilename = filepath.Base(descriptionsPath)
+	}
+
 	writer, err := os.Create(filename)
 	if err != nil {
 		panic(fmt.Errorf("Error opening file: %s", err))
@@ -367,9 +380,9 @@ func writeOutput(paths []*swagger.Path, config *swagger.Config, filename string,
 		}
 	}()
 
-	writeTemplate(writer, paths, config, structName)
+	writeTemplate(writer, paths, config, structName, schemaTable, descriptionsFilename)
 }
-func writeTemplate(w io.Writer, paths []*swagger.Path, config *swagger.Config, structName string) {
+func writeTemplate(w io.Writer, paths []*swagger.Path, config *swagger.Config, structName string, schemaTable *swagger.SchemaTable, descriptionsFilename string) {
 
 	funcMap := template.FuncMap{
 		"upper": strings.ToUpper,
@@ -377,13 +390,17 @@ func writeTemplate(w io.Writer, paths []*swagger.Path, config *swagger.Config, s
 	t := template.Must(template.New("code-gen").Funcs(funcMap).Parse(tmpl))
 
 	type Context struct {
-		Paths      []*swagger.Path
-		StructName string
+		Paths                []*swagger.Path
+		StructName           string
+		SchemaDefinitions    map[string]string
+		DescriptionsFilename string
 	}
 
 	context := Context{
-		Paths:      paths,
-		StructName: structName,
+		Paths:                paths,
+		StructName:           structName,
+		SchemaDefinitions:    schemaTable.Definitions,
+		DescriptionsFilename: descriptionsFilename,
 	}
 
 	err := t.Execute(w, context)
@@ -392,6 +409,27 @@ func writeTemplate(w io.Writer, paths []*swagger.Path, config *swagger.Config, s
 	}
 }
 
+// writeDescriptions writes the schema descriptions as JSON (with one description per line to keep diffs readable)
+func writeDescriptions(descriptions map[string]string, filename string) {
+	buf, err := json.MarshalIndent(descriptions, "", "  ")
+	if err != nil {
+		panic(fmt.Errorf("Error serializing descriptions: %s", err))
+	}
+	if err = ioutil.WriteFile(filename, append(buf, '\n'), 0644); err != nil {
+		panic(fmt.Errorf("Error writing descriptions: %s", err))
+	}
+}
+
+// addSchemasToTable replaces the schemas for the paths with de-duplicated versions from the SchemaTable
+func addSchemasToTable(schemaTable *swagger.SchemaTable, paths []*swagger.Path) {
+	for _, path := range paths {
+		path.Operations.Get.ResponseSchema = schemaTable.Add(path.Operations.Get.ResponseSchema)
+		path.Operations.Put.BodySchema = schemaTable.Add(path.Operations.Put.BodySchema)
+		addSchemasToTable(schemaTable, path.Children)
+		addSchemasToTable(schemaTable, path.SubPaths)
+	}
+}
+
 // getFirstNonCommonPath returns the first subfolder under path that is not named 'common'
 func getFirstNonCommonPath(path string) string {
 	// get the first non `common` path
@@ -7,12 +7,25 @@ const tmpl = `
 	Endpoint: endpoints.MustGetEndpointInfoFromURL("{{ .Operations.Get.Endpoint.TemplateURL }}", "{{ .Operations.Get.Endpoint.APIVersion}}"),
 	{{- if ne .Operations.Get.Verb "" }}
 	Verb: "{{upper .Operations.Get.Verb }}",{{end}}
+	{{- if .Operations.Get.ResponseSchema }}
+	ResponseSchema: {{ printf "%q" .Operations.Get.ResponseSchema }},{{end}}
 	{{- if .Operations.Delete.Permitted }}
 	DeleteEndpoint: endpoints.MustGetEndpointInfoFromURL("{{ .Operations.Delete.Endpoint.TemplateURL }}", "{{ .Operations.Delete.Endpoint.APIVersion}}"),{{end}}
 	{{- if .Operations.Patch.Permitted }}
 	PatchEndpoint: endpoints.MustGetEndpointInfoFromURL("{{ .Operations.Patch.Endpoint.TemplateURL }}", "{{ .Operations.Patch.Endpoint.APIVersion}}"),{{end}}
 	{{- if .Operations.Put.Permitted }}
-	PutEndpoint: endpoints.MustGetEndpointInfoFromURL("{{ .Operations.Put.Endpoint.TemplateURL }}", "{{ .Operations.Put.Endpoint.APIVersion}}"),{{end}}
+	PutEndpoint: endpoints.MustGetEndpointInfoFromURL("{{ .Operations.Put.Endpoint.TemplateURL }}", "{{ .Operations.Put.Endpoint.APIVersion}}"),
+	{{- if .Operations.Put.BodySchema }}
+	PutBodySchema: {{ printf "%q" .Operations.Put.BodySchema }},{{end}}{{end}}
+	{{- with .GetPostActions }}
+	PostActions: []swagger.PostAction{ {{range .}}
+		{
+			Name: "{{ .Name }}",
+			Endpoint: endpoints.MustGetEndpointInfoFromURL("{{ .Endpoint.TemplateURL }}", "{{ .Endpoint.APIVersion}}"),
+			{{- if .BodySkeleton }}
+			BodySkeleton: {{ printf "%q" .BodySkeleton }},{{end}}
+		},{{end}}
+	},{{end}}
 	{{- if .FixedContent}}
 	FixedContent: "{{ .FixedContent}}",{{end}}
 	{{- if .Children}}
@@ -24,6 +37,9 @@ const tmpl = `
 package expanders
 
 import (
+{{- if .DescriptionsFilename }}
+	_ "embed"
+{{ end }}
 	"github.com/lawrencegripper/azbrowse/pkg/endpoints"	
 	"github.com/lawrencegripper/azbrowse/pkg/swagger"	
 )
@@ -32,4 +48,19 @@ func (e *{{ .StructName }}) loadResourceTypes() []swagger.ResourceType {
 	return  {{template "PathList" .Paths }}
 
 }
+{{- if .DescriptionsFilename }}
+
+//go:embed {{ .DescriptionsFilename }}
+var schemaDescriptions{{ .StructName }} []byte
+{{- end }}
+{{- if .SchemaDefinitions }}
+
+func init() {
+	swagger.RegisterSchemaDefinitions(map[string]string{ {{range $key, $value := .SchemaDefinitions}}
+		"{{ $key }}": {{ printf "%q" $value }},{{end}}
+	})
+	{{- if .DescriptionsFilename }}
+	swagger.RegisterSchemaDescriptions(schemaDescriptions{{ .StructName }})
+	{{- end }}
+}{{end}}
 `
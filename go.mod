@@ -18,6 +18,7 @@ require (
 	github.com/go-openapi/spec v0.19.3
 	github.com/go-xmlfmt/xmlfmt v0.0.0-20191208150333-d5b6f63a941b
 	github.com/gogo/protobuf v1.3.1 // indirect
+	github.com/gorilla/websocket v1.4.2
 	github.com/guptarohit/asciigraph v0.4.2-0.20190112130928-1bc9b2452856
 	github.com/hashicorp/go-hclog v0.15.0
 	github.com/hashicorp/go-plugin v1.4.0
@@ -107,7 +107,7 @@ func StartAutomatedFuzzer(list *views.ListWidget, settings *config.Settings, gui
 		if settings.NavigateToID != "" {
 			for {
 				<-time.After(time.Second * 1)
-				if !navigateToInProgress {
+				if !isNavigateToInProgress() {
 
 					// `-navigate` is finished, subscribe to nav events and get started
 					// by expanding the current item
@@ -2,63 +2,150 @@ package automation
 
 import (
 	"strings"
+	"sync"
 
+	"github.com/lawrencegripper/azbrowse/internal/pkg/errorhandling"
 	"github.com/lawrencegripper/azbrowse/internal/pkg/eventing"
 	"github.com/lawrencegripper/azbrowse/internal/pkg/expanders"
 	"github.com/lawrencegripper/azbrowse/internal/pkg/views"
 )
 
-var navigateToInProgress = true
+// navigateToCount is the number of navigations (from NavigateTo, RestoreTabs or `list.navigateto` events) in progress.
+// It is a count rather than a flag as the navigations can overlap, e.g. a `list.navigateto` event while restoring tabs
+var navigateToCount int
+var navigateToCountLock sync.Mutex
+
+func beginNavigateTo() {
+	navigateToCountLock.Lock()
+	defer navigateToCountLock.Unlock()
+	navigateToCount++
+}
+
+func endNavigateTo() {
+	navigateToCountLock.Lock()
+	defer navigateToCountLock.Unlock()
+	navigateToCount--
+}
+
+// isNavigateToInProgress returns true if any navigations are in progress
+func isNavigateToInProgress() bool {
+	navigateToCountLock.Lock()
+	defer navigateToCountLock.Unlock()
+	return navigateToCount > 0
+}
 
 // NavigateTo will navigate through the tree to a node with
 // a matching ItemID or as far as it can get
 func NavigateTo(list *views.ListWidget, itemID string) {
+	beginNavigateTo()
+	navigatedChannel := eventing.SubscribeToTopic("list.navigated")
+	go followNavigation(list, itemID, navigatedChannel, nil)
+}
 
-	navigateToIDLower := strings.ToLower(itemID)
+// NavigateToFromRoot returns the list to the root and then navigates through the tree
+// to a node with a matching ItemID or as far as it can get
+func NavigateToFromRoot(list *views.ListWidget, itemID string) {
+	navigatedChannel, node := startNavigateToFromRoot(list, itemID)
+	if node != nil {
+		go followNavigation(list, itemID, navigatedChannel, node)
+	}
+}
+
+// startNavigateToFromRoot returns the list to the root and expands the first node towards the item ID.
+// If there is a matching node then followNavigation should be called to continue the navigation
+func startNavigateToFromRoot(list *views.ListWidget, itemID string) (chan interface{}, *expanders.TreeNode) {
+	list.ResetToRoot()
+
+	beginNavigateTo()
+	navigatedChannel := eventing.SubscribeToTopic("list.navigated")
+	node := expandMatchingNode(list, itemID, list.GetNodes())
+	if node == nil {
+		eventing.Unsubscribe(navigatedChannel)
+		endNavigateTo()
+		list.SetShouldRender(true)
+		return nil, nil
+	}
+	return navigatedChannel, node
+}
+
+// RestoreTabs navigates to the first item ID (as per NavigateTo) and then opens a tab for each of the other
+// item IDs, finally selecting the current tab. This is used to restore the tabs from the last session
+func RestoreTabs(list *views.ListWidget, itemIDs []string, currentTab int) {
+	beginNavigateTo() // for the whole restore (the navigation for each tab is counted separately)
+	beginNavigateTo()
+	navigatedChannel := eventing.SubscribeToTopic("list.navigated")
 	go func() {
-		navigatedChannel := eventing.SubscribeToTopic("list.navigated")
-		var lastNavigatedNode *expanders.TreeNode
+		defer errorhandling.RecoveryWithCleanup()
+		defer endNavigateTo()
 
-		for {
-			navigateStateInterface := <-navigatedChannel
-
-			if navigateToInProgress {
-				navigateState := navigateStateInterface.(views.ListNavigatedEventState)
-				if !navigateState.Success {
-					// we got as far as we could - now stop!
-					navigateToInProgress = false
-					list.SetShouldRender(true)
-					continue
-				}
-				nodeList := navigateState.NewNodes
-
-				if lastNavigatedNode != nil && lastNavigatedNode != list.CurrentExpandedItem() {
-					navigateToInProgress = false
-					list.SetShouldRender(true)
-				} else {
-
-					gotNode := false
-					for nodeIndex, node := range nodeList {
-						// use prefix matching
-						// but need additional checks as target of /foo/bar would be matched by  /foo/bar  and /foo/ba
-						// additional check is that the lengths match, or the next char in target is a '/'
-						nodeIDLower := strings.ToLower(node.ID)
-						if strings.HasPrefix(navigateToIDLower, nodeIDLower) && (len(itemID) == len(nodeIDLower) || navigateToIDLower[len(nodeIDLower)] == '/') {
-							list.ChangeSelection(nodeIndex)
-							lastNavigatedNode = node
-							list.ExpandCurrentSelection()
-							gotNode = true
-							break
-						}
-					}
-
-					if !gotNode {
-						// we got as far as we could - now stop!
-						navigateToInProgress = false
-						list.SetShouldRender(true)
-					}
-				}
+		followNavigation(list, itemIDs[0], navigatedChannel, nil)
+		for _, itemID := range itemIDs[1:] {
+			list.AddTab()
+			if navigatedChannel, node := startNavigateToFromRoot(list, itemID); node != nil {
+				followNavigation(list, itemID, navigatedChannel, node)
 			}
 		}
+		list.SelectTab(currentTab)
 	}()
 }
+
+// HandleNavigateToEvents navigates to the item ID published on the `list.navigateto` topic in a new tab,
+// this allows expanders to link to other nodes in the tree while keeping the history of the current tab
+func HandleNavigateToEvents(list *views.ListWidget) {
+	navigateToChannel := eventing.SubscribeToTopic("list.navigateto")
+	go func() {
+		defer errorhandling.RecoveryWithCleanup()
+		for {
+			itemID := (<-navigateToChannel).(string)
+			list.AddTab()
+			NavigateToFromRoot(list, itemID)
+		}
+	}()
+}
+
+func followNavigation(list *views.ListWidget, itemID string, navigatedChannel chan interface{}, lastNavigatedNode *expanders.TreeNode) {
+	defer errorhandling.RecoveryWithCleanup()
+	defer eventing.Unsubscribe(navigatedChannel)
+	defer endNavigateTo()
+
+	for {
+		navigateStateInterface := <-navigatedChannel
+
+		navigateState := navigateStateInterface.(views.ListNavigatedEventState)
+		if !navigateState.Success {
+			// we got as far as we could - now stop!
+			list.SetShouldRender(true)
+			return
+		}
+		nodeList := navigateState.NewNodes
+
+		if lastNavigatedNode != nil && lastNavigatedNode != list.CurrentExpandedItem() {
+			list.SetShouldRender(true)
+			return
+		}
+
+		lastNavigatedNode = expandMatchingNode(list, itemID, nodeList)
+		if lastNavigatedNode == nil {
+			// we got as far as we could - now stop!
+			list.SetShouldRender(true)
+			return
+		}
+	}
+}
+
+// expandMatchingNode expands the node that is (or is an ancestor of) the item ID, returning nil if there isn't one
+func expandMatchingNode(list *views.ListWidget, itemID string, nodeList []*expanders.TreeNode) *expanders.TreeNode {
+	navigateToIDLower := strings.ToLower(itemID)
+	for nodeIndex, node := range nodeList {
+		// use prefix matching
+		// but need additional checks as target of /foo/bar would be matched by  /foo/bar  and /foo/ba
+		// additional check is that the lengths match, or the next char in target is a '/'
+		nodeIDLower := strings.ToLower(node.ID)
+		if strings.HasPrefix(navigateToIDLower, nodeIDLower) && (len(itemID) == len(nodeIDLower) || navigateToIDLower[len(nodeIDLower)] == '/') {
+			list.ChangeSelection(nodeIndex)
+			list.ExpandCurrentSelection()
+			return node
+		}
+	}
+	return nil
+}
@@ -2,9 +2,12 @@ package config
 
 import (
 	"encoding/json"
+	"fmt"
 	"io/ioutil"
 	"os"
 	"os/user"
+	"reflect"
+	"strings"
 )
 
 // Settings to enable different behavior on startup
@@ -12,6 +15,8 @@ type Settings struct {
 	EnableTracing         bool
 	HideGuids             bool
 	NavigateToID          string
+	NavigateToTabIDs      []string // the node IDs to open a tab for when resuming (see ListTabsState)
+	CurrentTab            int      // the index of the tab to select after opening NavigateToTabIDs
 	FuzzerEnabled         bool
 	FuzzerDurationMinutes int
 	TenantID              string // the tenant ID to get an access token for from `az account get-access-token`
@@ -23,6 +28,9 @@ type Settings struct {
 type Config struct {
 	KeyBindings map[string]interface{} `json:"keyBindings,omitempty"`
 	Editor      EditorConfig           `json:"editor,omitempty"`
+	Graph       GraphConfig            `json:"graph,omitempty"`
+	SwaggerAPIs []SwaggerAPIConfig     `json:"swaggerAPIs,omitempty"`
+	APIVersions map[string]string      `json:"apiVersions,omitempty"` // API versions to use in place of the most recent version, keyed on resource type (e.g. Microsoft.Web/sites)
 }
 
 // EditorConfig represents the user options for external editor
@@ -31,6 +39,36 @@ type EditorConfig struct {
 	TranslateFilePathForWSL bool          `json:"translateFilePathForWSL,omitEmpty"` //nolint:golint,staticcheck // WSL use only. True to translate the path to a Windows path (e.g. when running under WSL but using a Windows editor)
 	TempDir                 string        `json:"tempDir,omitempty"`                 // Specify the directory to use for temporary files for editing (defaults to OS temp dir)
 	RevertToStandardBuffer  bool          `json:"revertToStandardBuffer,omitempty"`  // Set to true to revert to standard buffer while editing (e.g. for terminal-based editors)
+	GotoLineArguments       []string      `json:"gotoLineArguments,omitempty"`       // Arguments to open the file at a line, with {file} and {line} placeholders (defaults are provided for code, vim, nano, emacs and micro)
+}
+
+// GraphConfig represents the user options for the MS Graph views
+type GraphConfig struct {
+	CredentialExpiryDays int `json:"credentialExpiryDays,omitempty"` // The number of days ahead to report expiring app secrets and certificates (defaults to 30)
+}
+
+// SwaggerAPIConfig represents a user-supplied set of swagger specs to browse beneath an ARM resource type
+type SwaggerAPIConfig struct {
+	Name              string                  `json:"name,omitempty"`              // The name of the node added to the ARM resource
+	SpecsDirectory    string                  `json:"specsDirectory,omitempty"`    // The directory containing the swagger specs (*.json, *.yaml)
+	ResourceType      string                  `json:"resourceType,omitempty"`      // The ARM resource type to attach the API to (e.g. Microsoft.Web/sites)
+	BaseURL           SwaggerAPIBaseURLConfig `json:"baseUrl,omitempty"`           // Where to get the base URL for requests from
+	Auth              SwaggerAPIAuthConfig    `json:"auth,omitempty"`              // How to authenticate requests
+	IncludeAPIVersion bool                    `json:"includeApiVersion,omitempty"` // Set to true to add the spec version as the api-version query string parameter
+}
+
+// SwaggerAPIBaseURLConfig represents the options for determining the base URL for a SwaggerAPIConfig
+type SwaggerAPIBaseURLConfig struct {
+	Fixed    string `json:"fixed,omitempty"`    // A fixed base URL
+	Property string `json:"property,omitempty"` // The path to a property in the ARM resource JSON (e.g. properties.defaultHostName)
+}
+
+// SwaggerAPIAuthConfig represents the options for authenticating requests for a SwaggerAPIConfig
+type SwaggerAPIAuthConfig struct {
+	Scheme     string `json:"scheme,omitempty"`     // none (default), aad or header
+	Resource   string `json:"resource,omitempty"`   // aad only. The resource to get an access token for from the Azure CLI
+	HeaderName string `json:"headerName,omitempty"` // header only. The name of the header to send
+	HeaderEnv  string `json:"headerEnv,omitempty"`  // header only. The environment variable containing the header value
 }
 
 // CommandConfig respresents the options for launching a command
@@ -39,10 +77,7 @@ type CommandConfig struct {
 	Arguments  []string `json:"args,omitempty"`       // The arguments to pass to the executable (filename will automatically be appended)
 }
 
-// Load the user configuration settings
-func Load() (Config, error) {
-	var config Config
-
+func getConfigLocation() string {
 	configLocation := os.Getenv("AZBROWSE_SETTINGS_PATH")
 	if configLocation == "" {
 		configLocation = "/root/.azbrowse-settings.json"
@@ -51,6 +86,14 @@ func Load() (Config, error) {
 			configLocation = user.HomeDir + "/.azbrowse-settings.json"
 		}
 	}
+	return configLocation
+}
+
+// Load the user configuration settings
+func Load() (Config, error) {
+	var config Config
+
+	configLocation := getConfigLocation()
 	_, err := os.Stat(configLocation)
 	if err != nil {
 		// don't error on no config file
@@ -68,6 +111,42 @@ func Load() (Config, error) {
 	return config, nil
 }
 
+// Save the user configuration settings. Top-level properties in the settings file that
+// aren't part of Config are preserved
+func Save(config Config) error {
+	configLocation := getConfigLocation()
+
+	settings := map[string]json.RawMessage{}
+	if existingBytes, err := ioutil.ReadFile(configLocation); err == nil {
+		if err := json.Unmarshal(existingBytes, &settings); err != nil {
+			return fmt.Errorf("Failed to parse existing settings file %q: %s", configLocation, err)
+		}
+	}
+
+	configBytes, err := json.Marshal(config)
+	if err != nil {
+		return err
+	}
+	configSettings := map[string]json.RawMessage{}
+	if err := json.Unmarshal(configBytes, &configSettings); err != nil {
+		return err
+	}
+	configType := reflect.TypeOf(config)
+	for i := 0; i < configType.NumField(); i++ {
+		name := strings.Split(configType.Field(i).Tag.Get("json"), ",")[0]
+		delete(settings, name) // remove properties that are now empty (and omitted)
+	}
+	for name, value := range configSettings {
+		settings[name] = value
+	}
+
+	bytes, err := json.MarshalIndent(settings, "", "  ")
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(configLocation, bytes, 0600)
+}
+
 var (
 	debuggingEnabled = false
 )
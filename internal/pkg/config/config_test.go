@@ -0,0 +1,57 @@
+package config
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func Test_Save_PreservesUnknownSettings(t *testing.T) {
+	settingsPath := filepath.Join(t.TempDir(), "settings.json")
+	os.Setenv("AZBROWSE_SETTINGS_PATH", settingsPath) //nolint:errcheck
+	defer os.Unsetenv("AZBROWSE_SETTINGS_PATH")       //nolint:errcheck
+
+	err := ioutil.WriteFile(settingsPath, []byte(`{"futureSetting": {"a": 1}, "apiVersions": {"Microsoft.Web/sites": "2020-01-01"}}`), 0600)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	config, err := Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if config.APIVersions["Microsoft.Web/sites"] != "2020-01-01" {
+		t.Errorf("Expected apiVersions to be loaded, got %v", config.APIVersions)
+	}
+
+	config.APIVersions = map[string]string{"Microsoft.Storage/storageAccounts": "2021-09-01-preview"}
+	if err = Save(config); err != nil {
+		t.Fatal(err)
+	}
+
+	config, err = Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(config.APIVersions) != 1 || config.APIVersions["Microsoft.Storage/storageAccounts"] != "2021-09-01-preview" {
+		t.Errorf("Expected saved apiVersions, got %v", config.APIVersions)
+	}
+
+	delete(config.APIVersions, "Microsoft.Storage/storageAccounts")
+	if err = Save(config); err != nil {
+		t.Fatal(err)
+	}
+	savedBytes, err := ioutil.ReadFile(settingsPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	saved := string(savedBytes)
+	if strings.Contains(saved, "apiVersions") {
+		t.Errorf("Expected empty apiVersions to be removed: %s", saved)
+	}
+	if !strings.Contains(saved, "futureSetting") {
+		t.Errorf("Expected unknown settings to be preserved: %s", saved)
+	}
+}
@@ -5,6 +5,9 @@ import (
 	"io/ioutil"
 	"os"
 	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
 
 	"github.com/awesome-gocui/gocui"
 	"github.com/lawrencegripper/azbrowse/internal/pkg/config"
@@ -13,6 +16,12 @@ import (
 
 // OpenForContent creates a temporary file with the specified content and opens the editor for it. Returned value is the contents after editing
 func OpenForContent(content string, fileExtension string) (string, error) {
+	return OpenForContentAtLine(content, fileExtension, 0)
+}
+
+// OpenForContentAtLine creates a temporary file with the specified content and opens the editor for it with the cursor at the specified line
+// (1-based, or 0 to not position the cursor). Returned value is the contents after editing
+func OpenForContentAtLine(content string, fileExtension string, line int) (string, error) {
 
 	editorConfig, err := getEditorConfig()
 	if err != nil {
@@ -54,7 +63,7 @@ func OpenForContent(content string, fileExtension string) (string, error) {
 		gocui.Suspend()
 	}
 
-	editorErr := openEditor(editorConfig.Command, editorTmpFile)
+	editorErr := openEditor(editorConfig, editorTmpFile, line)
 	if editorConfig.RevertToStandardBuffer {
 		// Init termbox to switch back to alternate buffer and Flush content
 		err := gocui.Resume()
@@ -77,10 +86,11 @@ func OpenForContent(content string, fileExtension string) (string, error) {
 
 }
 
-func openEditor(command config.CommandConfig, filename string) error {
+func openEditor(editorConfig config.EditorConfig, filename string, line int) error {
 	// TODO - handle no Executable configured
-	args := command.Arguments
-	args = append(args, filename)
+	command := editorConfig.Command
+	args := append([]string{}, command.Arguments...)
+	args = append(args, getFileArguments(editorConfig, filename, line)...)
 	cmd := exec.Command(command.Executable, args...)
 	cmd.Stdin = os.Stdin
 	cmd.Stdout = os.Stdout
@@ -88,6 +98,32 @@ func openEditor(command config.CommandConfig, filename string) error {
 	return cmd.Run()
 }
 
+// getFileArguments returns the arguments to open the file in the editor, positioning the cursor at the line if the editor supports it
+func getFileArguments(editorConfig config.EditorConfig, filename string, line int) []string {
+	if line <= 0 {
+		return []string{filename}
+	}
+	gotoLineArguments := editorConfig.GotoLineArguments
+	if len(gotoLineArguments) == 0 {
+		executable := strings.TrimSuffix(filepath.Base(editorConfig.Command.Executable), ".exe")
+		switch executable {
+		case "code", "code-insiders":
+			gotoLineArguments = []string{"--goto", "{file}:{line}"}
+		case "vi", "vim", "nvim", "nano", "emacs", "micro":
+			gotoLineArguments = []string{"+{line}", "{file}"}
+		default:
+			return []string{filename}
+		}
+	}
+	args := []string{}
+	for _, arg := range gotoLineArguments {
+		arg = strings.ReplaceAll(arg, "{file}", filename)
+		arg = strings.ReplaceAll(arg, "{line}", strconv.Itoa(line))
+		args = append(args, arg)
+	}
+	return args
+}
+
 func getEditorConfig() (config.EditorConfig, error) {
 	userConfig, err := config.Load()
 	if err != nil {
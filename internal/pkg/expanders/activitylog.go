@@ -3,18 +3,42 @@ package expanders
 import (
 	"context"
 	"encoding/json"
+	"fmt"
 	"net/url"
+	"sort"
+	"strings"
 	"time"
 
+	"github.com/awesome-gocui/gocui"
+	"github.com/lawrencegripper/azbrowse/internal/pkg/eventing"
 	"github.com/lawrencegripper/azbrowse/internal/pkg/interfaces"
 	"github.com/lawrencegripper/azbrowse/internal/pkg/style"
 	"github.com/lawrencegripper/azbrowse/pkg/armclient"
 )
 
-// ActivityLogExpander expands activity logs under an RG
+const (
+	activityLogCorrelationType  = "activityLog.correlation"
+	activityLogResourceLinkType = "activityLog.resourceLink"
+
+	activityLogActionFilter      = "activity-log-filter"
+	activityLogActionClearFilter = "activity-log-clear-filter"
+
+	activityLogDefaultTimespan = "7d"
+	activityLogMaxTimespan     = 90 * 24 * time.Hour
+	activityLogDefaultLevels   = "Critical,Error,Warning,Informational"
+	// activityLogMaxPages limits the number of pages read as events are grouped (and filtered) after reading
+	activityLogMaxPages = 10
+)
+
+// activityLogPromptTimeoutSeconds allows time for the user to respond to the filter prompts
+var activityLogPromptTimeoutSeconds = 300
+
+// ActivityLogExpander expands activity logs for a subscription, RG or resource
 type ActivityLogExpander struct {
 	ExpanderBase
-	client *armclient.Client
+	client       *armclient.Client
+	gui          *gocui.Gui
+	commandPanel interfaces.CommandPanel
 }
 
 func (e *ActivityLogExpander) setClient(c *armclient.Client) {
@@ -26,154 +50,608 @@ func (e *ActivityLogExpander) Name() string {
 	return "ActivityLogExpander"
 }
 
-// DoesExpand checks if this is an RG
+// DoesExpand checks if this is an activity log (or a resource, to add an activity log node)
 func (e *ActivityLogExpander) DoesExpand(ctx context.Context, currentItem *TreeNode) (bool, error) {
-	if currentItem.ItemType == activityLogType {
+	switch currentItem.ItemType {
+	case ResourceType, activityLogType, activityLogCorrelationType, activityLogResourceLinkType:
 		return true, nil
+	case subActivityLogType:
+		return currentItem.Metadata["ResourceID"] != "", nil
 	}
 	return false, nil
 }
 
-// Expand returns Resources in the RG
+// Expand returns the activity log events
 func (e *ActivityLogExpander) Expand(ctx context.Context, currentItem *TreeNode) ExpanderResult {
-	method := "GET"
-	data, err := e.client.DoRequest(ctx, method, currentItem.ExpandURL)
+	switch currentItem.ItemType {
+	case ResourceType:
+		return ExpanderResult{
+			Nodes:             []*TreeNode{newActivityLogNode(currentItem, "", currentItem.ID)},
+			SourceDescription: "ActivityLogExpander",
+			IsPrimaryResponse: false,
+		}
+	case activityLogCorrelationType:
+		return e.expandCorrelation(currentItem)
+	case subActivityLogType:
+		return ExpanderResult{
+			Nodes:             []*TreeNode{newActivityLogResourceLinkNode(currentItem, currentItem.Metadata["ResourceID"])},
+			SourceDescription: "ActivityLogExpander",
+			IsPrimaryResponse: false,
+		}
+	case activityLogResourceLinkType:
+		resourceID := currentItem.Metadata["ResourceID"]
+		// The list handles this event by opening a new tab and navigating from the root of the tree to the resource
+		eventing.Publish("list.navigateto", resourceID)
+		return ExpanderResult{
+			Response:          ExpanderResponse{Response: "Navigating to " + resourceID, ResponseType: interfaces.ResponsePlainText},
+			SourceDescription: "ActivityLogExpander",
+			IsPrimaryResponse: true,
+		}
+	}
+
+	return e.expandActivityLog(ctx, currentItem)
+}
+
+// newActivityLogNode creates the "Activity Log" node for a subscription, resource group or resource
+func newActivityLogNode(parent *TreeNode, resourceGroupName string, resourceID string) *TreeNode {
+	return &TreeNode{
+		Parentid:              parent.ID,
+		Namespace:             "None",
+		Display:               style.Subtle("[Microsoft.Insights]") + "\n  Activity Log",
+		Name:                  "Activity Log",
+		ID:                    parent.ID + "/<activitylog>",
+		ExpandURL:             ExpandURLNotSupported,
+		ItemType:              activityLogType,
+		DeleteURL:             "",
+		SubscriptionID:        parent.SubscriptionID,
+		SuppressSwaggerExpand: true,
+		SuppressGenericExpand: true,
+		Metadata: map[string]string{
+			"ResourceGroupName": resourceGroupName,
+			"ResourceID":        resourceID,
+			"Timespan":          activityLogDefaultTimespan,
+			"Levels":            activityLogDefaultLevels,
+		},
+	}
+}
+
+func newActivityLogResourceLinkNode(parent *TreeNode, resourceID string) *TreeNode {
+	return &TreeNode{
+		Parentid:              parent.ID,
+		Namespace:             "None",
+		Name:                  "Go to resource",
+		Display:               "Go to resource\n  " + style.Subtle(resourceID),
+		ID:                    parent.ID + "/<resource>",
+		ExpandURL:             ExpandURLNotSupported,
+		ItemType:              activityLogResourceLinkType,
+		SubscriptionID:        parent.SubscriptionID,
+		SuppressSwaggerExpand: true,
+		SuppressGenericExpand: true,
+		Metadata: map[string]string{
+			"ResourceID": resourceID,
+		},
+	}
+}
+
+// activityLogFilter is the filter applied to an activity log node. It is stored in the node metadata
+type activityLogFilter struct {
+	SubscriptionID    string
+	ResourceGroupName string
+	ResourceID        string
+	Timespan          string
+	Caller            string
+	Status            string
+	Operation         string
+	Levels            string
+}
+
+func activityLogFilterFromNode(node *TreeNode) activityLogFilter {
+	filter := activityLogFilter{
+		SubscriptionID:    node.SubscriptionID,
+		ResourceGroupName: node.Metadata["ResourceGroupName"],
+		ResourceID:        node.Metadata["ResourceID"],
+		Timespan:          node.Metadata["Timespan"],
+		Caller:            node.Metadata["Caller"],
+		Status:            node.Metadata["Status"],
+		Operation:         node.Metadata["Operation"],
+		Levels:            node.Metadata["Levels"],
+	}
+	if filter.Timespan == "" {
+		filter.Timespan = activityLogDefaultTimespan
+	}
+	if filter.Levels == "" {
+		filter.Levels = activityLogDefaultLevels
+	}
+	return filter
+}
+
+func (f activityLogFilter) applyToNode(node *TreeNode) {
+	node.Metadata["Timespan"] = f.Timespan
+	node.Metadata["Caller"] = f.Caller
+	node.Metadata["Status"] = f.Status
+	node.Metadata["Operation"] = f.Operation
+	node.Metadata["Levels"] = f.Levels
+}
+
+// description summarises the filter for display
+func (f activityLogFilter) description() string {
+	parts := []string{"last " + f.Timespan}
+	if f.Caller != "" {
+		parts = append(parts, "caller: "+f.Caller)
+	}
+	if f.Status != "" {
+		parts = append(parts, "status: "+f.Status)
+	}
+	if f.Operation != "" {
+		parts = append(parts, "operation: "+f.Operation)
+	}
+	if f.Levels != activityLogDefaultLevels {
+		parts = append(parts, "levels: "+f.Levels)
+	}
+	return strings.Join(parts, ", ")
+}
+
+// matches applies the caller, status and operation filters which are applied client-side
+func (f activityLogFilter) matches(event ActivityLogEvent) bool {
+	if f.Caller != "" && !strings.Contains(strings.ToLower(event.Caller), strings.ToLower(f.Caller)) {
+		return false
+	}
+	if f.Status != "" && !strings.EqualFold(event.Status.Value, f.Status) {
+		return false
+	}
+	if f.Operation != "" &&
+		!strings.Contains(strings.ToLower(event.OperationName.Value), strings.ToLower(f.Operation)) &&
+		!strings.Contains(strings.ToLower(event.OperationName.LocalizedValue), strings.ToLower(f.Operation)) {
+		return false
+	}
+	return true
+}
+
+// buildActivityLogURL gets the url which should be used to get the activity log for the filter
+func buildActivityLogURL(filter activityLogFilter, now time.Time) (string, error) {
+	duration, err := parseMetricsTimespan(filter.Timespan)
+	if err != nil {
+		return "", err
+	}
+	if duration > activityLogMaxTimespan {
+		return "", fmt.Errorf("Timespan %q is too long, the activity log is kept for 90 days", filter.Timespan)
+	}
+
+	queryString := `eventTimestamp ge '` + now.Add(-duration).Format("2006-01-02T15:04:05Z07:00") + `' and eventTimestamp le '` +
+		now.Format("2006-01-02T15:04:05Z07:00") + `' and eventChannels eq 'Admin, Operation'`
+	if filter.ResourceID != "" {
+		queryString += ` and resourceUri eq '` + filter.ResourceID + `'`
+	} else if filter.ResourceGroupName != "" {
+		queryString += ` and resourceGroupName eq '` + filter.ResourceGroupName + `'`
+	}
+	queryString += ` and levels eq '` + filter.Levels + `' | orderby eventTimestamp desc`
+	return `/subscriptions/` + filter.SubscriptionID + `/providers/microsoft.insights/eventtypes/management/values?api-version=2017-03-01-preview&$filter=` +
+		url.QueryEscape(queryString), nil
+}
+
+// activityLogEventWithJSON keeps the original JSON for an event to display
+type activityLogEventWithJSON struct {
+	Event ActivityLogEvent
+	JSON  json.RawMessage
+}
+
+// activityLogGroup is a set of events with the same correlationId
+type activityLogGroup struct {
+	CorrelationID string
+	Events        []activityLogEventWithJSON // newest first
+}
+
+// groupActivityLogEvents groups events by correlationId, ordering the groups by their most recent event
+func groupActivityLogEvents(events []activityLogEventWithJSON) []activityLogGroup {
+	groups := []activityLogGroup{}
+	groupIndex := map[string]int{}
+	for _, event := range events {
+		correlationID := event.Event.CorrelationID
+		if correlationID == "" {
+			correlationID = event.Event.EventDataID
+		}
+		index, ok := groupIndex[correlationID]
+		if !ok {
+			index = len(groups)
+			groupIndex[correlationID] = index
+			groups = append(groups, activityLogGroup{CorrelationID: correlationID})
+		}
+		groups[index].Events = append(groups[index].Events, event)
+	}
+	for _, group := range groups {
+		sort.SliceStable(group.Events, func(i, j int) bool {
+			return group.Events[i].Event.EventTimestamp.After(group.Events[j].Event.EventTimestamp)
+		})
+	}
+	sort.SliceStable(groups, func(i, j int) bool {
+		return groups[i].Events[0].Event.EventTimestamp.After(groups[j].Events[0].Event.EventTimestamp)
+	})
+	return groups
+}
+
+func (e *ActivityLogExpander) getEvents(ctx context.Context, filter activityLogFilter) ([]activityLogEventWithJSON, bool, error) {
+	requestURL, err := buildActivityLogURL(filter, time.Now().UTC())
+	if err != nil {
+		return nil, false, err
+	}
+
+	events := []activityLogEventWithJSON{}
+	for page := 0; page < activityLogMaxPages && requestURL != ""; page++ {
+		data, err := e.client.DoRequest(ctx, "GET", requestURL)
+		if err != nil {
+			return nil, false, err
+		}
+		var response struct {
+			Value    []json.RawMessage `json:"value"`
+			NextLink string            `json:"nextLink"`
+		}
+		if err := json.Unmarshal([]byte(data), &response); err != nil {
+			return nil, false, fmt.Errorf("Error unmarshalling activity log response: %s", err)
+		}
+		for _, raw := range response.Value {
+			var event ActivityLogEvent
+			if err := json.Unmarshal(raw, &event); err != nil {
+				return nil, false, fmt.Errorf("Error unmarshalling activity log event: %s", err)
+			}
+			if filter.matches(event) {
+				events = append(events, activityLogEventWithJSON{Event: event, JSON: raw})
+			}
+		}
+		requestURL = response.NextLink
+	}
+	// requestURL is only set if we stopped before reading all of the pages
+	return events, requestURL != "", nil
+}
+
+func (e *ActivityLogExpander) expandActivityLog(ctx context.Context, currentItem *TreeNode) ExpanderResult {
+	filter := activityLogFilterFromNode(currentItem)
+	events, truncated, err := e.getEvents(ctx, filter)
 	if err != nil {
 		return ExpanderResult{
 			Err:               err,
-			Response:          ExpanderResponse{Response: string(data), ResponseType: interfaces.ResponseJSON},
-			SourceDescription: "Activity Log",
+			SourceDescription: "ActivityLogExpander request",
 			IsPrimaryResponse: true,
 		}
 	}
+
 	newItems := []*TreeNode{}
+	for _, group := range groupActivityLogEvents(events) {
+		if len(group.Events) == 1 {
+			newItems = append(newItems, newActivityLogEventNode(currentItem, group.Events[0]))
+			continue
+		}
+		newItems = append(newItems, newActivityLogCorrelationNode(currentItem, group))
+	}
 
-	var activityLogs ActivityLogResource
-	err = json.Unmarshal([]byte(data), &activityLogs)
-	if err != nil {
-		panic(err)
+	summary := style.Title("Activity Log") + "\n\n" +
+		"Filter:       " + filter.description() + "\n" +
+		fmt.Sprintf("Events:       %d\n", len(events)) +
+		fmt.Sprintf("Operations:   %d (events grouped by correlationId)\n", len(newItems))
+	if truncated {
+		summary += style.Warning(fmt.Sprintf("\nOnly the first %d pages of events were read, narrow the time window to see older events\n", activityLogMaxPages))
 	}
+	summary += style.Subtle("\nUse the actions for this node to change the filter\n")
 
-	value, err := fastJSONParser.Parse(data)
-	if err != nil {
-		panic(err)
+	return ExpanderResult{
+		Response:          ExpanderResponse{Response: summary, ResponseType: interfaces.ResponsePlainText},
+		SourceDescription: "ActivityLogExpander request",
+		Nodes:             newItems,
+		IsPrimaryResponse: true,
+	}
+}
+
+func newActivityLogEventNode(parent *TreeNode, event activityLogEventWithJSON) *TreeNode {
+	log := event.Event
+	return &TreeNode{
+		Name: log.OperationName.Value,
+		Display: log.OperationName.LocalizedValue + "\n   " +
+			style.Subtle("At:  "+log.EventTimestamp.String()) + "\n   " +
+			style.Subtle("ResourceType: "+log.ResourceType.Value) + "\n   " +
+			style.Subtle("Status: "+log.Status.Value+"  Level: "+log.Level) + "\n   " +
+			style.Subtle("Caller: "+log.Caller),
+		ID:                    log.ID,
+		Parentid:              parent.ID,
+		ExpandURL:             ExpandURLNotSupported,
+		ItemType:              subActivityLogType,
+		SubscriptionID:        parent.SubscriptionID,
+		StatusIndicator:       DrawStatus(log.Status.Value),
+		SuppressSwaggerExpand: true,
+		SuppressGenericExpand: true,
+		Metadata: map[string]string{
+			"jsonItem":   string(event.JSON),
+			"ResourceID": log.ResourceID,
+		},
 	}
+}
 
-	for i, log := range activityLogs.Value {
-		if len(value.GetArray("value"))-1 < i {
-			break
+func newActivityLogCorrelationNode(parent *TreeNode, group activityLogGroup) *TreeNode {
+	latest := group.Events[0].Event
+	first := group.Events[len(group.Events)-1].Event
+
+	// Store the original JSON for the events so they can be shown when the node is expanded
+	rawEvents := []json.RawMessage{}
+	for _, event := range group.Events {
+		rawEvents = append(rawEvents, event.JSON)
+	}
+	eventsJSON, _ := json.Marshal(rawEvents)
+	return &TreeNode{
+		Name: first.OperationName.Value,
+		Display: first.OperationName.LocalizedValue + "\n   " +
+			style.Subtle("At:  "+first.EventTimestamp.String()) + "\n   " +
+			style.Subtle("ResourceType: "+first.ResourceType.Value) + "\n   " +
+			style.Subtle(fmt.Sprintf("Status: %s  Events: %d", latest.Status.Value, len(group.Events))) + "\n   " +
+			style.Subtle("Caller: "+first.Caller),
+		ID:                    parent.ID + "/<correlation>/" + group.CorrelationID,
+		Parentid:              parent.ID,
+		ExpandURL:             ExpandURLNotSupported,
+		ItemType:              activityLogCorrelationType,
+		SubscriptionID:        parent.SubscriptionID,
+		StatusIndicator:       DrawStatus(latest.Status.Value),
+		SuppressSwaggerExpand: true,
+		SuppressGenericExpand: true,
+		Metadata: map[string]string{
+			"CorrelationID": group.CorrelationID,
+			"Events":        string(eventsJSON),
+		},
+	}
+}
+
+func (e *ActivityLogExpander) expandCorrelation(currentItem *TreeNode) ExpanderResult {
+	var rawEvents []json.RawMessage
+	if err := json.Unmarshal([]byte(currentItem.Metadata["Events"]), &rawEvents); err != nil {
+		return ExpanderResult{
+			Err:               fmt.Errorf("Error unmarshalling correlated events: %s", err),
+			SourceDescription: "ActivityLogExpander request",
+			IsPrimaryResponse: true,
 		}
-		// Update the existing state as we have more up-to-date info
-		objectJSON := string(value.GetArray("value")[i].MarshalTo([]byte("")))
+	}
 
-		newItems = append(newItems, &TreeNode{
-			Name:            log.OperationName.Value,
-			Display:         log.OperationName.LocalizedValue + "\n   " + style.Subtle("At:  "+log.EventTimestamp.String()) + "\n   " + style.Subtle("ResourceType: "+log.ResourceType.Value) + "\n   " + style.Subtle("Status: "+log.Status.Value+""),
-			ID:              log.ID,
-			Parentid:        currentItem.ID,
-			ExpandURL:       ExpandURLNotSupported,
-			ItemType:        subActivityLogType,
-			SubscriptionID:  currentItem.SubscriptionID,
-			StatusIndicator: DrawStatus(log.Status.Value),
-			Metadata: map[string]string{
-				"jsonItem": objectJSON,
-			},
-		})
+	newItems := []*TreeNode{}
+	for _, raw := range rawEvents {
+		var event ActivityLogEvent
+		if err := json.Unmarshal(raw, &event); err != nil {
+			return ExpanderResult{
+				Err:               fmt.Errorf("Error unmarshalling activity log event: %s", err),
+				SourceDescription: "ActivityLogExpander request",
+				IsPrimaryResponse: true,
+			}
+		}
+		newItems = append(newItems, newActivityLogEventNode(currentItem, activityLogEventWithJSON{Event: event, JSON: raw}))
 	}
 
 	return ExpanderResult{
-		Err:               err,
-		Response:          ExpanderResponse{Response: string(data), ResponseType: interfaces.ResponseJSON},
-		SourceDescription: "Deployments request",
+		Response:          ExpanderResponse{Response: currentItem.Metadata["Events"], ResponseType: interfaces.ResponseJSON},
+		SourceDescription: "ActivityLogExpander request",
 		Nodes:             newItems,
 		IsPrimaryResponse: true,
 	}
 }
 
-// GetActivityLogExpandURL gets the urls which should be used to get activity logs
-func GetActivityLogExpandURL(subscriptionID, resourceName string) string {
-	queryString := `eventTimestamp ge '` + time.Now().AddDate(0, 0, -30).Format("2006-01-02T15:04:05Z07:00") + `' and eventTimestamp le '` +
-		time.Now().Format("2006-01-02T15:04:05Z07:00") + `' and eventChannels eq 'Admin, Operation' and resourceGroupName eq '` +
-		resourceName + `' and levels eq 'Critical,Error,Warning,Informational' | orderby eventTimestamp desc`
-	return `/subscriptions/` + subscriptionID + `/providers/microsoft.insights/eventtypes/management/values?api-version=2017-03-01-preview&$filter=` +
-		url.QueryEscape(queryString)
+// HasActions returns true for activity log nodes
+func (e *ActivityLogExpander) HasActions(ctx context.Context, item *TreeNode) (bool, error) {
+	return item.ItemType == activityLogType, nil
+}
+
+// ListActions returns the actions to filter the activity log
+func (e *ActivityLogExpander) ListActions(ctx context.Context, item *TreeNode) ListActionsResult {
+	newAction := func(actionID string, name string, timeoutOverride *int) *TreeNode {
+		return &TreeNode{
+			Parentid:               item.ID,
+			ID:                     item.ID + "?" + actionID,
+			Name:                   name,
+			Display:                name,
+			ItemType:               ActionType,
+			SuppressGenericExpand:  true,
+			TimeoutOverrideSeconds: timeoutOverride,
+			Metadata: map[string]string{
+				"ActionID": actionID,
+			},
+		}
+	}
+
+	return ListActionsResult{
+		Nodes: []*TreeNode{
+			newAction(activityLogActionFilter, "Filter activity log", &activityLogPromptTimeoutSeconds),
+			newAction(activityLogActionClearFilter, "Clear activity log filter", nil),
+		},
+		SourceDescription: "ActivityLogExpander",
+		IsPrimaryResponse: true,
+	}
+}
+
+// ExecuteAction updates the filter on the activity log and shows the filtered events
+func (e *ActivityLogExpander) ExecuteAction(ctx context.Context, item *TreeNode) ExpanderResult {
+	actionID := item.Metadata["ActionID"]
+
+	if item.Parent == nil {
+		return ExpanderResult{
+			SourceDescription: "ActivityLogExpander",
+			IsPrimaryResponse: true,
+			Err:               fmt.Errorf("Activity log not set on action: %q", item.ID),
+		}
+	}
+
+	filter := activityLogFilterFromNode(item.Parent)
+	switch actionID {
+	case activityLogActionFilter:
+		if err := e.promptForFilter(ctx, &filter); err != nil {
+			return ExpanderResult{
+				SourceDescription: "ActivityLogExpander",
+				IsPrimaryResponse: true,
+				Err:               err,
+			}
+		}
+	case activityLogActionClearFilter:
+		filter = activityLogFilter{
+			SubscriptionID:    filter.SubscriptionID,
+			ResourceGroupName: filter.ResourceGroupName,
+			ResourceID:        filter.ResourceID,
+			Timespan:          activityLogDefaultTimespan,
+			Levels:            activityLogDefaultLevels,
+		}
+	case "":
+		return ExpanderResult{
+			SourceDescription: "ActivityLogExpander",
+			IsPrimaryResponse: true,
+			Err:               fmt.Errorf("ActionID metadata not set: %q", item.ID),
+		}
+	default:
+		return ExpanderResult{
+			SourceDescription: "ActivityLogExpander",
+			IsPrimaryResponse: true,
+			Err:               fmt.Errorf("Unhandled ActionID: %q", actionID),
+		}
+	}
+
+	filter.applyToNode(item.Parent)
+	return e.expandActivityLog(ctx, item.Parent)
+}
+
+func (e *ActivityLogExpander) promptForFilter(ctx context.Context, filter *activityLogFilter) error {
+	timespan, err := promptForInput(ctx, e.gui, e.commandPanel, "Time window (e.g. 30m, 12h, 7d - max 90d)", filter.Timespan)
+	if err != nil {
+		return err
+	}
+	timespan = strings.TrimSpace(timespan)
+	if _, err := buildActivityLogURL(activityLogFilter{Timespan: timespan, Levels: activityLogDefaultLevels}, time.Now()); err != nil {
+		return err
+	}
+
+	caller, err := promptForInput(ctx, e.gui, e.commandPanel, "Caller contains (empty for any)", filter.Caller)
+	if err != nil {
+		return err
+	}
+	status, err := promptForInput(ctx, e.gui, e.commandPanel, "Status (e.g. Succeeded, Failed, Started - empty for any)", filter.Status)
+	if err != nil {
+		return err
+	}
+	operation, err := promptForInput(ctx, e.gui, e.commandPanel, "Operation name contains (empty for any)", filter.Operation)
+	if err != nil {
+		return err
+	}
+	levels, err := promptForInput(ctx, e.gui, e.commandPanel, "Levels ("+activityLogDefaultLevels+")", filter.Levels)
+	if err != nil {
+		return err
+	}
+	levels, err = normalizeActivityLogLevels(levels)
+	if err != nil {
+		return err
+	}
+
+	filter.Timespan = timespan
+	filter.Caller = strings.TrimSpace(caller)
+	filter.Status = strings.TrimSpace(status)
+	filter.Operation = strings.TrimSpace(operation)
+	filter.Levels = levels
+	return nil
 }
 
-// ActivityLogResource is returned when requesting activity logs for an RG
+// normalizeActivityLogLevels validates a comma separated list of levels, an empty list selects all levels
+func normalizeActivityLogLevels(input string) (string, error) {
+	validLevels := strings.Split(activityLogDefaultLevels, ",")
+	levels := []string{}
+	for _, level := range strings.Split(input, ",") {
+		level = strings.TrimSpace(level)
+		if level == "" {
+			continue
+		}
+		found := false
+		for _, validLevel := range validLevels {
+			if strings.EqualFold(validLevel, level) {
+				levels = append(levels, validLevel)
+				found = true
+				break
+			}
+		}
+		if !found {
+			return "", fmt.Errorf("Unknown level %q, expected one of %s", level, activityLogDefaultLevels)
+		}
+	}
+	if len(levels) == 0 {
+		return activityLogDefaultLevels, nil
+	}
+	return strings.Join(levels, ","), nil
+}
+
+// ActivityLogResource is returned when requesting activity logs
 type ActivityLogResource struct {
-	Value []struct {
-		Authorization struct {
-			Action string `json:"action"`
-			Scope  string `json:"scope"`
-		} `json:"authorization"`
-		Caller   string `json:"caller"`
-		Channels string `json:"channels"`
-		Claims   struct {
-			Aud                                                       string `json:"aud"`
-			Iss                                                       string `json:"iss"`
-			Iat                                                       string `json:"iat"`
-			Nbf                                                       string `json:"nbf"`
-			Exp                                                       string `json:"exp"`
-			Aio                                                       string `json:"aio"`
-			Appid                                                     string `json:"appid"`
-			Appidacr                                                  string `json:"appidacr"`
-			HTTPSchemasMicrosoftComIdentityClaimsIdentityprovider     string `json:"http://schemas.microsoft.com/identity/claims/identityprovider"`
-			HTTPSchemasMicrosoftComIdentityClaimsObjectidentifier     string `json:"http://schemas.microsoft.com/identity/claims/objectidentifier"`
-			HTTPSchemasXmlsoapOrgWs200505IdentityClaimsNameidentifier string `json:"http://schemas.xmlsoap.org/ws/2005/05/identity/claims/nameidentifier"`
-			HTTPSchemasMicrosoftComIdentityClaimsTenantid             string `json:"http://schemas.microsoft.com/identity/claims/tenantid"`
-			Uti                                                       string `json:"uti"`
-			Ver                                                       string `json:"ver"`
-		} `json:"claims"`
-		CorrelationID string `json:"correlationId"`
-		Description   string `json:"description"`
-		EventDataID   string `json:"eventDataId"`
-		EventName     struct {
-			Value          string `json:"value"`
-			LocalizedValue string `json:"localizedValue"`
-		} `json:"eventName"`
-		Category struct {
-			Value          string `json:"value"`
-			LocalizedValue string `json:"localizedValue"`
-		} `json:"category"`
-		ID                   string `json:"id"`
-		Level                string `json:"level"`
-		ResourceGroupName    string `json:"resourceGroupName"`
-		ResourceProviderName struct {
-			Value          string `json:"value"`
-			LocalizedValue string `json:"localizedValue"`
-		} `json:"resourceProviderName"`
-		ResourceID   string `json:"resourceId"`
-		ResourceType struct {
-			Value          string `json:"value"`
-			LocalizedValue string `json:"localizedValue"`
-		} `json:"resourceType"`
-		OperationID   string `json:"operationId"`
-		OperationName struct {
-			Value          string `json:"value"`
-			LocalizedValue string `json:"localizedValue"`
-		} `json:"operationName"`
-		Properties struct {
-			IsComplianceCheck string `json:"isComplianceCheck"`
-			ResourceLocation  string `json:"resourceLocation"`
-			Ancestors         string `json:"ancestors"`
-			Policies          string `json:"policies"`
-		} `json:"properties,omitempty"`
-		Status struct {
-			Value          string `json:"value"`
-			LocalizedValue string `json:"localizedValue"`
-		} `json:"status"`
-		SubStatus struct {
-			Value          string `json:"value"`
-			LocalizedValue string `json:"localizedValue"`
-		} `json:"subStatus"`
-		EventTimestamp      time.Time `json:"eventTimestamp"`
-		SubmissionTimestamp time.Time `json:"submissionTimestamp"`
-		SubscriptionID      string    `json:"subscriptionId"`
-		TenantID            string    `json:"tenantId"`
-		HTTPRequest         struct {
-			ClientRequestID string `json:"clientRequestId"`
-			ClientIPAddress string `json:"clientIpAddress"`
-			Method          string `json:"method"`
-		} `json:"httpRequest,omitempty"`
-	} `json:"value"`
+	Value    []ActivityLogEvent `json:"value"`
+	NextLink string             `json:"nextLink"`
+}
+
+// ActivityLogEvent is a single event in the activity log
+type ActivityLogEvent struct {
+	Authorization struct {
+		Action string `json:"action"`
+		Scope  string `json:"scope"`
+	} `json:"authorization"`
+	Caller   string `json:"caller"`
+	Channels string `json:"channels"`
+	Claims   struct {
+		Aud                                                       string `json:"aud"`
+		Iss                                                       string `json:"iss"`
+		Iat                                                       string `json:"iat"`
+		Nbf                                                       string `json:"nbf"`
+		Exp                                                       string `json:"exp"`
+		Aio                                                       string `json:"aio"`
+		Appid                                                     string `json:"appid"`
+		Appidacr                                                  string `json:"appidacr"`
+		HTTPSchemasMicrosoftComIdentityClaimsIdentityprovider     string `json:"http://schemas.microsoft.com/identity/claims/identityprovider"`
+		HTTPSchemasMicrosoftComIdentityClaimsObjectidentifier     string `json:"http://schemas.microsoft.com/identity/claims/objectidentifier"`
+		HTTPSchemasXmlsoapOrgWs200505IdentityClaimsNameidentifier string `json:"http://schemas.xmlsoap.org/ws/2005/05/identity/claims/nameidentifier"`
+		HTTPSchemasMicrosoftComIdentityClaimsTenantid             string `json:"http://schemas.microsoft.com/identity/claims/tenantid"`
+		Uti                                                       string `json:"uti"`
+		Ver                                                       string `json:"ver"`
+	} `json:"claims"`
+	CorrelationID string `json:"correlationId"`
+	Description   string `json:"description"`
+	EventDataID   string `json:"eventDataId"`
+	EventName     struct {
+		Value          string `json:"value"`
+		LocalizedValue string `json:"localizedValue"`
+	} `json:"eventName"`
+	Category struct {
+		Value          string `json:"value"`
+		LocalizedValue string `json:"localizedValue"`
+	} `json:"category"`
+	ID                   string `json:"id"`
+	Level                string `json:"level"`
+	ResourceGroupName    string `json:"resourceGroupName"`
+	ResourceProviderName struct {
+		Value          string `json:"value"`
+		LocalizedValue string `json:"localizedValue"`
+	} `json:"resourceProviderName"`
+	ResourceID   string `json:"resourceId"`
+	ResourceType struct {
+		Value          string `json:"value"`
+		LocalizedValue string `json:"localizedValue"`
+	} `json:"resourceType"`
+	OperationID   string `json:"operationId"`
+	OperationName struct {
+		Value          string `json:"value"`
+		LocalizedValue string `json:"localizedValue"`
+	} `json:"operationName"`
+	Properties struct {
+		IsComplianceCheck string `json:"isComplianceCheck"`
+		ResourceLocation  string `json:"resourceLocation"`
+		Ancestors         string `json:"ancestors"`
+		Policies          string `json:"policies"`
+	} `json:"properties,omitempty"`
+	Status struct {
+		Value          string `json:"value"`
+		LocalizedValue string `json:"localizedValue"`
+	} `json:"status"`
+	SubStatus struct {
+		Value          string `json:"value"`
+		LocalizedValue string `json:"localizedValue"`
+	} `json:"subStatus"`
+	EventTimestamp      time.Time `json:"eventTimestamp"`
+	SubmissionTimestamp time.Time `json:"submissionTimestamp"`
+	SubscriptionID      string    `json:"subscriptionId"`
+	TenantID            string    `json:"tenantId"`
+	HTTPRequest         struct {
+		ClientRequestID string `json:"clientRequestId"`
+		ClientIPAddress string `json:"clientIpAddress"`
+		Method          string `json:"method"`
+	} `json:"httpRequest,omitempty"`
 }
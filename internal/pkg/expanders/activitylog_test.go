@@ -0,0 +1,80 @@
+package expanders
+
+import (
+	"testing"
+	"time"
+
+	"github.com/nbio/st"
+)
+
+func newTestActivityLogEvent(correlationID string, operation string, timestamp string) activityLogEventWithJSON {
+	event := ActivityLogEvent{CorrelationID: correlationID, Caller: "someone@example.com"}
+	event.OperationName.Value = operation
+	event.Status.Value = "Succeeded"
+	event.EventTimestamp, _ = time.Parse(time.RFC3339, timestamp)
+	return activityLogEventWithJSON{Event: event, JSON: []byte("{}")}
+}
+
+func Test_ActivityLog_GroupEventsByCorrelationID(t *testing.T) {
+	events := []activityLogEventWithJSON{
+		newTestActivityLogEvent("a", "write", "2020-01-01T10:00:00Z"),
+		newTestActivityLogEvent("b", "delete", "2020-01-01T12:00:00Z"),
+		newTestActivityLogEvent("a", "write", "2020-01-01T11:00:00Z"),
+	}
+
+	groups := groupActivityLogEvents(events)
+
+	st.Expect(t, len(groups), 2)
+	st.Expect(t, groups[0].CorrelationID, "b")
+	st.Expect(t, groups[1].CorrelationID, "a")
+	st.Expect(t, len(groups[1].Events), 2)
+	// newest event first
+	st.Expect(t, groups[1].Events[0].Event.EventTimestamp.Hour(), 11)
+}
+
+func Test_ActivityLog_FilterMatches(t *testing.T) {
+	event := newTestActivityLogEvent("a", "Microsoft.Compute/virtualMachines/write", "2020-01-01T10:00:00Z").Event
+
+	st.Expect(t, activityLogFilter{}.matches(event), true)
+	st.Expect(t, activityLogFilter{Caller: "SOMEONE"}.matches(event), true)
+	st.Expect(t, activityLogFilter{Caller: "other"}.matches(event), false)
+	st.Expect(t, activityLogFilter{Status: "succeeded"}.matches(event), true)
+	st.Expect(t, activityLogFilter{Status: "Failed"}.matches(event), false)
+	st.Expect(t, activityLogFilter{Operation: "virtualmachines/write"}.matches(event), true)
+	st.Expect(t, activityLogFilter{Operation: "delete"}.matches(event), false)
+}
+
+func Test_ActivityLog_BuildURL(t *testing.T) {
+	now, _ := time.Parse(time.RFC3339, "2020-06-01T12:00:00Z")
+	filter := activityLogFilter{
+		SubscriptionID: "1",
+		ResourceID:     "/subscriptions/1/resourceGroups/rg/providers/Microsoft.Storage/storageAccounts/sa",
+		Timespan:       "1d",
+		Levels:         "Error",
+	}
+
+	activityLogURL, err := buildActivityLogURL(filter, now)
+
+	st.Expect(t, err, nil)
+	st.Expect(t, activityLogURL, "/subscriptions/1/providers/microsoft.insights/eventtypes/management/values?api-version=2017-03-01-preview&$filter="+
+		"eventTimestamp+ge+%272020-05-31T12%3A00%3A00Z%27+and+eventTimestamp+le+%272020-06-01T12%3A00%3A00Z%27+and+eventChannels+eq+%27Admin%2C+Operation%27"+
+		"+and+resourceUri+eq+%27%2Fsubscriptions%2F1%2FresourceGroups%2Frg%2Fproviders%2FMicrosoft.Storage%2FstorageAccounts%2Fsa%27"+
+		"+and+levels+eq+%27Error%27+%7C+orderby+eventTimestamp+desc")
+
+	filter.Timespan = "91d"
+	_, err = buildActivityLogURL(filter, now)
+	st.Reject(t, err, nil)
+}
+
+func Test_ActivityLog_NormalizeLevels(t *testing.T) {
+	levels, err := normalizeActivityLogLevels(" error, warning ")
+	st.Expect(t, err, nil)
+	st.Expect(t, levels, "Error,Warning")
+
+	levels, err = normalizeActivityLogLevels("")
+	st.Expect(t, err, nil)
+	st.Expect(t, levels, activityLogDefaultLevels)
+
+	_, err = normalizeActivityLogLevels("verbose-ish")
+	st.Reject(t, err, nil)
+}
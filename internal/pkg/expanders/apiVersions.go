@@ -0,0 +1,264 @@
+package expanders
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/awesome-gocui/gocui"
+	"github.com/lawrencegripper/azbrowse/internal/pkg/config"
+	"github.com/lawrencegripper/azbrowse/internal/pkg/interfaces"
+	"github.com/lawrencegripper/azbrowse/pkg/armclient"
+)
+
+const (
+	apiVersionsActionID = "ChooseAPIVersion"
+
+	// apiVersionsLatestOptionID is the option for removing the override (uses angle brackets to avoid clashing with API versions)
+	apiVersionsLatestOptionID = "<latest>"
+)
+
+// Check interface
+var _ Expander = &APIVersionsExpander{}
+
+// APIVersionsExpander provides an action to choose the API version used for a resource type.
+// The chosen versions are persisted in the config and used by armclient.GetAPIVersion
+type APIVersionsExpander struct {
+	ExpanderBase
+	client       *armclient.Client
+	gui          *gocui.Gui
+	commandPanel interfaces.CommandPanel
+}
+
+// NewAPIVersionsExpander creates a new APIVersionsExpander, applying the API version overrides from the config
+func NewAPIVersionsExpander(client *armclient.Client, gui *gocui.Gui, commandPanel interfaces.CommandPanel) *APIVersionsExpander {
+	userConfig, err := config.Load()
+	if err == nil {
+		armclient.SetAPIVersionOverrides(userConfig.APIVersions)
+	}
+	return &APIVersionsExpander{
+		client:       client,
+		gui:          gui,
+		commandPanel: commandPanel,
+	}
+}
+
+func (e *APIVersionsExpander) setClient(c *armclient.Client) {
+	e.client = c
+}
+
+// Name returns the name of the expander
+func (e *APIVersionsExpander) Name() string {
+	return "APIVersionsExpander"
+}
+
+// DoesExpand checks if this is an item to expand
+func (e *APIVersionsExpander) DoesExpand(ctx context.Context, currentItem *TreeNode) (bool, error) {
+	return false, nil
+}
+
+// Expand returns nodes for the item
+func (e *APIVersionsExpander) Expand(ctx context.Context, currentItem *TreeNode) ExpanderResult {
+	return ExpanderResult{
+		Err:               fmt.Errorf("APIVersionsExpander doesn't expand items"),
+		SourceDescription: "APIVersionsExpander",
+	}
+}
+
+// HasActions returns true for resources with an ARM type and for swagger sub-resources under an ARM provider
+func (e *APIVersionsExpander) HasActions(ctx context.Context, item *TreeNode) (bool, error) {
+	if !strings.Contains(item.ExpandURL, "api-version=") {
+		return false, nil
+	}
+	switch item.ItemType {
+	case ResourceType:
+		return item.ArmType != "", nil
+	case SubResourceType:
+		return item.SwaggerResourceType != nil && getAPIVersionArmType(item) != "", nil
+	}
+	return false, nil
+}
+
+// ListActions returns the action to choose the API version
+func (e *APIVersionsExpander) ListActions(ctx context.Context, item *TreeNode) ListActionsResult {
+	return ListActionsResult{
+		Nodes: []*TreeNode{
+			{
+				Parentid:              item.ID,
+				ID:                    item.ID + "?" + apiVersionsActionID,
+				Namespace:             "apiversions",
+				Name:                  "Choose API version...",
+				Display:               "Choose API version...",
+				ItemType:              ActionType,
+				ArmType:               getAPIVersionArmType(item),
+				SuppressSwaggerExpand: true,
+				SuppressGenericExpand: true,
+				Metadata: map[string]string{
+					"ActionID":          apiVersionsActionID,
+					"ResourceExpandURL": item.ExpandURL,
+				},
+			},
+		},
+		SourceDescription: "APIVersionsExpander",
+	}
+}
+
+// ExecuteAction prompts for the API version, saves it as the override for the resource type and re-fetches the resource
+func (e *APIVersionsExpander) ExecuteAction(ctx context.Context, currentItem *TreeNode) ExpanderResult {
+	actionID := currentItem.Metadata["ActionID"]
+	if actionID != apiVersionsActionID {
+		return ExpanderResult{
+			Err:               fmt.Errorf("Unhandled ActionID: %q", actionID),
+			SourceDescription: "APIVersionsExpander",
+			IsPrimaryResponse: true,
+		}
+	}
+	armType := currentItem.ArmType
+
+	apiVersions, err := e.client.GetAPIVersions(ctx, armType)
+	if err != nil {
+		return ExpanderResult{
+			Err:               err,
+			SourceDescription: "APIVersionsExpander",
+			IsPrimaryResponse: true,
+		}
+	}
+	currentAPIVersion, _ := armclient.GetAPIVersion(armType)
+	options := []interfaces.CommandPanelListOption{
+		{ID: apiVersionsLatestOptionID, DisplayText: "Use the most recent version (remove override)"},
+	}
+	for _, apiVersion := range apiVersions {
+		displayText := apiVersion
+		if apiVersion == currentAPIVersion {
+			displayText += " (current)"
+		}
+		options = append(options, interfaces.CommandPanelListOption{ID: apiVersion, DisplayText: displayText})
+	}
+
+	selectedID, err := promptForOption(ctx, e.gui, e.commandPanel, "API version for "+armType+":", options)
+	if err != nil {
+		return ExpanderResult{
+			Err:               err,
+			SourceDescription: "APIVersionsExpander",
+			IsPrimaryResponse: true,
+		}
+	}
+	if selectedID == "" {
+		return ExpanderResult{
+			Err:               fmt.Errorf("User canceled"),
+			SourceDescription: "APIVersionsExpander",
+			IsPrimaryResponse: true,
+		}
+	}
+
+	overrideAPIVersion := selectedID
+	if selectedID == apiVersionsLatestOptionID {
+		overrideAPIVersion = ""
+	}
+	if err = saveAPIVersionOverride(armType, overrideAPIVersion); err != nil {
+		return ExpanderResult{
+			Err:               fmt.Errorf("Failed to save API version to config: %s", err),
+			SourceDescription: "APIVersionsExpander",
+			IsPrimaryResponse: true,
+		}
+	}
+	armclient.SetAPIVersionOverride(armType, overrideAPIVersion)
+
+	apiVersion, err := armclient.GetAPIVersion(armType)
+	if err != nil {
+		return ExpanderResult{
+			Err:               err,
+			SourceDescription: "APIVersionsExpander",
+			IsPrimaryResponse: true,
+		}
+	}
+	expandURL, err := setAPIVersionInURL(currentItem.Metadata["ResourceExpandURL"], apiVersion)
+	if err != nil {
+		return ExpanderResult{
+			Err:               err,
+			SourceDescription: "APIVersionsExpander",
+			IsPrimaryResponse: true,
+		}
+	}
+	if currentItem.Parent != nil {
+		currentItem.Parent.ExpandURL = expandURL // so that refreshing the resource uses the new version
+	}
+
+	data, err := e.client.DoRequest(ctx, "GET", expandURL)
+	if err != nil {
+		return ExpanderResult{
+			Err:               fmt.Errorf("Failed to get resource with API version %q: %s", apiVersion, err),
+			Response:          ExpanderResponse{Response: data, ResponseType: interfaces.ResponseJSON},
+			SourceDescription: "APIVersionsExpander",
+			IsPrimaryResponse: true,
+		}
+	}
+	return ExpanderResult{
+		Response:          ExpanderResponse{Response: data, ResponseType: interfaces.ResponseJSON},
+		SourceDescription: "APIVersionsExpander",
+		IsPrimaryResponse: true,
+	}
+}
+
+// saveAPIVersionOverride saves the API version for the resource type in the config. An empty apiVersion removes the override
+func saveAPIVersionOverride(armType string, apiVersion string) error {
+	userConfig, err := config.Load()
+	if err != nil {
+		return err
+	}
+	if userConfig.APIVersions == nil {
+		userConfig.APIVersions = map[string]string{}
+	}
+	for existingArmType := range userConfig.APIVersions {
+		if strings.EqualFold(existingArmType, armType) {
+			delete(userConfig.APIVersions, existingArmType)
+		}
+	}
+	if apiVersion != "" {
+		userConfig.APIVersions[armType] = apiVersion
+	}
+	return config.Save(userConfig)
+}
+
+// setAPIVersionInURL returns the URL with the api-version query string value replaced
+func setAPIVersionInURL(resourceURL string, apiVersion string) (string, error) {
+	parsedURL, err := url.Parse(resourceURL)
+	if err != nil {
+		return "", fmt.Errorf("Failed to parse URL %q: %s", resourceURL, err)
+	}
+	query := parsedURL.Query()
+	query.Set("api-version", apiVersion)
+	parsedURL.RawQuery = query.Encode()
+	return parsedURL.String(), nil
+}
+
+// getAPIVersionArmType returns the resource type that API version overrides are keyed on for the item.
+// Swagger sub-resources don't have an ArmType so the type is taken from the ExpandURL
+func getAPIVersionArmType(item *TreeNode) string {
+	if item.ArmType != "" {
+		return item.ArmType
+	}
+	return getResourceTypeFromID(strings.SplitN(item.ExpandURL, "?", 2)[0])
+}
+
+// applyAPIVersionOverride returns the URL with the api-version replaced by the override for the
+// resource type in the URL (if one has been chosen). Used for ExpandURLs built from swagger endpoints
+func applyAPIVersionOverride(resourceURL string) string {
+	if !strings.Contains(resourceURL, "api-version=") {
+		return resourceURL
+	}
+	armType := getResourceTypeFromID(strings.SplitN(resourceURL, "?", 2)[0])
+	if armType == "" {
+		return resourceURL
+	}
+	apiVersion, exists := armclient.GetAPIVersionOverride(armType)
+	if !exists {
+		return resourceURL
+	}
+	overriddenURL, err := setAPIVersionInURL(resourceURL, apiVersion)
+	if err != nil {
+		return resourceURL
+	}
+	return overriddenURL
+}
@@ -0,0 +1,72 @@
+package expanders
+
+import (
+	"context"
+	"testing"
+
+	"github.com/lawrencegripper/azbrowse/pkg/armclient"
+	"github.com/lawrencegripper/azbrowse/pkg/swagger"
+	"github.com/nbio/st"
+)
+
+func Test_APIVersions_SetAPIVersionInURL(t *testing.T) {
+	url, err := setAPIVersionInURL("/subscriptions/1/resourceGroups/rg/providers/Microsoft.Web/sites/app?api-version=2020-12-01", "2021-01-01-preview")
+	st.Expect(t, err, nil)
+	st.Expect(t, url, "/subscriptions/1/resourceGroups/rg/providers/Microsoft.Web/sites/app?api-version=2021-01-01-preview")
+}
+
+func Test_APIVersions_ListActions(t *testing.T) {
+	expander := &APIVersionsExpander{}
+	item := &TreeNode{
+		ID:        "/subscriptions/1/resourceGroups/rg/providers/Microsoft.Web/sites/app",
+		ItemType:  ResourceType,
+		ArmType:   "Microsoft.Web/sites",
+		ExpandURL: "/subscriptions/1/resourceGroups/rg/providers/Microsoft.Web/sites/app?api-version=2020-12-01",
+	}
+
+	hasActions, err := expander.HasActions(context.Background(), item)
+	st.Expect(t, err, nil)
+	st.Expect(t, hasActions, true)
+
+	result := expander.ListActions(context.Background(), item)
+	st.Expect(t, result.Err, nil)
+	st.Expect(t, len(result.Nodes), 1)
+	st.Expect(t, result.Nodes[0].ArmType, "Microsoft.Web/sites")
+	st.Expect(t, result.Nodes[0].Metadata["ActionID"], apiVersionsActionID)
+	st.Expect(t, result.Nodes[0].Metadata["ResourceExpandURL"], item.ExpandURL)
+
+	hasActions, err = expander.HasActions(context.Background(), &TreeNode{ItemType: SubResourceType, ArmType: "Microsoft.Web/sites"})
+	st.Expect(t, err, nil)
+	st.Expect(t, hasActions, false)
+}
+
+func Test_APIVersions_ListActions_SwaggerSubResource(t *testing.T) {
+	expander := &APIVersionsExpander{}
+	item := &TreeNode{
+		ID:                  "/subscriptions/1/resourceGroups/rg/providers/Microsoft.Web/sites/app/slots/staging",
+		ItemType:            SubResourceType,
+		ExpandURL:           "/subscriptions/1/resourceGroups/rg/providers/Microsoft.Web/sites/app/slots/staging?api-version=2020-12-01",
+		SwaggerResourceType: &swagger.ResourceType{},
+	}
+
+	hasActions, err := expander.HasActions(context.Background(), item)
+	st.Expect(t, err, nil)
+	st.Expect(t, hasActions, true)
+
+	result := expander.ListActions(context.Background(), item)
+	st.Expect(t, result.Err, nil)
+	st.Expect(t, len(result.Nodes), 1)
+	st.Expect(t, result.Nodes[0].ArmType, "Microsoft.Web/sites/slots")
+}
+
+func Test_APIVersions_ApplyAPIVersionOverride(t *testing.T) {
+	defer armclient.SetAPIVersionOverrides(nil)
+	armclient.SetAPIVersionOverrides(map[string]string{"Microsoft.Web/sites/slots": "2021-01-01-preview"})
+
+	st.Expect(t, applyAPIVersionOverride("/subscriptions/1/resourceGroups/rg/providers/Microsoft.Web/sites/app/slots/staging?api-version=2020-12-01"),
+		"/subscriptions/1/resourceGroups/rg/providers/Microsoft.Web/sites/app/slots/staging?api-version=2021-01-01-preview")
+	st.Expect(t, applyAPIVersionOverride("/subscriptions/1/resourceGroups/rg/providers/Microsoft.Web/sites/app?api-version=2020-12-01"),
+		"/subscriptions/1/resourceGroups/rg/providers/Microsoft.Web/sites/app?api-version=2020-12-01")
+	st.Expect(t, applyAPIVersionOverride("/subscriptions/1/resourceGroups/rg/providers/Microsoft.Web/sites/app/slots/staging"),
+		"/subscriptions/1/resourceGroups/rg/providers/Microsoft.Web/sites/app/slots/staging")
+}
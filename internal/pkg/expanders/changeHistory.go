@@ -0,0 +1,292 @@
+package expanders
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/lawrencegripper/azbrowse/internal/pkg/interfaces"
+	"github.com/lawrencegripper/azbrowse/internal/pkg/style"
+	"github.com/lawrencegripper/azbrowse/pkg/armclient"
+)
+
+// See https://docs.microsoft.com/en-us/azure/governance/resource-graph/how-to/get-resource-changes
+
+const (
+	changeHistoryType       = "changeHistory"
+	changeHistoryChangeType = "changeHistory.change"
+
+	changeHistoryMaxChanges = 200
+)
+
+// Check interface
+var _ Expander = &ChangeHistoryExpander{}
+
+// ChangeHistoryExpander lists the changes to a resource, or the resources in a resource group, from the Resource Graph resourcechanges table
+type ChangeHistoryExpander struct {
+	ExpanderBase
+	client *armclient.Client
+}
+
+func (e *ChangeHistoryExpander) setClient(c *armclient.Client) {
+	e.client = c
+}
+
+// Name returns the name of the expander
+func (e *ChangeHistoryExpander) Name() string {
+	return "ChangeHistoryExpander"
+}
+
+// DoesExpand checks if this is a resource or a change history node
+func (e *ChangeHistoryExpander) DoesExpand(ctx context.Context, currentItem *TreeNode) (bool, error) {
+	switch currentItem.ItemType {
+	case ResourceType, changeHistoryType, changeHistoryChangeType:
+		return true, nil
+	}
+	return false, nil
+}
+
+// Expand returns the change history nodes
+func (e *ChangeHistoryExpander) Expand(ctx context.Context, currentItem *TreeNode) ExpanderResult {
+	switch currentItem.ItemType {
+	case ResourceType:
+		return ExpanderResult{
+			Nodes:             []*TreeNode{newChangeHistoryNode(currentItem, "", currentItem.ID)},
+			SourceDescription: "ChangeHistoryExpander",
+			IsPrimaryResponse: false,
+		}
+	case changeHistoryChangeType:
+		var change resourceChange
+		if err := json.Unmarshal([]byte(currentItem.Metadata["Change"]), &change); err != nil {
+			return ExpanderResult{
+				Err:               fmt.Errorf("Error loading change: %s", err),
+				SourceDescription: "ChangeHistoryExpander",
+				IsPrimaryResponse: true,
+			}
+		}
+		return ExpanderResult{
+			Response:          ExpanderResponse{Response: renderResourceChange(change), ResponseType: interfaces.ResponsePlainText},
+			SourceDescription: "ChangeHistoryExpander",
+			IsPrimaryResponse: true,
+		}
+	}
+
+	return e.expandChangeHistory(ctx, currentItem)
+}
+
+// newChangeHistoryNode creates the "Change history" node for a resource group or resource
+func newChangeHistoryNode(parent *TreeNode, resourceGroupName string, resourceID string) *TreeNode {
+	return &TreeNode{
+		Parentid:              parent.ID,
+		Namespace:             "None",
+		Display:               style.Subtle("[Microsoft.ResourceGraph]") + "\n  Change history",
+		Name:                  "Change history",
+		ID:                    parent.ID + "/<changehistory>",
+		ExpandURL:             ExpandURLNotSupported,
+		ItemType:              changeHistoryType,
+		SubscriptionID:        parent.SubscriptionID,
+		SuppressSwaggerExpand: true,
+		SuppressGenericExpand: true,
+		Metadata: map[string]string{
+			"ResourceGroupName": resourceGroupName,
+			"ResourceID":        resourceID,
+		},
+	}
+}
+
+// resourceChange is a row from the resourcechanges query
+type resourceChange struct {
+	ChangeTime       time.Time                         `json:"changeTime"`
+	TargetResourceID string                            `json:"targetResourceId"`
+	ChangeType       string                            `json:"changeType"`
+	ChangedBy        string                            `json:"changedBy"`
+	ClientType       string                            `json:"clientType"`
+	Operation        string                            `json:"operation"`
+	Changes          map[string]resourcePropertyChange `json:"changes"`
+}
+
+// resourcePropertyChange is the before/after for a single property in a change
+type resourcePropertyChange struct {
+	PropertyChangeType string      `json:"propertyChangeType"`
+	ChangeCategory     string      `json:"changeCategory"`
+	PreviousValue      interface{} `json:"previousValue"`
+	NewValue           interface{} `json:"newValue"`
+}
+
+// buildChangeHistoryQuery returns the resourcechanges query for a resource or, if resourceID is empty, a resource group
+func buildChangeHistoryQuery(subscriptionID string, resourceGroupName string, resourceID string) string {
+	escape := func(s string) string {
+		return strings.ReplaceAll(s, "'", "\\'")
+	}
+	filter := fmt.Sprintf("where targetResourceId =~ '%s'", escape(resourceID))
+	if resourceID == "" {
+		filter = fmt.Sprintf("where subscriptionId =~ '%s' and resourceGroup =~ '%s'", escape(subscriptionID), escape(resourceGroupName))
+	}
+	return "resourcechanges" +
+		" | extend changeTime = todatetime(properties.changeAttributes.timestamp)," +
+		" targetResourceId = tostring(properties.targetResourceId)," +
+		" changeType = tostring(properties.changeType)," +
+		" changedBy = tostring(properties.changeAttributes.changedBy)," +
+		" clientType = tostring(properties.changeAttributes.clientType)," +
+		" operation = tostring(properties.changeAttributes.operation)," +
+		" changes = properties.changes" +
+		" | " + filter +
+		" | order by changeTime desc" +
+		" | take " + strconv.Itoa(changeHistoryMaxChanges) +
+		" | project changeTime, targetResourceId, changeType, changedBy, clientType, operation, changes"
+}
+
+func formatResourceChangeType(changeType string, s string) string {
+	switch changeType {
+	case "Create", "Insert":
+		return style.Added(s)
+	case "Delete", "Remove":
+		return style.Removed(s)
+	case "Update":
+		return style.Changed(s)
+	}
+	return style.Subtle(s)
+}
+
+// formatResourceChangeValue formats a before/after value, using JSON for objects and arrays
+func formatResourceChangeValue(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return "(none)"
+	case string:
+		return v
+	case map[string]interface{}, []interface{}:
+		buf, err := json.Marshal(v)
+		if err == nil {
+			return string(buf)
+		}
+	}
+	return fmt.Sprintf("%v", value)
+}
+
+func (change resourceChange) getInitiator() string {
+	initiator := change.ChangedBy
+	if initiator == "" {
+		initiator = "unknown"
+	}
+	if change.ClientType != "" {
+		initiator += " via " + change.ClientType
+	}
+	return initiator
+}
+
+// renderResourceChange renders the property-level before/after diff for a change
+func renderResourceChange(change resourceChange) string {
+	var sb strings.Builder
+	sb.WriteString(style.Title(change.ChangeType+" "+change.TargetResourceID) + "\n")
+	sb.WriteString(fmt.Sprintf("Time:      %s\n", change.ChangeTime.Local().Format("2006-01-02 15:04:05")))
+	sb.WriteString(fmt.Sprintf("Initiator: %s\n", change.getInitiator()))
+	if change.Operation != "" {
+		sb.WriteString(fmt.Sprintf("Operation: %s\n", change.Operation))
+	}
+	sb.WriteString("\n")
+
+	if len(change.Changes) == 0 {
+		sb.WriteString(style.Subtle("No property changes recorded") + "\n")
+		return sb.String()
+	}
+
+	paths := make([]string, 0, len(change.Changes))
+	for path := range change.Changes {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+	for _, path := range paths {
+		propertyChange := change.Changes[path]
+		category := ""
+		if propertyChange.ChangeCategory != "" {
+			category = style.Subtle(" (" + propertyChange.ChangeCategory + ")")
+		}
+		switch propertyChange.PropertyChangeType {
+		case "Insert":
+			sb.WriteString(formatResourceChangeType("Insert", "+ "+path+": "+formatResourceChangeValue(propertyChange.NewValue)) + category + "\n")
+		case "Remove":
+			sb.WriteString(formatResourceChangeType("Remove", "- "+path+": "+formatResourceChangeValue(propertyChange.PreviousValue)) + category + "\n")
+		default:
+			sb.WriteString(formatResourceChangeType("Update", "~ "+path) + category + "\n")
+			sb.WriteString("    " + style.Removed("before: "+formatResourceChangeValue(propertyChange.PreviousValue)) + "\n")
+			sb.WriteString("    " + style.Added("after:  "+formatResourceChangeValue(propertyChange.NewValue)) + "\n")
+		}
+	}
+	return sb.String()
+}
+
+func (e *ChangeHistoryExpander) expandChangeHistory(ctx context.Context, currentItem *TreeNode) ExpanderResult {
+	resourceID := currentItem.Metadata["ResourceID"]
+	query := buildChangeHistoryQuery(currentItem.SubscriptionID, currentItem.Metadata["ResourceGroupName"], resourceID)
+	data, err := e.client.DoResourceGraphChangesQueryReturningObjectArray(ctx, []string{currentItem.SubscriptionID}, query)
+	if err != nil {
+		return ExpanderResult{
+			Err:               fmt.Errorf("Error querying resource changes: %s %s", err, data),
+			SourceDescription: "ChangeHistoryExpander request",
+			IsPrimaryResponse: true,
+		}
+	}
+	var response struct {
+		Data []json.RawMessage `json:"data"`
+	}
+	if err := json.Unmarshal([]byte(data), &response); err != nil {
+		return ExpanderResult{
+			Err:               fmt.Errorf("Error unmarshalling resource changes: %s", err),
+			SourceDescription: "ChangeHistoryExpander request",
+			IsPrimaryResponse: true,
+		}
+	}
+
+	var sb strings.Builder
+	sb.WriteString(style.Title(fmt.Sprintf("%-20s %-8s %-40s %s", "TIME", "CHANGE", "RESOURCE", "INITIATOR")) + "\n")
+	nodes := []*TreeNode{}
+	for index, raw := range response.Data {
+		var change resourceChange
+		if err := json.Unmarshal(raw, &change); err != nil {
+			return ExpanderResult{
+				Err:               fmt.Errorf("Error unmarshalling resource change: %s", err),
+				SourceDescription: "ChangeHistoryExpander request",
+				IsPrimaryResponse: true,
+			}
+		}
+		changeTime := change.ChangeTime.Local().Format("2006-01-02 15:04:05")
+		resourceName := getResourceNameFromID(change.TargetResourceID)
+		display := formatResourceChangeType(change.ChangeType, change.ChangeType) + " " + changeTime
+		if resourceID == "" {
+			// Resource group history covers multiple resources
+			display += "\n  " + resourceName
+		}
+		display += "\n  " + style.Subtle(change.getInitiator())
+		nodes = append(nodes, &TreeNode{
+			Parentid:              currentItem.ID,
+			Namespace:             "None",
+			Name:                  change.ChangeType + " " + changeTime,
+			Display:               display,
+			ID:                    currentItem.ID + "/" + strconv.Itoa(index),
+			ExpandURL:             ExpandURLNotSupported,
+			ItemType:              changeHistoryChangeType,
+			SubscriptionID:        currentItem.SubscriptionID,
+			SuppressSwaggerExpand: true,
+			SuppressGenericExpand: true,
+			Metadata: map[string]string{
+				"Change": string(raw),
+			},
+		})
+		sb.WriteString(fmt.Sprintf("%-20s %s %-40s %s\n", changeTime, formatResourceChangeType(change.ChangeType, fmt.Sprintf("%-8s", change.ChangeType)), resourceName, change.getInitiator()))
+	}
+	if len(nodes) == 0 {
+		sb.WriteString("No changes found. Resource Graph keeps the last 14 days of changes\n")
+	}
+
+	return ExpanderResult{
+		Response:          ExpanderResponse{Response: sb.String(), ResponseType: interfaces.ResponsePlainText},
+		SourceDescription: "ChangeHistoryExpander request",
+		Nodes:             nodes,
+		IsPrimaryResponse: true,
+	}
+}
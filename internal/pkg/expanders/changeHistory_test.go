@@ -0,0 +1,54 @@
+package expanders
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/nbio/st"
+)
+
+func Test_ChangeHistory_Query(t *testing.T) {
+	resourceQuery := buildChangeHistoryQuery("1", "", "/subscriptions/1/resourceGroups/rg1/providers/Microsoft.Web/sites/app1")
+	st.Expect(t, strings.HasPrefix(resourceQuery, "resourcechanges | extend "), true)
+	st.Expect(t, strings.Contains(resourceQuery, "| where targetResourceId =~ '/subscriptions/1/resourceGroups/rg1/providers/Microsoft.Web/sites/app1' |"), true)
+	st.Expect(t, strings.Contains(resourceQuery, "| order by changeTime desc | take 200 |"), true)
+
+	groupQuery := buildChangeHistoryQuery("1", "o'rg", "")
+	st.Expect(t, strings.Contains(groupQuery, "| where subscriptionId =~ '1' and resourceGroup =~ 'o\\'rg' |"), true)
+	st.Expect(t, strings.Contains(groupQuery, "targetResourceId =~"), false)
+}
+
+func Test_ChangeHistory_RenderChange(t *testing.T) {
+	data := `{
+		"changeTime": "2021-06-01T10:00:00Z",
+		"targetResourceId": "/subscriptions/1/resourceGroups/rg1/providers/Microsoft.Storage/storageAccounts/sa1",
+		"changeType": "Update",
+		"changedBy": "user@contoso.com",
+		"clientType": "Azure Portal",
+		"operation": "Microsoft.Storage/storageAccounts/write",
+		"changes": {
+			"tags.env": {"propertyChangeType": "Insert", "changeCategory": "User", "newValue": "dev"},
+			"properties.minimumTlsVersion": {"propertyChangeType": "Update", "changeCategory": "User", "previousValue": "TLS1_0", "newValue": "TLS1_2"},
+			"properties.networkAcls.ipRules[0]": {"propertyChangeType": "Remove", "previousValue": {"value": "1.2.3.4"}}
+		}
+	}`
+	var change resourceChange
+	st.Expect(t, json.Unmarshal([]byte(data), &change), nil)
+	st.Expect(t, change.getInitiator(), "user@contoso.com via Azure Portal")
+
+	lines := strings.Split(renderResourceChange(change), "\n")
+	// Header, blank line then the property changes sorted by path
+	st.Expect(t, strings.Contains(lines[3], "Microsoft.Storage/storageAccounts/write"), true)
+	st.Expect(t, strings.Contains(lines[5], "~ properties.minimumTlsVersion"), true)
+	st.Expect(t, strings.Contains(lines[6], "before: TLS1_0"), true)
+	st.Expect(t, strings.Contains(lines[7], "after:  TLS1_2"), true)
+	st.Expect(t, strings.Contains(lines[8], `- properties.networkAcls.ipRules[0]: {"value":"1.2.3.4"}`), true)
+	st.Expect(t, strings.Contains(lines[9], "+ tags.env: dev"), true)
+}
+
+func Test_ChangeHistory_RenderChangeWithoutProperties(t *testing.T) {
+	change := resourceChange{ChangeType: "Create", TargetResourceID: "/subscriptions/1/resourceGroups/rg1/providers/Microsoft.Web/sites/app1"}
+	st.Expect(t, change.getInitiator(), "unknown")
+	st.Expect(t, strings.Contains(renderResourceChange(change), "No property changes recorded"), true)
+}
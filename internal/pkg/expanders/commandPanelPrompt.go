@@ -0,0 +1,66 @@
+package expanders
+
+import (
+	"context"
+
+	"github.com/awesome-gocui/gocui"
+	"github.com/lawrencegripper/azbrowse/internal/pkg/interfaces"
+)
+
+// promptForInput shows the command panel with the title and initial text and
+// blocks until the user presses enter, returning the entered text
+func promptForInput(ctx context.Context, gui *gocui.Gui, commandPanel interfaces.CommandPanel, title string, initialText string) (string, error) {
+	commandChannel := make(chan string, 1)
+	commandPanelNotification := func(state interfaces.CommandPanelNotification) {
+		if state.EnterPressed {
+			select {
+			case commandChannel <- state.CurrentText:
+			default:
+				// Already have a value, ignore repeated enter presses
+			}
+			commandPanel.Hide()
+		}
+	}
+	commandPanel.ShowWithText(title, initialText, nil, commandPanelNotification)
+	// Force UI to re-render to pickup
+	gui.Update(func(g *gocui.Gui) error {
+		return nil
+	})
+
+	select {
+	case <-ctx.Done():
+		commandPanel.Hide()
+		return "", ctx.Err()
+	case value := <-commandChannel:
+		return value, nil
+	}
+}
+
+// promptForOption shows the command panel with the options and blocks until the
+// user presses enter, returning the ID of the selected option ("" if none was selected)
+func promptForOption(ctx context.Context, gui *gocui.Gui, commandPanel interfaces.CommandPanel, title string, options []interfaces.CommandPanelListOption) (string, error) {
+	commandChannel := make(chan string, 1)
+	commandPanelNotification := func(state interfaces.CommandPanelNotification) {
+		if state.EnterPressed {
+			select {
+			case commandChannel <- state.SelectedID:
+			default:
+				// Already have a value, ignore repeated enter presses
+			}
+			commandPanel.Hide()
+		}
+	}
+	commandPanel.ShowWithText(title, "", &options, commandPanelNotification)
+	// Force UI to re-render to pickup
+	gui.Update(func(g *gocui.Gui) error {
+		return nil
+	})
+
+	select {
+	case <-ctx.Done():
+		commandPanel.Hide()
+		return "", ctx.Err()
+	case value := <-commandChannel:
+		return value, nil
+	}
+}
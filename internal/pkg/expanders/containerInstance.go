@@ -110,6 +110,7 @@ func (e *ContainerInstanceExpander) expandContainers(ctx context.Context, curren
 			StatusIndicator:       DrawStatus(container.Properties.InstanceView.CurrentState.State),
 			SuppressSwaggerExpand: true,
 			SuppressGenericExpand: true,
+			WatchIntervalSeconds:  logWatchIntervalSeconds,
 			Metadata: map[string]string{
 				"ContainerName": container.Name,
 			},
@@ -9,11 +9,18 @@ import (
 	"net/http"
 	"strings"
 
+	"github.com/awesome-gocui/gocui"
 	"github.com/lawrencegripper/azbrowse/internal/pkg/interfaces"
 	"github.com/lawrencegripper/azbrowse/internal/pkg/tracing"
 	"github.com/lawrencegripper/azbrowse/pkg/armclient"
 )
 
+const (
+	containerRegistryTemplate     = "/subscriptions/{subscriptionId}/resourceGroups/{resourceGroupName}/providers/Microsoft.ContainerRegistry/registries/{registryName}"
+	containerRegistryTaskTemplate = containerRegistryTemplate + "/tasks/{taskName}"
+	containerRegistryRunTemplate  = containerRegistryTemplate + "/runs/{runId}"
+)
+
 type containerRegistryResponse struct {
 	Properties struct {
 		LoginServer string `json:"loginServer"`
@@ -21,10 +28,13 @@ type containerRegistryResponse struct {
 }
 
 // NewContainerRegistryExpander creates a new instance of ContainerRegistryExpander
-func NewContainerRegistryExpander(armclient *armclient.Client) *ContainerRegistryExpander {
+func NewContainerRegistryExpander(armclient *armclient.Client, gui *gocui.Gui, commandPanel interfaces.CommandPanel, contentPanel interfaces.ItemWidget) *ContainerRegistryExpander {
 	return &ContainerRegistryExpander{
-		client:    &http.Client{},
-		armClient: armclient,
+		client:       &http.Client{},
+		armClient:    armclient,
+		gui:          gui,
+		commandPanel: commandPanel,
+		contentPanel: contentPanel,
 	}
 }
 
@@ -38,8 +48,11 @@ func (e *ContainerRegistryExpander) setClient(c *armclient.Client) {
 // ContainerRegistryExpander expands Tthe data-plane aspects of a Container Registry
 type ContainerRegistryExpander struct {
 	ExpanderBase
-	client    *http.Client
-	armClient *armclient.Client
+	client       *http.Client
+	armClient    *armclient.Client
+	gui          *gocui.Gui
+	commandPanel interfaces.CommandPanel
+	contentPanel interfaces.ItemWidget
 }
 
 // Name returns the name of the expander
@@ -49,9 +62,19 @@ func (e *ContainerRegistryExpander) Name() string {
 
 // DoesExpand checks if this is a storage account
 func (e *ContainerRegistryExpander) DoesExpand(ctx context.Context, currentItem *TreeNode) (bool, error) {
+	if currentItem.ItemType == ActionType {
+		// Actions are handled by the ActionExpander
+		return false, nil
+	}
 	swaggerResourceType := currentItem.SwaggerResourceType
 	if currentItem.ItemType == "resource" && swaggerResourceType != nil {
-		if swaggerResourceType.Endpoint.TemplateURL == "/subscriptions/{subscriptionId}/resourceGroups/{resourceGroupName}/providers/Microsoft.ContainerRegistry/registries/{registryName}" {
+		if swaggerResourceType.Endpoint.TemplateURL == containerRegistryTemplate {
+			return true, nil
+		}
+	}
+	if swaggerResourceType != nil {
+		switch swaggerResourceType.Endpoint.TemplateURL {
+		case containerRegistryTaskTemplate, containerRegistryRunTemplate:
 			return true, nil
 		}
 	}
@@ -67,7 +90,7 @@ func (e *ContainerRegistryExpander) Expand(ctx context.Context, currentItem *Tre
 	swaggerResourceType := currentItem.SwaggerResourceType
 	if currentItem.Namespace != "ContainerRegistry" &&
 		swaggerResourceType != nil &&
-		swaggerResourceType.Endpoint.TemplateURL == "/subscriptions/{subscriptionId}/resourceGroups/{resourceGroupName}/providers/Microsoft.ContainerRegistry/registries/{registryName}" {
+		swaggerResourceType.Endpoint.TemplateURL == containerRegistryTemplate {
 		newItems := []*TreeNode{}
 		newItems = append(newItems, &TreeNode{
 			Parentid:              currentItem.ID,
@@ -93,6 +116,14 @@ func (e *ContainerRegistryExpander) Expand(ctx context.Context, currentItem *Tre
 		}
 	}
 
+	if swaggerResourceType != nil && swaggerResourceType.Endpoint.TemplateURL == containerRegistryTaskTemplate {
+		return e.expandTask(ctx, currentItem)
+	}
+	if (swaggerResourceType != nil && swaggerResourceType.Endpoint.TemplateURL == containerRegistryRunTemplate) ||
+		currentItem.ItemType == "containerRegistry.run" {
+		return e.expandRun(ctx, currentItem)
+	}
+
 	if currentItem.Namespace == "containerRegistry" && currentItem.ItemType == SubResourceType {
 		return e.expandRepositories(ctx, currentItem)
 	} else if currentItem.ItemType == "containerRegistry.repository" {
@@ -105,6 +136,12 @@ func (e *ContainerRegistryExpander) Expand(ctx context.Context, currentItem *Tre
 		return e.expandRepositoryManifests(ctx, currentItem)
 	} else if currentItem.ItemType == "containerRegistry.repository.manifest" {
 		return e.expandRepositoryManifest(ctx, currentItem)
+	} else if currentItem.ItemType == "containerRegistry.repository.manifest.detail" {
+		return e.expandRepositoryManifestDetail(ctx, currentItem)
+	} else if currentItem.ItemType == "containerRegistry.task.runs" {
+		return e.expandTaskRuns(ctx, currentItem)
+	} else if currentItem.ItemType == "containerRegistry.run.log" {
+		return e.expandRunLog(ctx, currentItem)
 	}
 
 	return ExpanderResult{
@@ -328,7 +365,7 @@ func (e *ContainerRegistryExpander) expandRepositoryManifest(ctx context.Context
 	repository := currentItem.Metadata["repository"]
 	digest := currentItem.Metadata["digest"]
 
-	return e.expandNode(
+	result := e.expandNode(
 		ctx,
 		currentItem,
 		fmt.Sprintf("https://%s/v2/%s/manifests/%s", loginServer, repository, digest),
@@ -337,6 +374,10 @@ func (e *ContainerRegistryExpander) expandRepositoryManifest(ctx context.Context
 		"",
 		e.getCreateTagNodeFunc(loginServer, repository),
 		nil)
+	if result.Err == nil {
+		result.Nodes = append([]*TreeNode{e.getCreateManifestDetailNodeFunc(loginServer, repository, "Manifest Detail")(currentItem, digest)}, result.Nodes...)
+	}
+	return result
 }
 func (e *ContainerRegistryExpander) deleteRepositoryManifest(ctx context.Context, currentItem *TreeNode) (bool, error) {
 
@@ -379,6 +420,25 @@ func (e *ContainerRegistryExpander) getCreateManifestNodeFunc(loginServer string
 	}
 }
 
+func (e *ContainerRegistryExpander) getCreateManifestDetailNodeFunc(loginServer string, repository string, title string) createItemNode {
+	return func(currentItem *TreeNode, item string) *TreeNode {
+		return &TreeNode{
+			Parentid:  currentItem.ID,
+			ID:        currentItem.ID + "/<" + item + ">",
+			Namespace: "containerRegistry",
+			Name:      title,
+			Display:   title,
+			ItemType:  "containerRegistry.repository.manifest.detail",
+			ExpandURL: ExpandURLNotSupported,
+			Metadata: map[string]string{
+				"loginServer": loginServer,
+				"repository":  repository,
+				"digest":      item,
+			},
+		}
+	}
+}
+
 func (e *ContainerRegistryExpander) getCreateTagNodeFunc(loginServer string, repository string) createItemNode {
 	return func(currentItem *TreeNode, item string) *TreeNode {
 		return &TreeNode{
@@ -551,6 +611,10 @@ func (e *ContainerRegistryExpander) getItemsForURL(ctx context.Context, url stri
 }
 
 func (e *ContainerRegistryExpander) doRequest(ctx context.Context, verb string, url string, accessToken string) ([]byte, error) {
+	return e.doRequestWithHeaders(ctx, verb, url, accessToken, nil)
+}
+
+func (e *ContainerRegistryExpander) doRequestWithHeaders(ctx context.Context, verb string, url string, accessToken string, headers map[string]string) ([]byte, error) {
 	span, _ := tracing.StartSpanFromContext(ctx, "doRequest(containerregistry):"+url, tracing.SetTag("url", url))
 	defer span.Finish()
 
@@ -559,6 +623,9 @@ func (e *ContainerRegistryExpander) doRequest(ctx context.Context, verb string,
 		return []byte{}, fmt.Errorf("Failed to create request: %s", err)
 	}
 	req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", accessToken))
+	for name, value := range headers {
+		req.Header.Set(name, value)
+	}
 
 	response, err := e.client.Do(req.WithContext(ctx))
 	if err != nil {
@@ -0,0 +1,388 @@
+package expanders
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/lawrencegripper/azbrowse/internal/pkg/eventing"
+	"github.com/lawrencegripper/azbrowse/internal/pkg/interfaces"
+	"github.com/lawrencegripper/azbrowse/internal/pkg/style"
+)
+
+const (
+	containerRegistryImportAPIVersion = "2019-05-01"
+
+	containerRegistryActionImportImage  = "import-image"
+	containerRegistryActionPurgeTags    = "purge-tags"
+	containerRegistryActionConfirmPurge = "confirm-purge"
+)
+
+// containerRegistryTag is a tag returned from the ACR tags API
+type containerRegistryTag struct {
+	Name           string `json:"name"`
+	Digest         string `json:"digest"`
+	CreatedTime    string `json:"createdTime"`
+	LastUpdateTime string `json:"lastUpdateTime"`
+}
+
+// HasActions returns true for registries (import) and repositories (purge)
+func (e *ContainerRegistryExpander) HasActions(ctx context.Context, item *TreeNode) (bool, error) {
+	if item.ItemType == "resource" && item.SwaggerResourceType != nil && item.SwaggerResourceType.Endpoint.TemplateURL == containerRegistryTemplate {
+		return true, nil
+	}
+	if item.ItemType == "containerRegistry.repository" {
+		return true, nil
+	}
+	return false, nil
+}
+
+// ListActions returns the actions for registries and repositories
+func (e *ContainerRegistryExpander) ListActions(ctx context.Context, item *TreeNode) ListActionsResult {
+	nodes := []*TreeNode{}
+	if item.ItemType == "containerRegistry.repository" {
+		nodes = append(nodes, &TreeNode{
+			Parentid:              item.ID,
+			ID:                    item.ID + "?" + containerRegistryActionPurgeTags,
+			Namespace:             "containerRegistry",
+			Name:                  "Purge Tags",
+			Display:               "Purge Tags\n   " + style.Subtle("Delete tags older than N days matching a filter (preview first)"),
+			ItemType:              ActionType,
+			SuppressGenericExpand: true,
+			Metadata: map[string]string{
+				"ActionID":    containerRegistryActionPurgeTags,
+				"loginServer": item.Metadata["loginServer"],
+				"repository":  item.Metadata["repository"],
+			},
+		})
+	} else {
+		nodes = append(nodes, &TreeNode{
+			Parentid:              item.ID,
+			ID:                    item.ID + "?" + containerRegistryActionImportImage,
+			Namespace:             "containerRegistry",
+			Name:                  "Import Image",
+			Display:               "Import Image\n   " + style.Subtle("Import an image from another registry"),
+			ItemType:              ActionType,
+			SuppressGenericExpand: true,
+			Metadata: map[string]string{
+				"ActionID":   containerRegistryActionImportImage,
+				"RegistryID": item.ID,
+			},
+		})
+	}
+
+	return ListActionsResult{
+		Nodes:             nodes,
+		SourceDescription: "ContainerRegistryExpander",
+		IsPrimaryResponse: true,
+	}
+}
+
+// ExecuteAction runs the registry actions
+func (e *ContainerRegistryExpander) ExecuteAction(ctx context.Context, item *TreeNode) ExpanderResult {
+	actionID := item.Metadata["ActionID"]
+
+	switch actionID {
+	case containerRegistryActionImportImage:
+		return e.importImage(ctx, item)
+	case containerRegistryActionPurgeTags:
+		return e.previewPurgeTags(ctx, item)
+	case containerRegistryActionConfirmPurge:
+		return e.purgeTags(ctx, item)
+	case "":
+		return ExpanderResult{
+			SourceDescription: "ContainerRegistryExpander",
+			IsPrimaryResponse: true,
+			Err:               fmt.Errorf("ActionID metadata not set: %q", item.ID),
+		}
+	default:
+		return ExpanderResult{
+			SourceDescription: "ContainerRegistryExpander",
+			IsPrimaryResponse: true,
+			Err:               fmt.Errorf("Unhandled ActionID: %q", actionID),
+		}
+	}
+}
+
+// parseImportSource splits an image reference such as 'docker.io/library/nginx:latest' into the
+// registry and image, applying the docker hub defaults when no registry is specified
+func parseImportSource(source string) (string, string) {
+	registry := "docker.io"
+	image := source
+	if index := strings.Index(source, "/"); index >= 0 {
+		firstSegment := source[:index]
+		if strings.ContainsAny(firstSegment, ".:") || firstSegment == "localhost" {
+			registry = firstSegment
+			image = source[index+1:]
+		}
+	}
+	if registry == "docker.io" && !strings.Contains(image, "/") {
+		image = "library/" + image
+	}
+	if !strings.ContainsAny(image, ":@") {
+		image += ":latest"
+	}
+	return registry, image
+}
+
+func (e *ContainerRegistryExpander) importImage(ctx context.Context, item *TreeNode) ExpanderResult {
+	registryID := item.Metadata["RegistryID"]
+
+	source, err := promptForInput(ctx, e.gui, e.commandPanel, "source image (e.g. docker.io/library/nginx:latest):", "")
+	if err != nil || strings.TrimSpace(source) == "" {
+		return ExpanderResult{
+			Err:               fmt.Errorf("User canceled"),
+			SourceDescription: "ContainerRegistryExpander request",
+			IsPrimaryResponse: true,
+		}
+	}
+	sourceRegistry, sourceImage := parseImportSource(strings.TrimSpace(source))
+
+	defaultTarget := strings.TrimPrefix(sourceImage, "library/")
+	if index := strings.Index(defaultTarget, "@"); index >= 0 {
+		// Importing by digest so default to untagged target repository
+		defaultTarget = defaultTarget[:index]
+	}
+	target, err := promptForInput(ctx, e.gui, e.commandPanel, "target repository:tag:", defaultTarget)
+	if err != nil || strings.TrimSpace(target) == "" {
+		return ExpanderResult{
+			Err:               fmt.Errorf("User canceled"),
+			SourceDescription: "ContainerRegistryExpander request",
+			IsPrimaryResponse: true,
+		}
+	}
+
+	body, err := json.Marshal(map[string]interface{}{
+		"source": map[string]interface{}{
+			"registryUri": sourceRegistry,
+			"sourceImage": sourceImage,
+		},
+		"targetTags": []string{strings.TrimSpace(target)},
+		"mode":       "NoForce",
+	})
+	if err != nil {
+		return ExpanderResult{
+			Err:               err,
+			SourceDescription: "ContainerRegistryExpander request",
+			IsPrimaryResponse: true,
+		}
+	}
+
+	// Use DoRequestWithBody so that the async ARM watcher tracks the import through to completion
+	data, err := e.armClient.DoRequestWithBody(ctx, "POST", registryID+"/importImage?api-version="+containerRegistryImportAPIVersion, string(body))
+	if err != nil {
+		return ExpanderResult{
+			Err:               fmt.Errorf("Error importing image: %s %s", err, data),
+			SourceDescription: "ContainerRegistryExpander request",
+			IsPrimaryResponse: true,
+		}
+	}
+
+	return ExpanderResult{
+		Response:          ExpanderResponse{Response: fmt.Sprintf("Import of %s/%s to %s submitted.", sourceRegistry, sourceImage, target), ResponseType: interfaces.ResponsePlainText},
+		SourceDescription: "ContainerRegistryExpander request",
+		IsPrimaryResponse: true,
+	}
+}
+
+func (e *ContainerRegistryExpander) previewPurgeTags(ctx context.Context, item *TreeNode) ExpanderResult {
+	loginServer := item.Metadata["loginServer"]
+	repository := item.Metadata["repository"]
+
+	daysText, err := promptForInput(ctx, e.gui, e.commandPanel, "purge tags older than (days):", "30")
+	if err != nil {
+		return ExpanderResult{
+			Err:               fmt.Errorf("User canceled"),
+			SourceDescription: "ContainerRegistryExpander request",
+			IsPrimaryResponse: true,
+		}
+	}
+	days, err := strconv.Atoi(strings.TrimSpace(daysText))
+	if err != nil || days < 0 {
+		return ExpanderResult{
+			Err:               fmt.Errorf("Invalid number of days: %q", daysText),
+			SourceDescription: "ContainerRegistryExpander request",
+			IsPrimaryResponse: true,
+		}
+	}
+
+	filterText, err := promptForInput(ctx, e.gui, e.commandPanel, "tag filter (regex):", ".*")
+	if err != nil {
+		return ExpanderResult{
+			Err:               fmt.Errorf("User canceled"),
+			SourceDescription: "ContainerRegistryExpander request",
+			IsPrimaryResponse: true,
+		}
+	}
+	// Anchor the filter so that it needs to match the whole tag, as with `acr purge`
+	filter, err := regexp.Compile("^(?:" + filterText + ")$")
+	if err != nil {
+		return ExpanderResult{
+			Err:               fmt.Errorf("Invalid filter: %s", err),
+			SourceDescription: "ContainerRegistryExpander request",
+			IsPrimaryResponse: true,
+		}
+	}
+
+	tags, err := e.listAllTags(ctx, loginServer, repository)
+	if err != nil {
+		return ExpanderResult{
+			Err:               err,
+			SourceDescription: "ContainerRegistryExpander request",
+			IsPrimaryResponse: true,
+		}
+	}
+
+	cutoff := time.Now().UTC().AddDate(0, 0, -days)
+	tagsToPurge := selectTagsToPurge(tags, cutoff, filter)
+
+	var sb strings.Builder
+	sb.WriteString(style.Title("Purge preview (dry run)") + "\n\n")
+	sb.WriteString(fmt.Sprintf("Repository: %s/%s\n", loginServer, repository))
+	sb.WriteString(fmt.Sprintf("Filter:     %s\n", filterText))
+	sb.WriteString(fmt.Sprintf("Older than: %d days (last updated before %s)\n\n", days, cutoff.Format(time.RFC3339)))
+	if len(tagsToPurge) == 0 {
+		sb.WriteString("No tags match - nothing to purge.\n")
+		return ExpanderResult{
+			Response:          ExpanderResponse{Response: sb.String(), ResponseType: interfaces.ResponsePlainText},
+			SourceDescription: "ContainerRegistryExpander request",
+			IsPrimaryResponse: true,
+		}
+	}
+	sb.WriteString(fmt.Sprintf("%d of %d tags would be deleted:\n\n", len(tagsToPurge), len(tags)))
+	tagNames := []string{}
+	for _, tag := range tagsToPurge {
+		sb.WriteString(style.Removed(fmt.Sprintf("  - %-40s %s", tag.Name, tag.LastUpdateTime)) + "\n")
+		tagNames = append(tagNames, tag.Name)
+	}
+	sb.WriteString("\nOpen the 'Confirm' item to delete these tags.\n")
+
+	confirmName := fmt.Sprintf("Confirm: delete %d tags", len(tagsToPurge))
+	nodes := []*TreeNode{
+		{
+			Parentid:              item.ID,
+			ID:                    item.ID + "?" + containerRegistryActionConfirmPurge,
+			Namespace:             "containerRegistry",
+			Name:                  confirmName,
+			Display:               confirmName + "\n   " + style.Subtle("from "+loginServer+"/"+repository),
+			ItemType:              ActionType,
+			SuppressGenericExpand: true,
+			Metadata: map[string]string{
+				"ActionID":    containerRegistryActionConfirmPurge,
+				"loginServer": loginServer,
+				"repository":  repository,
+				"tags":        strings.Join(tagNames, ","),
+			},
+		},
+	}
+
+	return ExpanderResult{
+		Response:          ExpanderResponse{Response: sb.String(), ResponseType: interfaces.ResponsePlainText},
+		SourceDescription: "ContainerRegistryExpander request",
+		Nodes:             nodes,
+		IsPrimaryResponse: true,
+	}
+}
+
+func (e *ContainerRegistryExpander) purgeTags(ctx context.Context, item *TreeNode) ExpanderResult {
+	loginServer := item.Metadata["loginServer"]
+	repository := item.Metadata["repository"]
+	tags := strings.Split(item.Metadata["tags"], ",")
+
+	accessToken, err := e.getRegistryToken(ctx, loginServer, fmt.Sprintf("repository:%s:delete", repository))
+	if err != nil {
+		return ExpanderResult{
+			Err:               err,
+			SourceDescription: "ContainerRegistryExpander request",
+			IsPrimaryResponse: true,
+		}
+	}
+
+	event, _ := eventing.SendStatusEvent(&eventing.StatusEvent{
+		InProgress: true,
+		Message:    fmt.Sprintf("Purging %d tags from %s", len(tags), repository),
+	})
+	defer event.Done()
+
+	var sb strings.Builder
+	sb.WriteString(style.Title("Purge results") + "\n\n")
+	deleted := 0
+	for i, tag := range tags {
+		event.Message = fmt.Sprintf("Purging tags from %s (%d/%d)", repository, i+1, len(tags))
+		event.Update()
+
+		_, err := e.doRequest(ctx, "DELETE", fmt.Sprintf("https://%s/acr/v1/%s/_tags/%s", loginServer, repository, tag), accessToken)
+		if err != nil {
+			sb.WriteString(style.Warning(fmt.Sprintf("  ! %s: %s", tag, err)) + "\n")
+			continue
+		}
+		deleted++
+		sb.WriteString(style.Removed("  - "+tag) + "\n")
+	}
+	sb.WriteString(fmt.Sprintf("\nDeleted %d of %d tags.\n", deleted, len(tags)))
+
+	return ExpanderResult{
+		Response:          ExpanderResponse{Response: sb.String(), ResponseType: interfaces.ResponsePlainText},
+		SourceDescription: "ContainerRegistryExpander request",
+		IsPrimaryResponse: true,
+	}
+}
+
+// listAllTags pages through all of the tags in a repository
+func (e *ContainerRegistryExpander) listAllTags(ctx context.Context, loginServer string, repository string) ([]containerRegistryTag, error) {
+	accessToken, err := e.getRegistryToken(ctx, loginServer, fmt.Sprintf("repository:%s:metadata_read", repository))
+	if err != nil {
+		return nil, err
+	}
+
+	tags := []containerRegistryTag{}
+	last := ""
+	for {
+		url := fmt.Sprintf("https://%s/acr/v1/%s/_tags?n=100", loginServer, repository)
+		if last != "" {
+			url += "&last=" + last
+		}
+		responseBuf, err := e.doRequest(ctx, "GET", url, accessToken)
+		if err != nil {
+			return nil, err
+		}
+		var response struct {
+			Tags []containerRegistryTag `json:"tags"`
+		}
+		if err := json.Unmarshal(responseBuf, &response); err != nil {
+			return nil, fmt.Errorf("Error unmarshalling tags response: %s", err)
+		}
+		if len(response.Tags) == 0 {
+			return tags, nil
+		}
+		tags = append(tags, response.Tags...)
+		last = response.Tags[len(response.Tags)-1].Name
+	}
+}
+
+// selectTagsToPurge returns the tags matching the filter that were last updated before the cutoff, oldest first
+func selectTagsToPurge(tags []containerRegistryTag, cutoff time.Time, filter *regexp.Regexp) []containerRegistryTag {
+	result := []containerRegistryTag{}
+	for _, tag := range tags {
+		if !filter.MatchString(tag.Name) {
+			continue
+		}
+		lastUpdated, err := time.Parse(time.RFC3339, tag.LastUpdateTime)
+		if err != nil {
+			// Don't delete anything we can't be sure of the age of
+			continue
+		}
+		if lastUpdated.Before(cutoff) {
+			result = append(result, tag)
+		}
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].LastUpdateTime < result[j].LastUpdateTime
+	})
+	return result
+}
@@ -0,0 +1,41 @@
+package expanders
+
+import (
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/nbio/st"
+)
+
+func Test_ContainerRegistry_SelectTagsToPurge(t *testing.T) {
+	tags := []containerRegistryTag{
+		{Name: "v2", LastUpdateTime: "2020-03-01T00:00:00Z"},
+		{Name: "v1", LastUpdateTime: "2020-01-01T00:00:00Z"},
+		{Name: "latest", LastUpdateTime: "2020-01-01T00:00:00Z"},
+		{Name: "v3", LastUpdateTime: "2020-06-01T00:00:00Z"},
+		{Name: "v0", LastUpdateTime: "not a time"},
+	}
+	cutoff, _ := time.Parse(time.RFC3339, "2020-05-01T00:00:00Z")
+	filter := regexp.MustCompile("^(?:v.*)$")
+
+	result := selectTagsToPurge(tags, cutoff, filter)
+
+	st.Expect(t, len(result), 2)
+	st.Expect(t, result[0].Name, "v1")
+	st.Expect(t, result[1].Name, "v2")
+}
+
+func Test_ContainerRegistry_ParseImportSource(t *testing.T) {
+	registry, image := parseImportSource("nginx")
+	st.Expect(t, registry, "docker.io")
+	st.Expect(t, image, "library/nginx:latest")
+
+	registry, image = parseImportSource("mcr.microsoft.com/dotnet/runtime:5.0")
+	st.Expect(t, registry, "mcr.microsoft.com")
+	st.Expect(t, image, "dotnet/runtime:5.0")
+
+	registry, image = parseImportSource("myorg/myimage:1.0")
+	st.Expect(t, registry, "docker.io")
+	st.Expect(t, image, "myorg/myimage:1.0")
+}
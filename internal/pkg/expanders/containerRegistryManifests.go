@@ -0,0 +1,180 @@
+package expanders
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/lawrencegripper/azbrowse/internal/pkg/interfaces"
+	"github.com/lawrencegripper/azbrowse/internal/pkg/style"
+)
+
+// manifestAcceptHeader requests any of the manifest formats so that multi-arch indexes are returned as-is
+var manifestAcceptHeader = strings.Join([]string{
+	"application/vnd.docker.distribution.manifest.v2+json",
+	"application/vnd.docker.distribution.manifest.list.v2+json",
+	"application/vnd.oci.image.manifest.v1+json",
+	"application/vnd.oci.image.index.v1+json",
+}, ", ")
+
+type imageDescriptor struct {
+	MediaType string `json:"mediaType"`
+	Size      int64  `json:"size"`
+	Digest    string `json:"digest"`
+	Platform  *struct {
+		Architecture string `json:"architecture"`
+		OS           string `json:"os"`
+		OSVersion    string `json:"os.version"`
+		Variant      string `json:"variant"`
+	} `json:"platform,omitempty"`
+}
+
+// imageManifest covers both single image manifests and multi-arch indexes (manifest lists)
+type imageManifest struct {
+	SchemaVersion int               `json:"schemaVersion"`
+	MediaType     string            `json:"mediaType"`
+	Config        *imageDescriptor  `json:"config"`
+	Layers        []imageDescriptor `json:"layers"`
+	Manifests     []imageDescriptor `json:"manifests"`
+}
+
+type imageConfig struct {
+	Architecture string `json:"architecture"`
+	OS           string `json:"os"`
+	Created      string `json:"created"`
+	Config       struct {
+		Entrypoint []string `json:"Entrypoint"`
+		Cmd        []string `json:"Cmd"`
+	} `json:"config"`
+}
+
+func (e *ContainerRegistryExpander) expandRepositoryManifestDetail(ctx context.Context, currentItem *TreeNode) ExpanderResult {
+	loginServer := currentItem.Metadata["loginServer"]
+	repository := currentItem.Metadata["repository"]
+	digest := currentItem.Metadata["digest"]
+
+	accessToken, err := e.getRegistryToken(ctx, loginServer, fmt.Sprintf("repository:%s:pull", repository))
+	if err != nil {
+		return ExpanderResult{
+			Err:               err,
+			SourceDescription: "ContainerRegistryExpander request",
+			IsPrimaryResponse: true,
+		}
+	}
+
+	responseBuf, err := e.doRequestWithHeaders(ctx, "GET", fmt.Sprintf("https://%s/v2/%s/manifests/%s", loginServer, repository, digest), accessToken, map[string]string{"Accept": manifestAcceptHeader})
+	if err != nil {
+		return ExpanderResult{
+			Err:               err,
+			SourceDescription: "ContainerRegistryExpander request",
+			IsPrimaryResponse: true,
+		}
+	}
+
+	var manifest imageManifest
+	if err := json.Unmarshal(responseBuf, &manifest); err != nil {
+		return ExpanderResult{
+			Err:               fmt.Errorf("Error unmarshalling manifest response: %s, %s", err, string(responseBuf)),
+			SourceDescription: "ContainerRegistryExpander request",
+			IsPrimaryResponse: true,
+		}
+	}
+
+	newItems := []*TreeNode{}
+	var config *imageConfig
+	if len(manifest.Manifests) > 0 {
+		// Multi-arch index: add a node per platform so each image can be inspected
+		for _, platformManifest := range manifest.Manifests {
+			node := e.getCreateManifestDetailNodeFunc(loginServer, repository, formatPlatform(platformManifest))(currentItem, platformManifest.Digest)
+			node.Display = node.Name + "\n   " + style.Subtle(platformManifest.Digest)
+			newItems = append(newItems, node)
+		}
+	} else if manifest.Config != nil {
+		// The config blob is small and gives the platform and creation time for the image
+		configBuf, err := e.doRequest(ctx, "GET", fmt.Sprintf("https://%s/v2/%s/blobs/%s", loginServer, repository, manifest.Config.Digest), accessToken)
+		if err == nil {
+			var tempConfig imageConfig
+			if err := json.Unmarshal(configBuf, &tempConfig); err == nil {
+				config = &tempConfig
+			}
+		}
+	}
+
+	return ExpanderResult{
+		Response:          ExpanderResponse{Response: renderImageManifest(digest, manifest, config), ResponseType: interfaces.ResponsePlainText},
+		SourceDescription: "ContainerRegistryExpander request",
+		Nodes:             newItems,
+		IsPrimaryResponse: true,
+	}
+}
+
+// renderImageManifest formats a manifest as a breakdown of the config and layers, or the platforms for an index
+func renderImageManifest(digest string, manifest imageManifest, config *imageConfig) string {
+	var sb strings.Builder
+	sb.WriteString(style.Title("Manifest") + " " + digest + "\n")
+	sb.WriteString(style.Subtle("Media type: "+manifest.MediaType) + "\n\n")
+
+	if len(manifest.Manifests) > 0 {
+		sb.WriteString(style.Title(fmt.Sprintf("Platforms (%d)", len(manifest.Manifests))) + "\n")
+		for _, platformManifest := range manifest.Manifests {
+			sb.WriteString(fmt.Sprintf("  %-20s %10s  %s\n", formatPlatform(platformManifest), formatByteSize(platformManifest.Size), platformManifest.Digest))
+		}
+		return sb.String()
+	}
+
+	if manifest.Config != nil {
+		sb.WriteString(style.Title("Config") + "\n")
+		sb.WriteString("  Digest:     " + manifest.Config.Digest + "\n")
+		sb.WriteString("  Size:       " + formatByteSize(manifest.Config.Size) + "\n")
+		if config != nil {
+			sb.WriteString("  Platform:   " + config.OS + "/" + config.Architecture + "\n")
+			sb.WriteString("  Created:    " + config.Created + "\n")
+			if len(config.Config.Entrypoint) > 0 {
+				sb.WriteString("  Entrypoint: " + strings.Join(config.Config.Entrypoint, " ") + "\n")
+			}
+			if len(config.Config.Cmd) > 0 {
+				sb.WriteString("  Cmd:        " + strings.Join(config.Config.Cmd, " ") + "\n")
+			}
+		}
+		sb.WriteString("\n")
+	}
+
+	var totalSize int64
+	for _, layer := range manifest.Layers {
+		totalSize += layer.Size
+	}
+	sb.WriteString(style.Title(fmt.Sprintf("Layers (%d, total %s)", len(manifest.Layers), formatByteSize(totalSize))) + "\n")
+	for i, layer := range manifest.Layers {
+		sb.WriteString(fmt.Sprintf("  %3d  %10s  %s\n", i+1, formatByteSize(layer.Size), layer.Digest))
+	}
+	return sb.String()
+}
+
+func formatPlatform(descriptor imageDescriptor) string {
+	if descriptor.Platform == nil {
+		return "unknown"
+	}
+	platform := descriptor.Platform.OS + "/" + descriptor.Platform.Architecture
+	if descriptor.Platform.Variant != "" {
+		platform += "/" + descriptor.Platform.Variant
+	}
+	if descriptor.Platform.OSVersion != "" {
+		platform += " (" + descriptor.Platform.OSVersion + ")"
+	}
+	return platform
+}
+
+// formatByteSize converts a size in bytes to a human readable string
+func formatByteSize(size int64) string {
+	const unit = 1024
+	if size < unit {
+		return fmt.Sprintf("%d B", size)
+	}
+	div, exp := int64(unit), 0
+	for n := size / unit; n >= unit; n /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %ciB", float64(size)/float64(div), "KMGTPE"[exp])
+}
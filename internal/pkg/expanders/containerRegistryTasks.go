@@ -0,0 +1,287 @@
+package expanders
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/awesome-gocui/gocui"
+	"github.com/lawrencegripper/azbrowse/internal/pkg/errorhandling"
+	"github.com/lawrencegripper/azbrowse/internal/pkg/eventing"
+	"github.com/lawrencegripper/azbrowse/internal/pkg/interfaces"
+	"github.com/lawrencegripper/azbrowse/internal/pkg/style"
+)
+
+const containerRegistryTasksAPIVersion = "2019-06-01-preview"
+
+// containerRegistryRunLogPollInterval is the delay between refreshes of the log for an in-progress run
+var containerRegistryRunLogPollInterval = time.Second * 3
+
+// ContainerRegistryRunsResponse is returned by a request for ACR task runs
+type ContainerRegistryRunsResponse struct {
+	Value    []ContainerRegistryRun `json:"value"`
+	NextLink string                 `json:"nextLink"`
+}
+
+// ContainerRegistryRun is a single ACR task run
+type ContainerRegistryRun struct {
+	ID         string `json:"id"`
+	Name       string `json:"name"`
+	Properties struct {
+		RunID        string `json:"runId"`
+		Status       string `json:"status"`
+		Task         string `json:"task"`
+		RunType      string `json:"runType"`
+		TriggerType  string `json:"triggerType"`
+		CreateTime   string `json:"createTime"`
+		StartTime    string `json:"startTime"`
+		FinishTime   string `json:"finishTime"`
+		OutputImages []struct {
+			Registry   string `json:"registry"`
+			Repository string `json:"repository"`
+			Tag        string `json:"tag"`
+		} `json:"outputImages"`
+	} `json:"properties"`
+}
+
+func isContainerRegistryRunComplete(status string) bool {
+	switch status {
+	case "Succeeded", "Failed", "Canceled", "Error", "Timeout":
+		return true
+	}
+	return false
+}
+
+// getRegistryIDFromChildID returns the registry ID for the ID of a resource under the registry (e.g. a task or run)
+func getRegistryIDFromChildID(childID string, childSegment string) string {
+	index := strings.Index(strings.ToLower(childID), "/"+strings.ToLower(childSegment)+"/")
+	if index < 0 {
+		return childID
+	}
+	return childID[:index]
+}
+
+func (e *ContainerRegistryExpander) expandTask(ctx context.Context, currentItem *TreeNode) ExpanderResult {
+	taskName := currentItem.Name
+	registryID := getRegistryIDFromChildID(currentItem.ID, "tasks")
+
+	newItems := []*TreeNode{
+		{
+			Parentid:              currentItem.ID,
+			ID:                    currentItem.ID + "/<runs>",
+			Namespace:             "containerRegistry",
+			Name:                  "Runs",
+			Display:               "Runs\n   " + style.Subtle("Runs for task "+taskName),
+			ItemType:              "containerRegistry.task.runs",
+			ExpandURL:             ExpandURLNotSupported,
+			SuppressSwaggerExpand: true,
+			SuppressGenericExpand: true,
+			Metadata: map[string]string{
+				"RegistryID": registryID,
+				"TaskName":   taskName,
+			},
+		},
+	}
+
+	return ExpanderResult{
+		Nodes:             newItems,
+		SourceDescription: "ContainerRegistryExpander request",
+		IsPrimaryResponse: false,
+	}
+}
+
+func (e *ContainerRegistryExpander) expandTaskRuns(ctx context.Context, currentItem *TreeNode) ExpanderResult {
+	registryID := currentItem.Metadata["RegistryID"]
+	taskName := currentItem.Metadata["TaskName"]
+
+	filter := url.QueryEscape(fmt.Sprintf("TaskName eq '%s'", taskName))
+	data, err := e.armClient.DoRequest(ctx, "GET", fmt.Sprintf("%s/runs?api-version=%s&$top=50&$filter=%s", registryID, containerRegistryTasksAPIVersion, filter))
+	if err != nil {
+		return ExpanderResult{
+			Err:               fmt.Errorf("Error getting runs: %s", err),
+			SourceDescription: "ContainerRegistryExpander request",
+			IsPrimaryResponse: true,
+		}
+	}
+
+	var runs ContainerRegistryRunsResponse
+	if err := json.Unmarshal([]byte(data), &runs); err != nil {
+		return ExpanderResult{
+			Err:               fmt.Errorf("Error unmarshalling runs response: %s", err),
+			SourceDescription: "ContainerRegistryExpander request",
+			IsPrimaryResponse: true,
+		}
+	}
+
+	newItems := []*TreeNode{}
+	for _, run := range runs.Value {
+		display := run.Name + "\n   " +
+			style.Subtle("Status:  "+run.Properties.Status) + "\n   " +
+			style.Subtle("Trigger: "+run.Properties.TriggerType) + "\n   " +
+			style.Subtle("Started: "+run.Properties.StartTime)
+		if run.Properties.FinishTime != "" {
+			display += "\n   " + style.Subtle("Finished: "+run.Properties.FinishTime)
+		}
+		newItems = append(newItems, &TreeNode{
+			Parentid:        currentItem.ID,
+			ID:              run.ID,
+			Namespace:       "containerRegistry",
+			Name:            run.Name,
+			Display:         display,
+			ItemType:        "containerRegistry.run",
+			ExpandURL:       run.ID + "?api-version=" + containerRegistryTasksAPIVersion,
+			StatusIndicator: DrawStatus(run.Properties.Status),
+			Metadata: map[string]string{
+				"RunID": run.ID,
+			},
+		})
+	}
+
+	return ExpanderResult{
+		Response:          ExpanderResponse{Response: data, ResponseType: interfaces.ResponseJSON},
+		SourceDescription: "ContainerRegistryExpander request",
+		Nodes:             newItems,
+		IsPrimaryResponse: true,
+	}
+}
+
+func (e *ContainerRegistryExpander) expandRun(ctx context.Context, currentItem *TreeNode) ExpanderResult {
+	// The run itself is displayed by the swagger or default expander so just add the log
+	newItems := []*TreeNode{
+		{
+			Parentid:              currentItem.ID,
+			ID:                    currentItem.ID + "/<log>",
+			Namespace:             "containerRegistry",
+			Name:                  "Log",
+			Display:               "Log",
+			ItemType:              "containerRegistry.run.log",
+			ExpandURL:             ExpandURLNotSupported,
+			SuppressSwaggerExpand: true,
+			SuppressGenericExpand: true,
+			WatchIntervalSeconds:  logWatchIntervalSeconds,
+			Metadata: map[string]string{
+				"RunID": currentItem.ID,
+			},
+		},
+	}
+
+	return ExpanderResult{
+		Nodes:             newItems,
+		SourceDescription: "ContainerRegistryExpander request",
+		IsPrimaryResponse: false,
+	}
+}
+
+func (e *ContainerRegistryExpander) expandRunLog(ctx context.Context, currentItem *TreeNode) ExpanderResult {
+	runID := currentItem.Metadata["RunID"]
+
+	log, status, err := e.getRunLog(ctx, runID)
+	if err != nil {
+		return ExpanderResult{
+			Err:               err,
+			SourceDescription: "ContainerRegistryExpander request",
+			IsPrimaryResponse: true,
+		}
+	}
+
+	if !isContainerRegistryRunComplete(status) && e.contentPanel != nil {
+		go e.streamRunLog(ctx, runID)
+	}
+
+	return ExpanderResult{
+		Response:          ExpanderResponse{Response: log, ResponseType: interfaces.ResponsePlainText},
+		SourceDescription: "ContainerRegistryExpander request",
+		IsPrimaryResponse: true,
+	}
+}
+
+// streamRunLog refreshes the log in the item view until the run completes or the user navigates away
+func (e *ContainerRegistryExpander) streamRunLog(ctx context.Context, runID string) {
+	// recover from panic, if one occurrs, and leave terminal usable
+	defer errorhandling.RecoveryWithCleanup()
+
+	preNavigateChannel := eventing.SubscribeToTopic("list.prenavigate")
+	defer eventing.Unsubscribe(preNavigateChannel)
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-preNavigateChannel:
+			return
+		case <-time.After(containerRegistryRunLogPollInterval):
+		}
+
+		log, status, err := e.getRunLog(ctx, runID)
+		if err != nil {
+			eventing.SendFailureStatusFromError("Failed to refresh run log", err)
+			return
+		}
+
+		title := "Run log (" + status + ")"
+		if !isContainerRegistryRunComplete(status) {
+			title = "Run log (" + status + " - streaming)"
+		}
+		e.contentPanel.SetContent(log, interfaces.ResponsePlainText, title)
+		// Force UI to re-render to pickup
+		e.gui.Update(func(g *gocui.Gui) error {
+			return nil
+		})
+
+		if isContainerRegistryRunComplete(status) {
+			return
+		}
+	}
+}
+
+// getRunLog returns the current content of the log for a run along with the status of the run
+func (e *ContainerRegistryExpander) getRunLog(ctx context.Context, runID string) (string, string, error) {
+	data, err := e.armClient.DoRequest(ctx, "GET", runID+"?api-version="+containerRegistryTasksAPIVersion)
+	if err != nil {
+		return "", "", fmt.Errorf("Error getting run: %s", err)
+	}
+	var run ContainerRegistryRun
+	if err := json.Unmarshal([]byte(data), &run); err != nil {
+		return "", "", fmt.Errorf("Error unmarshalling run response: %s", err)
+	}
+
+	data, err = e.armClient.DoRequest(ctx, "POST", runID+"/listLogSasUrl?api-version="+containerRegistryTasksAPIVersion)
+	if err != nil {
+		return "", "", fmt.Errorf("Error getting run log URL: %s", err)
+	}
+	var logLink struct {
+		LogLink string `json:"logLink"`
+	}
+	if err := json.Unmarshal([]byte(data), &logLink); err != nil {
+		return "", "", fmt.Errorf("Error unmarshalling run log URL response: %s", err)
+	}
+
+	// The log link is a SAS URL to a blob so doesn't need authentication
+	req, err := http.NewRequest("GET", logLink.LogLink, nil)
+	if err != nil {
+		return "", "", fmt.Errorf("Failed to create request: %s", err)
+	}
+	response, err := e.client.Do(req.WithContext(ctx))
+	if err != nil {
+		return "", "", fmt.Errorf("Request failed: %s", err)
+	}
+	defer response.Body.Close() //nolint: errcheck
+	if response.StatusCode == http.StatusNotFound {
+		// The log blob isn't created until the run starts
+		return "Waiting for the run to start...", run.Properties.Status, nil
+	}
+	buf, err := ioutil.ReadAll(response.Body)
+	if err != nil {
+		return "", "", fmt.Errorf("Failed to read body: %s", err)
+	}
+	if response.StatusCode < 200 || response.StatusCode >= 300 {
+		return "", "", fmt.Errorf("Getting run log failed %v: %s", response.StatusCode, string(buf))
+	}
+
+	return string(buf), run.Properties.Status, nil
+}
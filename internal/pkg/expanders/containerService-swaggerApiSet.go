@@ -37,16 +37,18 @@ var maxTailLines = 100
 
 // SwaggerAPISetContainerService holds the config for working with an AKS cluster API
 type SwaggerAPISetContainerService struct {
-	resourceTypes []swagger.ResourceType
-	httpClient    http.Client
-	clusterID     string
-	serverURL     string
+	resourceTypes       []swagger.ResourceType
+	resourceTypeMatcher *swagger.ResourceTypeMatcher
+	httpClient          http.Client
+	clusterID           string
+	serverURL           string
 }
 
 // NewSwaggerAPISetContainerService creates a new SwaggerAPISetContainerService
 func NewSwaggerAPISetContainerService(resourceTypes []swagger.ResourceType, httpClient http.Client, clusterID string, serverURL string) SwaggerAPISetContainerService {
 	c := SwaggerAPISetContainerService{}
 	c.resourceTypes = resourceTypes
+	c.resourceTypeMatcher = swagger.NewResourceTypeMatcher(c.resourceTypes)
 	c.httpClient = httpClient
 	c.clusterID = clusterID
 	c.serverURL = serverURL
@@ -75,6 +77,11 @@ func (c SwaggerAPISetContainerService) GetResourceTypes() []swagger.ResourceType
 	return c.resourceTypes
 }
 
+// GetResourceTypeMatcher returns the ResourceTypeMatcher for the ResourceTypes in the API Set
+func (c SwaggerAPISetContainerService) GetResourceTypeMatcher() *swagger.ResourceTypeMatcher {
+	return c.resourceTypeMatcher
+}
+
 func (c SwaggerAPISetContainerService) doRequest(ctx context.Context, verb string, url string) (string, error) {
 	return c.doRequestWithBody(ctx, verb, url, "")
 }
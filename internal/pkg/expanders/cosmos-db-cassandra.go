@@ -0,0 +1,124 @@
+package expanders
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/lawrencegripper/azbrowse/internal/pkg/interfaces"
+)
+
+// The Cassandra API only exposes row data over CQL (there is no REST path for it) so browsing is limited
+// to the table schema, which is rendered as CQL from the ARM resource
+
+const cosmosdbCassandraAPIVersion = "2020-04-01"
+
+// CosmosDbCassandraTable is used to unmarshal the ARM response for a Cassandra table
+type CosmosDbCassandraTable struct {
+	Name       string `json:"name"`
+	Properties struct {
+		Resource struct {
+			ID         string `json:"id"`
+			DefaultTTL *int   `json:"defaultTtl"`
+			Schema     struct {
+				Columns []struct {
+					Name string `json:"name"`
+					Type string `json:"type"`
+				} `json:"columns"`
+				PartitionKeys []struct {
+					Name string `json:"name"`
+				} `json:"partitionKeys"`
+				ClusterKeys []struct {
+					Name    string `json:"name"`
+					OrderBy string `json:"orderBy"`
+				} `json:"clusterKeys"`
+			} `json:"schema"`
+		} `json:"resource"`
+	} `json:"properties"`
+}
+
+func (e *CosmosDbExpander) expandCassandraSchema(ctx context.Context, item *TreeNode) ExpanderResult {
+	tableID := item.Metadata["TableID"]
+
+	data, err := e.armClient.DoRequest(ctx, "GET", tableID+"?api-version="+cosmosdbCassandraAPIVersion)
+	if err != nil {
+		return ExpanderResult{
+			Err:               fmt.Errorf("Error getting table: %s", err),
+			IsPrimaryResponse: true,
+			SourceDescription: "CosmosDbExpander request",
+		}
+	}
+
+	var table CosmosDbCassandraTable
+	if err := json.Unmarshal([]byte(data), &table); err != nil {
+		return ExpanderResult{
+			Err:               fmt.Errorf("Error unmarshalling table response: %s", err),
+			IsPrimaryResponse: true,
+			SourceDescription: "CosmosDbExpander request",
+		}
+	}
+
+	keyspaceName := ""
+	if parts := strings.Split(tableID, "/"); len(parts) > 2 {
+		keyspaceName = parts[len(parts)-3]
+	}
+
+	return ExpanderResult{
+		Response:          ExpanderResponse{Response: renderCassandraTableSchema(keyspaceName, table), ResponseType: interfaces.ResponsePlainText},
+		IsPrimaryResponse: true,
+		SourceDescription: "CosmosDbExpander request",
+	}
+}
+
+// renderCassandraTableSchema formats the table schema as a CQL CREATE TABLE statement
+func renderCassandraTableSchema(keyspaceName string, table CosmosDbCassandraTable) string {
+	resource := table.Properties.Resource
+	tableName := resource.ID
+	if tableName == "" {
+		tableName = table.Name
+	}
+	if keyspaceName != "" {
+		tableName = keyspaceName + "." + tableName
+	}
+
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("CREATE TABLE %s (\n", tableName))
+	for _, column := range resource.Schema.Columns {
+		sb.WriteString(fmt.Sprintf("    %s %s,\n", column.Name, column.Type))
+	}
+
+	partitionKeys := []string{}
+	for _, key := range resource.Schema.PartitionKeys {
+		partitionKeys = append(partitionKeys, key.Name)
+	}
+	primaryKey := strings.Join(partitionKeys, ", ")
+	if len(partitionKeys) > 1 {
+		primaryKey = "(" + primaryKey + ")"
+	}
+	clusterKeys := []string{}
+	clusterOrder := []string{}
+	for _, key := range resource.Schema.ClusterKeys {
+		clusterKeys = append(clusterKeys, key.Name)
+		if key.OrderBy != "" {
+			clusterOrder = append(clusterOrder, key.Name+" "+strings.ToUpper(key.OrderBy))
+		}
+	}
+	if len(clusterKeys) > 0 {
+		primaryKey += ", " + strings.Join(clusterKeys, ", ")
+	}
+	sb.WriteString(fmt.Sprintf("    PRIMARY KEY (%s)\n)", primaryKey))
+
+	options := []string{}
+	if len(clusterOrder) > 0 {
+		options = append(options, "CLUSTERING ORDER BY ("+strings.Join(clusterOrder, ", ")+")")
+	}
+	if resource.DefaultTTL != nil {
+		options = append(options, fmt.Sprintf("default_time_to_live = %d", *resource.DefaultTTL))
+	}
+	if len(options) > 0 {
+		sb.WriteString(" WITH " + strings.Join(options, "\n    AND "))
+	}
+	sb.WriteString(";\n\n-- Row data for Cassandra API tables is only available over CQL\n")
+	return sb.String()
+}
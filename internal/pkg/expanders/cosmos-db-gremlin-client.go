@@ -0,0 +1,174 @@
+package expanders
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/gorilla/websocket"
+	uuid "github.com/satori/go.uuid"
+)
+
+// gremlinClient is a minimal client for the Cosmos DB Gremlin endpoint (which is only available over websockets)
+type gremlinClient struct {
+	conn *websocket.Conn
+}
+
+const (
+	gremlinMimeType       = "application/vnd.gremlin-v2.0+json"
+	gremlinDefaultTimeout = time.Minute * 2
+	gremlinMaxMessageSize = 64 * 1024 * 1024
+)
+
+type gremlinRequest struct {
+	RequestID string                 `json:"requestId"`
+	Op        string                 `json:"op"`
+	Processor string                 `json:"processor"`
+	Args      map[string]interface{} `json:"args"`
+}
+
+type gremlinResponse struct {
+	RequestID string `json:"requestId"`
+	Status    struct {
+		Code       int                    `json:"code"`
+		Message    string                 `json:"message"`
+		Attributes map[string]interface{} `json:"attributes"`
+	} `json:"status"`
+	Result struct {
+		Data json.RawMessage `json:"data"`
+	} `json:"result"`
+}
+
+// gremlinResult is the combined result from all of the responses to a query
+type gremlinResult struct {
+	Data          []interface{}
+	RequestCharge float64
+}
+
+// newGremlinClient connects to the Gremlin endpoint for a Cosmos DB account
+func newGremlinClient(ctx context.Context, accountName string) (*gremlinClient, error) {
+	return dialGremlinClient(ctx, "wss://"+accountName+".gremlin.cosmos.azure.com:443/")
+}
+
+// dialGremlinClient connects to the Gremlin endpoint at the websocket URL
+func dialGremlinClient(ctx context.Context, url string) (*gremlinClient, error) {
+	dialer := &websocket.Dialer{
+		Proxy:            http.ProxyFromEnvironment,
+		HandshakeTimeout: gremlinDefaultTimeout,
+	}
+	conn, response, err := dialer.DialContext(ctx, url, nil)
+	if err != nil {
+		if response != nil {
+			return nil, fmt.Errorf("Error connecting to %s: %s (%s)", url, err, response.Status)
+		}
+		return nil, fmt.Errorf("Error connecting to %s: %s", url, err)
+	}
+	deadline, ok := ctx.Deadline()
+	if !ok {
+		deadline = time.Now().Add(gremlinDefaultTimeout)
+	}
+	_ = conn.SetReadDeadline(deadline)
+	_ = conn.SetWriteDeadline(deadline)
+	conn.SetReadLimit(gremlinMaxMessageSize)
+
+	return &gremlinClient{conn: conn}, nil
+}
+
+// Close closes the connection
+func (c *gremlinClient) Close() error {
+	_ = c.conn.WriteMessage(websocket.CloseMessage, websocket.FormatCloseMessage(websocket.CloseNormalClosure, ""))
+	return c.conn.Close()
+}
+
+// Execute runs a query, authenticating with the username (/dbs/{db}/colls/{graph}) and password (account key) when challenged
+func (c *gremlinClient) Execute(query string, username string, password string) (gremlinResult, error) {
+	requestID := uuid.NewV4().String()
+	err := c.send(gremlinRequest{
+		RequestID: requestID,
+		Op:        "eval",
+		Processor: "",
+		Args: map[string]interface{}{
+			"gremlin":  query,
+			"bindings": map[string]interface{}{},
+			"language": "gremlin-groovy",
+		},
+	})
+	if err != nil {
+		return gremlinResult{}, err
+	}
+
+	result := gremlinResult{Data: []interface{}{}}
+	for {
+		message, err := c.readMessage()
+		if err != nil {
+			return result, err
+		}
+		var response gremlinResponse
+		if err := json.Unmarshal(message, &response); err != nil {
+			return result, fmt.Errorf("Error unmarshalling gremlin response: %s", err)
+		}
+		if charge, ok := response.Status.Attributes["x-ms-total-request-charge"].(float64); ok {
+			result.RequestCharge = charge
+		}
+
+		switch response.Status.Code {
+		case 407: // authentication required
+			auth := "\x00" + username + "\x00" + password
+			err := c.send(gremlinRequest{
+				RequestID: requestID,
+				Op:        "authentication",
+				Processor: "",
+				Args: map[string]interface{}{
+					"SASL": base64.StdEncoding.EncodeToString([]byte(auth)),
+				},
+			})
+			if err != nil {
+				return result, err
+			}
+		case 200, 206: // success, partial content
+			if len(response.Result.Data) > 0 && string(response.Result.Data) != "null" {
+				var data []interface{}
+				if err := json.Unmarshal(response.Result.Data, &data); err != nil {
+					return result, fmt.Errorf("Error unmarshalling gremlin result: %s", err)
+				}
+				result.Data = append(result.Data, data...)
+			}
+			if response.Status.Code == 200 {
+				return result, nil
+			}
+		case 204: // no content
+			return result, nil
+		default:
+			return result, fmt.Errorf("Gremlin query failed (%d): %s", response.Status.Code, response.Status.Message)
+		}
+	}
+}
+
+func (c *gremlinClient) send(request gremlinRequest) error {
+	buf, err := json.Marshal(request)
+	if err != nil {
+		return fmt.Errorf("Error marshalling gremlin request: %s", err)
+	}
+	// Messages are prefixed with the length of the mime type followed by the mime type
+	payload := append([]byte{byte(len(gremlinMimeType))}, []byte(gremlinMimeType)...)
+	payload = append(payload, buf...)
+	if err := c.conn.WriteMessage(websocket.BinaryMessage, payload); err != nil {
+		return fmt.Errorf("Error sending gremlin request: %s", err)
+	}
+	return nil
+}
+
+// readMessage reads a complete data message (the websocket library reassembles fragments and replies to pings)
+func (c *gremlinClient) readMessage() ([]byte, error) {
+	_, message, err := c.conn.ReadMessage()
+	if err != nil {
+		if websocket.IsCloseError(err, websocket.CloseNormalClosure, websocket.CloseGoingAway) {
+			return nil, fmt.Errorf("Connection closed by server")
+		}
+		return nil, fmt.Errorf("Error reading gremlin response: %s", err)
+	}
+	return message, nil
+}
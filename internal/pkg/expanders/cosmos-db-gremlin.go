@@ -0,0 +1,341 @@
+package expanders
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/awesome-gocui/gocui"
+	"github.com/lawrencegripper/azbrowse/internal/pkg/interfaces"
+	"github.com/lawrencegripper/azbrowse/internal/pkg/style"
+)
+
+// Graphs are stored as documents in the underlying container so vertices and edges are listed through the core (SQL) gateway.
+// Edge documents are marked with _isEdge and link the out vertex (_vertexId) to the in vertex (_sink)
+
+const (
+	cosmosdbGremlinVerticesQuery = "SELECT * FROM c WHERE NOT IS_DEFINED(c._isEdge)"
+	cosmosdbGremlinEdgesQuery    = "SELECT * FROM c WHERE c._isEdge = true"
+)
+
+// CosmosDbGremlinConnectionDetails groups the details needed for connecting to a Cosmos DB graph
+type CosmosDbGremlinConnectionDetails struct {
+	AccountName  string
+	DatabaseName string
+	GraphName    string
+	AccountKey   string
+}
+
+func (e *CosmosDbExpander) expandGremlinVertices(ctx context.Context, item *TreeNode) ExpanderResult {
+	return e.expandGremlinDocuments(ctx, item, CosmosDbQuery{Query: cosmosdbGremlinVerticesQuery}, cosmosdbListGremlinVerticesContinuation)
+}
+
+func (e *CosmosDbExpander) expandGremlinEdges(ctx context.Context, item *TreeNode) ExpanderResult {
+	return e.expandGremlinDocuments(ctx, item, CosmosDbQuery{Query: cosmosdbGremlinEdgesQuery}, cosmosdbListGremlinEdgesContinuation)
+}
+
+// expandGremlinDocuments lists a page of vertices or edges for the query, adding a continuation node when there are more results
+func (e *CosmosDbExpander) expandGremlinDocuments(ctx context.Context, item *TreeNode, query CosmosDbQuery, continuationItemType string) ExpanderResult {
+	accountName := item.Metadata["AccountName"]
+	databaseName := item.Metadata["DatabaseName"]
+	containerName := item.Metadata["ContainerName"]
+	continuationToken := item.Metadata["ContinuationToken"]
+
+	accountKey := item.Metadata["AccountKey"]
+	if accountKey == "" {
+		var err error
+		accountKey, err = e.getAccountKey(ctx, item)
+		if err != nil {
+			return ExpanderResult{
+				Err:               fmt.Errorf("Error getting account key: %s", err),
+				IsPrimaryResponse: true,
+				SourceDescription: "CosmosDbExpander request",
+			}
+		}
+	}
+
+	response, err := e.queryDocuments(ctx, accountName, databaseName, containerName, accountKey, query, continuationToken)
+	if err != nil {
+		return ExpanderResult{
+			Err:               err,
+			IsPrimaryResponse: true,
+			SourceDescription: "CosmosDbExpander request",
+		}
+	}
+
+	var list CosmosDbListDocumentResponse
+	if err = json.Unmarshal(response.Data, &list); err != nil {
+		return ExpanderResult{
+			Err:               fmt.Errorf("Error unmarshalling response: %s", err),
+			IsPrimaryResponse: true,
+			SourceDescription: "CosmosDbExpander request",
+		}
+	}
+
+	nodes := []*TreeNode{}
+	for _, document := range list.Documents {
+		document, ok := document.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		nodes = append(nodes, e.getGremlinDocumentNode(item, document, accountName, databaseName, containerName, accountKey))
+	}
+
+	if continuationToken := response.Headers.Get("x-ms-continuation"); continuationToken != "" && continuationItemType != "" {
+		nodes = append(nodes, &TreeNode{
+			Parentid:      item.ID,
+			Namespace:     "cosmosdb",
+			ID:            item.ID + "/" + "...more",
+			Name:          "more...",
+			Display:       "more...",
+			ItemType:      continuationItemType,
+			ExpandURL:     ExpandURLNotSupported,
+			ExpandInPlace: true,
+			Metadata: map[string]string{
+				"AccountName":       accountName,
+				"DatabaseName":      databaseName,
+				"ContainerName":     containerName,
+				"AccountKey":        accountKey,
+				"ContinuationToken": continuationToken,
+			},
+		})
+	}
+
+	return ExpanderResult{
+		Response:          ExpanderResponse{Response: string(response.Data), ResponseType: interfaces.ResponseJSON},
+		Nodes:             nodes,
+		IsPrimaryResponse: true,
+		SourceDescription: "CosmosDbExpander request",
+	}
+}
+
+func (e *CosmosDbExpander) getGremlinDocumentNode(parent *TreeNode, document map[string]interface{}, accountName string, databaseName string, containerName string, accountKey string) *TreeNode {
+	id := fmt.Sprintf("%v", document["id"])
+	label := fmt.Sprintf("%v", document["label"])
+	metadata := map[string]string{
+		"AccountName":   accountName,
+		"DatabaseName":  databaseName,
+		"ContainerName": containerName,
+		"AccountKey":    accountKey,
+		"ItemID":        id,
+	}
+
+	node := &TreeNode{
+		Parentid:              parent.ID,
+		ID:                    parent.ID + "/" + id,
+		Namespace:             "cosmosdb",
+		Name:                  id,
+		Display:               style.Subtle(label) + "\n  " + id,
+		ItemType:              cosmosdbGremlinVertex,
+		ExpandURL:             ExpandURLNotSupported,
+		SuppressSwaggerExpand: true,
+		SuppressGenericExpand: true,
+		Metadata:              metadata,
+	}
+
+	if isEdge, _ := document["_isEdge"].(bool); isEdge {
+		outVertex := fmt.Sprintf("%v", document["_vertexId"])
+		inVertex := fmt.Sprintf("%v", document["_sink"])
+		metadata["OutVertexID"] = outVertex
+		metadata["InVertexID"] = inVertex
+		node.ItemType = cosmosdbGremlinEdge
+		node.Display = style.Subtle(outVertex+" -["+label+"]-> "+inVertex) + "\n  " + id
+	}
+	return node
+}
+
+func (e *CosmosDbExpander) expandGremlinVertex(ctx context.Context, item *TreeNode) ExpanderResult {
+	accountName := item.Metadata["AccountName"]
+	databaseName := item.Metadata["DatabaseName"]
+	containerName := item.Metadata["ContainerName"]
+	accountKey := item.Metadata["AccountKey"]
+	itemID := item.Metadata["ItemID"]
+
+	document, err := e.getDocumentByID(ctx, accountName, databaseName, containerName, accountKey, itemID)
+	if err != nil {
+		return ExpanderResult{
+			Err:               err,
+			IsPrimaryResponse: true,
+			SourceDescription: "CosmosDbExpander request",
+		}
+	}
+	content, err := json.Marshal(document)
+	if err != nil {
+		return ExpanderResult{
+			Err:               fmt.Errorf("Error formatting vertex: %s", err),
+			IsPrimaryResponse: true,
+			SourceDescription: "CosmosDbExpander request",
+		}
+	}
+
+	// List the edges in and out of the vertex so that the graph can be walked
+	edgesQuery := CosmosDbQuery{
+		Query: cosmosdbGremlinEdgesQuery + " AND (c._vertexId = @id OR c._sink = @id)",
+		Parameters: []CosmosDbQueryParameter{
+			{Name: "@id", Value: itemID},
+		},
+	}
+	edgesResult := e.expandGremlinDocuments(ctx, item, edgesQuery, "")
+	if edgesResult.Err != nil {
+		return edgesResult
+	}
+
+	return ExpanderResult{
+		Response:          ExpanderResponse{Response: string(content), ResponseType: interfaces.ResponseJSON},
+		Nodes:             edgesResult.Nodes,
+		IsPrimaryResponse: true,
+		SourceDescription: "CosmosDbExpander request",
+	}
+}
+
+func (e *CosmosDbExpander) expandGremlinEdge(ctx context.Context, item *TreeNode) ExpanderResult {
+	accountName := item.Metadata["AccountName"]
+	databaseName := item.Metadata["DatabaseName"]
+	containerName := item.Metadata["ContainerName"]
+	accountKey := item.Metadata["AccountKey"]
+	itemID := item.Metadata["ItemID"]
+
+	document, err := e.getDocumentByID(ctx, accountName, databaseName, containerName, accountKey, itemID)
+	if err != nil {
+		return ExpanderResult{
+			Err:               err,
+			IsPrimaryResponse: true,
+			SourceDescription: "CosmosDbExpander request",
+		}
+	}
+	content, err := json.Marshal(document)
+	if err != nil {
+		return ExpanderResult{
+			Err:               fmt.Errorf("Error formatting edge: %s", err),
+			IsPrimaryResponse: true,
+			SourceDescription: "CosmosDbExpander request",
+		}
+	}
+
+	nodes := []*TreeNode{}
+	for _, vertex := range []struct {
+		id        string
+		direction string
+	}{
+		{id: item.Metadata["OutVertexID"], direction: "out"},
+		{id: item.Metadata["InVertexID"], direction: "in"},
+	} {
+		nodes = append(nodes, &TreeNode{
+			Parentid:              item.ID,
+			ID:                    item.ID + "/" + vertex.direction + "/" + vertex.id,
+			Namespace:             "cosmosdb",
+			Name:                  vertex.id,
+			Display:               style.Subtle(vertex.direction+" vertex") + "\n  " + vertex.id,
+			ItemType:              cosmosdbGremlinVertex,
+			ExpandURL:             ExpandURLNotSupported,
+			SuppressSwaggerExpand: true,
+			SuppressGenericExpand: true,
+			Metadata: map[string]string{
+				"AccountName":   accountName,
+				"DatabaseName":  databaseName,
+				"ContainerName": containerName,
+				"AccountKey":    accountKey,
+				"ItemID":        vertex.id,
+			},
+		})
+	}
+
+	return ExpanderResult{
+		Response:          ExpanderResponse{Response: string(content), ResponseType: interfaces.ResponseJSON},
+		Nodes:             nodes,
+		IsPrimaryResponse: true,
+		SourceDescription: "CosmosDbExpander request",
+	}
+}
+
+func (e *CosmosDbExpander) walkParentsToGetGremlinConnectionDetails(ctx context.Context, item *TreeNode) (CosmosDbGremlinConnectionDetails, error) {
+
+	tempItem := item.Parent // item is action node, item.Parent is the node the action relates to
+	for tempItem != nil && tempItem.SwaggerResourceType == nil {
+		tempItem = tempItem.Parent
+	}
+	if tempItem == nil {
+		return CosmosDbGremlinConnectionDetails{}, fmt.Errorf("Unable to find graph for item")
+	}
+	matchResult := tempItem.SwaggerResourceType.Endpoint.Match(tempItem.ID)
+	if !matchResult.IsMatch {
+		return CosmosDbGremlinConnectionDetails{}, fmt.Errorf("Endpoint should match")
+	}
+
+	accountKey, err := e.getAccountKey(ctx, tempItem)
+	if err != nil {
+		return CosmosDbGremlinConnectionDetails{}, fmt.Errorf("Error getting account key: %s", err)
+	}
+
+	return CosmosDbGremlinConnectionDetails{
+		AccountName:  matchResult.Values["accountName"],
+		DatabaseName: matchResult.Values["databaseName"],
+		GraphName:    matchResult.Values["graphName"],
+		AccountKey:   accountKey,
+	}, nil
+}
+
+func (e *CosmosDbExpander) cosmosdbActionExecuteGremlinQuery(ctx context.Context, item *TreeNode) ExpanderResult {
+
+	connectionDetails, err := e.walkParentsToGetGremlinConnectionDetails(ctx, item)
+	if err != nil {
+		return ExpanderResult{
+			Err:               err,
+			SourceDescription: "CosmosDbExpander request",
+			IsPrimaryResponse: true,
+		}
+	}
+
+	commandPanelNotification := func(state interfaces.CommandPanelNotification) {
+		if state.EnterPressed {
+			queryText := state.CurrentText
+			result, err := e.executeGremlinQuery(ctx, connectionDetails, queryText)
+			if err != nil {
+				e.contentPanel.SetContent("\n\n\n\n"+err.Error(), interfaces.ResponsePlainText, "Error executing query")
+			} else {
+				e.contentPanel.SetContent(result.content, interfaces.ResponseJSON, fmt.Sprintf("Results (%d, %.2f RUs)", result.count, result.requestCharge))
+			}
+		}
+	}
+	e.commandPanel.ShowWithText("gremlin:", "g.V().limit(10)", nil, commandPanelNotification)
+	// Force UI to re-render to pickup
+	e.gui.Update(func(g *gocui.Gui) error {
+		return nil
+	})
+
+	return ExpanderResult{
+		Response:          ExpanderResponse{Response: "", ResponseType: interfaces.ResponsePlainText},
+		SourceDescription: "CosmosDbExpander request",
+		IsPrimaryResponse: true,
+	}
+}
+
+type gremlinQueryOutput struct {
+	content       string
+	count         int
+	requestCharge float64
+}
+
+func (e *CosmosDbExpander) executeGremlinQuery(ctx context.Context, connectionDetails CosmosDbGremlinConnectionDetails, queryText string) (gremlinQueryOutput, error) {
+	client, err := newGremlinClient(ctx, connectionDetails.AccountName)
+	if err != nil {
+		return gremlinQueryOutput{}, err
+	}
+	defer client.Close() //nolint: errcheck
+
+	username := fmt.Sprintf("/dbs/%s/colls/%s", connectionDetails.DatabaseName, connectionDetails.GraphName)
+	result, err := client.Execute(queryText, username, connectionDetails.AccountKey)
+	if err != nil {
+		return gremlinQueryOutput{}, err
+	}
+
+	buf, err := json.Marshal(result.Data)
+	if err != nil {
+		return gremlinQueryOutput{}, fmt.Errorf("Error formatting results: %s", err)
+	}
+	return gremlinQueryOutput{
+		content:       string(buf),
+		count:         len(result.Data),
+		requestCharge: result.RequestCharge,
+	}, nil
+}
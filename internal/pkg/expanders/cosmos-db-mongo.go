@@ -0,0 +1,222 @@
+package expanders
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/lawrencegripper/azbrowse/internal/pkg/interfaces"
+)
+
+// Mongo API collections are read through the core (SQL) gateway using the master key rather than the Mongo wire protocol.
+// The gateway returns the documents in the internal Cosmos representation where Mongo values are wrapped as {"$t": <bson type>, "$v": <value>}
+// so the values are unwrapped before display
+
+func (e *CosmosDbExpander) expandMongoDocuments(ctx context.Context, item *TreeNode) ExpanderResult {
+	accountName := item.Metadata["AccountName"]
+	databaseName := item.Metadata["DatabaseName"]
+	containerName := item.Metadata["ContainerName"]
+	continuationToken := item.Metadata["ContinuationToken"]
+
+	accountKey := item.Metadata["AccountKey"]
+	if accountKey == "" {
+		var err error
+		accountKey, err = e.getAccountKey(ctx, item)
+		if err != nil {
+			return ExpanderResult{
+				Err:               fmt.Errorf("Error getting account key: %s", err),
+				IsPrimaryResponse: true,
+				SourceDescription: "CosmosDbExpander request",
+			}
+		}
+	}
+
+	requestURL := fmt.Sprintf("/dbs/%s/colls/%s/docs", databaseName, containerName)
+	headers := map[string]string{}
+	if continuationToken != "" {
+		headers["x-ms-continuation"] = continuationToken
+	}
+	response, err := e.doRequestWithHeaders(ctx, "GET", accountName, requestURL, accountKey, headers)
+	if err != nil {
+		return ExpanderResult{
+			Err:               fmt.Errorf("Error getting documents: %s", err),
+			IsPrimaryResponse: true,
+			SourceDescription: "CosmosDbExpander request",
+		}
+	}
+	if !e.isSuccessCode(response.StatusCode) {
+		return ExpanderResult{
+			Err:               fmt.Errorf("Error listing documents. StatusCode=%d, Response=%s", response.StatusCode, string(response.Data)),
+			IsPrimaryResponse: true,
+			SourceDescription: "CosmosDbExpander request",
+		}
+	}
+
+	var list CosmosDbListDocumentResponse
+	if err = json.Unmarshal(response.Data, &list); err != nil {
+		return ExpanderResult{
+			Err:               fmt.Errorf("Error unmarshalling response: %s", err),
+			IsPrimaryResponse: true,
+			SourceDescription: "CosmosDbExpander request",
+		}
+	}
+
+	nodes := []*TreeNode{}
+	documents := []interface{}{}
+	for _, document := range list.Documents {
+		document, ok := document.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		id, _ := document["id"].(string)
+		unwrapped := unwrapMongoDocument(document)
+		documents = append(documents, unwrapped)
+
+		displayText := id
+		if mongoID, ok := unwrapped["_id"]; ok {
+			displayText = formatMongoID(mongoID)
+		}
+		nodes = append(nodes, &TreeNode{
+			Parentid:              item.ID,
+			ID:                    item.ID + "/" + id,
+			Namespace:             "cosmosdb",
+			Name:                  displayText,
+			Display:               displayText,
+			ItemType:              cosmosdbMongoDocument,
+			ExpandURL:             ExpandURLNotSupported,
+			SuppressSwaggerExpand: true,
+			SuppressGenericExpand: true,
+			Metadata: map[string]string{
+				"AccountName":   accountName,
+				"DatabaseName":  databaseName,
+				"ContainerName": containerName,
+				"AccountKey":    accountKey,
+				"ItemID":        id,
+			},
+		})
+	}
+
+	if continuationToken := response.Headers.Get("x-ms-continuation"); continuationToken != "" {
+		nodes = append(nodes, &TreeNode{
+			Parentid:      item.ID,
+			Namespace:     "cosmosdb",
+			ID:            item.ID + "/" + "...more",
+			Name:          "more...",
+			Display:       "more...",
+			ItemType:      cosmosdbListMongoDocumentsContinuation,
+			ExpandURL:     ExpandURLNotSupported,
+			ExpandInPlace: true,
+			Metadata: map[string]string{
+				"AccountName":       accountName,
+				"DatabaseName":      databaseName,
+				"ContainerName":     containerName,
+				"AccountKey":        accountKey,
+				"ContinuationToken": continuationToken,
+			},
+		})
+	}
+
+	content, err := json.Marshal(documents)
+	if err != nil {
+		return ExpanderResult{
+			Err:               fmt.Errorf("Error formatting documents: %s", err),
+			IsPrimaryResponse: true,
+			SourceDescription: "CosmosDbExpander request",
+		}
+	}
+
+	return ExpanderResult{
+		Response:          ExpanderResponse{Response: string(content), ResponseType: interfaces.ResponseJSON},
+		Nodes:             nodes,
+		IsPrimaryResponse: true,
+		SourceDescription: "CosmosDbExpander request",
+	}
+}
+
+func (e *CosmosDbExpander) expandMongoDocument(ctx context.Context, item *TreeNode) ExpanderResult {
+	accountName := item.Metadata["AccountName"]
+	databaseName := item.Metadata["DatabaseName"]
+	containerName := item.Metadata["ContainerName"]
+	accountKey := item.Metadata["AccountKey"]
+	itemID := item.Metadata["ItemID"]
+
+	document, err := e.getDocumentByID(ctx, accountName, databaseName, containerName, accountKey, itemID)
+	if err != nil {
+		return ExpanderResult{
+			Err:               err,
+			IsPrimaryResponse: true,
+			SourceDescription: "CosmosDbExpander request",
+		}
+	}
+
+	content, err := json.Marshal(unwrapMongoDocument(document))
+	if err != nil {
+		return ExpanderResult{
+			Err:               fmt.Errorf("Error formatting document: %s", err),
+			IsPrimaryResponse: true,
+			SourceDescription: "CosmosDbExpander request",
+		}
+	}
+
+	return ExpanderResult{
+		Response:          ExpanderResponse{Response: string(content), ResponseType: interfaces.ResponseJSON},
+		IsPrimaryResponse: true,
+		SourceDescription: "CosmosDbExpander request",
+	}
+}
+
+// unwrapMongoDocument strips the Cosmos system properties (_rid, _etag etc) that aren't part of the Mongo document
+// and unwraps the property values
+func unwrapMongoDocument(document map[string]interface{}) map[string]interface{} {
+	result := map[string]interface{}{}
+	for key, propertyValue := range document {
+		switch key {
+		case "id", "_rid", "_self", "_etag", "_attachments", "_ts":
+			continue
+		}
+		result[key] = unwrapMongoValue(propertyValue)
+	}
+	return result
+}
+
+// unwrapMongoValue replaces the {"$t": <type>, "$v": <value>} wrappers with the wrapped values
+func unwrapMongoValue(value interface{}) interface{} {
+	switch typedValue := value.(type) {
+	case map[string]interface{}:
+		if wrappedValue, ok := typedValue["$v"]; ok {
+			if _, ok := typedValue["$t"]; ok && len(typedValue) == 2 {
+				return unwrapMongoValue(wrappedValue)
+			}
+		}
+		result := map[string]interface{}{}
+		for key, propertyValue := range typedValue {
+			result[key] = unwrapMongoValue(propertyValue)
+		}
+		return result
+	case []interface{}:
+		result := make([]interface{}, len(typedValue))
+		for i, item := range typedValue {
+			result[i] = unwrapMongoValue(item)
+		}
+		return result
+	default:
+		return value
+	}
+}
+
+// formatMongoID converts an unwrapped _id value to a display string
+func formatMongoID(id interface{}) string {
+	switch typedID := id.(type) {
+	case string:
+		return typedID
+	case map[string]interface{}:
+		if oid, ok := typedID["$oid"]; ok {
+			return fmt.Sprintf("ObjectId(%v)", oid)
+		}
+	}
+	buf, err := json.Marshal(id)
+	if err != nil {
+		return fmt.Sprintf("%v", id)
+	}
+	return string(buf)
+}
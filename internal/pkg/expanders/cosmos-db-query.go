@@ -0,0 +1,580 @@
+package expanders
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/lawrencegripper/azbrowse/internal/pkg/eventing"
+	"github.com/lawrencegripper/azbrowse/internal/pkg/interfaces"
+	"github.com/lawrencegripper/azbrowse/internal/pkg/storage"
+	"github.com/lawrencegripper/azbrowse/internal/pkg/style"
+)
+
+const (
+	cosmosdbQueryHistoryKey        = "cosmosdbQueryHistory"
+	cosmosdbQueryHistoryMaxEntries = 50
+	cosmosdbDefaultQuery           = "SELECT * FROM c"
+)
+
+// Queries (and exports) wait for user input so need longer than the default expand timeout
+var cosmosdbQueryTimeoutSeconds = 300
+var cosmosdbExportTimeoutSeconds = 1800
+
+var cosmosdbQueryParameterRegex = regexp.MustCompile(`@[A-Za-z_][A-Za-z0-9_]*`)
+var cosmosdbQueryStringLiteralRegex = regexp.MustCompile(`'(?:[^'\\]|\\.)*'|"(?:[^"\\]|\\.)*"`)
+
+// CosmosDbQueryHistoryEntry is a query that has been run against a container
+type CosmosDbQueryHistoryEntry struct {
+	AccountName   string        `json:"accountName"`
+	DatabaseName  string        `json:"databaseName"`
+	ContainerName string        `json:"containerName"`
+	Query         CosmosDbQuery `json:"query"`
+	LastRun       time.Time     `json:"lastRun"`
+}
+
+// CosmosDbQueryPage is displayed for each page of query results
+type CosmosDbQueryPage struct {
+	Page           int                `json:"page"`
+	ItemCount      int                `json:"itemCount"`
+	RequestCharge  float64            `json:"requestCharge"`
+	HasMoreResults bool               `json:"hasMoreResults"`
+	QueryMetrics   map[string]float64 `json:"queryMetrics,omitempty"`
+	Documents      []interface{}      `json:"documents"`
+}
+
+func (e *CosmosDbExpander) cosmosdbActionExecuteQuery(ctx context.Context, item *TreeNode) ExpanderResult {
+
+	connectionDetails, err := e.walkParentsToGetConnectionDetails(ctx, item)
+	if err != nil {
+		return ExpanderResult{
+			Err:               err,
+			SourceDescription: "CosmosDbExpander request",
+			IsPrimaryResponse: true,
+		}
+	}
+
+	// Start from the last query run against this container
+	initialQuery := cosmosdbDefaultQuery
+	history := getCosmosDbQueryHistory(connectionDetails)
+	if len(history) > 0 {
+		initialQuery = history[0].Query.Query
+	}
+
+	queryText, err := promptForInput(ctx, e.gui, e.commandPanel, "query:", initialQuery)
+	if err != nil || strings.TrimSpace(queryText) == "" {
+		return ExpanderResult{
+			Err:               fmt.Errorf("User canceled"),
+			SourceDescription: "CosmosDbExpander request",
+			IsPrimaryResponse: true,
+		}
+	}
+
+	previousParameters := []CosmosDbQueryParameter{}
+	for _, entry := range history {
+		if entry.Query.Query == queryText {
+			previousParameters = entry.Query.Parameters
+			break
+		}
+	}
+	return e.runQueryFromUserInput(ctx, item, connectionDetails, queryText, previousParameters)
+}
+
+func (e *CosmosDbExpander) cosmosdbActionQueryHistory(ctx context.Context, item *TreeNode) ExpanderResult {
+
+	connectionDetails, err := e.walkParentsToGetConnectionDetails(ctx, item)
+	if err != nil {
+		return ExpanderResult{
+			Err:               err,
+			SourceDescription: "CosmosDbExpander request",
+			IsPrimaryResponse: true,
+		}
+	}
+
+	history := getCosmosDbQueryHistory(connectionDetails)
+	if len(history) == 0 {
+		return ExpanderResult{
+			Response:          ExpanderResponse{Response: "No queries have been run against this container yet", ResponseType: interfaces.ResponsePlainText},
+			SourceDescription: "CosmosDbExpander request",
+			IsPrimaryResponse: true,
+		}
+	}
+
+	nodes := []*TreeNode{}
+	for i, entry := range history {
+		queryJSON, err := json.Marshal(entry.Query)
+		if err != nil {
+			continue
+		}
+		display := entry.Query.Query + "\n   " + style.Subtle("Last run: "+entry.LastRun.Local().Format(time.RFC1123))
+		for _, parameter := range entry.Query.Parameters {
+			display += "\n   " + style.Subtle(parameter.Name+" = "+formatQueryParameterValue(parameter.Value))
+		}
+		nodes = append(nodes, &TreeNode{
+			Parentid:               item.ID,
+			ID:                     fmt.Sprintf("%s/%d", item.ID, i),
+			Namespace:              "cosmos-db",
+			Name:                   entry.Query.Query,
+			Display:                display,
+			ItemType:               ActionType,
+			SuppressGenericExpand:  true,
+			TimeoutOverrideSeconds: &cosmosdbQueryTimeoutSeconds,
+			Metadata: map[string]string{
+				"ActionID": cosmosdbActionRunHistoryQuery,
+				"Query":    string(queryJSON),
+			},
+		})
+	}
+
+	return ExpanderResult{
+		Response:          ExpanderResponse{Response: "Open a query to run it again", ResponseType: interfaces.ResponsePlainText},
+		Nodes:             nodes,
+		SourceDescription: "CosmosDbExpander request",
+		IsPrimaryResponse: true,
+	}
+}
+
+func (e *CosmosDbExpander) cosmosdbActionRunHistoryQuery(ctx context.Context, item *TreeNode) ExpanderResult {
+
+	connectionDetails, err := e.walkParentsToGetConnectionDetails(ctx, item)
+	if err != nil {
+		return ExpanderResult{
+			Err:               err,
+			SourceDescription: "CosmosDbExpander request",
+			IsPrimaryResponse: true,
+		}
+	}
+
+	var query CosmosDbQuery
+	if err := json.Unmarshal([]byte(item.Metadata["Query"]), &query); err != nil {
+		return ExpanderResult{
+			Err:               fmt.Errorf("Error loading query: %s", err),
+			SourceDescription: "CosmosDbExpander request",
+			IsPrimaryResponse: true,
+		}
+	}
+
+	return e.runQueryFromUserInput(ctx, item, connectionDetails, query.Query, query.Parameters)
+}
+
+// runQueryFromUserInput prompts for any parameter values, saves the query to the history and returns the first page of results
+func (e *CosmosDbExpander) runQueryFromUserInput(ctx context.Context, item *TreeNode, connectionDetails CosmosDbSqlConnectionDetails, queryText string, previousParameters []CosmosDbQueryParameter) ExpanderResult {
+	query := CosmosDbQuery{Query: queryText}
+	for _, name := range getQueryParameterNames(queryText) {
+		initialValue := ""
+		for _, parameter := range previousParameters {
+			if parameter.Name == name {
+				initialValue = formatQueryParameterValue(parameter.Value)
+			}
+		}
+		valueText, err := promptForInput(ctx, e.gui, e.commandPanel, name+":", initialValue)
+		if err != nil {
+			return ExpanderResult{
+				Err:               fmt.Errorf("User canceled"),
+				SourceDescription: "CosmosDbExpander request",
+				IsPrimaryResponse: true,
+			}
+		}
+		query.Parameters = append(query.Parameters, CosmosDbQueryParameter{
+			Name:  name,
+			Value: parseQueryParameterValue(valueText),
+		})
+	}
+
+	if err := addCosmosDbQueryHistory(connectionDetails, query); err != nil {
+		eventing.SendFailureStatusFromError("Failed to save query history", err)
+	}
+
+	return e.expandSQLQueryPage(ctx, item, connectionDetails, query, "", 1)
+}
+
+func (e *CosmosDbExpander) expandSQLQueryContinuation(ctx context.Context, item *TreeNode) ExpanderResult {
+	connectionDetails := CosmosDbSqlConnectionDetails{
+		AccountName:   item.Metadata["AccountName"],
+		DatabaseName:  item.Metadata["DatabaseName"],
+		ContainerName: item.Metadata["ContainerName"],
+		PartitionKey:  item.Metadata["PartitionKey"],
+		AccountKey:    item.Metadata["AccountKey"],
+	}
+	var query CosmosDbQuery
+	if err := json.Unmarshal([]byte(item.Metadata["Query"]), &query); err != nil {
+		return ExpanderResult{
+			Err:               fmt.Errorf("Error loading query: %s", err),
+			SourceDescription: "CosmosDbExpander request",
+			IsPrimaryResponse: true,
+		}
+	}
+	page, _ := strconv.Atoi(item.Metadata["Page"])
+
+	return e.expandSQLQueryPage(ctx, item, connectionDetails, query, item.Metadata["ContinuationToken"], page)
+}
+
+// expandSQLQueryPage runs the query for a single page of results. Documents are returned as document nodes so that they can be edited,
+// other results (e.g. projections or aggregates) are returned as read-only result nodes
+func (e *CosmosDbExpander) expandSQLQueryPage(ctx context.Context, item *TreeNode, connectionDetails CosmosDbSqlConnectionDetails, query CosmosDbQuery, continuationToken string, page int) ExpanderResult {
+
+	response, err := e.queryDocuments(ctx, connectionDetails.AccountName, connectionDetails.DatabaseName, connectionDetails.ContainerName, connectionDetails.AccountKey, query, continuationToken)
+	if err != nil {
+		return ExpanderResult{
+			Err:               err,
+			SourceDescription: "CosmosDbExpander request",
+			IsPrimaryResponse: true,
+		}
+	}
+
+	var list CosmosDbListDocumentResponse
+	if err = json.Unmarshal(response.Data, &list); err != nil {
+		return ExpanderResult{
+			Err:               fmt.Errorf("Error unmarshalling response: %s", err),
+			IsPrimaryResponse: true,
+			SourceDescription: "CosmosDbExpander request",
+		}
+	}
+
+	queryJSON, err := json.Marshal(query)
+	if err != nil {
+		return ExpanderResult{
+			Err:               fmt.Errorf("Error marshaling query as JSON: %s", err),
+			IsPrimaryResponse: true,
+			SourceDescription: "CosmosDbExpander request",
+		}
+	}
+	connectionMetadata := func() map[string]string {
+		return map[string]string{
+			"AccountName":   connectionDetails.AccountName,
+			"DatabaseName":  connectionDetails.DatabaseName,
+			"ContainerName": connectionDetails.ContainerName,
+			"AccountKey":    connectionDetails.AccountKey,
+			"PartitionKey":  connectionDetails.PartitionKey,
+			"Query":         string(queryJSON),
+		}
+	}
+
+	nodes := []*TreeNode{}
+	if page == 1 {
+		exportMetadata := connectionMetadata()
+		exportMetadata["ActionID"] = cosmosdbActionExportQuery
+		nodes = append(nodes, &TreeNode{
+			Parentid:               item.ID,
+			ID:                     item.ID + "?" + cosmosdbActionExportQuery,
+			Namespace:              "cosmos-db",
+			Name:                   "Export results",
+			Display:                style.Subtle("Export all results to a JSON-lines file"),
+			ItemType:               ActionType,
+			SuppressGenericExpand:  true,
+			TimeoutOverrideSeconds: &cosmosdbExportTimeoutSeconds,
+			Metadata:               exportMetadata,
+		})
+	}
+
+	partitionKey := strings.TrimPrefix(connectionDetails.PartitionKey, "/")
+	for i, document := range list.Documents {
+		if documentMap, ok := document.(map[string]interface{}); ok && isCosmosDbDocument(documentMap) {
+			node, err := getSQLDocumentNode(item, documentMap, connectionDetails.AccountName, connectionDetails.DatabaseName, connectionDetails.ContainerName, connectionDetails.AccountKey, partitionKey)
+			if err == nil {
+				nodes = append(nodes, node)
+				continue
+			}
+		}
+
+		content, err := json.Marshal(document)
+		if err != nil {
+			continue
+		}
+		name := fmt.Sprintf("%d: %s", i+1, string(content))
+		if len(name) > 80 {
+			name = name[:77] + "..."
+		}
+		nodes = append(nodes, &TreeNode{
+			Parentid:              item.ID,
+			ID:                    fmt.Sprintf("%s/<result-%d-%d>", item.ID, page, i),
+			Namespace:             "cosmosdb",
+			Name:                  name,
+			Display:               name,
+			ItemType:              cosmosdbSQLQueryResult,
+			ExpandURL:             ExpandURLNotSupported,
+			SuppressSwaggerExpand: true,
+			SuppressGenericExpand: true,
+			Metadata: map[string]string{
+				"Content": string(content),
+			},
+		})
+	}
+
+	nextContinuationToken := response.Headers.Get("x-ms-continuation")
+	if nextContinuationToken != "" {
+		moreMetadata := connectionMetadata()
+		moreMetadata["ContinuationToken"] = nextContinuationToken
+		moreMetadata["Page"] = strconv.Itoa(page + 1)
+		nodes = append(nodes, &TreeNode{
+			Parentid:      item.ID,
+			Namespace:     "cosmosdb",
+			ID:            item.ID + "/" + "...more",
+			Name:          "More...",
+			Display:       "More...",
+			ItemType:      cosmosdbSQLQueryContinuation,
+			ExpandURL:     ExpandURLNotSupported,
+			ExpandInPlace: true,
+			Metadata:      moreMetadata,
+		})
+	}
+
+	requestCharge, _ := strconv.ParseFloat(response.Headers.Get("x-ms-request-charge"), 64)
+	pageResult := CosmosDbQueryPage{
+		Page:           page,
+		ItemCount:      len(list.Documents),
+		RequestCharge:  requestCharge,
+		HasMoreResults: nextContinuationToken != "",
+		QueryMetrics:   parseCosmosDbQueryMetrics(response.Headers.Get("x-ms-documentdb-query-metrics")),
+		Documents:      list.Documents,
+	}
+	content, err := json.Marshal(pageResult)
+	if err != nil {
+		return ExpanderResult{
+			Err:               fmt.Errorf("Error formatting results: %s", err),
+			IsPrimaryResponse: true,
+			SourceDescription: "CosmosDbExpander request",
+		}
+	}
+
+	return ExpanderResult{
+		Response:          ExpanderResponse{Response: string(content), ResponseType: interfaces.ResponseJSON},
+		Nodes:             nodes,
+		SourceDescription: "CosmosDbExpander request",
+		IsPrimaryResponse: true,
+	}
+}
+
+func (e *CosmosDbExpander) expandSQLQueryResult(ctx context.Context, item *TreeNode) ExpanderResult {
+	return ExpanderResult{
+		Response:          ExpanderResponse{Response: item.Metadata["Content"], ResponseType: interfaces.ResponseJSON},
+		SourceDescription: "CosmosDbExpander request",
+		IsPrimaryResponse: true,
+	}
+}
+
+func (e *CosmosDbExpander) cosmosdbActionExportQuery(ctx context.Context, item *TreeNode) ExpanderResult {
+	connectionDetails := CosmosDbSqlConnectionDetails{
+		AccountName:   item.Metadata["AccountName"],
+		DatabaseName:  item.Metadata["DatabaseName"],
+		ContainerName: item.Metadata["ContainerName"],
+		AccountKey:    item.Metadata["AccountKey"],
+	}
+	var query CosmosDbQuery
+	if err := json.Unmarshal([]byte(item.Metadata["Query"]), &query); err != nil {
+		return ExpanderResult{
+			Err:               fmt.Errorf("Error loading query: %s", err),
+			SourceDescription: "CosmosDbExpander request",
+			IsPrimaryResponse: true,
+		}
+	}
+
+	workingDir, err := os.Getwd()
+	if err != nil {
+		workingDir = os.TempDir()
+	}
+	defaultPath := filepath.Join(workingDir, fmt.Sprintf("%s-%s.jsonl", connectionDetails.ContainerName, time.Now().Format("20060102-150405")))
+	path, err := promptForInput(ctx, e.gui, e.commandPanel, "export to file:", defaultPath)
+	if err != nil || strings.TrimSpace(path) == "" {
+		return ExpanderResult{
+			Err:               fmt.Errorf("User canceled"),
+			SourceDescription: "CosmosDbExpander request",
+			IsPrimaryResponse: true,
+		}
+	}
+	path = strings.TrimSpace(path)
+
+	count, requestCharge, err := e.exportQueryResults(ctx, connectionDetails, query, path)
+	if err != nil {
+		return ExpanderResult{
+			Err:               fmt.Errorf("Error exporting results (%d documents written): %s", count, err),
+			SourceDescription: "CosmosDbExpander request",
+			IsPrimaryResponse: true,
+		}
+	}
+
+	return ExpanderResult{
+		Response: ExpanderResponse{
+			Response:     fmt.Sprintf("Exported %d results to %s\nTotal request charge: %.2f RUs", count, path, requestCharge),
+			ResponseType: interfaces.ResponsePlainText,
+		},
+		SourceDescription: "CosmosDbExpander request",
+		IsPrimaryResponse: true,
+	}
+}
+
+// exportQueryResults pages through all of the query results writing each result as a line of JSON
+func (e *CosmosDbExpander) exportQueryResults(ctx context.Context, connectionDetails CosmosDbSqlConnectionDetails, query CosmosDbQuery, path string) (int, float64, error) {
+	file, err := os.Create(path)
+	if err != nil {
+		return 0, 0, err
+	}
+	defer file.Close() //nolint: errcheck
+	writer := bufio.NewWriter(file)
+
+	event, _ := eventing.SendStatusEvent(&eventing.StatusEvent{
+		InProgress: true,
+		Message:    "Exporting query results to " + path,
+	})
+	defer event.Done()
+
+	count := 0
+	totalRequestCharge := 0.0
+	continuationToken := ""
+	for {
+		response, err := e.queryDocuments(ctx, connectionDetails.AccountName, connectionDetails.DatabaseName, connectionDetails.ContainerName, connectionDetails.AccountKey, query, continuationToken)
+		if err != nil {
+			return count, totalRequestCharge, err
+		}
+		requestCharge, _ := strconv.ParseFloat(response.Headers.Get("x-ms-request-charge"), 64)
+		totalRequestCharge += requestCharge
+
+		var list struct {
+			Documents []json.RawMessage `json:"Documents"`
+		}
+		if err = json.Unmarshal(response.Data, &list); err != nil {
+			return count, totalRequestCharge, fmt.Errorf("Error unmarshalling response: %s", err)
+		}
+		for _, document := range list.Documents {
+			if _, err := writer.Write(append(document, '\n')); err != nil {
+				return count, totalRequestCharge, err
+			}
+			count++
+		}
+
+		event.Message = fmt.Sprintf("Exporting query results to %s (%d so far)", path, count)
+		event.Update()
+
+		continuationToken = response.Headers.Get("x-ms-continuation")
+		if continuationToken == "" {
+			break
+		}
+	}
+
+	return count, totalRequestCharge, writer.Flush()
+}
+
+// isCosmosDbDocument checks whether a query result is a whole document (rather than a projection)
+func isCosmosDbDocument(document map[string]interface{}) bool {
+	_, hasID := document["id"].(string)
+	_, hasRID := document["_rid"]
+	return hasID && hasRID
+}
+
+// getQueryParameterNames returns the distinct @parameters in a query, ignoring any in string literals
+func getQueryParameterNames(query string) []string {
+	query = cosmosdbQueryStringLiteralRegex.ReplaceAllString(query, "''")
+	names := []string{}
+	seen := map[string]bool{}
+	for _, name := range cosmosdbQueryParameterRegex.FindAllString(query, -1) {
+		if !seen[name] {
+			seen[name] = true
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// parseQueryParameterValue treats the input as JSON (numbers, booleans, quoted strings, arrays) falling back to a plain string
+func parseQueryParameterValue(text string) interface{} {
+	var value interface{}
+	if err := json.Unmarshal([]byte(text), &value); err == nil {
+		return value
+	}
+	return text
+}
+
+// formatQueryParameterValue is the inverse of parseQueryParameterValue
+func formatQueryParameterValue(value interface{}) string {
+	if stringValue, ok := value.(string); ok {
+		if parsedValue, ok := parseQueryParameterValue(stringValue).(string); ok && parsedValue == stringValue {
+			// Plain strings don't need quoting
+			return stringValue
+		}
+	}
+	buf, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Sprintf("%v", value)
+	}
+	return string(buf)
+}
+
+// parseCosmosDbQueryMetrics parses the x-ms-documentdb-query-metrics header, e.g. "totalExecutionTimeInMs=0.35;retrievedDocumentCount=2"
+func parseCosmosDbQueryMetrics(header string) map[string]float64 {
+	if header == "" {
+		return nil
+	}
+	metrics := map[string]float64{}
+	for _, metric := range strings.Split(header, ";") {
+		parts := strings.SplitN(metric, "=", 2)
+		if len(parts) != 2 {
+			continue
+		}
+		value, err := strconv.ParseFloat(parts[1], 64)
+		if err != nil {
+			continue
+		}
+		metrics[parts[0]] = value
+	}
+	return metrics
+}
+
+func loadCosmosDbQueryHistory() []CosmosDbQueryHistoryEntry {
+	history := []CosmosDbQueryHistoryEntry{}
+	value, err := storage.GetCache(cosmosdbQueryHistoryKey)
+	if err != nil || value == "" {
+		return history
+	}
+	_ = json.Unmarshal([]byte(value), &history)
+	return history
+}
+
+// getCosmosDbQueryHistory returns the history for a container, most recent first
+func getCosmosDbQueryHistory(connectionDetails CosmosDbSqlConnectionDetails) []CosmosDbQueryHistoryEntry {
+	result := []CosmosDbQueryHistoryEntry{}
+	for _, entry := range loadCosmosDbQueryHistory() {
+		if entry.AccountName == connectionDetails.AccountName &&
+			entry.DatabaseName == connectionDetails.DatabaseName &&
+			entry.ContainerName == connectionDetails.ContainerName {
+			result = append(result, entry)
+		}
+	}
+	return result
+}
+
+func addCosmosDbQueryHistory(connectionDetails CosmosDbSqlConnectionDetails, query CosmosDbQuery) error {
+	newEntry := CosmosDbQueryHistoryEntry{
+		AccountName:   connectionDetails.AccountName,
+		DatabaseName:  connectionDetails.DatabaseName,
+		ContainerName: connectionDetails.ContainerName,
+		Query:         query,
+		LastRun:       time.Now().UTC(),
+	}
+	history := []CosmosDbQueryHistoryEntry{newEntry}
+	for _, entry := range loadCosmosDbQueryHistory() {
+		if entry.AccountName == newEntry.AccountName &&
+			entry.DatabaseName == newEntry.DatabaseName &&
+			entry.ContainerName == newEntry.ContainerName &&
+			entry.Query.Query == newEntry.Query.Query {
+			// Replaced by the new entry
+			continue
+		}
+		history = append(history, entry)
+	}
+	if len(history) > cosmosdbQueryHistoryMaxEntries {
+		history = history[:cosmosdbQueryHistoryMaxEntries]
+	}
+
+	buf, err := json.Marshal(history)
+	if err != nil {
+		return err
+	}
+	return storage.PutCache(cosmosdbQueryHistoryKey, string(buf))
+}
@@ -0,0 +1,213 @@
+package expanders
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/lawrencegripper/azbrowse/internal/pkg/interfaces"
+	"github.com/lawrencegripper/azbrowse/internal/pkg/style"
+	"github.com/lawrencegripper/azbrowse/internal/pkg/tracing"
+)
+
+const cosmosdbTablePageSize = 100
+
+// CosmosDbTableEntitiesResponse is used to unmarshal a Table API query entities response
+type CosmosDbTableEntitiesResponse struct {
+	Value []map[string]interface{} `json:"value"`
+}
+
+func (e *CosmosDbExpander) expandTableEntities(ctx context.Context, item *TreeNode) ExpanderResult {
+	accountName := item.Metadata["AccountName"]
+	tableName := item.Metadata["TableName"]
+
+	accountKey := item.Metadata["AccountKey"]
+	if accountKey == "" {
+		var err error
+		accountKey, err = e.getAccountKey(ctx, item)
+		if err != nil {
+			return ExpanderResult{
+				Err:               fmt.Errorf("Error getting account key: %s", err),
+				IsPrimaryResponse: true,
+				SourceDescription: "CosmosDbExpander request",
+			}
+		}
+	}
+
+	query := url.Values{}
+	query.Set("$top", fmt.Sprintf("%d", cosmosdbTablePageSize))
+	if nextPartitionKey := item.Metadata["NextPartitionKey"]; nextPartitionKey != "" {
+		query.Set("NextPartitionKey", nextPartitionKey)
+	}
+	if nextRowKey := item.Metadata["NextRowKey"]; nextRowKey != "" {
+		query.Set("NextRowKey", nextRowKey)
+	}
+
+	response, err := e.doTableRequest(ctx, "GET", accountName, "/"+tableName+"()", query, accountKey)
+	if err != nil {
+		return ExpanderResult{
+			Err:               fmt.Errorf("Error getting entities: %s", err),
+			IsPrimaryResponse: true,
+			SourceDescription: "CosmosDbExpander request",
+		}
+	}
+	if !e.isSuccessCode(response.StatusCode) {
+		return ExpanderResult{
+			Err:               fmt.Errorf("Error listing entities. StatusCode=%d, Response=%s", response.StatusCode, string(response.Data)),
+			IsPrimaryResponse: true,
+			SourceDescription: "CosmosDbExpander request",
+		}
+	}
+
+	var list CosmosDbTableEntitiesResponse
+	if err = json.Unmarshal(response.Data, &list); err != nil {
+		return ExpanderResult{
+			Err:               fmt.Errorf("Error unmarshalling response: %s", err),
+			IsPrimaryResponse: true,
+			SourceDescription: "CosmosDbExpander request",
+		}
+	}
+
+	nodes := []*TreeNode{}
+	for _, entity := range list.Value {
+		partitionKey := fmt.Sprintf("%v", entity["PartitionKey"])
+		rowKey := fmt.Sprintf("%v", entity["RowKey"])
+		nodes = append(nodes, &TreeNode{
+			Parentid:              item.ID,
+			ID:                    item.ID + "/" + partitionKey + "/" + rowKey,
+			Namespace:             "cosmosdb",
+			Name:                  partitionKey + " " + rowKey,
+			Display:               style.Subtle(partitionKey) + "\n  " + rowKey,
+			ItemType:              cosmosdbTableEntity,
+			ExpandURL:             ExpandURLNotSupported,
+			SuppressSwaggerExpand: true,
+			SuppressGenericExpand: true,
+			Metadata: map[string]string{
+				"AccountName":  accountName,
+				"TableName":    tableName,
+				"AccountKey":   accountKey,
+				"PartitionKey": partitionKey,
+				"RowKey":       rowKey,
+			},
+		})
+	}
+
+	nextPartitionKey := response.Headers.Get("x-ms-continuation-NextPartitionKey")
+	if nextPartitionKey != "" {
+		nodes = append(nodes, &TreeNode{
+			Parentid:      item.ID,
+			Namespace:     "cosmosdb",
+			ID:            item.ID + "/" + "...more",
+			Name:          "more...",
+			Display:       "more...",
+			ItemType:      cosmosdbListTableEntitiesContinuation,
+			ExpandURL:     ExpandURLNotSupported,
+			ExpandInPlace: true,
+			Metadata: map[string]string{
+				"AccountName":      accountName,
+				"TableName":        tableName,
+				"AccountKey":       accountKey,
+				"NextPartitionKey": nextPartitionKey,
+				"NextRowKey":       response.Headers.Get("x-ms-continuation-NextRowKey"),
+			},
+		})
+	}
+
+	return ExpanderResult{
+		Response:          ExpanderResponse{Response: string(response.Data), ResponseType: interfaces.ResponseJSON},
+		Nodes:             nodes,
+		IsPrimaryResponse: true,
+		SourceDescription: "CosmosDbExpander request",
+	}
+}
+
+func (e *CosmosDbExpander) expandTableEntity(ctx context.Context, item *TreeNode) ExpanderResult {
+	accountName := item.Metadata["AccountName"]
+	tableName := item.Metadata["TableName"]
+	accountKey := item.Metadata["AccountKey"]
+
+	resourcePath := fmt.Sprintf("/%s(PartitionKey='%s',RowKey='%s')",
+		tableName,
+		escapeTableKey(item.Metadata["PartitionKey"]),
+		escapeTableKey(item.Metadata["RowKey"]))
+	response, err := e.doTableRequest(ctx, "GET", accountName, resourcePath, url.Values{}, accountKey)
+	if err != nil {
+		return ExpanderResult{
+			Err:               fmt.Errorf("Error getting entity: %s", err),
+			IsPrimaryResponse: true,
+			SourceDescription: "CosmosDbExpander request",
+		}
+	}
+	if !e.isSuccessCode(response.StatusCode) {
+		return ExpanderResult{
+			Err:               fmt.Errorf("Error getting entity. StatusCode=%d, Response=%s", response.StatusCode, string(response.Data)),
+			IsPrimaryResponse: true,
+			SourceDescription: "CosmosDbExpander request",
+		}
+	}
+
+	return ExpanderResult{
+		Response:          ExpanderResponse{Response: string(response.Data), ResponseType: interfaces.ResponseJSON},
+		IsPrimaryResponse: true,
+		SourceDescription: "CosmosDbExpander request",
+	}
+}
+
+// escapeTableKey quotes a PartitionKey or RowKey value for use in an entity path
+func escapeTableKey(key string) string {
+	return url.PathEscape(strings.ReplaceAll(key, "'", "''"))
+}
+
+// doTableRequest makes a request to the Table API endpoint using SharedKeyLite authorization with the account key
+func (e *CosmosDbExpander) doTableRequest(ctx context.Context, verb string, accountName string, resourcePath string, query url.Values, accountKey string) (*doRequestResponse, error) {
+
+	span, _ := tracing.StartSpanFromContext(ctx, "doTableRequest(cosmosexpander):"+resourcePath, tracing.SetTag("url", resourcePath))
+	defer span.Finish()
+
+	fullURL := fmt.Sprintf("https://%s.table.cosmos.azure.com%s", accountName, resourcePath)
+	if len(query) > 0 {
+		fullURL += "?" + query.Encode()
+	}
+
+	req, err := http.NewRequestWithContext(ctx, verb, fullURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to create request: %s", err)
+	}
+
+	dateString := time.Now().UTC().Format(http.TimeFormat)
+	req.Header.Set("x-ms-date", dateString)
+	req.Header.Set("x-ms-version", "2019-02-02")
+	req.Header.Set("Accept", "application/json;odata=nometadata")
+	req.Header.Set("DataServiceVersion", "3.0;NetFx")
+	req.Header.Set("MaxDataServiceVersion", "3.0;NetFx")
+
+	// https://docs.microsoft.com/en-us/rest/api/storageservices/authorize-with-shared-key#shared-key-lite-and-table-service-format-for-2009-09-19-and-later
+	stringToSign := dateString + "\n/" + accountName + req.URL.EscapedPath()
+	sig, err := signString(stringToSign, accountKey)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Authorization", "SharedKeyLite "+accountName+":"+sig)
+
+	response, err := e.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("Request failed: %s", err)
+	}
+
+	defer response.Body.Close() //nolint: errcheck
+	buf, err := ioutil.ReadAll(response.Body)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to read body: %s", err)
+	}
+
+	return &doRequestResponse{
+		Data:       buf,
+		StatusCode: response.StatusCode,
+		Headers:    response.Header,
+	}, nil
+}
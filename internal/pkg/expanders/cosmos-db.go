@@ -67,13 +67,48 @@ type doRequestResponse struct {
 }
 
 type CosmosDbQuery struct {
-	Query string `json:"query"`
+	Query      string                   `json:"query"`
+	Parameters []CosmosDbQueryParameter `json:"parameters,omitempty"`
 }
 
+// CosmosDbQueryParameter is a named parameter for a CosmosDbQuery
+type CosmosDbQueryParameter struct {
+	Name  string      `json:"name"`
+	Value interface{} `json:"value"`
+}
+
+const (
+	cosmosdbAccountTemplate         = "/subscriptions/{subscriptionId}/resourceGroups/{resourceGroupName}/providers/Microsoft.DocumentDB/databaseAccounts/{accountName}"
+	cosmosdbSQLContainerTemplate    = cosmosdbAccountTemplate + "/sqlDatabases/{databaseName}/containers/{containerName}"
+	cosmosdbMongoCollectionTemplate = cosmosdbAccountTemplate + "/mongodbDatabases/{databaseName}/collections/{collectionName}"
+	cosmosdbTableTemplate           = cosmosdbAccountTemplate + "/tables/{tableName}"
+	cosmosdbGremlinGraphTemplate    = cosmosdbAccountTemplate + "/gremlinDatabases/{databaseName}/graphs/{graphName}"
+	cosmosdbCassandraTableTemplate  = cosmosdbAccountTemplate + "/cassandraKeyspaces/{keyspaceName}/tables/{tableName}"
+)
+
 const (
 	cosmosdbListSQLDocuments             = "sql-listdocs"
 	cosmosdbListSQLDocumentsContinuation = "sql-listdocs-continue"
 	cosmosdbSQLDocument                  = "sql-document"
+	cosmosdbSQLQueryContinuation         = "sql-query-continue"
+	cosmosdbSQLQueryResult               = "sql-query-result"
+
+	cosmosdbListMongoDocuments             = "mongo-listdocs"
+	cosmosdbListMongoDocumentsContinuation = "mongo-listdocs-continue"
+	cosmosdbMongoDocument                  = "mongo-document"
+
+	cosmosdbListTableEntities             = "table-listentities"
+	cosmosdbListTableEntitiesContinuation = "table-listentities-continue"
+	cosmosdbTableEntity                   = "table-entity"
+
+	cosmosdbListGremlinVertices             = "gremlin-listvertices"
+	cosmosdbListGremlinVerticesContinuation = "gremlin-listvertices-continue"
+	cosmosdbListGremlinEdges                = "gremlin-listedges"
+	cosmosdbListGremlinEdgesContinuation    = "gremlin-listedges-continue"
+	cosmosdbGremlinVertex                   = "gremlin-vertex"
+	cosmosdbGremlinEdge                     = "gremlin-edge"
+
+	cosmosdbCassandraSchema = "cassandra-schema"
 )
 
 const (
@@ -81,6 +116,12 @@ const (
 	cosmosdbActionGetDocument = "get-document"
 	cosmosdbActionSQLQuery    = "sql-query"
 	cosmosdbActionAddDocument = "add-document"
+
+	cosmosdbActionGremlinQuery = "gremlin-query"
+
+	cosmosdbActionQueryHistory    = "query-history"
+	cosmosdbActionRunHistoryQuery = "run-history-query"
+	cosmosdbActionExportQuery     = "export-query"
 )
 
 func (e *CosmosDbExpander) setClient(c *armclient.Client) {
@@ -106,7 +147,12 @@ func (e *CosmosDbExpander) Name() string {
 func (e *CosmosDbExpander) DoesExpand(ctx context.Context, currentItem *TreeNode) (bool, error) {
 	swaggerResourceType := currentItem.SwaggerResourceType
 	if currentItem.ItemType == SubResourceType && swaggerResourceType != nil {
-		if swaggerResourceType.Endpoint.TemplateURL == "/subscriptions/{subscriptionId}/resourceGroups/{resourceGroupName}/providers/Microsoft.DocumentDB/databaseAccounts/{accountName}/sqlDatabases/{databaseName}/containers/{containerName}" {
+		switch swaggerResourceType.Endpoint.TemplateURL {
+		case cosmosdbSQLContainerTemplate,
+			cosmosdbMongoCollectionTemplate,
+			cosmosdbTableTemplate,
+			cosmosdbGremlinGraphTemplate,
+			cosmosdbCassandraTableTemplate:
 			return true, nil
 		}
 	}
@@ -119,30 +165,12 @@ func (e *CosmosDbExpander) DoesExpand(ctx context.Context, currentItem *TreeNode
 // Expand returns items in the Cosmos DB account
 func (e *CosmosDbExpander) Expand(ctx context.Context, currentItem *TreeNode) ExpanderResult {
 
-	newItems := []*TreeNode{}
-
 	swaggerResourceType := currentItem.SwaggerResourceType
-	if currentItem.Namespace != "cosmosdb" &&
-		swaggerResourceType != nil &&
-		swaggerResourceType.Endpoint.TemplateURL == "/subscriptions/{subscriptionId}/resourceGroups/{resourceGroupName}/providers/Microsoft.DocumentDB/databaseAccounts/{accountName}/sqlDatabases/{databaseName}/containers/{containerName}" {
+	if currentItem.Namespace != "cosmosdb" && swaggerResourceType != nil {
+		newItems := []*TreeNode{}
 		matchResult := swaggerResourceType.Endpoint.Match(currentItem.ID)
 		if matchResult.IsMatch {
-			newItems = append(newItems, &TreeNode{
-				Parentid:              currentItem.ID,
-				ID:                    currentItem.ID + "/<documents>",
-				Namespace:             "cosmosdb",
-				Name:                  "Documents",
-				Display:               "Documents",
-				ItemType:              cosmosdbListSQLDocuments,
-				ExpandURL:             ExpandURLNotSupported,
-				SuppressSwaggerExpand: true,
-				SuppressGenericExpand: true,
-				Metadata: map[string]string{
-					"AccountName":   matchResult.Values["accountName"],
-					"DatabaseName":  matchResult.Values["databaseName"],
-					"ContainerName": matchResult.Values["containerName"],
-				},
-			})
+			newItems = e.getDataPlaneRootNodes(currentItem, swaggerResourceType.Endpoint.TemplateURL, matchResult.Values)
 		}
 
 		return ExpanderResult{
@@ -161,6 +189,28 @@ func (e *CosmosDbExpander) Expand(ctx context.Context, currentItem *TreeNode) Ex
 		return e.expandSQLDocumentsContinuation(ctx, currentItem)
 	case cosmosdbSQLDocument:
 		return e.expandSQLDocumentNode(ctx, currentItem)
+	case cosmosdbSQLQueryContinuation:
+		return e.expandSQLQueryContinuation(ctx, currentItem)
+	case cosmosdbSQLQueryResult:
+		return e.expandSQLQueryResult(ctx, currentItem)
+	case cosmosdbListMongoDocuments, cosmosdbListMongoDocumentsContinuation:
+		return e.expandMongoDocuments(ctx, currentItem)
+	case cosmosdbMongoDocument:
+		return e.expandMongoDocument(ctx, currentItem)
+	case cosmosdbListTableEntities, cosmosdbListTableEntitiesContinuation:
+		return e.expandTableEntities(ctx, currentItem)
+	case cosmosdbTableEntity:
+		return e.expandTableEntity(ctx, currentItem)
+	case cosmosdbListGremlinVertices, cosmosdbListGremlinVerticesContinuation:
+		return e.expandGremlinVertices(ctx, currentItem)
+	case cosmosdbListGremlinEdges, cosmosdbListGremlinEdgesContinuation:
+		return e.expandGremlinEdges(ctx, currentItem)
+	case cosmosdbGremlinVertex:
+		return e.expandGremlinVertex(ctx, currentItem)
+	case cosmosdbGremlinEdge:
+		return e.expandGremlinEdge(ctx, currentItem)
+	case cosmosdbCassandraSchema:
+		return e.expandCassandraSchema(ctx, currentItem)
 	}
 
 	return ExpanderResult{
@@ -170,6 +220,66 @@ func (e *CosmosDbExpander) Expand(ctx context.Context, currentItem *TreeNode) Ex
 	}
 }
 
+// getDataPlaneRootNodes returns the nodes for browsing the data in a SQL container, Mongo collection, table, graph or Cassandra table
+func (e *CosmosDbExpander) getDataPlaneRootNodes(currentItem *TreeNode, templateURL string, values map[string]string) []*TreeNode {
+	newNode := func(idSuffix string, name string, itemType string, metadata map[string]string) *TreeNode {
+		metadata["AccountName"] = values["accountName"]
+		return &TreeNode{
+			Parentid:              currentItem.ID,
+			ID:                    currentItem.ID + "/<" + idSuffix + ">",
+			Namespace:             "cosmosdb",
+			Name:                  name,
+			Display:               name,
+			ItemType:              itemType,
+			ExpandURL:             ExpandURLNotSupported,
+			SuppressSwaggerExpand: true,
+			SuppressGenericExpand: true,
+			Metadata:              metadata,
+		}
+	}
+
+	switch templateURL {
+	case cosmosdbSQLContainerTemplate:
+		return []*TreeNode{
+			newNode("documents", "Documents", cosmosdbListSQLDocuments, map[string]string{
+				"DatabaseName":  values["databaseName"],
+				"ContainerName": values["containerName"],
+			}),
+		}
+	case cosmosdbMongoCollectionTemplate:
+		return []*TreeNode{
+			newNode("documents", "Documents", cosmosdbListMongoDocuments, map[string]string{
+				"DatabaseName":  values["databaseName"],
+				"ContainerName": values["collectionName"],
+			}),
+		}
+	case cosmosdbTableTemplate:
+		return []*TreeNode{
+			newNode("entities", "Entities", cosmosdbListTableEntities, map[string]string{
+				"TableName": values["tableName"],
+			}),
+		}
+	case cosmosdbGremlinGraphTemplate:
+		return []*TreeNode{
+			newNode("vertices", "Vertices", cosmosdbListGremlinVertices, map[string]string{
+				"DatabaseName":  values["databaseName"],
+				"ContainerName": values["graphName"],
+			}),
+			newNode("edges", "Edges", cosmosdbListGremlinEdges, map[string]string{
+				"DatabaseName":  values["databaseName"],
+				"ContainerName": values["graphName"],
+			}),
+		}
+	case cosmosdbCassandraTableTemplate:
+		return []*TreeNode{
+			newNode("schema", "Schema (CQL)", cosmosdbCassandraSchema, map[string]string{
+				"TableID": currentItem.ID,
+			}),
+		}
+	}
+	return []*TreeNode{}
+}
+
 // CanUpdate indicates if the item can be updated
 func (e CosmosDbExpander) CanUpdate(ctx context.Context, item *TreeNode) (bool, error) {
 	switch item.ItemType {
@@ -208,10 +318,13 @@ func (e *CosmosDbExpander) HasActions(context context.Context, item *TreeNode) (
 		swaggerResourceType = tempItem.SwaggerResourceType
 	}
 	if swaggerResourceType != nil {
-		if swaggerResourceType.Endpoint.TemplateURL == "/subscriptions/{subscriptionId}/resourceGroups/{resourceGroupName}/providers/Microsoft.DocumentDB/databaseAccounts/{accountName}" {
+		if swaggerResourceType.Endpoint.TemplateURL == cosmosdbAccountTemplate {
 			return true, nil
 		}
-		if strings.HasPrefix(swaggerResourceType.Endpoint.TemplateURL, "/subscriptions/{subscriptionId}/resourceGroups/{resourceGroupName}/providers/Microsoft.DocumentDB/databaseAccounts/{accountName}/sqlDatabases/{databaseName}/containers/{containerName}") {
+		if strings.HasPrefix(swaggerResourceType.Endpoint.TemplateURL, cosmosdbSQLContainerTemplate) {
+			return true, nil
+		}
+		if strings.HasPrefix(swaggerResourceType.Endpoint.TemplateURL, cosmosdbGremlinGraphTemplate) {
 			return true, nil
 		}
 	}
@@ -229,7 +342,7 @@ func (e *CosmosDbExpander) ListActions(context context.Context, item *TreeNode)
 		swaggerResourceType = tempItem.SwaggerResourceType
 	}
 	if swaggerResourceType != nil {
-		if swaggerResourceType.Endpoint.TemplateURL == "/subscriptions/{subscriptionId}/resourceGroups/{resourceGroupName}/providers/Microsoft.DocumentDB/databaseAccounts/{accountName}" {
+		if swaggerResourceType.Endpoint.TemplateURL == cosmosdbAccountTemplate {
 			nodes = append(nodes,
 				&TreeNode{
 					Parentid:              item.ID,
@@ -244,7 +357,7 @@ func (e *CosmosDbExpander) ListActions(context context.Context, item *TreeNode)
 					},
 				})
 		}
-		if strings.HasPrefix(swaggerResourceType.Endpoint.TemplateURL, "/subscriptions/{subscriptionId}/resourceGroups/{resourceGroupName}/providers/Microsoft.DocumentDB/databaseAccounts/{accountName}/sqlDatabases/{databaseName}/containers/{containerName}") {
+		if strings.HasPrefix(swaggerResourceType.Endpoint.TemplateURL, cosmosdbSQLContainerTemplate) {
 			nodes = append(nodes,
 				&TreeNode{
 					Parentid:              item.ID,
@@ -258,21 +371,34 @@ func (e *CosmosDbExpander) ListActions(context context.Context, item *TreeNode)
 						"ActionID": cosmosdbActionGetDocument,
 					},
 				},
+				&TreeNode{
+					Parentid:               item.ID,
+					ID:                     item.ID + "?sql-query",
+					Namespace:              "cosmos-db",
+					Name:                   "Execute Query",
+					Display:                "Execute Query",
+					ItemType:               ActionType,
+					SuppressGenericExpand:  true,
+					TimeoutOverrideSeconds: &cosmosdbQueryTimeoutSeconds,
+					Metadata: map[string]string{
+						"ActionID": cosmosdbActionSQLQuery,
+					},
+				},
 				&TreeNode{
 					Parentid:              item.ID,
-					ID:                    item.ID + "?sql-query",
+					ID:                    item.ID + "?query-history",
 					Namespace:             "cosmos-db",
-					Name:                  "Execute Query",
-					Display:               "Execute Query",
+					Name:                  "Query History",
+					Display:               "Query History",
 					ItemType:              ActionType,
 					SuppressGenericExpand: true,
 					Metadata: map[string]string{
-						"ActionID": cosmosdbActionSQLQuery,
+						"ActionID": cosmosdbActionQueryHistory,
 					},
 				},
 				&TreeNode{
 					Parentid:              item.ID,
-					ID:                    item.ID + "?sql-query",
+					ID:                    item.ID + "?add-document",
 					Namespace:             "cosmos-db",
 					Name:                  "Add New Document",
 					Display:               "Add New Document",
@@ -283,6 +409,21 @@ func (e *CosmosDbExpander) ListActions(context context.Context, item *TreeNode)
 					},
 				})
 		}
+		if strings.HasPrefix(swaggerResourceType.Endpoint.TemplateURL, cosmosdbGremlinGraphTemplate) {
+			nodes = append(nodes,
+				&TreeNode{
+					Parentid:              item.ID,
+					ID:                    item.ID + "?gremlin-query",
+					Namespace:             "cosmos-db",
+					Name:                  "Execute Gremlin Query",
+					Display:               "Execute Gremlin Query",
+					ItemType:              ActionType,
+					SuppressGenericExpand: true,
+					Metadata: map[string]string{
+						"ActionID": cosmosdbActionGremlinQuery,
+					},
+				})
+		}
 	}
 
 	return ListActionsResult{
@@ -305,6 +446,14 @@ func (e *CosmosDbExpander) ExecuteAction(context context.Context, item *TreeNode
 		return e.cosmosdbActionAddDocument(context, item)
 	case cosmosdbActionSQLQuery:
 		return e.cosmosdbActionExecuteQuery(context, item)
+	case cosmosdbActionQueryHistory:
+		return e.cosmosdbActionQueryHistory(context, item)
+	case cosmosdbActionRunHistoryQuery:
+		return e.cosmosdbActionRunHistoryQuery(context, item)
+	case cosmosdbActionExportQuery:
+		return e.cosmosdbActionExportQuery(context, item)
+	case cosmosdbActionGremlinQuery:
+		return e.cosmosdbActionExecuteGremlinQuery(context, item)
 	case "":
 		return ExpanderResult{
 			SourceDescription: "CosmosDbExpander",
@@ -360,8 +509,6 @@ func (e *CosmosDbExpander) expandSQLDocumentsContinuation(ctx context.Context, i
 }
 
 func (e *CosmosDbExpander) expandSQLDocumentsCommon(ctx context.Context, item *TreeNode, accountName string, databaseName string, containerName string, accountKey string, partitionKey string, continuationToken string) ExpanderResult {
-	partitionKeySegments := strings.Split(partitionKey, "/")
-
 	requestURL := fmt.Sprintf("/dbs/%s/colls/%s/docs", databaseName, containerName)
 	headers := map[string]string{}
 	if continuationToken != "" {
@@ -399,47 +546,15 @@ func (e *CosmosDbExpander) expandSQLDocumentsCommon(ctx context.Context, item *T
 
 	nodes := []*TreeNode{}
 	for _, document := range list.Documents {
-		document := document.(map[string]interface{})
-		id := document["id"].(string)
-		idWithPartitionKey := id
-		displayText := id
-		partitionKeyValue := ""
-		if partitionKey != "" {
-			// get the partitionKey value for the current document
-			v, err := getJSONProperty(document, partitionKeySegments...)
-			if err != nil {
-				return ExpanderResult{
-					Err:               fmt.Errorf("Error determining partition key value: %s", err),
-					IsPrimaryResponse: true,
-					SourceDescription: "CosmosDbExpander request",
-				}
+		node, err := getSQLDocumentNode(item, document.(map[string]interface{}), accountName, databaseName, containerName, accountKey, partitionKey)
+		if err != nil {
+			return ExpanderResult{
+				Err:               err,
+				IsPrimaryResponse: true,
+				SourceDescription: "CosmosDbExpander request",
 			}
-			vString := fmt.Sprintf("%v", v)
-			partitionKeyValue = fmt.Sprintf("[\"%s\"]", vString)
-			idWithPartitionKey = fmt.Sprintf("%s %s", partitionKeyValue, id)
-			displayText = style.Subtle(vString) + "\n  " + id
-		}
-		node := TreeNode{
-			Parentid:              item.ID,
-			ID:                    item.ID + "/" + idWithPartitionKey,
-			Namespace:             "cosmosdb",
-			Name:                  idWithPartitionKey,
-			Display:               displayText,
-			ItemType:              cosmosdbSQLDocument,
-			ExpandURL:             ExpandURLNotSupported,
-			DeleteURL:             item.ID + "/" + idWithPartitionKey,
-			SuppressSwaggerExpand: true,
-			SuppressGenericExpand: true,
-			Metadata: map[string]string{
-				"AccountName":       accountName,
-				"DatabaseName":      databaseName,
-				"ContainerName":     containerName,
-				"AccountKey":        accountKey,
-				"PartitionKeyValue": partitionKeyValue,
-				"ItemID":            id,
-			},
 		}
-		nodes = append(nodes, &node)
+		nodes = append(nodes, node)
 	}
 
 	if continuationToken := response.Headers.Get("x-ms-continuation"); continuationToken != "" {
@@ -472,6 +587,45 @@ func (e *CosmosDbExpander) expandSQLDocumentsCommon(ctx context.Context, item *T
 	}
 }
 
+// getSQLDocumentNode creates the node for a document. partitionKey is the partition key path without the leading '/'
+func getSQLDocumentNode(parent *TreeNode, document map[string]interface{}, accountName string, databaseName string, containerName string, accountKey string, partitionKey string) (*TreeNode, error) {
+	id := document["id"].(string)
+	idWithPartitionKey := id
+	displayText := id
+	partitionKeyValue := ""
+	if partitionKey != "" {
+		// get the partitionKey value for the current document
+		v, err := getJSONProperty(document, strings.Split(partitionKey, "/")...)
+		if err != nil {
+			return nil, fmt.Errorf("Error determining partition key value: %s", err)
+		}
+		vString := fmt.Sprintf("%v", v)
+		partitionKeyValue = fmt.Sprintf("[\"%s\"]", vString)
+		idWithPartitionKey = fmt.Sprintf("%s %s", partitionKeyValue, id)
+		displayText = style.Subtle(vString) + "\n  " + id
+	}
+	return &TreeNode{
+		Parentid:              parent.ID,
+		ID:                    parent.ID + "/" + idWithPartitionKey,
+		Namespace:             "cosmosdb",
+		Name:                  idWithPartitionKey,
+		Display:               displayText,
+		ItemType:              cosmosdbSQLDocument,
+		ExpandURL:             ExpandURLNotSupported,
+		DeleteURL:             parent.ID + "/" + idWithPartitionKey,
+		SuppressSwaggerExpand: true,
+		SuppressGenericExpand: true,
+		Metadata: map[string]string{
+			"AccountName":       accountName,
+			"DatabaseName":      databaseName,
+			"ContainerName":     containerName,
+			"AccountKey":        accountKey,
+			"PartitionKeyValue": partitionKeyValue,
+			"ItemID":            id,
+		},
+	}, nil
+}
+
 func (e *CosmosDbExpander) expandSQLDocumentNode(ctx context.Context, item *TreeNode) ExpanderResult {
 	accountName := item.Metadata["AccountName"]
 	databaseName := item.Metadata["DatabaseName"]
@@ -863,72 +1017,63 @@ func (e *CosmosDbExpander) cosmosdbActionAddDocument(ctx context.Context, item *
 	}
 }
 
-func (e *CosmosDbExpander) cosmosdbActionExecuteQuery(ctx context.Context, item *TreeNode) ExpanderResult {
-
-	connectionDetails, err := e.walkParentsToGetConnectionDetails(ctx, item)
-	if err != nil {
-		return ExpanderResult{
-			Err:               err,
-			SourceDescription: "CosmosDbExpander request",
-			IsPrimaryResponse: true,
-		}
-
-	}
-
-	commandPanelNotification := func(state interfaces.CommandPanelNotification) {
-		if state.EnterPressed {
-			queryText := state.CurrentText
-			result, err := e.executeQuery(ctx, connectionDetails.AccountName, connectionDetails.DatabaseName, connectionDetails.ContainerName, connectionDetails.AccountKey, queryText)
-			if err != nil {
-				e.contentPanel.SetContent("\n\n\n\n"+err.Error(), interfaces.ResponsePlainText, "Error executing query")
-			} else {
-				e.contentPanel.SetContent(result, interfaces.ResponseJSON, "Results")
-			}
-		}
-	}
-	e.commandPanel.ShowWithText("query:", "SELECT * FROM c", nil, commandPanelNotification)
-	// Force UI to re-render to pickup
-	e.gui.Update(func(g *gocui.Gui) error {
-		return nil
-	})
-
-	return ExpanderResult{
-		Response:          ExpanderResponse{Response: "", ResponseType: interfaces.ResponsePlainText},
-		SourceDescription: "CosmosDbExpander request",
-		IsPrimaryResponse: true,
-	}
-
-}
-
-func (e *CosmosDbExpander) executeQuery(ctx context.Context, accountName string, databaseName string, containerName string, accountKey string, queryText string) (string, error) {
+// queryDocuments runs a (cross-partition) query against a container, returning the page of results for the continuationToken
+func (e *CosmosDbExpander) queryDocuments(ctx context.Context, accountName string, databaseName string, containerName string, accountKey string, query CosmosDbQuery, continuationToken string) (*doRequestResponse, error) {
 
 	headers := map[string]string{}
 	headers["x-ms-documentdb-isquery"] = "true"
 	headers["Content-Type"] = "application/query+json"
 	headers["x-ms-documentdb-query-enablecrosspartition"] = "true" // enable cross-parition queries - can be restricted to single-partition via WHERE clause
-
-	query := CosmosDbQuery{
-		Query: queryText,
+	headers["x-ms-documentdb-populatequerymetrics"] = "true"
+	if continuationToken != "" {
+		headers["x-ms-continuation"] = continuationToken
 	}
+
 	buf, err := json.Marshal(query)
 	if err != nil {
-		return "", fmt.Errorf("Error marshaling query as JSON: %s", err)
+		return nil, fmt.Errorf("Error marshaling query as JSON: %s", err)
 	}
 
 	requestURL := fmt.Sprintf("/dbs/%s/colls/%s/docs", databaseName, containerName)
 	response, err := e.doRequestWithHeadersAndBody(ctx, "POST", accountName, requestURL, accountKey, headers, bytes.NewBuffer(buf))
 	if err != nil {
-		return "", fmt.Errorf("Error getting documents: %s", err)
+		return nil, fmt.Errorf("Error getting documents: %s", err)
 	}
 	if !e.isSuccessCode(response.StatusCode) {
 		data := ""
 		if response != nil {
 			data = string(response.Data)
 		}
-		return "", fmt.Errorf("Error getting document. StatusCode=%d, Response=%s", response.StatusCode, data)
+		return nil, fmt.Errorf("Error getting document. StatusCode=%d, Response=%s", response.StatusCode, data)
 	}
 
-	return string(response.Data), nil
+	return response, nil
+}
+
+// getDocumentByID looks up a document by id without needing the partition key value
+func (e *CosmosDbExpander) getDocumentByID(ctx context.Context, accountName string, databaseName string, containerName string, accountKey string, id string) (map[string]interface{}, error) {
+	query := CosmosDbQuery{
+		Query: "SELECT * FROM c WHERE c.id = @id",
+		Parameters: []CosmosDbQueryParameter{
+			{Name: "@id", Value: id},
+		},
+	}
+	response, err := e.queryDocuments(ctx, accountName, databaseName, containerName, accountKey, query, "")
+	if err != nil {
+		return nil, err
+	}
+	var list CosmosDbListDocumentResponse
+	if err = json.Unmarshal(response.Data, &list); err != nil {
+		return nil, fmt.Errorf("Error unmarshalling response: %s", err)
+	}
+	if len(list.Documents) == 0 {
+		return nil, fmt.Errorf("Document %q not found", id)
+	}
+	document, ok := list.Documents[0].(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("Unexpected document format for %q", id)
+	}
+	return document, nil
 }
 
 func (e *CosmosDbExpander) getAccountKey(ctx context.Context, item *TreeNode) (string, error) {
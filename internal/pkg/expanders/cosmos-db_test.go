@@ -0,0 +1,171 @@
+package expanders
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/nbio/st"
+)
+
+func Test_CosmosDb_UnwrapMongoDocument(t *testing.T) {
+	data := `{
+		"id": "NWY",
+		"_rid": "abc",
+		"_etag": "\"0000\"",
+		"_id": {"$t": 7, "$v": {"$oid": "5f1c"}},
+		"name": {"$t": 2, "$v": "test"},
+		"tags": {"$t": 4, "$v": [{"$t": 2, "$v": "a"}, {"$t": 2, "$v": "b"}]},
+		"nested": {"$t": 3, "$v": {"id": {"$t": 16, "$v": 1}}}
+	}`
+	var document map[string]interface{}
+	st.Assert(t, json.Unmarshal([]byte(data), &document), nil)
+
+	result := unwrapMongoDocument(document)
+
+	st.Expect(t, len(result), 4)
+	st.Expect(t, formatMongoID(result["_id"]), "ObjectId(5f1c)")
+	st.Expect(t, result["name"], "test")
+	st.Expect(t, result["tags"], []interface{}{"a", "b"})
+	st.Expect(t, result["nested"], map[string]interface{}{"id": float64(1)})
+}
+
+func Test_CosmosDb_RenderCassandraTableSchema(t *testing.T) {
+	data := `{
+		"name": "table1",
+		"properties": {
+			"resource": {
+				"id": "table1",
+				"defaultTtl": 100,
+				"schema": {
+					"columns": [{"name": "id", "type": "int"}, {"name": "ts", "type": "timestamp"}, {"name": "value", "type": "text"}],
+					"partitionKeys": [{"name": "id"}],
+					"clusterKeys": [{"name": "ts", "orderBy": "desc"}]
+				}
+			}
+		}
+	}`
+	var table CosmosDbCassandraTable
+	st.Assert(t, json.Unmarshal([]byte(data), &table), nil)
+
+	result := renderCassandraTableSchema("ks1", table)
+
+	st.Expect(t, strings.HasPrefix(result, "CREATE TABLE ks1.table1 (\n    id int,\n    ts timestamp,\n    value text,\n    PRIMARY KEY (id, ts)\n)"), true)
+	st.Expect(t, strings.Contains(result, "CLUSTERING ORDER BY (ts DESC)"), true)
+	st.Expect(t, strings.Contains(result, "AND default_time_to_live = 100;"), true)
+}
+
+// newTestGremlinServer starts a websocket server that passes the gremlin requests to handler
+func newTestGremlinServer(handler func(conn *websocket.Conn, readRequest func() gremlinRequest, writeResponse func(string))) (*httptest.Server, chan error) {
+	serverErr := make(chan error, 1)
+	// a small write buffer so that long messages are sent as several fragments
+	upgrader := websocket.Upgrader{WriteBufferSize: 64}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			serverErr <- err
+			return
+		}
+		defer conn.Close() //nolint: errcheck
+
+		readRequest := func() gremlinRequest {
+			_, payload, err := conn.ReadMessage()
+			if err != nil {
+				serverErr <- err
+				return gremlinRequest{}
+			}
+			var request gremlinRequest
+			_ = json.Unmarshal(payload[1+len(gremlinMimeType):], &request)
+			return request
+		}
+		writeResponse := func(response string) {
+			_ = conn.WriteMessage(websocket.BinaryMessage, []byte(response))
+		}
+		handler(conn, readRequest, writeResponse)
+		serverErr <- nil
+	}))
+	return server, serverErr
+}
+
+func Test_CosmosDb_GremlinClient_AuthenticatesAndCombinesPartialResults(t *testing.T) {
+	server, serverErr := newTestGremlinServer(func(conn *websocket.Conn, readRequest func() gremlinRequest, writeResponse func(string)) {
+		eval := readRequest()
+		if eval.Args["gremlin"] != "g.V()" {
+			writeResponse(`{"requestId":"` + eval.RequestID + `","status":{"code":500,"message":"unexpected query"}}`)
+			return
+		}
+		writeResponse(`{"requestId":"` + eval.RequestID + `","status":{"code":407}}`)
+
+		auth := readRequest()
+		sasl, _ := base64.StdEncoding.DecodeString(auth.Args["SASL"].(string))
+		if auth.Op != "authentication" || string(sasl) != "\x00/dbs/db1/colls/graph1\x00key" {
+			writeResponse(`{"requestId":"` + eval.RequestID + `","status":{"code":401,"message":"bad auth"}}`)
+			return
+		}
+		// pings between the responses are answered by the client
+		_ = conn.WriteControl(websocket.PingMessage, []byte("ping"), time.Now().Add(time.Second))
+		writeResponse(`{"requestId":"` + eval.RequestID + `","status":{"code":206},"result":{"data":[{"id":"1"}]}}`)
+
+		// the final response is longer than the write buffer so is fragmented
+		writeResponse(`{"requestId":"` + eval.RequestID + `","status":{"code":200,"attributes":{"x-ms-total-request-charge":2.5}},"result":{"data":[{"id":"2"}]}}`)
+	})
+	defer server.Close()
+
+	client, err := dialGremlinClient(context.Background(), "ws"+strings.TrimPrefix(server.URL, "http"))
+	st.Assert(t, err, nil)
+	defer client.Close() //nolint: errcheck
+
+	result, err := client.Execute("g.V()", "/dbs/db1/colls/graph1", "key")
+	st.Assert(t, err, nil)
+	st.Expect(t, len(result.Data), 2)
+	st.Expect(t, result.RequestCharge, 2.5)
+	st.Expect(t, <-serverErr, nil)
+}
+
+func Test_CosmosDb_GremlinClient_ServerClose(t *testing.T) {
+	server, serverErr := newTestGremlinServer(func(conn *websocket.Conn, readRequest func() gremlinRequest, writeResponse func(string)) {
+		readRequest()
+		_ = conn.WriteMessage(websocket.CloseMessage, websocket.FormatCloseMessage(websocket.CloseGoingAway, "shutting down"))
+	})
+	defer server.Close()
+
+	client, err := dialGremlinClient(context.Background(), "ws"+strings.TrimPrefix(server.URL, "http"))
+	st.Assert(t, err, nil)
+	defer client.Close() //nolint: errcheck
+
+	_, err = client.Execute("g.V()", "/dbs/db1/colls/graph1", "key")
+	st.Expect(t, err.Error(), "Connection closed by server")
+	st.Expect(t, <-serverErr, nil)
+}
+
+func Test_CosmosDb_GetQueryParameterNames(t *testing.T) {
+	names := getQueryParameterNames(`SELECT * FROM c WHERE c.email = 'someone@example.com' AND c.age > @minAge AND c.name = @name AND c.age < @maxAge OR c.name = @name`)
+
+	st.Expect(t, names, []string{"@minAge", "@name", "@maxAge"})
+}
+
+func Test_CosmosDb_QueryParameterValues(t *testing.T) {
+	st.Expect(t, parseQueryParameterValue("42"), float64(42))
+	st.Expect(t, parseQueryParameterValue("true"), true)
+	st.Expect(t, parseQueryParameterValue("hello"), "hello")
+	st.Expect(t, parseQueryParameterValue(`"42"`), "42")
+
+	for _, text := range []string{"42", "true", "hello", `"42"`, `["a","b"]`} {
+		st.Expect(t, formatQueryParameterValue(parseQueryParameterValue(text)), text)
+	}
+}
+
+func Test_CosmosDb_ParseQueryMetrics(t *testing.T) {
+	metrics := parseCosmosDbQueryMetrics("totalExecutionTimeInMs=0.35;retrievedDocumentCount=2;invalid")
+
+	st.Expect(t, len(metrics), 2)
+	st.Expect(t, metrics["totalExecutionTimeInMs"], 0.35)
+	st.Expect(t, metrics["retrievedDocumentCount"], float64(2))
+	st.Expect(t, len(parseCosmosDbQueryMetrics("")), 0)
+}
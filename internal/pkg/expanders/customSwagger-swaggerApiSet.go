@@ -0,0 +1,287 @@
+package expanders
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strings"
+
+	"github.com/lawrencegripper/azbrowse/internal/pkg/interfaces"
+	"github.com/lawrencegripper/azbrowse/pkg/swagger"
+)
+
+var _ SwaggerAPISet = SwaggerAPISetCustom{}
+var _ SwaggerAPISetActions = SwaggerAPISetCustom{}
+
+// customSwaggerHeadersFunc returns the headers to add to each request (e.g. for authentication).
+// refresh is set to force new headers to be fetched after a request is rejected with a 401
+type customSwaggerHeadersFunc func(refresh bool) (map[string]string, error)
+
+// SwaggerAPISetCustom holds the config for working with a user-supplied set of swagger specs
+type SwaggerAPISetCustom struct {
+	resourceTypes       []swagger.ResourceType
+	resourceTypeMatcher *swagger.ResourceTypeMatcher
+	httpClient          http.Client
+	nodeID              string // ID of the node that the API is attached to
+	baseURL             string // e.g. https://myapp.azurewebsites.net
+	getHeaders          customSwaggerHeadersFunc
+}
+
+// NewSwaggerAPISetCustom creates a new SwaggerAPISetCustom
+func NewSwaggerAPISetCustom(resourceTypes []swagger.ResourceType, nodeID string, baseURL string, getHeaders customSwaggerHeadersFunc) SwaggerAPISetCustom {
+	c := SwaggerAPISetCustom{}
+	c.resourceTypes = resourceTypes
+	c.resourceTypeMatcher = swagger.NewResourceTypeMatcher(c.resourceTypes)
+	c.httpClient = http.Client{}
+	c.nodeID = nodeID
+	c.baseURL = baseURL
+	c.getHeaders = getHeaders
+	return c
+}
+
+// ID returns the ID for the APISet
+func (c SwaggerAPISetCustom) ID() string {
+	return c.nodeID
+}
+
+// MatchChildNodesByName indicates whether child nodes should be matched by name (or position)
+func (c SwaggerAPISetCustom) MatchChildNodesByName() bool {
+	return true
+}
+
+// AppliesToNode is called by the Swagger exapnder to test whether the node applies to this APISet
+func (c SwaggerAPISetCustom) AppliesToNode(node *TreeNode) bool {
+	// this function is only called for nodes that don't have the SwaggerAPISetID set
+	// this should never happen for custom swagger nodes
+	return false
+}
+
+// GetResourceTypes returns the ResourceTypes for the API Set
+func (c SwaggerAPISetCustom) GetResourceTypes() []swagger.ResourceType {
+	return c.resourceTypes
+}
+
+// GetResourceTypeMatcher returns the ResourceTypeMatcher for the ResourceTypes in the API Set
+func (c SwaggerAPISetCustom) GetResourceTypeMatcher() *swagger.ResourceTypeMatcher {
+	return c.resourceTypeMatcher
+}
+
+// DoRequest makes a request against the API
+func (c SwaggerAPISetCustom) DoRequest(verb string, url string) (string, error) {
+	return c.DoRequestWithBody(verb, url, "")
+}
+
+// DoRequestWithBody makes a request against the API
+func (c SwaggerAPISetCustom) DoRequestWithBody(verb string, url string, body string) (string, error) {
+	url = c.baseURL + url
+	response, err := c.doRawRequest(verb, url, body, false)
+	if err != nil {
+		return "", err
+	}
+	if response.StatusCode == http.StatusUnauthorized {
+		// The cached headers may hold an expired token so get fresh headers and retry
+		response.Body.Close() //nolint: errcheck
+		response, err = c.doRawRequest(verb, url, body, true)
+		if err != nil {
+			return "", err
+		}
+	}
+	defer response.Body.Close() //nolint: errcheck
+	buf, err := ioutil.ReadAll(response.Body)
+	if err != nil {
+		err = fmt.Errorf("Failed to read body: %s", err)
+		return "", err
+	}
+	data := string(buf)
+	if 200 <= response.StatusCode && response.StatusCode < 300 {
+		return data, nil
+	}
+	return "", fmt.Errorf("Response failed with %s (%s): %s", response.Status, url, data)
+}
+
+func (c SwaggerAPISetCustom) doRawRequest(verb string, url string, body string, refreshHeaders bool) (*http.Response, error) {
+	request, err := http.NewRequest(verb, url, bytes.NewReader([]byte(body)))
+	if err != nil {
+		err = fmt.Errorf("Failed to create request" + err.Error() + url)
+		return nil, err
+	}
+
+	headers, err := c.getHeaders(refreshHeaders)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to get request headers: %s", err)
+	}
+	for name, value := range headers {
+		request.Header.Set(name, value)
+	}
+	if body != "" {
+		request.Header.Set("Content-Type", "application/json")
+	}
+	response, err := c.httpClient.Do(request)
+	if err != nil {
+		err = fmt.Errorf("Failed" + err.Error() + url)
+		return nil, err
+	}
+	return response, nil
+}
+
+// getCustomSwaggerListItems returns the items from a list response. Lists can either be a
+// top-level array or an object with a `value` array (as used by ARM and OData APIs)
+func getCustomSwaggerListItems(data string) ([]map[string]interface{}, error) {
+	decoder := json.NewDecoder(strings.NewReader(data))
+	decoder.UseNumber() // keep numeric IDs as they appear in the response
+	var response interface{}
+	if err := decoder.Decode(&response); err != nil {
+		return nil, fmt.Errorf("Error parsing response: %s", err)
+	}
+
+	var items []interface{}
+	switch typedResponse := response.(type) {
+	case []interface{}:
+		items = typedResponse
+	case map[string]interface{}:
+		if value, ok := typedResponse["value"].([]interface{}); ok {
+			items = value
+		}
+	}
+
+	result := []map[string]interface{}{}
+	for _, item := range items {
+		if itemMap, ok := item.(map[string]interface{}); ok {
+			result = append(result, itemMap)
+		}
+	}
+	return result, nil
+}
+
+// getCustomSwaggerItemName returns the value for the template segment from a list item,
+// falling back to the `id` or `name` properties
+func getCustomSwaggerItemName(item map[string]interface{}, segmentName string) string {
+	for _, propertyName := range []string{segmentName, "id", "name"} {
+		if value, ok := item[propertyName]; ok && value != nil {
+			switch value.(type) {
+			case string, json.Number:
+				return fmt.Sprintf("%v", value)
+			}
+		}
+	}
+	return ""
+}
+
+// getCustomSwaggerSubResourceType returns the first SubResource whose final URL segment is a template value (e.g. /orders/{orderId})
+func getCustomSwaggerSubResourceType(resourceType swagger.ResourceType) *swagger.ResourceType {
+	for _, subResourceType := range resourceType.SubResources {
+		segments := subResourceType.Endpoint.URLSegments
+		if len(segments) > 0 && segments[len(segments)-1].Name != "" {
+			result := subResourceType
+			return &result
+		}
+	}
+	return nil
+}
+
+// ExpandResource returns metadata about child resources of the specified resource node
+func (c SwaggerAPISetCustom) ExpandResource(ctx context.Context, currentItem *TreeNode, resourceType swagger.ResourceType) (APISetExpandResponse, error) {
+	if resourceType.FixedContent != "" {
+		return APISetExpandResponse{
+			Response:     resourceType.FixedContent,
+			ResponseType: interfaces.ResponsePlainText,
+		}, nil
+	}
+
+	method := resourceType.Verb
+	if method == "" {
+		method = "GET"
+	}
+	data, err := c.DoRequest(method, currentItem.ExpandURL)
+	if err != nil {
+		return APISetExpandResponse{}, fmt.Errorf("Failed to make request: %s", err)
+	}
+
+	subResources := []SubResource{}
+	subResourceType := getCustomSwaggerSubResourceType(resourceType)
+	if subResourceType != nil {
+		items, err := getCustomSwaggerListItems(data)
+		if err != nil {
+			return APISetExpandResponse{Response: data, ResponseType: interfaces.ResponseJSON}, err
+		}
+
+		templateValues := resourceType.Endpoint.Match(currentItem.ExpandURL).Values
+		segments := subResourceType.Endpoint.URLSegments
+		newTemplateName := segments[len(segments)-1].Name
+		for _, item := range items {
+			name := getCustomSwaggerItemName(item, newTemplateName)
+			if name == "" {
+				continue
+			}
+
+			templateValues[newTemplateName] = name
+			subResourceURL, err := subResourceType.Endpoint.BuildURL(templateValues)
+			if err != nil {
+				return APISetExpandResponse{Response: data, ResponseType: interfaces.ResponseJSON}, fmt.Errorf("Error building subresource URL: %s", err)
+			}
+
+			deleteURL := ""
+			if subResourceType.DeleteEndpoint != nil {
+				deleteURL, err = subResourceType.DeleteEndpoint.BuildURL(templateValues)
+				if err != nil {
+					err = fmt.Errorf("Error building subresource delete url '%s': %s", subResourceType.DeleteEndpoint.TemplateURL, err)
+					return APISetExpandResponse{Response: data, ResponseType: interfaces.ResponseJSON}, err
+				}
+			}
+			subResources = append(subResources, SubResource{
+				ID:           c.nodeID + subResourceURL,
+				Name:         name,
+				ResourceType: *subResourceType,
+				ExpandURL:    subResourceURL,
+				DeleteURL:    deleteURL,
+			})
+		}
+	}
+
+	return APISetExpandResponse{
+		Response:     data,
+		ResponseType: interfaces.ResponseJSON,
+		SubResources: subResources,
+	}, nil
+}
+
+// Delete attempts to delete the item. Returns true if deleted, false if not handled, an error if an error occurred attempting to delete
+func (c SwaggerAPISetCustom) Delete(ctx context.Context, item *TreeNode) (bool, error) {
+	if item.DeleteURL == "" {
+		return false, fmt.Errorf("Item cannot be deleted (No DeleteURL)")
+	}
+
+	_, err := c.DoRequest("DELETE", item.DeleteURL)
+	if err != nil {
+		err = fmt.Errorf("Failed to delete: %s (%s)", err.Error(), item.DeleteURL)
+		return false, err
+	}
+	return true, nil
+}
+
+// Update attempts to update the specified item with new content
+func (c SwaggerAPISetCustom) Update(ctx context.Context, item *TreeNode, content string) error {
+	matchResult := item.SwaggerResourceType.Endpoint.Match(item.ExpandURL)
+	if !matchResult.IsMatch {
+		return fmt.Errorf("item.ExpandURL didn't match current Endpoint")
+	}
+
+	url, err := item.SwaggerResourceType.PutEndpoint.BuildURL(matchResult.Values)
+	if err != nil {
+		return fmt.Errorf("Error building PUT url: %s", err)
+	}
+
+	_, err = c.DoRequestWithBody("PUT", url, content)
+	if err != nil {
+		return fmt.Errorf("Error from PUT: %s", err)
+	}
+	return nil
+}
+
+// DoPostAction performs a POST action against the API
+func (c SwaggerAPISetCustom) DoPostAction(ctx context.Context, item *TreeNode, url string, body string) (string, error) {
+	return c.DoRequestWithBody("POST", url, body)
+}
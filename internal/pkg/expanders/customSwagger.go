@@ -0,0 +1,344 @@
+package expanders
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+
+	"github.com/lawrencegripper/azbrowse/internal/pkg/config"
+	"github.com/lawrencegripper/azbrowse/internal/pkg/eventing"
+	"github.com/lawrencegripper/azbrowse/internal/pkg/style"
+	"github.com/lawrencegripper/azbrowse/pkg/armclient"
+	"github.com/lawrencegripper/azbrowse/pkg/swagger"
+)
+
+const customSwaggerNamespace = "CustomSwaggerExpander"
+
+// customSwaggerAPI holds the config and loaded ResourceTypes for a user-supplied set of swagger specs
+type customSwaggerAPI struct {
+	config        config.SwaggerAPIConfig
+	resourceTypes []swagger.ResourceType
+	loadErr       error
+}
+
+// Check interface
+var _ Expander = &CustomSwaggerExpander{}
+
+// CustomSwaggerExpander attaches the user-supplied swagger specs from the `swaggerAPIs` config to their ARM resources
+type CustomSwaggerExpander struct {
+	ExpanderBase
+	client *armclient.Client
+	apis   []customSwaggerAPI
+}
+
+// NewCustomSwaggerExpander creates a CustomSwaggerExpander and loads the specs configured in `swaggerAPIs`
+func NewCustomSwaggerExpander(client *armclient.Client) *CustomSwaggerExpander {
+	e := &CustomSwaggerExpander{
+		client: client,
+	}
+	userConfig, err := config.Load()
+	if err != nil {
+		eventing.SendFailureStatusFromError("Failed to load swaggerAPIs from config", err)
+		return e
+	}
+	for _, apiConfig := range userConfig.SwaggerAPIs {
+		e.apis = append(e.apis, loadCustomSwaggerAPI(apiConfig))
+	}
+	for _, name := range getDuplicateCustomSwaggerAPINames(userConfig.SwaggerAPIs) {
+		eventing.SendFailureStatus(fmt.Sprintf("Swagger API name %q is used by more than one entry in swaggerAPIs", name))
+	}
+	return e
+}
+
+// getDuplicateCustomSwaggerAPINames returns the names that are used by more than one API. The
+// name is used to identify the API from its node so duplicates can't be told apart
+func getDuplicateCustomSwaggerAPINames(apiConfigs []config.SwaggerAPIConfig) []string {
+	counts := map[string]int{}
+	duplicates := []string{}
+	for _, apiConfig := range apiConfigs {
+		counts[apiConfig.Name]++
+		if counts[apiConfig.Name] == 2 {
+			duplicates = append(duplicates, apiConfig.Name)
+		}
+	}
+	return duplicates
+}
+
+// loadCustomSwaggerAPI loads the ResourceTypes for an API. Errors are kept to show when the API node is expanded
+func loadCustomSwaggerAPI(apiConfig config.SwaggerAPIConfig) customSwaggerAPI {
+	swaggerConfig := &swagger.Config{
+		SuppressAPIVersion: !apiConfig.IncludeAPIVersion,
+	}
+	resourceTypes, err := swagger.LoadResourceTypesFromDirectory(expandHomeDirectory(apiConfig.SpecsDirectory), swaggerConfig)
+	return customSwaggerAPI{
+		config:        apiConfig,
+		resourceTypes: resourceTypes,
+		loadErr:       err,
+	}
+}
+
+// expandHomeDirectory replaces a leading ~ with the user's home directory
+func expandHomeDirectory(path string) string {
+	if path == "~" || strings.HasPrefix(path, "~/") {
+		if home, err := os.UserHomeDir(); err == nil {
+			return home + path[1:]
+		}
+	}
+	return path
+}
+
+func (e *CustomSwaggerExpander) setClient(c *armclient.Client) {
+	e.client = c
+}
+
+// Name returns the name of the expander
+func (e *CustomSwaggerExpander) Name() string {
+	return "CustomSwaggerExpander"
+}
+
+func (e *CustomSwaggerExpander) getAPIsForResourceType(armType string) []customSwaggerAPI {
+	apis := []customSwaggerAPI{}
+	for _, api := range e.apis {
+		if strings.EqualFold(api.config.ResourceType, armType) {
+			apis = append(apis, api)
+		}
+	}
+	return apis
+}
+
+func (e *CustomSwaggerExpander) getAPIByName(name string) (*customSwaggerAPI, error) {
+	var result *customSwaggerAPI
+	for _, api := range e.apis {
+		if api.config.Name == name {
+			if result != nil {
+				return nil, fmt.Errorf("Swagger API name %q is used by more than one entry in swaggerAPIs", name)
+			}
+			match := api
+			result = &match
+		}
+	}
+	if result == nil {
+		return nil, fmt.Errorf("Swagger API %q not found in config", name)
+	}
+	return result, nil
+}
+
+// DoesExpand checks if this is a resource with configured APIs or a custom API node
+func (e *CustomSwaggerExpander) DoesExpand(ctx context.Context, currentItem *TreeNode) (bool, error) {
+	if currentItem.Namespace == customSwaggerNamespace {
+		return true, nil
+	}
+	if currentItem.ItemType == ResourceType && len(e.getAPIsForResourceType(currentItem.ArmType)) > 0 {
+		return true, nil
+	}
+	return false, nil
+}
+
+// Expand adds the API nodes to resources and the root ResourceTypes to the API nodes
+func (e *CustomSwaggerExpander) Expand(ctx context.Context, currentItem *TreeNode) ExpanderResult {
+	if currentItem.Namespace == customSwaggerNamespace {
+		return e.expandAPIRoot(ctx, currentItem)
+	}
+
+	newItems := []*TreeNode{}
+	for _, api := range e.getAPIsForResourceType(currentItem.ArmType) {
+		newItems = append(newItems, &TreeNode{
+			ID:                    currentItem.ID + "/<swagger:" + api.config.Name + ">",
+			Parentid:              currentItem.ID,
+			Namespace:             customSwaggerNamespace,
+			Name:                  api.config.Name,
+			Display:               style.Subtle("[Swagger]") + "\n  " + api.config.Name,
+			ItemType:              SubResourceType,
+			ExpandURL:             ExpandURLNotSupported,
+			SubscriptionID:        currentItem.SubscriptionID,
+			SuppressSwaggerExpand: true,
+			SuppressGenericExpand: true,
+			Metadata: map[string]string{
+				"APIName":           api.config.Name,
+				"ResourceID":        currentItem.ID,
+				"ResourceExpandURL": currentItem.ExpandURL,
+			},
+		})
+	}
+	return ExpanderResult{
+		Response:          ExpanderResponse{Response: ""}, // Swagger expander will supply the response
+		SourceDescription: "CustomSwaggerExpander request",
+		Nodes:             newItems,
+		IsPrimaryResponse: false,
+	}
+}
+
+func (e *CustomSwaggerExpander) expandAPIRoot(ctx context.Context, currentItem *TreeNode) ExpanderResult {
+	api, err := e.getAPIByName(currentItem.Metadata["APIName"])
+	if err != nil {
+		return ExpanderResult{
+			Err:               err,
+			SourceDescription: "CustomSwaggerExpander request",
+			IsPrimaryResponse: true,
+		}
+	}
+	if api.loadErr != nil {
+		return ExpanderResult{
+			Err:               fmt.Errorf("Error loading swagger for %q: %s", api.config.Name, api.loadErr),
+			SourceDescription: "CustomSwaggerExpander request",
+			IsPrimaryResponse: true,
+		}
+	}
+
+	// Check for an existing APISet for the node
+	var apiSet SwaggerAPISet
+	if apiSetPtr := GetSwaggerResourceExpander().GetAPISet(currentItem.ID); apiSetPtr != nil {
+		apiSet = *apiSetPtr
+	} else {
+		newAPISet, err := e.createAPISet(ctx, currentItem, *api)
+		if err != nil {
+			return ExpanderResult{
+				Err:               err,
+				SourceDescription: "CustomSwaggerExpander request",
+				IsPrimaryResponse: true,
+			}
+		}
+		GetSwaggerResourceExpander().AddAPISet(newAPISet)
+		apiSet = newAPISet
+	}
+
+	newItems := []*TreeNode{}
+	for _, child := range apiSet.GetResourceTypes() {
+		resourceType := child
+		// Only paths without template values can be listed at the root (others are reached via their parent paths)
+		url, err := resourceType.Endpoint.BuildURL(map[string]string{})
+		if err != nil {
+			continue
+		}
+		newItems = append(newItems, &TreeNode{
+			Parentid:            currentItem.ID,
+			ID:                  currentItem.ID + "/" + resourceType.Display,
+			Namespace:           "swagger",
+			Name:                resourceType.Display,
+			Display:             resourceType.Display,
+			ExpandURL:           url,
+			ItemType:            SubResourceType,
+			SwaggerResourceType: &resourceType,
+			Metadata: map[string]string{
+				"SwaggerAPISetID": currentItem.ID,
+			},
+		})
+	}
+
+	return ExpanderResult{
+		Response:          ExpanderResponse{Response: fmt.Sprintf("%s\nSpecs: %s\nBase URL: %s", api.config.Name, api.config.SpecsDirectory, apiSet.(SwaggerAPISetCustom).baseURL)},
+		SourceDescription: "CustomSwaggerExpander request",
+		Nodes:             newItems,
+		IsPrimaryResponse: true,
+	}
+}
+
+func (e *CustomSwaggerExpander) createAPISet(ctx context.Context, currentItem *TreeNode, api customSwaggerAPI) (SwaggerAPISetCustom, error) {
+	baseURL, err := e.getBaseURL(ctx, currentItem, api.config.BaseURL)
+	if err != nil {
+		return SwaggerAPISetCustom{}, err
+	}
+	getHeaders := newCustomSwaggerHeadersFunc(api.config.Auth, armclient.GetSubscriptionIDFromResourceID(currentItem.Metadata["ResourceID"]))
+	// Get the headers up front so that config errors are shown when the API node is expanded
+	if _, err := getHeaders(false); err != nil {
+		return SwaggerAPISetCustom{}, err
+	}
+	return NewSwaggerAPISetCustom(api.resourceTypes, currentItem.ID, baseURL, getHeaders), nil
+}
+
+// getBaseURL returns the fixed base URL or looks up the configured property on the ARM resource
+func (e *CustomSwaggerExpander) getBaseURL(ctx context.Context, currentItem *TreeNode, baseURLConfig config.SwaggerAPIBaseURLConfig) (string, error) {
+	if baseURLConfig.Fixed != "" {
+		return normalizeCustomSwaggerBaseURL(baseURLConfig.Fixed), nil
+	}
+	if baseURLConfig.Property == "" {
+		return "", fmt.Errorf("Swagger API %q must set baseUrl.fixed or baseUrl.property", currentItem.Name)
+	}
+
+	data, err := e.client.DoRequest(ctx, "GET", currentItem.Metadata["ResourceExpandURL"])
+	if err != nil {
+		return "", fmt.Errorf("Failed to get resource: %s", err)
+	}
+	value, err := getJSONPropertyString(data, baseURLConfig.Property)
+	if err != nil {
+		return "", err
+	}
+	return normalizeCustomSwaggerBaseURL(value), nil
+}
+
+// getJSONPropertyString returns the string value at a dotted property path (e.g. properties.defaultHostName)
+func getJSONPropertyString(data string, propertyPath string) (string, error) {
+	var value interface{}
+	if err := json.Unmarshal([]byte(data), &value); err != nil {
+		return "", fmt.Errorf("Error parsing resource: %s", err)
+	}
+	for _, segment := range strings.Split(propertyPath, ".") {
+		object, ok := value.(map[string]interface{})
+		if !ok {
+			return "", fmt.Errorf("Property %q not found", propertyPath)
+		}
+		value = object[segment]
+	}
+	result, ok := value.(string)
+	if !ok || result == "" {
+		return "", fmt.Errorf("Property %q not found or not a string", propertyPath)
+	}
+	return result, nil
+}
+
+// normalizeCustomSwaggerBaseURL adds https:// to host names (e.g. from properties.defaultHostName) and removes any trailing slash
+func normalizeCustomSwaggerBaseURL(baseURL string) string {
+	if !strings.Contains(baseURL, "://") {
+		baseURL = "https://" + baseURL
+	}
+	return strings.TrimSuffix(baseURL, "/")
+}
+
+// newCustomSwaggerHeadersFunc returns a customSwaggerHeadersFunc for the configured auth scheme. The headers are
+// cached between requests and re-fetched when refresh is set, e.g. when an aad token has expired
+func newCustomSwaggerHeadersFunc(auth config.SwaggerAPIAuthConfig, subscriptionID string) customSwaggerHeadersFunc {
+	var lock sync.Mutex
+	var headers map[string]string
+	return func(refresh bool) (map[string]string, error) {
+		lock.Lock()
+		defer lock.Unlock()
+		if headers == nil || refresh {
+			newHeaders, err := getCustomSwaggerAuthHeaders(auth, subscriptionID)
+			if err != nil {
+				return nil, err
+			}
+			headers = newHeaders
+		}
+		return headers, nil
+	}
+}
+
+// getCustomSwaggerAuthHeaders returns the headers to add to requests for the configured auth scheme
+func getCustomSwaggerAuthHeaders(auth config.SwaggerAPIAuthConfig, subscriptionID string) (map[string]string, error) {
+	switch strings.ToLower(auth.Scheme) {
+	case "", "none":
+		return map[string]string{}, nil
+	case "aad":
+		if auth.Resource == "" {
+			return nil, fmt.Errorf("auth.resource must be set for the aad auth scheme")
+		}
+		token, err := armclient.AcquireTokenForResourceFromAzCLI(subscriptionID, auth.Resource)
+		if err != nil {
+			return nil, fmt.Errorf("Failed to get token for %q: %s", auth.Resource, err)
+		}
+		return map[string]string{"Authorization": "Bearer " + token.AccessToken}, nil
+	case "header":
+		if auth.HeaderName == "" || auth.HeaderEnv == "" {
+			return nil, fmt.Errorf("auth.headerName and auth.headerEnv must be set for the header auth scheme")
+		}
+		value := os.Getenv(auth.HeaderEnv)
+		if value == "" {
+			return nil, fmt.Errorf("Environment variable %q is not set", auth.HeaderEnv)
+		}
+		return map[string]string{auth.HeaderName: value}, nil
+	}
+	return nil, fmt.Errorf("Unhandled auth scheme: %q", auth.Scheme)
+}
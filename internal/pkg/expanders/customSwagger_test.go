@@ -0,0 +1,193 @@
+package expanders
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+
+	"github.com/lawrencegripper/azbrowse/internal/pkg/config"
+	"github.com/lawrencegripper/azbrowse/pkg/endpoints"
+	"github.com/lawrencegripper/azbrowse/pkg/swagger"
+	"github.com/nbio/st"
+)
+
+func Test_CustomSwagger_ListItems(t *testing.T) {
+	items, err := getCustomSwaggerListItems(`[{"orderId": 12345678901}, {"orderId": "abc"}, "ignored"]`)
+	st.Expect(t, err, nil)
+	st.Expect(t, len(items), 2)
+	st.Expect(t, getCustomSwaggerItemName(items[0], "orderId"), "12345678901")
+	st.Expect(t, getCustomSwaggerItemName(items[1], "orderId"), "abc")
+
+	items, err = getCustomSwaggerListItems(`{"value": [{"name": "one"}], "nextLink": null}`)
+	st.Expect(t, err, nil)
+	st.Expect(t, len(items), 1)
+	st.Expect(t, getCustomSwaggerItemName(items[0], "customerId"), "one")
+
+	items, err = getCustomSwaggerListItems(`{"count": 1}`)
+	st.Expect(t, err, nil)
+	st.Expect(t, len(items), 0)
+}
+
+func Test_CustomSwagger_BaseURL(t *testing.T) {
+	value, err := getJSONPropertyString(`{"properties": {"defaultHostName": "myapp.azurewebsites.net"}}`, "properties.defaultHostName")
+	st.Expect(t, err, nil)
+	st.Expect(t, normalizeCustomSwaggerBaseURL(value), "https://myapp.azurewebsites.net")
+	st.Expect(t, normalizeCustomSwaggerBaseURL("http://localhost:8080/"), "http://localhost:8080")
+
+	_, err = getJSONPropertyString(`{"properties": {}}`, "properties.defaultHostName")
+	st.Reject(t, err, nil)
+	_, err = getJSONPropertyString(`{"properties": "text"}`, "properties.defaultHostName")
+	st.Reject(t, err, nil)
+}
+
+func Test_CustomSwagger_AuthHeaders(t *testing.T) {
+	headers, err := getCustomSwaggerAuthHeaders(config.SwaggerAPIAuthConfig{}, "sub")
+	st.Expect(t, err, nil)
+	st.Expect(t, len(headers), 0)
+
+	os.Setenv("AZBROWSE_TEST_API_KEY", "secret") //nolint:errcheck
+	defer os.Unsetenv("AZBROWSE_TEST_API_KEY")   //nolint:errcheck
+	headers, err = getCustomSwaggerAuthHeaders(config.SwaggerAPIAuthConfig{Scheme: "header", HeaderName: "x-api-key", HeaderEnv: "AZBROWSE_TEST_API_KEY"}, "sub")
+	st.Expect(t, err, nil)
+	st.Expect(t, headers["x-api-key"], "secret")
+
+	_, err = getCustomSwaggerAuthHeaders(config.SwaggerAPIAuthConfig{Scheme: "aad"}, "sub")
+	st.Reject(t, err, nil)
+	_, err = getCustomSwaggerAuthHeaders(config.SwaggerAPIAuthConfig{Scheme: "basic"}, "sub")
+	st.Reject(t, err, nil)
+}
+
+func Test_CustomSwagger_DuplicateNames(t *testing.T) {
+	apiConfigs := []config.SwaggerAPIConfig{{Name: "orders"}, {Name: "customers"}, {Name: "orders"}, {Name: "orders"}}
+	st.Expect(t, getDuplicateCustomSwaggerAPINames(apiConfigs), []string{"orders"})
+
+	expander := &CustomSwaggerExpander{}
+	for _, apiConfig := range apiConfigs {
+		expander.apis = append(expander.apis, customSwaggerAPI{config: apiConfig})
+	}
+	_, err := expander.getAPIByName("orders")
+	st.Reject(t, err, nil)
+	api, err := expander.getAPIByName("customers")
+	st.Expect(t, err, nil)
+	st.Expect(t, api.config.Name, "customers")
+	_, err = expander.getAPIByName("missing")
+	st.Reject(t, err, nil)
+}
+
+func Test_CustomSwagger_RefreshesHeadersOnUnauthorized(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer fresh" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		_, _ = w.Write([]byte(`[]`))
+	}))
+	defer server.Close()
+
+	// The first token has expired by the time the request is made
+	refreshCount := 0
+	getHeaders := func(refresh bool) (map[string]string, error) {
+		if refresh {
+			refreshCount++
+			return map[string]string{"Authorization": "Bearer fresh"}, nil
+		}
+		return map[string]string{"Authorization": "Bearer expired"}, nil
+	}
+	apiSet := NewSwaggerAPISetCustom([]swagger.ResourceType{}, "/node", server.URL, getHeaders)
+	result, err := apiSet.DoRequest("GET", "/orders")
+	st.Expect(t, err, nil)
+	st.Expect(t, result, `[]`)
+	st.Expect(t, refreshCount, 1)
+}
+
+func staticCustomSwaggerHeaders(headers map[string]string) customSwaggerHeadersFunc {
+	return func(refresh bool) (map[string]string, error) {
+		return headers, nil
+	}
+}
+
+func Test_CustomSwagger_ExpandResource(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		st.Expect(t, r.URL.Path, "/orders")
+		st.Expect(t, r.Header.Get("x-api-key"), "secret")
+		_, _ = w.Write([]byte(`{"value": [{"orderId": "o1"}, {"orderId": "o2"}]}`))
+	}))
+	defer server.Close()
+
+	resourceType := swagger.ResourceType{
+		Display:  "orders",
+		Endpoint: endpoints.MustGetEndpointInfoFromURL("/orders", ""),
+		SubResources: []swagger.ResourceType{
+			{
+				Display:        "{orderId}",
+				Endpoint:       endpoints.MustGetEndpointInfoFromURL("/orders/{orderId}", ""),
+				DeleteEndpoint: endpoints.MustGetEndpointInfoFromURL("/orders/{orderId}", ""),
+			},
+		},
+	}
+	apiSet := NewSwaggerAPISetCustom([]swagger.ResourceType{resourceType}, "/node", server.URL, staticCustomSwaggerHeaders(map[string]string{"x-api-key": "secret"}))
+
+	item := &TreeNode{ExpandURL: "/orders", SwaggerResourceType: &resourceType}
+	result, err := apiSet.ExpandResource(context.Background(), item, resourceType)
+	st.Expect(t, err, nil)
+	st.Expect(t, len(result.SubResources), 2)
+	st.Expect(t, result.SubResources[0].ID, "/node/orders/o1")
+	st.Expect(t, result.SubResources[0].Name, "o1")
+	st.Expect(t, result.SubResources[1].ExpandURL, "/orders/o2")
+	st.Expect(t, result.SubResources[1].DeleteURL, "/orders/o2")
+}
+
+func Test_CustomSwagger_DoPostAction(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		st.Expect(t, r.Method, "POST")
+		st.Expect(t, r.URL.Path, "/orders/o1/cancel")
+		st.Expect(t, r.Header.Get("Content-Type"), "application/json")
+		_, _ = w.Write([]byte(`{"status": "cancelled"}`))
+	}))
+	defer server.Close()
+
+	apiSet := NewSwaggerAPISetCustom([]swagger.ResourceType{}, "/node", server.URL, staticCustomSwaggerHeaders(map[string]string{}))
+	result, err := apiSet.DoPostAction(context.Background(), &TreeNode{}, "/orders/o1/cancel", `{"reason": ""}`)
+	st.Expect(t, err, nil)
+	st.Expect(t, result, `{"status": "cancelled"}`)
+}
+
+func Test_CustomSwagger_ListActions_CreateNew(t *testing.T) {
+	resourceType := swagger.ResourceType{
+		Display:  "orders",
+		Endpoint: endpoints.MustGetEndpointInfoFromURL("/orders", ""),
+		SubResources: []swagger.ResourceType{
+			{
+				Display:     "{orderId}",
+				Endpoint:    endpoints.MustGetEndpointInfoFromURL("/orders/{orderId}", ""),
+				PutEndpoint: endpoints.MustGetEndpointInfoFromURL("/orders/{orderId}", ""),
+			},
+		},
+		PostActions: []swagger.PostAction{
+			{Name: "archive", Endpoint: endpoints.MustGetEndpointInfoFromURL("/orders/archive", "")},
+		},
+	}
+	apiSet := NewSwaggerAPISetCustom([]swagger.ResourceType{resourceType}, "/node", "http://localhost", staticCustomSwaggerHeaders(map[string]string{}))
+	expander := NewSwaggerResourcesExpander(nil, nil)
+	expander.AddAPISet(apiSet)
+
+	item := &TreeNode{
+		ID:                  "/node/orders",
+		ExpandURL:           "/orders",
+		SwaggerResourceType: &resourceType,
+		Metadata:            map[string]string{"SwaggerAPISetID": "/node"},
+	}
+	hasActions, err := expander.HasActions(context.Background(), item)
+	st.Expect(t, err, nil)
+	st.Expect(t, hasActions, true)
+
+	result := expander.ListActions(context.Background(), item)
+	st.Expect(t, result.Err, nil)
+	st.Expect(t, len(result.Nodes), 2)
+	st.Expect(t, result.Nodes[0].Display, "Create new...")
+	st.Expect(t, result.Nodes[0].Metadata["ActionID"], swaggerCreateActionID)
+	st.Expect(t, result.Nodes[0].Metadata["ResourceID"], "/node/orders")
+	st.Expect(t, result.Nodes[1].Metadata["ActionID"], "archive")
+}
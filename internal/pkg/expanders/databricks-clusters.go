@@ -0,0 +1,321 @@
+package expanders
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/lawrencegripper/azbrowse/internal/pkg/errorhandling"
+	"github.com/lawrencegripper/azbrowse/internal/pkg/eventing"
+	"github.com/lawrencegripper/azbrowse/internal/pkg/interfaces"
+	"github.com/lawrencegripper/azbrowse/internal/pkg/style"
+)
+
+// See https://docs.databricks.com/dev-tools/api/latest/clusters.html
+
+const (
+	databricksClusterEventsPageSize  = 50
+	databricksClusterPollInterval    = time.Second * 10
+	databricksClusterTrackingTimeout = time.Minute * 30
+
+	databricksActionStartCluster     = "start"
+	databricksActionRestartCluster   = "restart"
+	databricksActionTerminateCluster = "delete" // clusters/delete terminates the cluster, clusters/permanent-delete removes it
+)
+
+// DatabricksCluster holds the cluster state returned by the clusters/get API
+type DatabricksCluster struct {
+	ClusterID    string `json:"cluster_id"`
+	ClusterName  string `json:"cluster_name"`
+	State        string `json:"state"`
+	StateMessage string `json:"state_message"`
+}
+
+// DatabricksClusterEvent is an entry in the cluster event log
+type DatabricksClusterEvent struct {
+	Timestamp int64                  `json:"timestamp"`
+	Type      string                 `json:"type"`
+	Details   map[string]interface{} `json:"details"`
+}
+
+// DatabricksClusterEventsResponse is returned by the clusters/events API
+type DatabricksClusterEventsResponse struct {
+	Events     []json.RawMessage `json:"events"`
+	NextPage   interface{}       `json:"next_page"`
+	TotalCount int               `json:"total_count"`
+}
+
+func drawDatabricksClusterState(state string) string {
+	switch state {
+	case "RUNNING":
+		return "▶"
+	case "PENDING", "RESTARTING", "RESIZING":
+		return "⛅"
+	case "TERMINATING":
+		return "⌛"
+	case "TERMINATED":
+		return "⏹"
+	case "ERROR":
+		return DrawStatus("Failed")
+	}
+	return ""
+}
+
+// getDatabricksClusterActionResult determines whether a cluster action has completed based on the cluster state.
+// hasTransitioned indicates that the cluster has been seen in a starting state since the action was submitted
+// (as a restarting cluster is RUNNING before it transitions)
+func getDatabricksClusterActionResult(action string, state string, hasTransitioned bool) (completed bool, failed bool) {
+	if state == "ERROR" {
+		return true, true
+	}
+	switch action {
+	case databricksActionTerminateCluster:
+		return state == "TERMINATED", false
+	case databricksActionStartCluster, databricksActionRestartCluster:
+		switch state {
+		case "RUNNING":
+			return action == databricksActionStartCluster || hasTransitioned, false
+		case "TERMINATED":
+			return hasTransitioned, hasTransitioned
+		}
+	}
+	return false, false
+}
+
+// summarizeDatabricksEventDetails returns the scalar details for an event as name=value pairs
+func summarizeDatabricksEventDetails(details map[string]interface{}) string {
+	keys := make([]string, 0, len(details))
+	for key := range details {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	values := []string{}
+	for _, key := range keys {
+		switch value := details[key].(type) {
+		case string, float64, bool:
+			values = append(values, fmt.Sprintf("%s=%v", key, value))
+		case map[string]interface{}:
+			// e.g. "reason": {"code": "INACTIVITY", ...}
+			if code, ok := value["code"]; ok {
+				values = append(values, fmt.Sprintf("%s=%v", key, code))
+			}
+		}
+	}
+	return strings.Join(values, " ")
+}
+
+// renderDatabricksClusterEvents renders the events as a timeline (most recent first)
+func renderDatabricksClusterEvents(events []DatabricksClusterEvent) string {
+	if len(events) == 0 {
+		return "No events found"
+	}
+	var sb strings.Builder
+	sb.WriteString(style.Title(fmt.Sprintf("%-20s %-28s %s", "TIME", "EVENT", "DETAILS")) + "\n")
+	for _, event := range events {
+		sb.WriteString(fmt.Sprintf("%-20s %-28s %s\n",
+			formatDatabricksTime(event.Timestamp),
+			event.Type,
+			style.Subtle(summarizeDatabricksEventDetails(event.Details))))
+	}
+	return sb.String()
+}
+
+func newDatabricksClusterEventsNode(parent *TreeNode, offset string) *TreeNode {
+	node := &TreeNode{
+		Parentid:              parent.ID,
+		ID:                    parent.ID + "/events",
+		Namespace:             databricksNamespace,
+		Name:                  "Event timeline",
+		Display:               "Event timeline",
+		ItemType:              databricksClusterEventsType,
+		ExpandURL:             ExpandURLNotSupported,
+		SuppressSwaggerExpand: true,
+		SuppressGenericExpand: true,
+		Metadata: map[string]string{
+			"SwaggerAPISetID": parent.Metadata["SwaggerAPISetID"],
+			"cluster_id":      parent.Metadata["cluster_id"],
+			"offset":          offset,
+		},
+	}
+	if offset != "0" {
+		node.ID = parent.ID + "/...more" + offset
+		node.Name = "More..."
+		node.Display = "More..."
+		node.ExpandInPlace = true
+	}
+	return node
+}
+
+func (e *AzureDatabricksExpander) expandClusterEvents(ctx context.Context, currentItem *TreeNode) ExpanderResult {
+	apiSet, err := e.getAPISetForNode(currentItem)
+	if err != nil {
+		return ExpanderResult{
+			Err:               err,
+			SourceDescription: "AzureDatabricksExpander request",
+			IsPrimaryResponse: true,
+		}
+	}
+
+	offset, _ := strconv.Atoi(currentItem.Metadata["offset"])
+	body := fmt.Sprintf(`{"cluster_id": %q, "order": "DESC", "limit": %d, "offset": %d}`, currentItem.Metadata["cluster_id"], databricksClusterEventsPageSize, offset)
+	data, err := apiSet.DoRequestWithBody("POST", "https://"+apiSet.workspaceURL+"/api/2.0/clusters/events", body)
+	if err != nil {
+		return ExpanderResult{
+			Err:               fmt.Errorf("Failed to get cluster events: %s", err),
+			SourceDescription: "AzureDatabricksExpander request",
+			IsPrimaryResponse: true,
+		}
+	}
+	var response DatabricksClusterEventsResponse
+	if err := json.Unmarshal([]byte(data), &response); err != nil {
+		return ExpanderResult{
+			Err:               fmt.Errorf("Error unmarshalling cluster events: %s", err),
+			SourceDescription: "AzureDatabricksExpander request",
+			IsPrimaryResponse: true,
+		}
+	}
+
+	// "More..." nodes are replaced by the expanded nodes so parent them to the events node
+	parent := currentItem
+	if currentItem.ExpandInPlace && currentItem.Parent != nil {
+		parent = currentItem.Parent
+	}
+	events := []DatabricksClusterEvent{}
+	nodes := []*TreeNode{}
+	for index, rawEvent := range response.Events {
+		var event DatabricksClusterEvent
+		if err := json.Unmarshal(rawEvent, &event); err != nil {
+			return ExpanderResult{
+				Err:               fmt.Errorf("Error unmarshalling cluster event: %s", err),
+				SourceDescription: "AzureDatabricksExpander request",
+				IsPrimaryResponse: true,
+			}
+		}
+		events = append(events, event)
+		nodes = append(nodes, &TreeNode{
+			Parentid: parent.ID,
+			ID:       parent.ID + "/" + strconv.Itoa(offset+index),
+			Name:     event.Type,
+			Display: event.Type + "\n   " +
+				style.Subtle("At: "+formatDatabricksTime(event.Timestamp)),
+			ItemType:              databricksClusterEventType,
+			ExpandURL:             ExpandURLNotSupported,
+			SuppressSwaggerExpand: true,
+			SuppressGenericExpand: true,
+			Metadata: map[string]string{
+				"jsonItem": string(rawEvent),
+			},
+		})
+	}
+	if response.NextPage != nil {
+		nodes = append(nodes, newDatabricksClusterEventsNode(parent, strconv.Itoa(offset+len(events))))
+	}
+
+	return ExpanderResult{
+		Response:          ExpanderResponse{Response: renderDatabricksClusterEvents(events), ResponseType: interfaces.ResponsePlainText},
+		SourceDescription: "AzureDatabricksExpander request",
+		Nodes:             nodes,
+		IsPrimaryResponse: true,
+	}
+}
+
+func (e *AzureDatabricksExpander) getCluster(apiSet *SwaggerAPISetDatabricks, clusterID string) (DatabricksCluster, error) {
+	data, err := apiSet.DoRequest("GET", "https://"+apiSet.workspaceURL+"/api/2.0/clusters/get?cluster_id="+clusterID)
+	if err != nil {
+		return DatabricksCluster{}, fmt.Errorf("Failed to get cluster: %s", err)
+	}
+	var cluster DatabricksCluster
+	if err := json.Unmarshal([]byte(data), &cluster); err != nil {
+		return DatabricksCluster{}, fmt.Errorf("Error unmarshalling cluster: %s", err)
+	}
+	return cluster, nil
+}
+
+// clusterAction submits the start/restart/terminate request and tracks the cluster state in the background,
+// updating the StatusIndicator of the action and the cluster until the action completes
+func (e *AzureDatabricksExpander) clusterAction(ctx context.Context, actionItem *TreeNode, action string) ExpanderResult {
+	clusterItem := actionItem.Parent
+	apiSet, err := e.getAPISetForNode(clusterItem)
+	if err != nil {
+		return ExpanderResult{
+			Err:               err,
+			SourceDescription: "AzureDatabricksExpander request",
+			IsPrimaryResponse: true,
+		}
+	}
+
+	clusterID := clusterItem.Metadata["cluster_id"]
+	body := fmt.Sprintf(`{"cluster_id": %q}`, clusterID)
+	_, err = apiSet.DoRequestWithBody("POST", "https://"+apiSet.workspaceURL+"/api/2.0/clusters/"+action, body)
+	if err != nil {
+		return ExpanderResult{
+			Err:               fmt.Errorf("%s failed: %s", actionItem.Name, err),
+			SourceDescription: "AzureDatabricksExpander request",
+			IsPrimaryResponse: true,
+		}
+	}
+
+	go func() {
+		// recover from panic, if one occurrs, and leave terminal usable
+		defer errorhandling.RecoveryWithCleanup()
+
+		message := actionItem.Name + " " + clusterItem.Name
+		event, _ := eventing.SendStatusEvent(&eventing.StatusEvent{
+			InProgress: true,
+			IsToast:    true,
+			Message:    message,
+			Timeout:    databricksClusterTrackingTimeout,
+		})
+
+		deadline := time.Now().Add(databricksClusterTrackingTimeout)
+		hasTransitioned := false
+		completed, failed := false, false
+		var cluster DatabricksCluster
+		for !completed && time.Now().Before(deadline) {
+			time.Sleep(databricksClusterPollInterval)
+			cluster, err = e.getCluster(apiSet, clusterID)
+			if err != nil {
+				break
+			}
+			if cluster.State == "PENDING" || cluster.State == "RESTARTING" {
+				hasTransitioned = true
+			}
+			completed, failed = getDatabricksClusterActionResult(action, cluster.State, hasTransitioned)
+
+			statusIndicator := drawDatabricksClusterState(cluster.State)
+			actionItem.StatusIndicator = statusIndicator
+			clusterItem.StatusIndicator = statusIndicator
+			event.Message = message + ": " + cluster.State
+			event.Update()
+			e.refreshGui()
+		}
+
+		event.InProgress = false
+		switch {
+		case err != nil:
+			event.Failure = true
+			event.Message = message + " failed: " + err.Error()
+		case !completed:
+			event.Failure = true
+			event.Message = message + " still " + cluster.State + " - stopped tracking"
+		case failed:
+			event.Failure = true
+			event.Message = message + " failed: " + cluster.State + " " + cluster.StateMessage
+		default:
+			event.Message = message + " COMPLETED"
+		}
+		event.SetTimeout(time.Second * 15)
+		event.Update()
+	}()
+
+	return ExpanderResult{
+		Response:          ExpanderResponse{Response: actionItem.Name + " submitted for " + clusterItem.Name + ". Progress is shown in the status bar and next to the action.", ResponseType: interfaces.ResponsePlainText},
+		SourceDescription: "AzureDatabricksExpander request",
+		IsPrimaryResponse: true,
+	}
+}
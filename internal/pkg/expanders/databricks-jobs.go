@@ -0,0 +1,670 @@
+package expanders
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/awesome-gocui/gocui"
+	"github.com/lawrencegripper/azbrowse/internal/pkg/editor"
+	"github.com/lawrencegripper/azbrowse/internal/pkg/errorhandling"
+	"github.com/lawrencegripper/azbrowse/internal/pkg/eventing"
+	"github.com/lawrencegripper/azbrowse/internal/pkg/interfaces"
+	"github.com/lawrencegripper/azbrowse/internal/pkg/style"
+)
+
+// The runs/get, runs/list and run-now APIs used here are the 2.1 versions as they include
+// the task runs for multi-task jobs. See https://docs.databricks.com/dev-tools/api/latest/jobs.html
+
+const (
+	databricksRunHistoryPageSize = 25
+	databricksRunPollInterval    = time.Second * 10
+	databricksRunTrackingTimeout = time.Hour * 6
+)
+
+// DatabricksRunState is the state of a job run (or task run)
+type DatabricksRunState struct {
+	LifeCycleState string `json:"life_cycle_state"`
+	ResultState    string `json:"result_state"`
+	StateMessage   string `json:"state_message"`
+}
+
+// DatabricksRun is a job run, or a task run within a multi-task job run. Times and durations are in milliseconds
+type DatabricksRun struct {
+	RunID             int64              `json:"run_id"`
+	JobID             int64              `json:"job_id"`
+	RunName           string             `json:"run_name"`
+	TaskKey           string             `json:"task_key"`
+	State             DatabricksRunState `json:"state"`
+	Trigger           string             `json:"trigger"`
+	StartTime         int64              `json:"start_time"`
+	EndTime           int64              `json:"end_time"`
+	SetupDuration     int64              `json:"setup_duration"`
+	ExecutionDuration int64              `json:"execution_duration"`
+	CleanupDuration   int64              `json:"cleanup_duration"`
+	RunDuration       int64              `json:"run_duration"`
+	RunPageURL        string             `json:"run_page_url"`
+	Tasks             []DatabricksRun    `json:"tasks"`
+}
+
+// DatabricksRunsListResponse is returned by the runs/list API
+type DatabricksRunsListResponse struct {
+	Runs    []DatabricksRun `json:"runs"`
+	HasMore bool            `json:"has_more"`
+}
+
+// DatabricksRunOutput is returned by the runs/get-output API
+type DatabricksRunOutput struct {
+	NotebookOutput struct {
+		Result    string `json:"result"`
+		Truncated bool   `json:"truncated"`
+	} `json:"notebook_output"`
+	Logs          string        `json:"logs"`
+	LogsTruncated bool          `json:"logs_truncated"`
+	Error         string        `json:"error"`
+	ErrorTrace    string        `json:"error_trace"`
+	Metadata      DatabricksRun `json:"metadata"`
+}
+
+// DatabricksJobSettings holds the parts of the job settings used to build the run-now parameters
+type DatabricksJobSettings struct {
+	Name       string `json:"name"`
+	Parameters []struct {
+		Name    string `json:"name"`
+		Default string `json:"default"`
+	} `json:"parameters"`
+	DatabricksTaskSettings
+	Tasks []DatabricksTaskSettings `json:"tasks"`
+}
+
+// DatabricksTaskSettings holds the task parameters for a job task (or a single task job)
+type DatabricksTaskSettings struct {
+	NotebookTask *struct {
+		BaseParameters map[string]string `json:"base_parameters"`
+	} `json:"notebook_task"`
+	SparkJarTask *struct {
+		Parameters []string `json:"parameters"`
+	} `json:"spark_jar_task"`
+	SparkPythonTask *struct {
+		Parameters []string `json:"parameters"`
+	} `json:"spark_python_task"`
+	SparkSubmitTask *struct {
+		Parameters []string `json:"parameters"`
+	} `json:"spark_submit_task"`
+}
+
+// DatabricksJob is returned by the jobs/get API
+type DatabricksJob struct {
+	JobID    int64                 `json:"job_id"`
+	Settings DatabricksJobSettings `json:"settings"`
+}
+
+// isTerminal returns true once the run has finished (successfully or otherwise)
+func (r DatabricksRun) isTerminal() bool {
+	switch r.State.LifeCycleState {
+	case "TERMINATED", "SKIPPED", "INTERNAL_ERROR":
+		return true
+	}
+	return false
+}
+
+// isSuccess returns true if the run completed successfully
+func (r DatabricksRun) isSuccess() bool {
+	return r.State.ResultState == "SUCCESS"
+}
+
+// getStateDisplay returns the result state for completed runs, otherwise the life cycle state
+func (r DatabricksRun) getStateDisplay() string {
+	if r.State.ResultState != "" {
+		return r.State.ResultState
+	}
+	return r.State.LifeCycleState
+}
+
+// getDuration returns the duration of the run, using now as the end time for runs still in progress
+func (r DatabricksRun) getDuration(now time.Time) time.Duration {
+	duration := r.RunDuration
+	if duration == 0 {
+		duration = r.SetupDuration + r.ExecutionDuration + r.CleanupDuration
+	}
+	if duration == 0 && r.StartTime > 0 {
+		end := now
+		if r.EndTime > 0 {
+			end = databricksTime(r.EndTime)
+		}
+		return end.Sub(databricksTime(r.StartTime))
+	}
+	return time.Duration(duration) * time.Millisecond
+}
+
+// databricksTime converts milliseconds since the epoch to a time
+func databricksTime(value int64) time.Time {
+	return time.Unix(0, value*int64(time.Millisecond))
+}
+
+func formatDatabricksTime(value int64) string {
+	if value == 0 {
+		return "-"
+	}
+	return databricksTime(value).Local().Format("2006-01-02 15:04:05")
+}
+
+func formatDatabricksDuration(duration time.Duration) string {
+	if duration <= 0 {
+		return "-"
+	}
+	return duration.Round(time.Second).String()
+}
+
+func drawDatabricksRunState(run DatabricksRun) string {
+	switch run.getStateDisplay() {
+	case "SUCCESS":
+		return DrawStatus("Succeeded")
+	case "FAILED", "TIMEDOUT", "INTERNAL_ERROR":
+		return DrawStatus("Failed")
+	case "CANCELED", "SKIPPED", "UPSTREAM_CANCELED", "UPSTREAM_FAILED", "EXCLUDED":
+		return "⛔"
+	case "RUNNING", "TERMINATING":
+		return DrawStatus("Running")
+	case "PENDING", "QUEUED", "BLOCKED", "WAITING_FOR_RETRY":
+		return DrawStatus("Provisioning")
+	}
+	return ""
+}
+
+// renderDatabricksRunHistory renders a table of runs with a line per task for multi-task runs
+func renderDatabricksRunHistory(runs []DatabricksRun, now time.Time) string {
+	if len(runs) == 0 {
+		return "No runs found"
+	}
+	var sb strings.Builder
+	sb.WriteString(style.Title(fmt.Sprintf("%-16s %-20s %-16s %-10s %s", "RUN ID", "STARTED", "STATE", "DURATION", "TRIGGER")) + "\n")
+	for _, run := range runs {
+		sb.WriteString(fmt.Sprintf("%-16d %-20s %-16s %-10s %s\n",
+			run.RunID,
+			formatDatabricksTime(run.StartTime),
+			run.getStateDisplay(),
+			formatDatabricksDuration(run.getDuration(now)),
+			run.Trigger))
+		for _, task := range run.Tasks {
+			sb.WriteString(style.Subtle(fmt.Sprintf("  └ %-31s %-16s %s", task.TaskKey, task.getStateDisplay(), formatDatabricksDuration(task.getDuration(now)))) + "\n")
+		}
+	}
+	return sb.String()
+}
+
+// renderDatabricksRun renders the state of a run and its tasks
+func renderDatabricksRun(run DatabricksRun, now time.Time) string {
+	var sb strings.Builder
+	title := fmt.Sprintf("Run %d", run.RunID)
+	if run.RunName != "" {
+		title += ": " + run.RunName
+	}
+	sb.WriteString(style.Title(title) + "\n\n")
+	state := run.State.LifeCycleState
+	if run.State.ResultState != "" {
+		state += " / " + run.State.ResultState
+	}
+	sb.WriteString(fmt.Sprintf("State:     %s\n", state))
+	if run.State.StateMessage != "" {
+		sb.WriteString(fmt.Sprintf("Message:   %s\n", run.State.StateMessage))
+	}
+	sb.WriteString(fmt.Sprintf("Started:   %s\n", formatDatabricksTime(run.StartTime)))
+	sb.WriteString(fmt.Sprintf("Ended:     %s\n", formatDatabricksTime(run.EndTime)))
+	sb.WriteString(fmt.Sprintf("Duration:  %s\n", formatDatabricksDuration(run.getDuration(now))))
+	if run.Trigger != "" {
+		sb.WriteString(fmt.Sprintf("Trigger:   %s\n", run.Trigger))
+	}
+	if run.RunPageURL != "" {
+		sb.WriteString(fmt.Sprintf("URL:       %s\n", run.RunPageURL))
+	}
+
+	if len(run.Tasks) > 0 {
+		sb.WriteString("\n" + style.Title(fmt.Sprintf("%-32s %-16s %-20s %-10s %s", "TASK", "STATE", "STARTED", "DURATION", "RUN ID")) + "\n")
+		for _, task := range run.Tasks {
+			sb.WriteString(fmt.Sprintf("%-32s %-16s %-20s %-10s %d\n",
+				task.TaskKey,
+				task.getStateDisplay(),
+				formatDatabricksTime(task.StartTime),
+				formatDatabricksDuration(task.getDuration(now)),
+				task.RunID))
+			if task.State.StateMessage != "" {
+				sb.WriteString(style.Subtle("  "+task.State.StateMessage) + "\n")
+			}
+		}
+	}
+	return sb.String()
+}
+
+// renderDatabricksRunOutput renders the notebook result, logs and error details of a (task) run
+func renderDatabricksRunOutput(output DatabricksRunOutput) string {
+	var sb strings.Builder
+	writeSection := func(title string, content string, truncated bool) {
+		if content == "" {
+			return
+		}
+		sb.WriteString(style.Title(title) + "\n")
+		sb.WriteString(content + "\n")
+		if truncated {
+			sb.WriteString(style.Subtle("(truncated)") + "\n")
+		}
+		sb.WriteString("\n")
+	}
+	writeSection("Error", output.Error, false)
+	writeSection("Error trace", output.ErrorTrace, false)
+	writeSection("Notebook result", output.NotebookOutput.Result, output.NotebookOutput.Truncated)
+	writeSection("Logs", output.Logs, output.LogsTruncated)
+	if sb.Len() == 0 {
+		return "No output for run " + strconv.FormatInt(output.Metadata.RunID, 10) + " (" + output.Metadata.getStateDisplay() + ")"
+	}
+	return sb.String()
+}
+
+func newDatabricksRunHistoryNode(parent *TreeNode, offset string) *TreeNode {
+	node := &TreeNode{
+		Parentid:              parent.ID,
+		ID:                    parent.ID + "/runHistory",
+		Namespace:             databricksNamespace,
+		Name:                  "Run history",
+		Display:               "Run history",
+		ItemType:              databricksRunHistoryType,
+		ExpandURL:             ExpandURLNotSupported,
+		SuppressSwaggerExpand: true,
+		SuppressGenericExpand: true,
+		Metadata: map[string]string{
+			"SwaggerAPISetID": parent.Metadata["SwaggerAPISetID"],
+			"job_id":          parent.Metadata["job_id"],
+			"offset":          offset,
+		},
+	}
+	if offset != "0" {
+		node.ID = parent.ID + "/...more" + offset
+		node.Name = "More..."
+		node.Display = "More..."
+		node.ExpandInPlace = true
+	}
+	return node
+}
+
+func newDatabricksRunNode(parent *TreeNode, run DatabricksRun, now time.Time) *TreeNode {
+	name := strconv.FormatInt(run.RunID, 10)
+	display := name
+	if run.TaskKey != "" {
+		name = run.TaskKey
+		display = run.TaskKey + style.Subtle(" ("+strconv.FormatInt(run.RunID, 10)+")")
+	}
+	return &TreeNode{
+		Parentid: parent.ID,
+		ID:       parent.ID + "/" + strconv.FormatInt(run.RunID, 10),
+		Name:     name,
+		Display: display + "\n   " +
+			style.Subtle("Started:  "+formatDatabricksTime(run.StartTime)) + "\n   " +
+			style.Subtle("State:    "+run.getStateDisplay()+"  Duration: "+formatDatabricksDuration(run.getDuration(now))),
+		Namespace:             databricksNamespace,
+		ItemType:              databricksRunType,
+		ExpandURL:             ExpandURLNotSupported,
+		StatusIndicator:       drawDatabricksRunState(run),
+		SuppressSwaggerExpand: true,
+		SuppressGenericExpand: true,
+		Metadata: map[string]string{
+			"SwaggerAPISetID": parent.Metadata["SwaggerAPISetID"],
+			"job_id":          strconv.FormatInt(run.JobID, 10),
+			"run_id":          strconv.FormatInt(run.RunID, 10),
+		},
+	}
+}
+
+func newDatabricksRunOutputNode(parent *TreeNode, runID int64) *TreeNode {
+	return &TreeNode{
+		Parentid:              parent.ID,
+		ID:                    parent.ID + "/output",
+		Namespace:             databricksNamespace,
+		Name:                  "Output",
+		Display:               "Output",
+		ItemType:              databricksRunOutputType,
+		ExpandURL:             ExpandURLNotSupported,
+		SuppressSwaggerExpand: true,
+		SuppressGenericExpand: true,
+		Metadata: map[string]string{
+			"SwaggerAPISetID": parent.Metadata["SwaggerAPISetID"],
+			"run_id":          strconv.FormatInt(runID, 10),
+		},
+	}
+}
+
+func (e *AzureDatabricksExpander) getRun(apiSet *SwaggerAPISetDatabricks, runID string) (DatabricksRun, error) {
+	data, err := apiSet.DoRequest("GET", "https://"+apiSet.workspaceURL+"/api/2.1/jobs/runs/get?run_id="+runID)
+	if err != nil {
+		return DatabricksRun{}, fmt.Errorf("Failed to get run: %s", err)
+	}
+	var run DatabricksRun
+	if err := json.Unmarshal([]byte(data), &run); err != nil {
+		return DatabricksRun{}, fmt.Errorf("Error unmarshalling run: %s", err)
+	}
+	return run, nil
+}
+
+func (e *AzureDatabricksExpander) expandRunHistory(ctx context.Context, currentItem *TreeNode) ExpanderResult {
+	apiSet, err := e.getAPISetForNode(currentItem)
+	if err != nil {
+		return ExpanderResult{
+			Err:               err,
+			SourceDescription: "AzureDatabricksExpander request",
+			IsPrimaryResponse: true,
+		}
+	}
+
+	offset, _ := strconv.Atoi(currentItem.Metadata["offset"])
+	url := fmt.Sprintf("https://%s/api/2.1/jobs/runs/list?expand_tasks=true&limit=%d&offset=%d&job_id=%s",
+		apiSet.workspaceURL, databricksRunHistoryPageSize, offset, currentItem.Metadata["job_id"])
+	data, err := apiSet.DoRequest("GET", url)
+	if err != nil {
+		return ExpanderResult{
+			Err:               fmt.Errorf("Failed to list runs: %s", err),
+			SourceDescription: "AzureDatabricksExpander request",
+			IsPrimaryResponse: true,
+		}
+	}
+	var response DatabricksRunsListResponse
+	if err := json.Unmarshal([]byte(data), &response); err != nil {
+		return ExpanderResult{
+			Err:               fmt.Errorf("Error unmarshalling runs: %s", err),
+			SourceDescription: "AzureDatabricksExpander request",
+			IsPrimaryResponse: true,
+		}
+	}
+
+	now := time.Now()
+	// "More..." nodes are replaced by the expanded nodes so parent them to the history node
+	parent := currentItem
+	if currentItem.ExpandInPlace && currentItem.Parent != nil {
+		parent = currentItem.Parent
+	}
+	nodes := []*TreeNode{}
+	for _, run := range response.Runs {
+		nodes = append(nodes, newDatabricksRunNode(parent, run, now))
+	}
+	if response.HasMore {
+		nodes = append(nodes, newDatabricksRunHistoryNode(parent, strconv.Itoa(offset+len(response.Runs))))
+	}
+
+	return ExpanderResult{
+		Response:          ExpanderResponse{Response: renderDatabricksRunHistory(response.Runs, now), ResponseType: interfaces.ResponsePlainText},
+		SourceDescription: "AzureDatabricksExpander request",
+		Nodes:             nodes,
+		IsPrimaryResponse: true,
+	}
+}
+
+// expandRun shows the run with a node per task run, or an output node for single task runs
+func (e *AzureDatabricksExpander) expandRun(ctx context.Context, currentItem *TreeNode) ExpanderResult {
+	apiSet, err := e.getAPISetForNode(currentItem)
+	if err != nil {
+		return ExpanderResult{
+			Err:               err,
+			SourceDescription: "AzureDatabricksExpander request",
+			IsPrimaryResponse: true,
+		}
+	}
+
+	run, err := e.getRun(apiSet, currentItem.Metadata["run_id"])
+	if err != nil {
+		return ExpanderResult{
+			Err:               err,
+			SourceDescription: "AzureDatabricksExpander request",
+			IsPrimaryResponse: true,
+		}
+	}
+
+	now := time.Now()
+	nodes := []*TreeNode{}
+	if len(run.Tasks) > 0 {
+		for _, task := range run.Tasks {
+			// Output is retrieved per task run for multi-task runs
+			taskNode := newDatabricksRunNode(currentItem, task, now)
+			taskNode.ItemType = databricksRunOutputType
+			nodes = append(nodes, taskNode)
+		}
+	} else {
+		nodes = append(nodes, newDatabricksRunOutputNode(currentItem, run.RunID))
+	}
+
+	return ExpanderResult{
+		Response:          ExpanderResponse{Response: renderDatabricksRun(run, now), ResponseType: interfaces.ResponsePlainText},
+		SourceDescription: "AzureDatabricksExpander request",
+		Nodes:             nodes,
+		IsPrimaryResponse: true,
+	}
+}
+
+func (e *AzureDatabricksExpander) expandRunOutput(ctx context.Context, currentItem *TreeNode) ExpanderResult {
+	apiSet, err := e.getAPISetForNode(currentItem)
+	if err != nil {
+		return ExpanderResult{
+			Err:               err,
+			SourceDescription: "AzureDatabricksExpander request",
+			IsPrimaryResponse: true,
+		}
+	}
+
+	data, err := apiSet.DoRequest("GET", "https://"+apiSet.workspaceURL+"/api/2.1/jobs/runs/get-output?run_id="+currentItem.Metadata["run_id"])
+	if err != nil {
+		return ExpanderResult{
+			Err:               fmt.Errorf("Failed to get run output: %s", err),
+			SourceDescription: "AzureDatabricksExpander request",
+			IsPrimaryResponse: true,
+		}
+	}
+	var output DatabricksRunOutput
+	if err := json.Unmarshal([]byte(data), &output); err != nil {
+		return ExpanderResult{
+			Err:               fmt.Errorf("Error unmarshalling run output: %s", err),
+			SourceDescription: "AzureDatabricksExpander request",
+			IsPrimaryResponse: true,
+		}
+	}
+
+	return ExpanderResult{
+		Response:          ExpanderResponse{Response: renderDatabricksRunOutput(output), ResponseType: interfaces.ResponsePlainText},
+		SourceDescription: "AzureDatabricksExpander request",
+		IsPrimaryResponse: true,
+	}
+}
+
+// getDatabricksRunNowRequest builds a run-now request body with the job's current parameters
+// so that they can be overridden in the editor
+func getDatabricksRunNowRequest(job DatabricksJob) map[string]interface{} {
+	request := map[string]interface{}{
+		"job_id": job.JobID,
+	}
+
+	if len(job.Settings.Parameters) > 0 {
+		jobParameters := map[string]string{}
+		for _, parameter := range job.Settings.Parameters {
+			jobParameters[parameter.Name] = parameter.Default
+		}
+		request["job_parameters"] = jobParameters
+	}
+
+	tasks := append([]DatabricksTaskSettings{job.Settings.DatabricksTaskSettings}, job.Settings.Tasks...)
+	notebookParams := map[string]string{}
+	hasNotebookTask := false
+	for _, task := range tasks {
+		switch {
+		case task.NotebookTask != nil:
+			hasNotebookTask = true
+			for name, value := range task.NotebookTask.BaseParameters {
+				notebookParams[name] = value
+			}
+		case task.SparkJarTask != nil:
+			request["jar_params"] = nonNilStrings(task.SparkJarTask.Parameters)
+		case task.SparkPythonTask != nil:
+			request["python_params"] = nonNilStrings(task.SparkPythonTask.Parameters)
+		case task.SparkSubmitTask != nil:
+			request["spark_submit_params"] = nonNilStrings(task.SparkSubmitTask.Parameters)
+		}
+	}
+	if hasNotebookTask {
+		request["notebook_params"] = notebookParams
+	}
+	return request
+}
+
+func nonNilStrings(values []string) []string {
+	if values == nil {
+		return []string{}
+	}
+	return values
+}
+
+// runNow opens the editor with the run-now request for the job, submits the edited request
+// and tracks the run in the background until it completes
+func (e *AzureDatabricksExpander) runNow(ctx context.Context, actionItem *TreeNode) ExpanderResult {
+	jobItem := actionItem.Parent
+	apiSet, err := e.getAPISetForNode(jobItem)
+	if err != nil {
+		return ExpanderResult{
+			Err:               err,
+			SourceDescription: "AzureDatabricksExpander request",
+			IsPrimaryResponse: true,
+		}
+	}
+
+	data, err := apiSet.DoRequest("GET", "https://"+apiSet.workspaceURL+"/api/2.1/jobs/get?job_id="+jobItem.Metadata["job_id"])
+	if err != nil {
+		return ExpanderResult{
+			Err:               fmt.Errorf("Failed to get job: %s", err),
+			SourceDescription: "AzureDatabricksExpander request",
+			IsPrimaryResponse: true,
+		}
+	}
+	var job DatabricksJob
+	if err := json.Unmarshal([]byte(data), &job); err != nil {
+		return ExpanderResult{
+			Err:               fmt.Errorf("Error unmarshalling job: %s", err),
+			SourceDescription: "AzureDatabricksExpander request",
+			IsPrimaryResponse: true,
+		}
+	}
+
+	requestBuf, err := json.MarshalIndent(getDatabricksRunNowRequest(job), "", "  ")
+	if err != nil {
+		return ExpanderResult{
+			Err:               fmt.Errorf("Error building run-now request: %s", err),
+			SourceDescription: "AzureDatabricksExpander request",
+			IsPrimaryResponse: true,
+		}
+	}
+	content, err := editor.OpenForContent(string(requestBuf), ".json")
+	if err != nil {
+		return ExpanderResult{
+			Err:               err,
+			SourceDescription: "AzureDatabricksExpander request",
+			IsPrimaryResponse: true,
+		}
+	}
+	if strings.TrimSpace(content) == "" {
+		return ExpanderResult{
+			Err:               fmt.Errorf("User canceled"),
+			SourceDescription: "AzureDatabricksExpander request",
+			IsPrimaryResponse: true,
+		}
+	}
+
+	data, err = apiSet.DoRequestWithBody("POST", "https://"+apiSet.workspaceURL+"/api/2.1/jobs/run-now", content)
+	if err != nil {
+		return ExpanderResult{
+			Err:               fmt.Errorf("Failed to run job: %s", err),
+			SourceDescription: "AzureDatabricksExpander request",
+			IsPrimaryResponse: true,
+		}
+	}
+	var response struct {
+		RunID int64 `json:"run_id"`
+	}
+	if err := json.Unmarshal([]byte(data), &response); err != nil {
+		return ExpanderResult{
+			Err:               fmt.Errorf("Error unmarshalling run-now response: %s", err),
+			SourceDescription: "AzureDatabricksExpander request",
+			IsPrimaryResponse: true,
+		}
+	}
+
+	jobName := job.Settings.Name
+	if jobName == "" {
+		jobName = jobItem.Name
+	}
+	go e.trackRun(apiSet, actionItem, jobName, strconv.FormatInt(response.RunID, 10))
+
+	return ExpanderResult{
+		Response:          ExpanderResponse{Response: fmt.Sprintf("Run %d submitted for %s. Progress is shown in the status bar and next to the action.\n\n%s", response.RunID, jobName, content), ResponseType: interfaces.ResponsePlainText},
+		SourceDescription: "AzureDatabricksExpander request",
+		IsPrimaryResponse: true,
+	}
+}
+
+// trackRun polls the run until it reaches a terminal state, updating the status event and action StatusIndicator
+func (e *AzureDatabricksExpander) trackRun(apiSet *SwaggerAPISetDatabricks, actionItem *TreeNode, jobName string, runID string) {
+	// recover from panic, if one occurrs, and leave terminal usable
+	defer errorhandling.RecoveryWithCleanup()
+
+	message := "Run " + runID + " of " + jobName
+	event, _ := eventing.SendStatusEvent(&eventing.StatusEvent{
+		InProgress: true,
+		IsToast:    true,
+		Message:    message + ": submitted",
+		Timeout:    databricksRunTrackingTimeout,
+	})
+
+	deadline := time.Now().Add(databricksRunTrackingTimeout)
+	var run DatabricksRun
+	var err error
+	for time.Now().Before(deadline) {
+		run, err = e.getRun(apiSet, runID)
+		if err != nil {
+			break
+		}
+		actionItem.StatusIndicator = drawDatabricksRunState(run)
+		event.Message = message + ": " + run.getStateDisplay() + " (" + formatDatabricksDuration(run.getDuration(time.Now())) + ")"
+		event.Update()
+		e.refreshGui()
+		if run.isTerminal() {
+			break
+		}
+		time.Sleep(databricksRunPollInterval)
+	}
+
+	event.InProgress = false
+	switch {
+	case err != nil:
+		event.Failure = true
+		event.Message = message + " failed: " + err.Error()
+	case !run.isTerminal():
+		event.Failure = true
+		event.Message = message + " still " + run.getStateDisplay() + " - stopped tracking"
+	case !run.isSuccess():
+		event.Failure = true
+		event.Message = message + " " + run.getStateDisplay() + ": " + run.State.StateMessage
+		for _, task := range run.Tasks {
+			if task.isTerminal() && !task.isSuccess() {
+				event.Message += " [" + task.TaskKey + ": " + task.getStateDisplay() + "]"
+			}
+		}
+	default:
+		event.Message = message + " COMPLETED in " + formatDatabricksDuration(run.getDuration(time.Now()))
+	}
+	event.SetTimeout(time.Second * 15)
+	event.Update()
+}
+
+// refreshGui forces the UI to re-render to pick up StatusIndicator changes
+func (e *AzureDatabricksExpander) refreshGui() {
+	if e.gui != nil {
+		e.gui.Update(func(g *gocui.Gui) error {
+			return nil
+		})
+	}
+}
@@ -59,19 +59,21 @@ var _ SwaggerAPISet = SwaggerAPISetDatabricks{}
 
 // SwaggerAPISetDatabricks holds the config for working with an Azure Search Service
 type SwaggerAPISetDatabricks struct {
-	resourceTypes   []swagger.ResourceType
-	httpClient      http.Client
-	workspaceID     string // ARM resource ID for the search service (/subscriptions/....)
-	nodeID          string
-	workspaceURL    string
-	managementToken string
-	databricksToken string
+	resourceTypes       []swagger.ResourceType
+	resourceTypeMatcher *swagger.ResourceTypeMatcher
+	httpClient          http.Client
+	workspaceID         string // ARM resource ID for the search service (/subscriptions/....)
+	nodeID              string
+	workspaceURL        string
+	managementToken     string
+	databricksToken     string
 }
 
 // NewSwaggerAPISetDatabricks creates a new SwaggerAPISetDatabricks
 func NewSwaggerAPISetDatabricks(resourceTypes []swagger.ResourceType, workspaceID string, nodeID string, workspaceURL string, managementToken string, databricksToken string) SwaggerAPISetDatabricks {
 	c := SwaggerAPISetDatabricks{}
 	c.resourceTypes = resourceTypes
+	c.resourceTypeMatcher = swagger.NewResourceTypeMatcher(c.resourceTypes)
 	c.httpClient = http.Client{}
 	c.workspaceID = workspaceID
 	c.nodeID = nodeID
@@ -103,6 +105,11 @@ func (c SwaggerAPISetDatabricks) GetResourceTypes() []swagger.ResourceType {
 	return c.resourceTypes
 }
 
+// GetResourceTypeMatcher returns the ResourceTypeMatcher for the ResourceTypes in the API Set
+func (c SwaggerAPISetDatabricks) GetResourceTypeMatcher() *swagger.ResourceTypeMatcher {
+	return c.resourceTypeMatcher
+}
+
 // DoRequest makes a request against the search endpoint
 func (c SwaggerAPISetDatabricks) DoRequest(verb string, url string) (string, error) {
 	return c.DoRequestWithBody(verb, url, "")
@@ -4,7 +4,9 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"strings"
 
+	"github.com/awesome-gocui/gocui"
 	"github.com/lawrencegripper/azbrowse/pkg/armclient"
 )
 
@@ -19,6 +21,22 @@ const azureDatabricksGlobalApplicationID string = "2ff814a6-3304-4ab8-85cb-cd0e6
 // This is the azure management endpoint used. Would need updating for sovereign clouds etc
 const azureManagementEndpoint string = "https://management.core.windows.net/"
 
+const (
+	databricksNamespace = "AzureDatabricksExpander"
+
+	databricksRunHistoryType    = "databricks.runHistory"
+	databricksRunType           = "databricks.run"
+	databricksRunOutputType     = "databricks.runOutput"
+	databricksClusterEventsType = "databricks.clusterEvents"
+	databricksClusterEventType  = "databricks.clusterEvent"
+
+	databricksActionRunNow = "run-now"
+
+	databricksClusterTemplateURL = "/api/2.0/clusters/get"
+	databricksJobTemplateURL     = "/api/2.0/jobs/get"
+	databricksRunTemplateURL     = "/api/2.0/jobs/runs/get"
+)
+
 type workspaceResponse struct {
 	Properties struct {
 		WorkspaceURL string `json:"workspaceUrl"`
@@ -29,6 +47,7 @@ type workspaceResponse struct {
 type AzureDatabricksExpander struct {
 	ExpanderBase
 	client *armclient.Client
+	gui    *gocui.Gui
 }
 
 func (e *AzureDatabricksExpander) setClient(c *armclient.Client) {
@@ -48,24 +67,42 @@ func (e *AzureDatabricksExpander) DoesExpand(ctx context.Context, currentItem *T
 			return true, nil
 		}
 	}
-	if currentItem.Namespace == "AzureDatabricksExpander" {
+	if currentItem.Namespace == databricksNamespace {
+		return true, nil
+	}
+	if getDatabricksTemplateURL(currentItem) != "" {
 		return true, nil
 	}
 	return false, nil
 }
 
+// getDatabricksTemplateURL returns the TemplateURL for nodes added by the swagger expander for the Databricks API set
+// that this expander adds run, output and event nodes to (or "" for other nodes)
+func getDatabricksTemplateURL(item *TreeNode) string {
+	if item.Namespace != "swagger" || item.SwaggerResourceType == nil ||
+		!strings.HasSuffix(item.Metadata["SwaggerAPISetID"], "/<workspace>") {
+		return ""
+	}
+	templateURL := item.SwaggerResourceType.Endpoint.TemplateURL
+	switch templateURL {
+	case databricksClusterTemplateURL, databricksJobTemplateURL, databricksRunTemplateURL:
+		return templateURL
+	}
+	return ""
+}
+
 // Expand returns the SwaggerExpander set up for this Databricks workspace
 func (e *AzureDatabricksExpander) Expand(ctx context.Context, currentItem *TreeNode) ExpanderResult {
 
 	swaggerResourceType := currentItem.SwaggerResourceType
-	if currentItem.Namespace != "AzureDatabricksExpander" &&
+	if currentItem.Namespace != databricksNamespace &&
 		swaggerResourceType != nil &&
 		swaggerResourceType.Endpoint.TemplateURL == azureDatabricksTemplateURL {
 		newItems := []*TreeNode{}
 		newItems = append(newItems, &TreeNode{
 			ID:                    currentItem.ID + "/<workspace>",
 			Parentid:              currentItem.ID,
-			Namespace:             "AzureDatabricksExpander",
+			Namespace:             databricksNamespace,
 			Name:                  "Connect to Databricks workspace",
 			Display:               "Connect to Databricks workspace",
 			ItemType:              SubResourceType,
@@ -86,8 +123,39 @@ func (e *AzureDatabricksExpander) Expand(ctx context.Context, currentItem *TreeN
 		}
 	}
 
-	if currentItem.Namespace == "AzureDatabricksExpander" && currentItem.ItemType == SubResourceType {
-		return e.expandWorkspaceRoot(ctx, currentItem)
+	if currentItem.Namespace == databricksNamespace {
+		switch currentItem.ItemType {
+		case SubResourceType:
+			return e.expandWorkspaceRoot(ctx, currentItem)
+		case databricksRunHistoryType:
+			return e.expandRunHistory(ctx, currentItem)
+		case databricksRunType:
+			return e.expandRun(ctx, currentItem)
+		case databricksRunOutputType:
+			return e.expandRunOutput(ctx, currentItem)
+		case databricksClusterEventsType:
+			return e.expandClusterEvents(ctx, currentItem)
+		}
+	}
+
+	switch getDatabricksTemplateURL(currentItem) {
+	case databricksClusterTemplateURL:
+		return ExpanderResult{
+			Response:          ExpanderResponse{Response: ""}, // Swagger expander will supply the response
+			SourceDescription: "AzureDatabricksExpander request",
+			Nodes:             []*TreeNode{newDatabricksClusterEventsNode(currentItem, "0")},
+		}
+	case databricksJobTemplateURL:
+		return ExpanderResult{
+			Response:          ExpanderResponse{Response: ""}, // Swagger expander will supply the response
+			SourceDescription: "AzureDatabricksExpander request",
+			Nodes:             []*TreeNode{newDatabricksRunHistoryNode(currentItem, "0")},
+		}
+	case databricksRunTemplateURL:
+		result := e.expandRun(ctx, currentItem)
+		result.Response = ExpanderResponse{Response: ""} // Swagger expander will supply the response
+		result.IsPrimaryResponse = false
+		return result
 	}
 
 	return ExpanderResult{
@@ -194,6 +262,98 @@ func (e *AzureDatabricksExpander) getAPISetForWorkspace(workspaceID string) *Swa
 	return &apiSet
 }
 
+// HasActions returns true for clusters and jobs
+func (e *AzureDatabricksExpander) HasActions(ctx context.Context, item *TreeNode) (bool, error) {
+	switch getDatabricksTemplateURL(item) {
+	case databricksClusterTemplateURL, databricksJobTemplateURL:
+		return true, nil
+	}
+	return false, nil
+}
+
+// ListActions returns the start/restart/terminate actions for clusters and the run action for jobs
+func (e *AzureDatabricksExpander) ListActions(ctx context.Context, item *TreeNode) ListActionsResult {
+	// Editing the run parameters waits for the editor to close
+	runNowTimeout := 600
+	newAction := func(actionID string, name string, timeoutOverride *int) *TreeNode {
+		return &TreeNode{
+			Parentid:               item.ID,
+			ID:                     item.ID + "?" + actionID,
+			Namespace:              databricksNamespace,
+			Name:                   name,
+			Display:                name,
+			ItemType:               ActionType,
+			SuppressGenericExpand:  true,
+			TimeoutOverrideSeconds: timeoutOverride,
+			Metadata: map[string]string{
+				"ActionID": actionID,
+			},
+		}
+	}
+
+	nodes := []*TreeNode{}
+	switch getDatabricksTemplateURL(item) {
+	case databricksClusterTemplateURL:
+		nodes = append(nodes,
+			newAction(databricksActionStartCluster, "Start cluster", nil),
+			newAction(databricksActionRestartCluster, "Restart cluster", nil),
+			newAction(databricksActionTerminateCluster, "Terminate cluster", nil))
+	case databricksJobTemplateURL:
+		nodes = append(nodes, newAction(databricksActionRunNow, "Run now (with parameters)", &runNowTimeout))
+	}
+
+	return ListActionsResult{
+		Nodes:             nodes,
+		SourceDescription: "AzureDatabricksExpander",
+		IsPrimaryResponse: true,
+	}
+}
+
+// ExecuteAction runs the selected cluster or job action
+func (e *AzureDatabricksExpander) ExecuteAction(ctx context.Context, item *TreeNode) ExpanderResult {
+	actionID := item.Metadata["ActionID"]
+
+	if item.Parent == nil {
+		return ExpanderResult{
+			SourceDescription: "AzureDatabricksExpander",
+			IsPrimaryResponse: true,
+			Err:               fmt.Errorf("Cluster or job not set on action: %q", item.ID),
+		}
+	}
+
+	switch actionID {
+	case databricksActionStartCluster, databricksActionRestartCluster, databricksActionTerminateCluster:
+		return e.clusterAction(ctx, item, actionID)
+	case databricksActionRunNow:
+		return e.runNow(ctx, item)
+	case "":
+		return ExpanderResult{
+			SourceDescription: "AzureDatabricksExpander",
+			IsPrimaryResponse: true,
+			Err:               fmt.Errorf("ActionID metadata not set: %q", item.ID),
+		}
+	default:
+		return ExpanderResult{
+			SourceDescription: "AzureDatabricksExpander",
+			IsPrimaryResponse: true,
+			Err:               fmt.Errorf("Unhandled ActionID: %q", actionID),
+		}
+	}
+}
+
+// getAPISetForNode returns the Databricks APISet for nodes under a workspace
+func (e *AzureDatabricksExpander) getAPISetForNode(item *TreeNode) (*SwaggerAPISetDatabricks, error) {
+	swaggerAPISet := GetSwaggerResourceExpander().GetAPISet(item.Metadata["SwaggerAPISetID"])
+	if swaggerAPISet == nil {
+		return nil, fmt.Errorf("Databricks workspace connection not found for %q", item.ID)
+	}
+	apiSet, ok := (*swaggerAPISet).(SwaggerAPISetDatabricks)
+	if !ok {
+		return nil, fmt.Errorf("Unexpected APISet type for %q", item.ID)
+	}
+	return &apiSet, nil
+}
+
 func (e *AzureDatabricksExpander) getWorkspaceUrl(ctx context.Context, workspaceID string) (string, error) {
 	data, err := e.client.DoRequest(ctx, "GET", workspaceID+"?api-version=2018-04-01")
 	if err != nil {
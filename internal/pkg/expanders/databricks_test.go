@@ -0,0 +1,113 @@
+package expanders
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/nbio/st"
+)
+
+func Test_Databricks_RunDuration(t *testing.T) {
+	now := time.Unix(1000, 0)
+
+	multiTask := DatabricksRun{RunDuration: 90000, SetupDuration: 1000}
+	st.Expect(t, multiTask.getDuration(now), 90*time.Second)
+
+	singleTask := DatabricksRun{SetupDuration: 1000, ExecutionDuration: 58000, CleanupDuration: 1000}
+	st.Expect(t, singleTask.getDuration(now), time.Minute)
+
+	inProgress := DatabricksRun{StartTime: 940000}
+	st.Expect(t, inProgress.getDuration(now), time.Minute)
+
+	notStarted := DatabricksRun{}
+	st.Expect(t, notStarted.getDuration(now), time.Duration(0))
+	st.Expect(t, formatDatabricksDuration(notStarted.getDuration(now)), "-")
+	st.Expect(t, formatDatabricksDuration(1500*time.Millisecond), "2s")
+}
+
+func Test_Databricks_RunState(t *testing.T) {
+	running := DatabricksRun{State: DatabricksRunState{LifeCycleState: "RUNNING"}}
+	st.Expect(t, running.isTerminal(), false)
+	st.Expect(t, running.getStateDisplay(), "RUNNING")
+
+	succeeded := DatabricksRun{State: DatabricksRunState{LifeCycleState: "TERMINATED", ResultState: "SUCCESS"}}
+	st.Expect(t, succeeded.isTerminal(), true)
+	st.Expect(t, succeeded.isSuccess(), true)
+	st.Expect(t, succeeded.getStateDisplay(), "SUCCESS")
+
+	failed := DatabricksRun{State: DatabricksRunState{LifeCycleState: "INTERNAL_ERROR", ResultState: "FAILED"}}
+	st.Expect(t, failed.isTerminal(), true)
+	st.Expect(t, failed.isSuccess(), false)
+}
+
+func Test_Databricks_ClusterActionResult(t *testing.T) {
+	type testCase struct {
+		action            string
+		state             string
+		hasTransitioned   bool
+		expectedCompleted bool
+		expectedFailed    bool
+	}
+	testCases := []testCase{
+		{databricksActionStartCluster, "PENDING", true, false, false},
+		{databricksActionStartCluster, "RUNNING", true, true, false},
+		{databricksActionStartCluster, "TERMINATED", false, false, false},
+		{databricksActionStartCluster, "TERMINATED", true, true, true},
+		{databricksActionRestartCluster, "RUNNING", false, false, false},
+		{databricksActionRestartCluster, "RUNNING", true, true, false},
+		{databricksActionRestartCluster, "ERROR", true, true, true},
+		{databricksActionTerminateCluster, "TERMINATING", false, false, false},
+		{databricksActionTerminateCluster, "TERMINATED", false, true, false},
+	}
+	for _, tc := range testCases {
+		completed, failed := getDatabricksClusterActionResult(tc.action, tc.state, tc.hasTransitioned)
+		st.Expect(t, completed, tc.expectedCompleted)
+		st.Expect(t, failed, tc.expectedFailed)
+	}
+}
+
+func Test_Databricks_RunNowRequest(t *testing.T) {
+	jobJSON := `{
+		"job_id": 42,
+		"settings": {
+			"name": "nightly",
+			"tasks": [
+				{"task_key": "ingest", "notebook_task": {"notebook_path": "/ingest", "base_parameters": {"date": "today"}}},
+				{"task_key": "score", "spark_python_task": {"python_file": "dbfs:/score.py", "parameters": ["--fast"]}}
+			]
+		}
+	}`
+	var job DatabricksJob
+	err := json.Unmarshal([]byte(jobJSON), &job)
+	st.Expect(t, err, nil)
+
+	request := getDatabricksRunNowRequest(job)
+	st.Expect(t, request["job_id"], int64(42))
+	st.Expect(t, request["notebook_params"], map[string]string{"date": "today"})
+	st.Expect(t, request["python_params"], []string{"--fast"})
+	_, hasJarParams := request["jar_params"]
+	st.Expect(t, hasJarParams, false)
+}
+
+func Test_Databricks_RenderRunOutput(t *testing.T) {
+	output := DatabricksRunOutput{Error: "boom", ErrorTrace: "Traceback: line 1"}
+	rendered := renderDatabricksRunOutput(output)
+	st.Assert(t, strings.Contains(rendered, "boom"), true)
+	st.Assert(t, strings.Contains(rendered, "Traceback: line 1"), true)
+
+	empty := DatabricksRunOutput{}
+	empty.Metadata.RunID = 7
+	empty.Metadata.State.LifeCycleState = "RUNNING"
+	st.Expect(t, renderDatabricksRunOutput(empty), "No output for run 7 (RUNNING)")
+}
+
+func Test_Databricks_EventDetails(t *testing.T) {
+	details := map[string]interface{}{
+		"current_num_workers": float64(2),
+		"reason":              map[string]interface{}{"code": "INACTIVITY", "parameters": map[string]interface{}{}},
+		"attributes":          map[string]interface{}{"spark_version": "7.3"},
+	}
+	st.Expect(t, summarizeDatabricksEventDetails(details), "current_num_workers=2 reason=INACTIVITY")
+}
@@ -125,6 +125,10 @@ func (e *DefaultExpander) ListActions(ctx context.Context, item *TreeNode) ListA
 		panic(err)
 	}
 
+	// The SwaggerResourceExpander lists the POST actions from the swagger specs (which include request bodies)
+	// so skip the operations that it already lists
+	swaggerActionNames := getSwaggerPostActionNames(ctx, item)
+
 	items := []*TreeNode{}
 	for _, resOps := range opsRequest.ResourceTypes {
 		if resOps.Name == strings.Split(armType, "/")[1] {
@@ -136,6 +140,9 @@ func (e *DefaultExpander) ListActions(ctx context.Context, item *TreeNode) ListA
 					}
 				}
 				stripArmType := strings.Replace(op.Name, item.ArmType, "", -1)
+				if swaggerActionNames[strings.ToLower(strings.Trim(strings.Replace(stripArmType, "/action", "", -1), "/"))] {
+					continue
+				}
 				name := op.DisplayName
 				if name == "" {
 					name = strings.Replace(stripArmType, "/action", "", -1)
@@ -157,6 +164,21 @@ func (e *DefaultExpander) ListActions(ctx context.Context, item *TreeNode) ListA
 	}
 }
 
+// getSwaggerPostActionNames returns the (lower-case) names of the swagger POST actions for the item
+func getSwaggerPostActionNames(ctx context.Context, item *TreeNode) map[string]bool {
+	names := map[string]bool{}
+	swaggerExpander := GetSwaggerResourceExpander()
+	if swaggerExpander == nil {
+		return names
+	}
+	if apiSet, resourceType := swaggerExpander.getPostActions(ctx, item); apiSet != nil {
+		for _, action := range resourceType.PostActions {
+			names[strings.ToLower(action.Name)] = true
+		}
+	}
+	return names
+}
+
 // ExecuteAction executes an action returned from ListActions
 func (e *DefaultExpander) ExecuteAction(ctx context.Context, currentItem *TreeNode) ExpanderResult {
 	method := "POST"
@@ -3,7 +3,12 @@ package expanders
 import (
 	"context"
 	"encoding/json"
+	"fmt"
+	"strings"
+	"sync"
 
+	"github.com/lawrencegripper/azbrowse/internal/pkg/editor"
+	"github.com/lawrencegripper/azbrowse/internal/pkg/eventing"
 	"github.com/lawrencegripper/azbrowse/internal/pkg/interfaces"
 	"github.com/lawrencegripper/azbrowse/internal/pkg/style"
 	"github.com/lawrencegripper/azbrowse/pkg/armclient"
@@ -12,10 +17,26 @@ import (
 // Check interface
 var _ Expander = &DeploymentsExpander{}
 
+const (
+	deploymentsAPIVersion = "2020-10-01"
+
+	deploymentActionViewTemplate   = "view-template"
+	deploymentActionViewParameters = "view-parameters"
+	deploymentActionEditParameters = "edit-parameters"
+	deploymentActionWhatIf         = "what-if"
+	deploymentActionRedeploy       = "redeploy"
+)
+
 // DeploymentsExpander expands RGs under a subscription
 type DeploymentsExpander struct {
 	ExpanderBase
 	client *armclient.Client
+
+	// editedParameters holds parameters edited by the user until they are used by a redeploy.
+	// They are held here rather than on the deployment node so they survive the list being
+	// refreshed or the deployment being expanded again. Keyed on lower case deployment ID
+	editedParameters     map[string]string
+	editedParametersLock sync.Mutex
 }
 
 func (e *DeploymentsExpander) setClient(c *armclient.Client) {
@@ -65,9 +86,13 @@ func (e *DeploymentsExpander) Expand(ctx context.Context, currentItem *TreeNode)
 		for i, dep := range deployments.Value {
 			// Update the existing state as we have more up-to-date info
 			objectJSON := string(value.GetArray("value")[i].MarshalTo([]byte("")))
+			display := dep.Name + "\n   " + style.Subtle("Started:  "+dep.Properties.Timestamp) + "\n   " + style.Subtle("Duration: "+dep.Properties.Duration) + "\n   " + style.Subtle("DeploymentStatus: "+dep.Properties.ProvisioningState+"")
+			if e.getEditedParameters(dep.ID) != "" {
+				display += "\n   " + style.Subtle("Parameters: edited (not deployed)")
+			}
 			newItems = append(newItems, &TreeNode{
 				Name:            dep.Name,
-				Display:         dep.Name + "\n   " + style.Subtle("Started:  "+dep.Properties.Timestamp) + "\n   " + style.Subtle("Duration: "+dep.Properties.Duration) + "\n   " + style.Subtle("DeploymentStatus: "+dep.Properties.ProvisioningState+""),
+				Display:         display,
 				ID:              dep.ID,
 				Parentid:        currentItem.ID + "/operations/",
 				ExpandURL:       dep.ID + "/operations/?api-version=2017-05-10",
@@ -131,6 +156,383 @@ func (e *DeploymentsExpander) Expand(ctx context.Context, currentItem *TreeNode)
 	}
 }
 
+// HasActions returns true for deployments to allow template inspection, what-if and redeploy
+func (e *DeploymentsExpander) HasActions(ctx context.Context, item *TreeNode) (bool, error) {
+	return item.ItemType == deploymentType, nil
+}
+
+// ListActions returns the actions available for a deployment
+func (e *DeploymentsExpander) ListActions(ctx context.Context, item *TreeNode) ListActionsResult {
+	// What-if and redeploy can take a while for larger templates
+	timeoutOverride := 300
+	newAction := func(actionID string, name string, timeoutOverride *int) *TreeNode {
+		return &TreeNode{
+			Parentid:               item.ID,
+			ID:                     item.ID + "?" + actionID,
+			Namespace:              "deployments",
+			Name:                   name,
+			Display:                name,
+			ItemType:               ActionType,
+			SuppressGenericExpand:  true,
+			TimeoutOverrideSeconds: timeoutOverride,
+			Metadata: map[string]string{
+				"ActionID": actionID,
+			},
+		}
+	}
+
+	editParametersName := "Edit Parameters"
+	if e.getEditedParameters(item.ID) != "" {
+		editParametersName = "Edit Parameters (edited)"
+	}
+
+	return ListActionsResult{
+		Nodes: []*TreeNode{
+			newAction(deploymentActionViewTemplate, "View Template", nil),
+			newAction(deploymentActionViewParameters, "View Parameters", nil),
+			newAction(deploymentActionEditParameters, editParametersName, nil),
+			newAction(deploymentActionWhatIf, "What-If", &timeoutOverride),
+			newAction(deploymentActionRedeploy, "Redeploy", &timeoutOverride),
+		},
+		SourceDescription: "DeploymentsExpander",
+		IsPrimaryResponse: true,
+	}
+}
+
+// ExecuteAction runs the selected deployment action
+func (e *DeploymentsExpander) ExecuteAction(ctx context.Context, item *TreeNode) ExpanderResult {
+	actionID := item.Metadata["ActionID"]
+
+	if item.Parent == nil {
+		return ExpanderResult{
+			SourceDescription: "DeploymentsExpander",
+			IsPrimaryResponse: true,
+			Err:               fmt.Errorf("Deployment not set on action: %q", item.ID),
+		}
+	}
+
+	switch actionID {
+	case deploymentActionViewTemplate:
+		return e.viewTemplate(ctx, item.Parent)
+	case deploymentActionViewParameters:
+		return e.viewParameters(ctx, item.Parent)
+	case deploymentActionEditParameters:
+		return e.editParameters(ctx, item.Parent)
+	case deploymentActionWhatIf:
+		return e.whatIf(ctx, item.Parent)
+	case deploymentActionRedeploy:
+		return e.redeploy(ctx, item.Parent)
+	case "":
+		return ExpanderResult{
+			SourceDescription: "DeploymentsExpander",
+			IsPrimaryResponse: true,
+			Err:               fmt.Errorf("ActionID metadata not set: %q", item.ID),
+		}
+	default:
+		return ExpanderResult{
+			SourceDescription: "DeploymentsExpander",
+			IsPrimaryResponse: true,
+			Err:               fmt.Errorf("Unhandled ActionID: %q", actionID),
+		}
+	}
+}
+
+func (e *DeploymentsExpander) viewTemplate(ctx context.Context, deployment *TreeNode) ExpanderResult {
+	template, err := e.getTemplate(ctx, deployment.ID)
+	if err != nil {
+		return ExpanderResult{
+			Err:               err,
+			SourceDescription: "DeploymentsExpander request",
+			IsPrimaryResponse: true,
+		}
+	}
+	return ExpanderResult{
+		Response:          ExpanderResponse{Response: string(template), ResponseType: interfaces.ResponseJSON},
+		SourceDescription: "DeploymentsExpander request",
+		IsPrimaryResponse: true,
+	}
+}
+
+func (e *DeploymentsExpander) viewParameters(ctx context.Context, deployment *TreeNode) ExpanderResult {
+	parameters, err := e.getParameters(ctx, deployment)
+	if err != nil {
+		return ExpanderResult{
+			Err:               err,
+			SourceDescription: "DeploymentsExpander request",
+			IsPrimaryResponse: true,
+		}
+	}
+	return ExpanderResult{
+		Response:          ExpanderResponse{Response: parameters, ResponseType: interfaces.ResponseJSON},
+		SourceDescription: "DeploymentsExpander request",
+		IsPrimaryResponse: true,
+	}
+}
+
+func (e *DeploymentsExpander) editParameters(ctx context.Context, deployment *TreeNode) ExpanderResult {
+	parameters, err := e.getParameters(ctx, deployment)
+	if err != nil {
+		return ExpanderResult{
+			Err:               err,
+			SourceDescription: "DeploymentsExpander request",
+			IsPrimaryResponse: true,
+		}
+	}
+
+	initialContent := "// Edit the parameters then save and exit. Use What-If to preview the changes and Redeploy to apply them. SecureString values are not returned by Azure and must be re-entered\n" + parameters
+	content, err := editor.OpenForContent(initialContent, ".json")
+	if err != nil {
+		return ExpanderResult{
+			Err:               err,
+			SourceDescription: "DeploymentsExpander request",
+			IsPrimaryResponse: true,
+		}
+	}
+
+	if content == initialContent || strings.TrimSpace(content) == "" {
+		return ExpanderResult{
+			Err:               fmt.Errorf("User canceled"),
+			SourceDescription: "DeploymentsExpander request",
+			IsPrimaryResponse: true,
+		}
+	}
+
+	if strings.HasPrefix(content, "//") {
+		// remove the comment line we added!
+		newLineIndex := strings.Index(content, "\n")
+		content = content[newLineIndex+1:]
+	}
+
+	var editedParameters map[string]interface{}
+	if err := json.Unmarshal([]byte(content), &editedParameters); err != nil {
+		return ExpanderResult{
+			Err:               fmt.Errorf("Error parsing edited parameters: %s", err),
+			SourceDescription: "DeploymentsExpander request",
+			IsPrimaryResponse: true,
+		}
+	}
+
+	e.setEditedParameters(deployment.ID, content)
+
+	return ExpanderResult{
+		Response:          ExpanderResponse{Response: "Parameters updated. Use What-If to preview the changes or Redeploy to apply them.", ResponseType: interfaces.ResponsePlainText},
+		SourceDescription: "DeploymentsExpander request",
+		IsPrimaryResponse: true,
+	}
+}
+
+func (e *DeploymentsExpander) whatIf(ctx context.Context, deployment *TreeNode) ExpanderResult {
+	body, err := e.buildDeploymentRequestBody(ctx, deployment)
+	if err != nil {
+		return ExpanderResult{
+			Err:               err,
+			SourceDescription: "DeploymentsExpander request",
+			IsPrimaryResponse: true,
+		}
+	}
+
+	event, _ := eventing.SendStatusEvent(&eventing.StatusEvent{
+		InProgress: true,
+		Message:    "Running what-if for " + deployment.Name,
+	})
+	defer event.Done()
+
+	data, err := e.client.DoLongRunningRequestWithBody(ctx, "POST", deployment.ID+"/whatIf?api-version="+deploymentsAPIVersion, body, func(status string) {
+		event.Message = "Running what-if for " + deployment.Name + ": " + status
+		event.Update()
+	})
+	if err != nil {
+		return ExpanderResult{
+			Err:               fmt.Errorf("Error running what-if: %s %s", err, data),
+			SourceDescription: "DeploymentsExpander request",
+			IsPrimaryResponse: true,
+		}
+	}
+
+	content, err := renderWhatIfResult(data)
+	if err != nil {
+		return ExpanderResult{
+			Err:               err,
+			SourceDescription: "DeploymentsExpander request",
+			IsPrimaryResponse: true,
+		}
+	}
+
+	return ExpanderResult{
+		Response:          ExpanderResponse{Response: content, ResponseType: interfaces.ResponsePlainText},
+		SourceDescription: "DeploymentsExpander request",
+		IsPrimaryResponse: true,
+	}
+}
+
+func (e *DeploymentsExpander) redeploy(ctx context.Context, deployment *TreeNode) ExpanderResult {
+	body, err := e.buildDeploymentRequestBody(ctx, deployment)
+	if err != nil {
+		return ExpanderResult{
+			Err:               err,
+			SourceDescription: "DeploymentsExpander request",
+			IsPrimaryResponse: true,
+		}
+	}
+
+	// Use DoRequestWithBody so that the async ARM watcher picks up the 201
+	// and tracks the deployment through to completion
+	data, err := e.client.DoRequestWithBody(ctx, "PUT", deployment.ID+"?api-version="+deploymentsAPIVersion, body)
+	if err != nil {
+		return ExpanderResult{
+			Err:               fmt.Errorf("Error redeploying: %s %s", err, data),
+			SourceDescription: "DeploymentsExpander request",
+			IsPrimaryResponse: true,
+		}
+	}
+
+	// The parameters have been applied so any future actions should use the deployed values
+	e.setEditedParameters(deployment.ID, "")
+
+	return ExpanderResult{
+		Response:          ExpanderResponse{Response: data, ResponseType: interfaces.ResponseJSON},
+		SourceDescription: "DeploymentsExpander request",
+		IsPrimaryResponse: true,
+	}
+}
+
+// getEditedParameters returns the parameters edited by the user for a deployment, if any
+func (e *DeploymentsExpander) getEditedParameters(deploymentID string) string {
+	e.editedParametersLock.Lock()
+	defer e.editedParametersLock.Unlock()
+	return e.editedParameters[strings.ToLower(deploymentID)]
+}
+
+// setEditedParameters stores the parameters edited by the user for a deployment. Empty parameters clear the edits
+func (e *DeploymentsExpander) setEditedParameters(deploymentID string, parameters string) {
+	e.editedParametersLock.Lock()
+	defer e.editedParametersLock.Unlock()
+	if e.editedParameters == nil {
+		e.editedParameters = map[string]string{}
+	}
+	if parameters == "" {
+		delete(e.editedParameters, strings.ToLower(deploymentID))
+		return
+	}
+	e.editedParameters[strings.ToLower(deploymentID)] = parameters
+}
+
+// getDeployment returns the current definition of a deployment
+func (e *DeploymentsExpander) getDeployment(ctx context.Context, deploymentID string) (DeploymentResponse, error) {
+	var deployment DeploymentResponse
+	data, err := e.client.DoRequest(ctx, "GET", deploymentID+"?api-version="+deploymentsAPIVersion)
+	if err != nil {
+		return deployment, fmt.Errorf("Error getting deployment: %s", err)
+	}
+	if err := json.Unmarshal([]byte(data), &deployment); err != nil {
+		return deployment, fmt.Errorf("Error parsing deployment: %s", err)
+	}
+	return deployment, nil
+}
+
+// getTemplate returns the template used by a deployment
+func (e *DeploymentsExpander) getTemplate(ctx context.Context, deploymentID string) (json.RawMessage, error) {
+	data, err := e.client.DoRequest(ctx, "POST", deploymentID+"/exportTemplate?api-version="+deploymentsAPIVersion)
+	if err != nil {
+		return nil, fmt.Errorf("Error exporting template: %s", err)
+	}
+	var exported struct {
+		Template json.RawMessage `json:"template"`
+	}
+	if err := json.Unmarshal([]byte(data), &exported); err != nil {
+		return nil, fmt.Errorf("Error parsing exported template: %s", err)
+	}
+	return exported.Template, nil
+}
+
+// getParameters returns the parameters to use for the deployment in the format used by
+// deployment requests. Parameters edited by the user take precedence over the deployed values
+func (e *DeploymentsExpander) getParameters(ctx context.Context, deploymentNode *TreeNode) (string, error) {
+	if edited := e.getEditedParameters(deploymentNode.ID); edited != "" {
+		return edited, nil
+	}
+
+	deployment, err := e.getDeployment(ctx, deploymentNode.ID)
+	if err != nil {
+		return "", err
+	}
+
+	// The deployment returns the type of each parameter alongside the value
+	// but the type isn't accepted when submitting a deployment
+	parameters := map[string]map[string]interface{}{}
+	for name, parameter := range deployment.Properties.Parameters {
+		delete(parameter, "type")
+		parameters[name] = parameter
+	}
+	buf, err := json.MarshalIndent(parameters, "", "  ")
+	if err != nil {
+		return "", err
+	}
+	return string(buf), nil
+}
+
+// buildDeploymentRequestBody creates the body for a what-if or redeploy request
+// using the deployment's template and the current parameters
+func (e *DeploymentsExpander) buildDeploymentRequestBody(ctx context.Context, deploymentNode *TreeNode) (string, error) {
+	deployment, err := e.getDeployment(ctx, deploymentNode.ID)
+	if err != nil {
+		return "", err
+	}
+
+	parameters, err := e.getParameters(ctx, deploymentNode)
+	if err != nil {
+		return "", err
+	}
+
+	properties := map[string]interface{}{
+		"mode":       deployment.Properties.Mode,
+		"parameters": json.RawMessage(parameters),
+	}
+	if deployment.Properties.Mode == "" {
+		properties["mode"] = "Incremental"
+	}
+	if deployment.Properties.TemplateLink != nil && deployment.Properties.TemplateLink.URI != "" {
+		// Linked templates may reference further templates relative to their URI so reuse the link
+		properties["templateLink"] = deployment.Properties.TemplateLink
+	} else {
+		template, err := e.getTemplate(ctx, deploymentNode.ID)
+		if err != nil {
+			return "", err
+		}
+		properties["template"] = template
+	}
+
+	request := map[string]interface{}{
+		"properties": properties,
+	}
+	if deployment.Location != "" {
+		// Deployments above resource group scope require a location
+		request["location"] = deployment.Location
+	}
+
+	buf, err := json.Marshal(request)
+	if err != nil {
+		return "", err
+	}
+	return string(buf), nil
+}
+
+// DeploymentResponse is returned by a request for a single deployment
+type DeploymentResponse struct {
+	ID         string `json:"id"`
+	Name       string `json:"name"`
+	Location   string `json:"location"`
+	Properties struct {
+		Mode              string                            `json:"mode"`
+		ProvisioningState string                            `json:"provisioningState"`
+		Parameters        map[string]map[string]interface{} `json:"parameters"`
+		TemplateLink      *struct {
+			URI            string `json:"uri,omitempty"`
+			ContentVersion string `json:"contentVersion,omitempty"`
+		} `json:"templateLink"`
+	} `json:"properties"`
+}
+
 // DeploymentsResponse is returned by a request for deployments in an RG
 type DeploymentsResponse struct {
 	Value []struct {
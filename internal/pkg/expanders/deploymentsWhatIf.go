@@ -0,0 +1,180 @@
+package expanders
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/lawrencegripper/azbrowse/internal/pkg/style"
+)
+
+// WhatIfOperationResult is returned by a what-if request on a deployment
+type WhatIfOperationResult struct {
+	Status     string `json:"status"`
+	Properties struct {
+		Changes []WhatIfChange `json:"changes"`
+	} `json:"properties"`
+	Error *struct {
+		Code    string `json:"code"`
+		Message string `json:"message"`
+	} `json:"error"`
+}
+
+// WhatIfChange is a predicted change to a single resource
+type WhatIfChange struct {
+	ResourceID        string                 `json:"resourceId"`
+	ChangeType        string                 `json:"changeType"`
+	UnsupportedReason string                 `json:"unsupportedReason"`
+	Before            map[string]interface{} `json:"before"`
+	After             map[string]interface{} `json:"after"`
+	Delta             []WhatIfPropertyChange `json:"delta"`
+}
+
+// WhatIfPropertyChange is a predicted change to a single property of a resource
+type WhatIfPropertyChange struct {
+	Path               string                 `json:"path"`
+	PropertyChangeType string                 `json:"propertyChangeType"`
+	Before             interface{}            `json:"before"`
+	After              interface{}            `json:"after"`
+	Children           []WhatIfPropertyChange `json:"children"`
+}
+
+var whatIfChangeSymbols = map[string]string{
+	"Create":      "+",
+	"Delete":      "-",
+	"Modify":      "~",
+	"Deploy":      "!",
+	"NoChange":    "=",
+	"Ignore":      "*",
+	"Unsupported": "x",
+	"Array":       "~",
+	"NoEffect":    "x",
+}
+
+// changeTypeOrder controls the order the summary counts are listed in
+var changeTypeOrder = []string{"Create", "Modify", "Delete", "Deploy", "NoChange", "Ignore", "Unsupported"}
+
+func formatWhatIfChangeType(changeType string, s string) string {
+	switch changeType {
+	case "Create":
+		return style.Added(s)
+	case "Delete":
+		return style.Removed(s)
+	case "Modify", "Array", "Deploy":
+		return style.Changed(s)
+	}
+	return style.Subtle(s)
+}
+
+func whatIfSymbol(changeType string) string {
+	symbol, ok := whatIfChangeSymbols[changeType]
+	if !ok {
+		return "?"
+	}
+	return symbol
+}
+
+// renderWhatIfResult formats a what-if response as a coloured diff
+func renderWhatIfResult(data string) (string, error) {
+	var result WhatIfOperationResult
+	if err := json.Unmarshal([]byte(data), &result); err != nil {
+		return "", fmt.Errorf("Error parsing what-if response: %s", err)
+	}
+	if result.Error != nil {
+		return "", fmt.Errorf("What-if failed: %s %s", result.Error.Code, result.Error.Message)
+	}
+
+	var sb strings.Builder
+	sb.WriteString(style.Title("What-If results") + "\n")
+	sb.WriteString(style.Subtle("Note: the result may contain false positive predictions (noise)") + "\n\n")
+	sb.WriteString("Resource and property changes are indicated with these symbols:\n")
+	sb.WriteString("  " + formatWhatIfChangeType("Create", "+ Create") + "\n")
+	sb.WriteString("  " + formatWhatIfChangeType("Modify", "~ Modify") + "\n")
+	sb.WriteString("  " + formatWhatIfChangeType("Delete", "- Delete") + "\n")
+	sb.WriteString("  " + formatWhatIfChangeType("NoChange", "= NoChange  * Ignore  x Unsupported") + "\n")
+
+	changes := result.Properties.Changes
+	sort.Slice(changes, func(i, j int) bool {
+		return strings.ToLower(changes[i].ResourceID) < strings.ToLower(changes[j].ResourceID)
+	})
+
+	counts := map[string]int{}
+	currentScope := ""
+	for _, change := range changes {
+		counts[change.ChangeType]++
+
+		scope, resource := splitWhatIfResourceID(change.ResourceID)
+		if scope != currentScope {
+			sb.WriteString("\nScope: " + scope + "\n\n")
+			currentScope = scope
+		}
+
+		line := "  " + whatIfSymbol(change.ChangeType) + " " + resource
+		if change.UnsupportedReason != "" {
+			line += " (" + change.UnsupportedReason + ")"
+		}
+		sb.WriteString(formatWhatIfChangeType(change.ChangeType, line) + "\n")
+
+		for _, delta := range change.Delta {
+			renderWhatIfPropertyChange(&sb, delta, 6)
+		}
+	}
+
+	sb.WriteString("\nResource changes: ")
+	summary := []string{}
+	for _, changeType := range changeTypeOrder {
+		if count, ok := counts[changeType]; ok {
+			summary = append(summary, fmt.Sprintf("%d %s", count, strings.ToLower(changeType)))
+		}
+	}
+	if len(summary) == 0 {
+		sb.WriteString("none")
+	} else {
+		sb.WriteString(strings.Join(summary, ", "))
+	}
+	sb.WriteString(".\n")
+
+	return sb.String(), nil
+}
+
+func renderWhatIfPropertyChange(sb *strings.Builder, change WhatIfPropertyChange, indent int) {
+	prefix := strings.Repeat(" ", indent) + whatIfSymbol(change.PropertyChangeType) + " " + change.Path
+	var line string
+	switch change.PropertyChangeType {
+	case "Create":
+		line = prefix + ": " + formatWhatIfValue(change.After)
+	case "Delete":
+		line = prefix + ": " + formatWhatIfValue(change.Before)
+	case "Modify", "NoEffect":
+		line = prefix + ": " + formatWhatIfValue(change.Before) + " => " + formatWhatIfValue(change.After)
+	default:
+		line = prefix + ":"
+	}
+	sb.WriteString(formatWhatIfChangeType(change.PropertyChangeType, line) + "\n")
+
+	for _, child := range change.Children {
+		renderWhatIfPropertyChange(sb, child, indent+2)
+	}
+}
+
+func formatWhatIfValue(value interface{}) string {
+	if value == nil {
+		return "null"
+	}
+	buf, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Sprintf("%v", value)
+	}
+	return string(buf)
+}
+
+// splitWhatIfResourceID splits a resource ID into the scope (subscription or resource group)
+// and the relative resource type/name
+func splitWhatIfResourceID(resourceID string) (string, string) {
+	index := strings.Index(strings.ToLower(resourceID), "/providers/")
+	if index < 0 {
+		return resourceID, resourceID
+	}
+	return resourceID[:index], resourceID[index+len("/providers/"):]
+}
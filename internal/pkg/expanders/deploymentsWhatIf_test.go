@@ -0,0 +1,69 @@
+package expanders
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/nbio/st"
+)
+
+func Test_Deployments_RenderWhatIfResult(t *testing.T) {
+	data := `{
+		"status": "Succeeded",
+		"properties": {
+			"changes": [
+				{
+					"resourceId": "/subscriptions/1/resourceGroups/rg1/providers/Microsoft.Web/sites/site1",
+					"changeType": "Modify",
+					"delta": [
+						{ "path": "properties.siteConfig.alwaysOn", "propertyChangeType": "Modify", "before": false, "after": true },
+						{ "path": "tags.env", "propertyChangeType": "Create", "after": "dev" }
+					]
+				},
+				{
+					"resourceId": "/subscriptions/1/resourceGroups/rg1/providers/Microsoft.Storage/storageAccounts/store1",
+					"changeType": "Create"
+				},
+				{
+					"resourceId": "/subscriptions/1/resourceGroups/rg1/providers/Microsoft.Storage/storageAccounts/store2",
+					"changeType": "Delete"
+				}
+			]
+		}
+	}`
+
+	result, err := renderWhatIfResult(data)
+	st.Expect(t, err, nil)
+
+	st.Expect(t, strings.Contains(result, "Scope: /subscriptions/1/resourceGroups/rg1"), true)
+	st.Expect(t, strings.Contains(result, "+ Microsoft.Storage/storageAccounts/store1"), true)
+	st.Expect(t, strings.Contains(result, "- Microsoft.Storage/storageAccounts/store2"), true)
+	st.Expect(t, strings.Contains(result, "~ Microsoft.Web/sites/site1"), true)
+	st.Expect(t, strings.Contains(result, "~ properties.siteConfig.alwaysOn: false => true"), true)
+	st.Expect(t, strings.Contains(result, `+ tags.env: "dev"`), true)
+	st.Expect(t, strings.Contains(result, "Resource changes: 1 create, 1 modify, 1 delete."), true)
+}
+
+func Test_Deployments_RenderWhatIfResult_Error(t *testing.T) {
+	data := `{"status": "Failed", "error": {"code": "InvalidTemplate", "message": "bad template"}}`
+
+	_, err := renderWhatIfResult(data)
+	st.Reject(t, err, nil)
+}
+
+func Test_Deployments_EditedParametersSurviveNodeRefresh(t *testing.T) {
+	expander := &DeploymentsExpander{}
+	deploymentID := "/subscriptions/1/resourceGroups/rg1/providers/Microsoft.Resources/deployments/dep1"
+
+	expander.setEditedParameters(deploymentID, `{"name": {"value": "edited"}}`)
+
+	// A refreshed list creates new nodes for the deployment so the edits must not live on the node
+	refreshedNode := &TreeNode{ID: strings.ToUpper(deploymentID), ItemType: deploymentType, Metadata: map[string]string{}}
+	actions := expander.ListActions(context.Background(), refreshedNode)
+	st.Expect(t, actions.Nodes[2].Name, "Edit Parameters (edited)")
+
+	expander.setEditedParameters(deploymentID, "")
+	actions = expander.ListActions(context.Background(), refreshedNode)
+	st.Expect(t, actions.Nodes[2].Name, "Edit Parameters")
+}
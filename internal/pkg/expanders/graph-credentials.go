@@ -0,0 +1,367 @@
+package expanders
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/lawrencegripper/azbrowse/internal/pkg/config"
+	"github.com/lawrencegripper/azbrowse/internal/pkg/eventing"
+	"github.com/lawrencegripper/azbrowse/internal/pkg/interfaces"
+	"github.com/lawrencegripper/azbrowse/internal/pkg/style"
+)
+
+// See https://docs.microsoft.com/en-us/graph/api/resources/passwordcredential
+// and https://docs.microsoft.com/en-us/graph/api/resources/keycredential
+
+const (
+	itemTypeCredentialReport = "graph/credentialexpiry"
+
+	actionExportCredentialReport = "exportcredentialreport"
+
+	graphDefaultCredentialExpiryDays = 30
+	graphCredentialScanSelect        = "$select=id,appId,displayName,passwordCredentials,keyCredentials&$top=999"
+)
+
+// graphCredentialScanTimeoutSeconds allows time to page through all of the apps and service principals in the tenant
+var graphCredentialScanTimeoutSeconds = 600
+
+// GraphCredential is a client secret (passwordCredential) or certificate (keyCredential) on an app or service principal
+type GraphCredential struct {
+	DisplayName   string     `json:"displayName"`
+	KeyID         string     `json:"keyId"`
+	EndDateTime   *time.Time `json:"endDateTime"`
+	StartDateTime *time.Time `json:"startDateTime"`
+}
+
+// GraphCredentialObject holds the credentials for an app or service principal
+type GraphCredentialObject struct {
+	ID                  string            `json:"id"`
+	AppID               string            `json:"appId"`
+	DisplayName         string            `json:"displayName"`
+	PasswordCredentials []GraphCredential `json:"passwordCredentials"`
+	KeyCredentials      []GraphCredential `json:"keyCredentials"`
+}
+
+// GraphCredentialObjectListResponse is a page of apps or service principals with their credentials
+type GraphCredentialObjectListResponse struct {
+	Items    []GraphCredentialObject `json:"value"`
+	NextLink string                  `json:"@odata.nextLink"`
+}
+
+// CredentialExpiryEntry is a row in the credential expiry report
+type CredentialExpiryEntry struct {
+	Kind           string // App or Service principal
+	URLRoot        string // itemTypeAppList or itemTypeSpList
+	ObjectID       string
+	AppID          string
+	DisplayName    string
+	CredentialType string // Secret or Certificate
+	CredentialName string
+	KeyID          string
+	Expires        time.Time
+	Owners         []string
+}
+
+func (entry CredentialExpiryEntry) getDaysRemaining(now time.Time) int {
+	return int(entry.Expires.Sub(now) / (24 * time.Hour))
+}
+
+func (entry CredentialExpiryEntry) getExpiryDisplay(now time.Time) string {
+	if entry.Expires.Before(now) {
+		return "EXPIRED " + entry.Expires.Format("2006-01-02")
+	}
+	days := entry.getDaysRemaining(now)
+	if days == 0 {
+		return "expires today"
+	}
+	return fmt.Sprintf("expires in %d days", days)
+}
+
+// getExpiringCredentials returns the credentials which have expired or expire before now+window
+func getExpiringCredentials(objects []GraphCredentialObject, urlRoot string, kind string, now time.Time, window time.Duration) []CredentialExpiryEntry {
+	entries := []CredentialExpiryEntry{}
+	cutOff := now.Add(window)
+	for _, object := range objects {
+		credentialsByType := []struct {
+			credentialType string
+			credentials    []GraphCredential
+		}{
+			{"Secret", object.PasswordCredentials},
+			{"Certificate", object.KeyCredentials},
+		}
+		for _, credentials := range credentialsByType {
+			for _, credential := range credentials.credentials {
+				if credential.EndDateTime == nil || credential.EndDateTime.After(cutOff) {
+					continue
+				}
+				entries = append(entries, CredentialExpiryEntry{
+					Kind:           kind,
+					URLRoot:        urlRoot,
+					ObjectID:       object.ID,
+					AppID:          object.AppID,
+					DisplayName:    object.DisplayName,
+					CredentialType: credentials.credentialType,
+					CredentialName: credential.DisplayName,
+					KeyID:          credential.KeyID,
+					Expires:        *credential.EndDateTime,
+				})
+			}
+		}
+	}
+	return entries
+}
+
+func sortCredentialExpiryEntries(entries []CredentialExpiryEntry) {
+	sort.SliceStable(entries, func(i, j int) bool {
+		return entries[i].Expires.Before(entries[j].Expires)
+	})
+}
+
+func renderCredentialExpiryReport(entries []CredentialExpiryEntry, now time.Time, days int) string {
+	if len(entries) == 0 {
+		return fmt.Sprintf("No secrets or certificates expire in the next %d days", days)
+	}
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("%d secrets and certificates expired or expiring in the next %d days\n\n", len(entries), days))
+	sb.WriteString(style.Title(fmt.Sprintf("%-12s %-6s %-18s %-12s %-30s %-24s %s", "EXPIRES", "DAYS", "KIND", "TYPE", "NAME", "CREDENTIAL", "OWNERS")) + "\n")
+	for _, entry := range entries {
+		daysRemaining := fmt.Sprintf("%-6d", entry.getDaysRemaining(now))
+		if entry.Expires.Before(now) {
+			daysRemaining = style.Warning(daysRemaining)
+		}
+		sb.WriteString(fmt.Sprintf("%-12s %s %-18s %-12s %-30s %-24s %s\n",
+			entry.Expires.Format("2006-01-02"),
+			daysRemaining,
+			entry.Kind,
+			entry.CredentialType,
+			entry.DisplayName,
+			entry.CredentialName,
+			style.Subtle(strings.Join(entry.Owners, ", "))))
+	}
+	return sb.String()
+}
+
+func writeCredentialExpiryCSV(writer io.Writer, entries []CredentialExpiryEntry, now time.Time) error {
+	csvWriter := csv.NewWriter(writer)
+	if err := csvWriter.Write([]string{"Expires", "DaysRemaining", "Kind", "DisplayName", "ObjectID", "AppID", "CredentialType", "CredentialName", "KeyID", "Owners"}); err != nil {
+		return err
+	}
+	for _, entry := range entries {
+		err := csvWriter.Write([]string{
+			entry.Expires.Format(time.RFC3339),
+			strconv.Itoa(entry.getDaysRemaining(now)),
+			entry.Kind,
+			entry.DisplayName,
+			entry.ObjectID,
+			entry.AppID,
+			entry.CredentialType,
+			entry.CredentialName,
+			entry.KeyID,
+			strings.Join(entry.Owners, "; "),
+		})
+		if err != nil {
+			return err
+		}
+	}
+	csvWriter.Flush()
+	return csvWriter.Error()
+}
+
+func getCredentialExpiryDays() int {
+	userConfig, err := config.Load()
+	if err != nil || userConfig.Graph.CredentialExpiryDays <= 0 {
+		return graphDefaultCredentialExpiryDays
+	}
+	return userConfig.Graph.CredentialExpiryDays
+}
+
+func (e *GraphExpander) getCredentialReportNode(currentItem *TreeNode) *TreeNode {
+	return &TreeNode{
+		Parentid:               currentItem.ID,
+		ID:                     "/credentialexpiry",
+		Namespace:              namespace,
+		Name:                   "Credential Expiry",
+		Display:                "Credential Expiry",
+		ItemType:               itemTypeCredentialReport,
+		ExpandURL:              ExpandURLNotSupported,
+		SuppressSwaggerExpand:  true,
+		SuppressGenericExpand:  true,
+		TimeoutOverrideSeconds: &graphCredentialScanTimeoutSeconds,
+	}
+}
+
+// scanCredentials pages through all of the apps and service principals returning the expiring credentials sorted by expiry
+func (e *GraphExpander) scanCredentials(ctx context.Context, now time.Time, days int) ([]CredentialExpiryEntry, error) {
+	event, _ := eventing.SendStatusEvent(&eventing.StatusEvent{
+		InProgress: true,
+		Message:    "Scanning app and service principal credentials",
+	})
+	defer event.Done()
+
+	window := time.Duration(days) * 24 * time.Hour
+	scans := []struct {
+		urlRoot string
+		kind    string
+	}{
+		{itemTypeAppList, "App"},
+		{itemTypeSpList, "Service principal"},
+	}
+	entries := []CredentialExpiryEntry{}
+	scanned := 0
+	for _, scan := range scans {
+		nextURL := scan.urlRoot + "?" + graphCredentialScanSelect
+		for nextURL != "" {
+			data, err := e.armClient.DoRequest(ctx, "GET", nextURL)
+			if err != nil {
+				return nil, fmt.Errorf("Failed to list %s credentials: %s", scan.urlRoot, err)
+			}
+			var response GraphCredentialObjectListResponse
+			if err := json.Unmarshal([]byte(data), &response); err != nil {
+				return nil, fmt.Errorf("Failed to deserialise %s credentials: %s", scan.urlRoot, err)
+			}
+			entries = append(entries, getExpiringCredentials(response.Items, scan.urlRoot, scan.kind, now, window)...)
+
+			scanned += len(response.Items)
+			event.Message = fmt.Sprintf("Scanning app and service principal credentials (%d scanned)", scanned)
+			event.Update()
+			nextURL = getGraphRelativeURL(response.NextLink)
+		}
+	}
+
+	// Only resolve owners for the objects in the report
+	ownersByObject := map[string][]string{}
+	for i := range entries {
+		objectURL := entries[i].URLRoot + "/" + entries[i].ObjectID
+		owners, ok := ownersByObject[objectURL]
+		if !ok {
+			owners = e.getOwnerNames(ctx, objectURL)
+			ownersByObject[objectURL] = owners
+		}
+		entries[i].Owners = owners
+	}
+
+	sortCredentialExpiryEntries(entries)
+	return entries, nil
+}
+
+// getOwnerNames returns the UPN (or display name) of the owners of an app or service principal
+func (e *GraphExpander) getOwnerNames(ctx context.Context, objectURL string) []string {
+	owners := []string{}
+	data, err := e.armClient.DoRequest(ctx, "GET", objectURL+"/owners?$select=id,displayName,userPrincipalName")
+	if err != nil {
+		return owners
+	}
+	var response DirectoryObjectListResponse
+	if err := json.Unmarshal([]byte(data), &response); err != nil {
+		return owners
+	}
+	for _, owner := range response.Items {
+		name := owner.UserPrincipalName
+		if name == "" {
+			name = owner.DisplayName
+		}
+		owners = append(owners, name)
+	}
+	return owners
+}
+
+func (e *GraphExpander) showCredentialReport(ctx context.Context, currentItem *TreeNode) ExpanderResult {
+	now := time.Now()
+	days := getCredentialExpiryDays()
+	entries, err := e.scanCredentials(ctx, now, days)
+	if err != nil {
+		return ExpanderResult{
+			SourceDescription: "GraphExpander credential expiry report",
+			Err:               err,
+			IsPrimaryResponse: true,
+		}
+	}
+
+	nodes := []*TreeNode{}
+	for _, entry := range entries {
+		detail := fmt.Sprintf("%s '%s' %s", entry.CredentialType, entry.CredentialName, entry.getExpiryDisplay(now))
+		if entry.Expires.Before(now) {
+			detail = style.Warning(detail)
+		} else {
+			detail = style.Subtle(detail)
+		}
+		nodes = append(nodes, &TreeNode{
+			Parentid:              currentItem.ID,
+			ID:                    entry.URLRoot + "/" + entry.ObjectID,
+			Namespace:             namespace,
+			Name:                  entry.DisplayName,
+			Display:               entry.DisplayName + "\n  " + detail,
+			ItemType:              itemTypeAppOrSp,
+			ExpandURL:             entry.URLRoot + "/" + entry.ObjectID,
+			DeleteURL:             entry.URLRoot + "/" + entry.ObjectID,
+			SuppressSwaggerExpand: true,
+			SuppressGenericExpand: true,
+		})
+	}
+
+	return ExpanderResult{
+		Response:          ExpanderResponse{Response: renderCredentialExpiryReport(entries, now, days), ResponseType: interfaces.ResponsePlainText},
+		SourceDescription: "GraphExpander credential expiry report",
+		Nodes:             nodes,
+		IsPrimaryResponse: true,
+	}
+}
+
+func (e *GraphExpander) exportCredentialReport(ctx context.Context, currentItem *TreeNode) ExpanderResult {
+	workingDir, err := os.Getwd()
+	if err != nil {
+		workingDir = os.TempDir()
+	}
+	defaultPath := filepath.Join(workingDir, fmt.Sprintf("credential-expiry-%s.csv", time.Now().Format("20060102-150405")))
+	path, err := promptForInput(ctx, e.gui, e.commandPanel, "export to file:", defaultPath)
+	if err != nil || strings.TrimSpace(path) == "" {
+		return ExpanderResult{
+			Err:               fmt.Errorf("User canceled"),
+			SourceDescription: "GraphExpander credential expiry export",
+			IsPrimaryResponse: true,
+		}
+	}
+	path = strings.TrimSpace(path)
+
+	now := time.Now()
+	entries, err := e.scanCredentials(ctx, now, getCredentialExpiryDays())
+	if err != nil {
+		return ExpanderResult{
+			Err:               err,
+			SourceDescription: "GraphExpander credential expiry export",
+			IsPrimaryResponse: true,
+		}
+	}
+
+	file, err := os.Create(path)
+	if err != nil {
+		return ExpanderResult{
+			Err:               err,
+			SourceDescription: "GraphExpander credential expiry export",
+			IsPrimaryResponse: true,
+		}
+	}
+	defer file.Close() //nolint: errcheck
+	if err := writeCredentialExpiryCSV(file, entries, now); err != nil {
+		return ExpanderResult{
+			Err:               fmt.Errorf("Error writing %s: %s", path, err),
+			SourceDescription: "GraphExpander credential expiry export",
+			IsPrimaryResponse: true,
+		}
+	}
+
+	return ExpanderResult{
+		Response:          ExpanderResponse{Response: fmt.Sprintf("Exported %d credentials to %s", len(entries), path), ResponseType: interfaces.ResponsePlainText},
+		SourceDescription: "GraphExpander credential expiry export",
+		IsPrimaryResponse: true,
+	}
+}
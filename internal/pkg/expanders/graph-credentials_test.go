@@ -0,0 +1,72 @@
+package expanders
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/nbio/st"
+)
+
+func Test_Graph_ExpiringCredentials(t *testing.T) {
+	now := time.Date(2021, 6, 1, 0, 0, 0, 0, time.UTC)
+	at := func(days int) *time.Time {
+		value := now.Add(time.Duration(days) * 24 * time.Hour)
+		return &value
+	}
+	objects := []GraphCredentialObject{
+		{
+			ID:          "app1",
+			DisplayName: "App One",
+			PasswordCredentials: []GraphCredential{
+				{DisplayName: "later", KeyID: "k1", EndDateTime: at(90)},
+				{DisplayName: "soon", KeyID: "k2", EndDateTime: at(10)},
+				{DisplayName: "noexpiry", KeyID: "k3"},
+			},
+			KeyCredentials: []GraphCredential{
+				{DisplayName: "cert", KeyID: "k4", EndDateTime: at(-5)},
+			},
+		},
+		{
+			ID:                  "app2",
+			DisplayName:         "App Two",
+			PasswordCredentials: []GraphCredential{{DisplayName: "sooner", KeyID: "k5", EndDateTime: at(2)}},
+		},
+	}
+
+	entries := getExpiringCredentials(objects, itemTypeAppList, "App", now, 30*24*time.Hour)
+	sortCredentialExpiryEntries(entries)
+	st.Expect(t, len(entries), 3)
+	st.Expect(t, entries[0].KeyID, "k4")
+	st.Expect(t, entries[0].CredentialType, "Certificate")
+	st.Expect(t, entries[0].getExpiryDisplay(now), "EXPIRED 2021-05-27")
+	st.Expect(t, entries[1].KeyID, "k5")
+	st.Expect(t, entries[1].getExpiryDisplay(now), "expires in 2 days")
+	st.Expect(t, entries[2].KeyID, "k2")
+	st.Expect(t, entries[2].URLRoot, itemTypeAppList)
+}
+
+func Test_Graph_CredentialExpiryCSV(t *testing.T) {
+	now := time.Date(2021, 6, 1, 0, 0, 0, 0, time.UTC)
+	entries := []CredentialExpiryEntry{
+		{
+			Kind:           "Service principal",
+			ObjectID:       "sp1",
+			AppID:          "a1",
+			DisplayName:    "api, v2",
+			CredentialType: "Secret",
+			CredentialName: "deploy",
+			KeyID:          "k1",
+			Expires:        now.Add(72 * time.Hour),
+			Owners:         []string{"bob@contoso.com", "alice@contoso.com"},
+		},
+	}
+	var buffer bytes.Buffer
+	err := writeCredentialExpiryCSV(&buffer, entries, now)
+	st.Expect(t, err, nil)
+
+	lines := strings.Split(strings.TrimSpace(buffer.String()), "\n")
+	st.Expect(t, len(lines), 2)
+	st.Expect(t, lines[1], `2021-06-04T00:00:00Z,3,Service principal,"api, v2",sp1,a1,Secret,deploy,k1,bob@contoso.com; alice@contoso.com`)
+}
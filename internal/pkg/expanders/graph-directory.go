@@ -0,0 +1,547 @@
+package expanders
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/lawrencegripper/azbrowse/internal/pkg/interfaces"
+	"github.com/lawrencegripper/azbrowse/internal/pkg/style"
+)
+
+// See https://docs.microsoft.com/en-us/graph/api/resources/user
+// and https://docs.microsoft.com/en-us/graph/api/resources/group
+
+const (
+	itemTypeUserList               = "graph/users"
+	itemTypeUser                   = "graph/user"
+	itemTypeGroupList              = "graph/groups"
+	itemTypeGroup                  = "graph/group"
+	itemTypeGroupMembers           = "graph/group/members"
+	itemTypeDirectoryRoleList      = "graph/directoryroles"
+	itemTypeDirectoryRole          = "graph/directoryrole"
+	itemTypeDirectoryObjectList    = "graph/directoryobjects"
+	itemTypeDirectoryObject        = "graph/directoryobject"
+	itemTypeUserAppRoleAssignments = "graph/user/approleassignments"
+
+	actionSearchUsers       = "searchusers"
+	actionSearchGroups      = "searchgroups"
+	actionAddGroupMember    = "addgroupmember"
+	actionRemoveGroupMember = "removegroupmember"
+
+	actionConfirmRemoveGroupMember = "confirmremovegroupmember"
+
+	graphBaseURL          = "https://graph.microsoft.com/v1.0"
+	graphListPageSize     = 100
+	graphDefaultAppRoleID = "00000000-0000-0000-0000-000000000000"
+)
+
+// graphPromptTimeoutSeconds allows time for the user to respond to the prompts
+var graphPromptTimeoutSeconds = 600
+
+// DirectoryObject holds the common properties of the users, groups, service principals etc returned by Graph
+type DirectoryObject struct {
+	ODataType         string `json:"@odata.type"`
+	ID                string `json:"id"`
+	DisplayName       string `json:"displayName"`
+	UserPrincipalName string `json:"userPrincipalName"`
+	Mail              string `json:"mail"`
+}
+
+// DirectoryObjectListResponse is a page of directory objects
+type DirectoryObjectListResponse struct {
+	Items    []DirectoryObject `json:"value"`
+	NextLink string            `json:"@odata.nextLink"`
+}
+
+// AppRoleAssignment is an app role granted to a user, group or service principal
+type AppRoleAssignment struct {
+	ID                  string `json:"id"`
+	AppRoleID           string `json:"appRoleId"`
+	ResourceID          string `json:"resourceId"`
+	ResourceDisplayName string `json:"resourceDisplayName"`
+}
+
+// AppRoleAssignmentListResponse is a list of app role assignments
+type AppRoleAssignmentListResponse struct {
+	Items []AppRoleAssignment `json:"value"`
+}
+
+// AppRole is a role defined by an application
+type AppRole struct {
+	ID          string `json:"id"`
+	DisplayName string `json:"displayName"`
+	Value       string `json:"value"`
+}
+
+// ServicePrincipalAppRolesResponse is used to resolve app role IDs for a service principal
+type ServicePrincipalAppRolesResponse struct {
+	AppRoles []AppRole `json:"appRoles"`
+}
+
+// buildGraphSearchQuery returns a $search query string parameter matching text in any of the properties
+// e.g. $search="displayName:bob" OR "userPrincipalName:bob"
+func buildGraphSearchQuery(properties []string, text string) string {
+	// quotes would terminate the search clause
+	text = strings.TrimSpace(strings.ReplaceAll(text, "\"", ""))
+	clauses := []string{}
+	for _, property := range properties {
+		clauses = append(clauses, fmt.Sprintf("%q", property+":"+text))
+	}
+	return "$search=" + strings.ReplaceAll(url.QueryEscape(strings.Join(clauses, " OR ")), "+", "%20")
+}
+
+// getGraphRelativeURL converts an absolute Graph URL (e.g. an @odata.nextLink) to the relative form used by the graph client
+func getGraphRelativeURL(graphURL string) string {
+	return strings.TrimPrefix(graphURL, graphBaseURL)
+}
+
+// resolveAppRoleName returns the display name for an app role ID
+func resolveAppRoleName(appRoles []AppRole, appRoleID string) string {
+	if appRoleID == graphDefaultAppRoleID {
+		return "Default Access"
+	}
+	for _, appRole := range appRoles {
+		if appRole.ID == appRoleID {
+			if appRole.DisplayName != "" {
+				return appRole.DisplayName
+			}
+			return appRole.Value
+		}
+	}
+	return appRoleID
+}
+
+// getDirectoryObjectKind returns a friendly name for the @odata.type of a directory object
+func getDirectoryObjectKind(object DirectoryObject) string {
+	switch object.ODataType {
+	case "#microsoft.graph.user":
+		return "User"
+	case "#microsoft.graph.group":
+		return "Group"
+	case "#microsoft.graph.servicePrincipal":
+		return "Service principal"
+	case "#microsoft.graph.application":
+		return "App"
+	case "#microsoft.graph.directoryRole":
+		return "Directory role"
+	case "#microsoft.graph.device":
+		return "Device"
+	}
+	return strings.TrimPrefix(object.ODataType, "#microsoft.graph.")
+}
+
+// newDirectoryObjectNode creates a node for a user, group or other directory object
+// linking to the node type for the object
+func newDirectoryObjectNode(parent *TreeNode, object DirectoryObject) *TreeNode {
+	name := object.DisplayName
+	if name == "" {
+		name = object.ID
+	}
+	detail := getDirectoryObjectKind(object)
+	if object.UserPrincipalName != "" {
+		detail = object.UserPrincipalName
+	}
+
+	node := &TreeNode{
+		Parentid:              parent.ID,
+		ID:                    "/directoryObjects/" + object.ID,
+		Namespace:             namespace,
+		Name:                  name,
+		Display:               name + "\n  " + style.Subtle(detail),
+		ItemType:              itemTypeDirectoryObject,
+		ExpandURL:             "/directoryObjects/" + object.ID,
+		SuppressSwaggerExpand: true,
+		SuppressGenericExpand: true,
+		Metadata: map[string]string{
+			"objectID": object.ID,
+		},
+	}
+	switch object.ODataType {
+	case "#microsoft.graph.user":
+		node.ID = "/users/" + object.ID
+		node.ItemType = itemTypeUser
+	case "#microsoft.graph.group":
+		node.ID = "/groups/" + object.ID
+		node.ItemType = itemTypeGroup
+	case "#microsoft.graph.servicePrincipal":
+		node.ID = itemTypeSpList + "/" + object.ID
+		node.ItemType = itemTypeAppOrSp
+	case "#microsoft.graph.application":
+		node.ID = itemTypeAppList + "/" + object.ID
+		node.ItemType = itemTypeAppOrSp
+	case "#microsoft.graph.directoryRole":
+		node.ID = "/directoryRoles/" + object.ID
+		node.ItemType = itemTypeDirectoryRole
+	}
+	node.ExpandURL = node.ID
+	return node
+}
+
+// newDirectoryObjectListNode creates a node listing objects related to the parent.
+// objectType is the @odata.type of the listed objects when the list is cast to a single type
+// (Graph omits @odata.type from the items in that case)
+func newDirectoryObjectListNode(parent *TreeNode, name string, itemType string, expandURL string, objectType string) *TreeNode {
+	return &TreeNode{
+		Parentid:              parent.ID,
+		ID:                    parent.ID + "/" + strings.ToLower(strings.ReplaceAll(name, " ", "")),
+		Namespace:             namespace,
+		Name:                  name,
+		Display:               name,
+		ItemType:              itemType,
+		ExpandURL:             expandURL,
+		SuppressSwaggerExpand: true,
+		SuppressGenericExpand: true,
+		Metadata: map[string]string{
+			"objectID":   parent.Metadata["objectID"],
+			"objectType": objectType,
+		},
+	}
+}
+
+func (e *GraphExpander) getDirectoryRootNodes(currentItem *TreeNode) []*TreeNode {
+	selectUsers := "$select=id,displayName,userPrincipalName&$top=" + strconv.Itoa(graphListPageSize)
+	selectGroups := "$select=id,displayName,mail&$top=" + strconv.Itoa(graphListPageSize)
+	return []*TreeNode{
+		{
+			Parentid:              currentItem.ID,
+			ID:                    "/users",
+			Namespace:             namespace,
+			Name:                  "Users",
+			Display:               "Users",
+			ItemType:              itemTypeUserList,
+			ExpandURL:             "/users?" + selectUsers,
+			SuppressSwaggerExpand: true,
+			SuppressGenericExpand: true,
+			Metadata: map[string]string{
+				"objectType": "#microsoft.graph.user",
+			},
+		},
+		{
+			Parentid:              currentItem.ID,
+			ID:                    "/groups",
+			Namespace:             namespace,
+			Name:                  "Groups",
+			Display:               "Groups",
+			ItemType:              itemTypeGroupList,
+			ExpandURL:             "/groups?" + selectGroups,
+			SuppressSwaggerExpand: true,
+			SuppressGenericExpand: true,
+			Metadata: map[string]string{
+				"objectType": "#microsoft.graph.group",
+			},
+		},
+		{
+			Parentid:              currentItem.ID,
+			ID:                    "/directoryRoles",
+			Namespace:             namespace,
+			Name:                  "Directory Roles",
+			Display:               "Directory Roles",
+			ItemType:              itemTypeDirectoryRoleList,
+			ExpandURL:             "/directoryRoles",
+			SuppressSwaggerExpand: true,
+			SuppressGenericExpand: true,
+			Metadata: map[string]string{
+				"objectType": "#microsoft.graph.directoryRole",
+			},
+		},
+	}
+}
+
+// listDirectoryObjects expands a list of users, groups, members etc. adding a "More..." node when there are further pages
+func (e *GraphExpander) listDirectoryObjects(ctx context.Context, currentItem *TreeNode) ExpanderResult {
+	// "More..." nodes are replaced by the expanded nodes so parent them to the list node
+	parent := currentItem
+	if currentItem.ExpandInPlace && currentItem.Parent != nil {
+		parent = currentItem.Parent
+	}
+	offset, _ := strconv.Atoi(currentItem.Metadata["offset"])
+	return e.listDirectoryObjectsFromURL(ctx, parent, currentItem.ExpandURL, offset, parent.Metadata["objectType"])
+}
+
+func (e *GraphExpander) listDirectoryObjectsFromURL(ctx context.Context, parent *TreeNode, listURL string, offset int, objectType string) ExpanderResult {
+	data, err := e.armClient.DoRequest(ctx, "GET", listURL)
+	if err != nil {
+		return ExpanderResult{
+			SourceDescription: "GraphExpander failed to list directory objects",
+			Err:               err,
+		}
+	}
+	var response DirectoryObjectListResponse
+	if err := json.Unmarshal([]byte(data), &response); err != nil {
+		return ExpanderResult{
+			SourceDescription: "GraphExpander failed to deserialise directory objects",
+			Err:               err,
+		}
+	}
+
+	nodes := []*TreeNode{}
+	for _, object := range response.Items {
+		if object.ODataType == "" {
+			object.ODataType = objectType
+		}
+		node := newDirectoryObjectNode(parent, object)
+		if parent.ItemType == itemTypeGroupMembers {
+			// direct members can be removed from the group
+			node.Metadata["groupID"] = parent.Metadata["objectID"]
+		}
+		nodes = append(nodes, node)
+	}
+	if response.NextLink != "" {
+		nextOffset := strconv.Itoa(offset + len(nodes))
+		nodes = append(nodes, &TreeNode{
+			Parentid:              parent.ID,
+			ID:                    parent.ID + "/...more" + nextOffset,
+			Namespace:             namespace,
+			Name:                  "More...",
+			Display:               "More...",
+			ItemType:              itemTypeDirectoryObjectList,
+			ExpandURL:             getGraphRelativeURL(response.NextLink),
+			ExpandInPlace:         true,
+			SuppressSwaggerExpand: true,
+			SuppressGenericExpand: true,
+			Metadata: map[string]string{
+				"offset": nextOffset,
+			},
+		})
+	}
+
+	return ExpanderResult{
+		Response:          ExpanderResponse{Response: data, ResponseType: interfaces.ResponseJSON},
+		SourceDescription: "GraphDirectoryExpander request",
+		Nodes:             nodes,
+		IsPrimaryResponse: true,
+	}
+}
+
+// showDirectoryObject shows a user, group or role along with the lists of related objects
+func (e *GraphExpander) showDirectoryObject(ctx context.Context, currentItem *TreeNode) ExpanderResult {
+	data, err := e.armClient.DoRequest(ctx, "GET", currentItem.ExpandURL)
+	if err != nil {
+		return ExpanderResult{
+			SourceDescription: "GraphExpander failed to show directory object",
+			Err:               err,
+		}
+	}
+
+	nodes := []*TreeNode{}
+	switch currentItem.ItemType {
+	case itemTypeUser:
+		nodes = append(nodes,
+			newDirectoryObjectListNode(currentItem, "Group memberships", itemTypeDirectoryObjectList, currentItem.ExpandURL+"/transitiveMemberOf/microsoft.graph.group", "#microsoft.graph.group"),
+			newDirectoryObjectListNode(currentItem, "App role assignments", itemTypeUserAppRoleAssignments, currentItem.ExpandURL+"/appRoleAssignments", ""))
+	case itemTypeGroup:
+		nodes = append(nodes,
+			newDirectoryObjectListNode(currentItem, "Members", itemTypeGroupMembers, currentItem.ExpandURL+"/members", ""),
+			newDirectoryObjectListNode(currentItem, "Transitive members", itemTypeDirectoryObjectList, currentItem.ExpandURL+"/transitiveMembers", ""),
+			newDirectoryObjectListNode(currentItem, "Nested groups", itemTypeDirectoryObjectList, currentItem.ExpandURL+"/transitiveMembers/microsoft.graph.group", "#microsoft.graph.group"),
+			newDirectoryObjectListNode(currentItem, "Owners", itemTypeDirectoryObjectList, currentItem.ExpandURL+"/owners", ""),
+			newDirectoryObjectListNode(currentItem, "Member of", itemTypeDirectoryObjectList, currentItem.ExpandURL+"/transitiveMemberOf", ""))
+	case itemTypeDirectoryRole:
+		nodes = append(nodes,
+			newDirectoryObjectListNode(currentItem, "Members", itemTypeDirectoryObjectList, currentItem.ExpandURL+"/members", ""))
+	}
+
+	return ExpanderResult{
+		Response:          ExpanderResponse{Response: data, ResponseType: interfaces.ResponseJSON},
+		SourceDescription: "GraphDirectoryExpander request",
+		Nodes:             nodes,
+		IsPrimaryResponse: true,
+	}
+}
+
+// listAppRoleAssignments lists the app roles assigned to a user, resolving the role names from the resource service principals
+func (e *GraphExpander) listAppRoleAssignments(ctx context.Context, currentItem *TreeNode) ExpanderResult {
+	data, err := e.armClient.DoRequest(ctx, "GET", currentItem.ExpandURL)
+	if err != nil {
+		return ExpanderResult{
+			SourceDescription: "GraphExpander failed to list app role assignments",
+			Err:               err,
+		}
+	}
+	var response AppRoleAssignmentListResponse
+	if err := json.Unmarshal([]byte(data), &response); err != nil {
+		return ExpanderResult{
+			SourceDescription: "GraphExpander failed to deserialise app role assignments",
+			Err:               err,
+		}
+	}
+
+	appRolesByResource := map[string][]AppRole{}
+	nodes := []*TreeNode{}
+	for _, assignment := range response.Items {
+		appRoles, ok := appRolesByResource[assignment.ResourceID]
+		if !ok {
+			// Fall back to showing the role ID if the service principal can't be read
+			spData, err := e.armClient.DoRequest(ctx, "GET", itemTypeSpList+"/"+assignment.ResourceID+"?$select=appRoles")
+			if err == nil {
+				var spResponse ServicePrincipalAppRolesResponse
+				if err := json.Unmarshal([]byte(spData), &spResponse); err == nil {
+					appRoles = spResponse.AppRoles
+				}
+			}
+			appRolesByResource[assignment.ResourceID] = appRoles
+		}
+
+		nodes = append(nodes, &TreeNode{
+			Parentid:              currentItem.ID,
+			ID:                    itemTypeSpList + "/" + assignment.ResourceID,
+			Namespace:             namespace,
+			Name:                  assignment.ResourceDisplayName,
+			Display:               assignment.ResourceDisplayName + "\n  " + style.Subtle("Role: "+resolveAppRoleName(appRoles, assignment.AppRoleID)),
+			ItemType:              itemTypeAppOrSp,
+			ExpandURL:             itemTypeSpList + "/" + assignment.ResourceID,
+			SuppressSwaggerExpand: true,
+			SuppressGenericExpand: true,
+		})
+	}
+
+	return ExpanderResult{
+		Response:          ExpanderResponse{Response: data, ResponseType: interfaces.ResponseJSON},
+		SourceDescription: "GraphDirectoryExpander request",
+		Nodes:             nodes,
+		IsPrimaryResponse: true,
+	}
+}
+
+func (e *GraphExpander) searchDirectoryObjects(ctx context.Context, currentItem *TreeNode, title string, properties []string) ExpanderResult {
+	queryText, err := promptForInput(ctx, e.gui, e.commandPanel, title, "")
+	if err != nil {
+		return ExpanderResult{
+			SourceDescription: "GraphExpander search request",
+			Err:               err,
+		}
+	}
+	if strings.TrimSpace(queryText) == "" {
+		return ExpanderResult{
+			SourceDescription: "GraphExpander search request",
+			Err:               fmt.Errorf("User canceled"),
+		}
+	}
+
+	listURL := currentItem.Parent.ExpandURL + "&" + buildGraphSearchQuery(properties, queryText)
+	return e.listDirectoryObjectsFromURL(ctx, currentItem, listURL, 0, currentItem.Parent.Metadata["objectType"])
+}
+
+// resolveMemberID resolves a user principal name or object ID to the object ID of the directory object
+func (e *GraphExpander) resolveMemberID(ctx context.Context, member string) (DirectoryObject, error) {
+	member = strings.TrimSpace(member)
+	objectURL := "/directoryObjects/" + url.PathEscape(member)
+	if strings.Contains(member, "@") {
+		objectURL = "/users/" + url.PathEscape(member)
+	}
+	data, err := e.armClient.DoRequest(ctx, "GET", objectURL)
+	if err != nil {
+		return DirectoryObject{}, fmt.Errorf("Failed to find %q: %s", member, err)
+	}
+	var object DirectoryObject
+	if err := json.Unmarshal([]byte(data), &object); err != nil {
+		return DirectoryObject{}, fmt.Errorf("Failed to deserialise %q: %s", member, err)
+	}
+	return object, nil
+}
+
+func (e *GraphExpander) addGroupMember(ctx context.Context, currentItem *TreeNode) ExpanderResult {
+	groupID := currentItem.Parent.Metadata["objectID"]
+	member, err := promptForInput(ctx, e.gui, e.commandPanel, "User principal name or object ID to add:", "")
+	if err != nil {
+		return ExpanderResult{
+			SourceDescription: "GraphExpander add member request",
+			Err:               err,
+			IsPrimaryResponse: true,
+		}
+	}
+	if strings.TrimSpace(member) == "" {
+		return ExpanderResult{
+			SourceDescription: "GraphExpander add member request",
+			Err:               fmt.Errorf("User canceled"),
+			IsPrimaryResponse: true,
+		}
+	}
+
+	object, err := e.resolveMemberID(ctx, member)
+	if err != nil {
+		return ExpanderResult{
+			SourceDescription: "GraphExpander add member request",
+			Err:               err,
+			IsPrimaryResponse: true,
+		}
+	}
+
+	body := fmt.Sprintf(`{"@odata.id": %q}`, graphBaseURL+"/directoryObjects/"+object.ID)
+	_, err = e.armClient.DoRequestWithBody(ctx, "POST", "/groups/"+groupID+"/members/$ref", body)
+	if err != nil {
+		return ExpanderResult{
+			SourceDescription: "GraphExpander add member request",
+			Err:               err,
+			IsPrimaryResponse: true,
+		}
+	}
+
+	return ExpanderResult{
+		Response:          ExpanderResponse{Response: fmt.Sprintf("Added %s (%s) to group %s", object.DisplayName, object.ID, groupID), ResponseType: interfaces.ResponsePlainText},
+		SourceDescription: "GraphExpander add member request",
+		IsPrimaryResponse: true,
+	}
+}
+
+// previewRemoveGroupMember shows the member and group that would be affected, with a 'Confirm' item to remove the member
+func (e *GraphExpander) previewRemoveGroupMember(ctx context.Context, currentItem *TreeNode) ExpanderResult {
+	memberItem := currentItem.Parent
+	groupID := memberItem.Metadata["groupID"]
+	memberID := memberItem.Metadata["objectID"]
+
+	var sb strings.Builder
+	sb.WriteString(style.Title("Remove from group") + "\n\n")
+	sb.WriteString(fmt.Sprintf("Member: %s (%s)\n", memberItem.Name, memberID))
+	sb.WriteString(fmt.Sprintf("Group:  %s\n", groupID))
+	sb.WriteString("\nOpen the 'Confirm' item to remove the member from the group.\n")
+
+	confirmName := "Confirm: remove " + memberItem.Name
+	nodes := []*TreeNode{
+		{
+			Parentid:              currentItem.ID,
+			ID:                    actionConfirmRemoveGroupMember,
+			Namespace:             namespace,
+			Name:                  confirmName,
+			Display:               confirmName + "\n   " + style.Subtle("from group "+groupID),
+			ItemType:              ActionType,
+			SuppressGenericExpand: true,
+			Metadata: map[string]string{
+				"groupID":    groupID,
+				"objectID":   memberID,
+				"memberName": memberItem.Name,
+			},
+		},
+	}
+
+	return ExpanderResult{
+		Response:          ExpanderResponse{Response: sb.String(), ResponseType: interfaces.ResponsePlainText},
+		SourceDescription: "GraphExpander remove member preview",
+		Nodes:             nodes,
+		IsPrimaryResponse: true,
+	}
+}
+
+func (e *GraphExpander) removeGroupMember(ctx context.Context, currentItem *TreeNode) ExpanderResult {
+	groupID := currentItem.Metadata["groupID"]
+	memberID := currentItem.Metadata["objectID"]
+
+	_, err := e.armClient.DoRequest(ctx, "DELETE", "/groups/"+groupID+"/members/"+memberID+"/$ref")
+	if err != nil {
+		return ExpanderResult{
+			SourceDescription: "GraphExpander remove member request",
+			Err:               err,
+			IsPrimaryResponse: true,
+		}
+	}
+
+	return ExpanderResult{
+		Response:          ExpanderResponse{Response: fmt.Sprintf("Removed %s (%s) from group %s", currentItem.Metadata["memberName"], memberID, groupID), ResponseType: interfaces.ResponsePlainText},
+		SourceDescription: "GraphExpander remove member request",
+		IsPrimaryResponse: true,
+	}
+}
@@ -0,0 +1,66 @@
+package expanders
+
+import (
+	"context"
+	"testing"
+
+	"github.com/nbio/st"
+)
+
+func Test_Graph_BuildSearchQuery(t *testing.T) {
+	query := buildGraphSearchQuery([]string{"userPrincipalName", "displayName"}, ` bob "smith" `)
+	st.Expect(t, query, "$search=%22userPrincipalName%3Abob%20smith%22%20OR%20%22displayName%3Abob%20smith%22")
+}
+
+func Test_Graph_RelativeURL(t *testing.T) {
+	st.Expect(t, getGraphRelativeURL("https://graph.microsoft.com/v1.0/users?$skiptoken=abc"), "/users?$skiptoken=abc")
+	st.Expect(t, getGraphRelativeURL("/users"), "/users")
+}
+
+func Test_Graph_ResolveAppRoleName(t *testing.T) {
+	appRoles := []AppRole{
+		{ID: "1", DisplayName: "Readers", Value: "Data.Read"},
+		{ID: "2", Value: "Data.Write"},
+	}
+	st.Expect(t, resolveAppRoleName(appRoles, "1"), "Readers")
+	st.Expect(t, resolveAppRoleName(appRoles, "2"), "Data.Write")
+	st.Expect(t, resolveAppRoleName(appRoles, "3"), "3")
+	st.Expect(t, resolveAppRoleName(nil, graphDefaultAppRoleID), "Default Access")
+}
+
+func Test_Graph_DirectoryObjectNode(t *testing.T) {
+	parent := &TreeNode{ID: "/groups/g1/members"}
+
+	user := newDirectoryObjectNode(parent, DirectoryObject{ODataType: "#microsoft.graph.user", ID: "u1", DisplayName: "Bob", UserPrincipalName: "bob@contoso.com"})
+	st.Expect(t, user.ItemType, itemTypeUser)
+	st.Expect(t, user.ExpandURL, "/users/u1")
+	st.Expect(t, user.Metadata["objectID"], "u1")
+
+	group := newDirectoryObjectNode(parent, DirectoryObject{ODataType: "#microsoft.graph.group", ID: "g2"})
+	st.Expect(t, group.ItemType, itemTypeGroup)
+	st.Expect(t, group.Name, "g2")
+
+	sp := newDirectoryObjectNode(parent, DirectoryObject{ODataType: "#microsoft.graph.servicePrincipal", ID: "sp1", DisplayName: "api"})
+	st.Expect(t, sp.ItemType, itemTypeAppOrSp)
+	st.Expect(t, sp.ExpandURL, "/serviceprincipals/sp1")
+	st.Expect(t, sp.DeleteURL, "")
+
+	device := newDirectoryObjectNode(parent, DirectoryObject{ODataType: "#microsoft.graph.device", ID: "d1"})
+	st.Expect(t, device.ItemType, itemTypeDirectoryObject)
+	st.Expect(t, device.ExpandURL, "/directoryObjects/d1")
+}
+
+func Test_Graph_RemoveGroupMemberNeedsConfirmation(t *testing.T) {
+	expander := &GraphExpander{}
+	member := &TreeNode{ID: "/groups/g1/members/u1", Name: "Bob", Metadata: map[string]string{"groupID": "g1", "objectID": "u1"}}
+	action := &TreeNode{ID: actionRemoveGroupMember, Parent: member}
+
+	// the first action only previews the removal (the expander has no client so a request would panic)
+	result := expander.ExecuteAction(context.Background(), action)
+	st.Expect(t, result.Err, nil)
+	st.Expect(t, len(result.Nodes), 1)
+	st.Expect(t, result.Nodes[0].ID, actionConfirmRemoveGroupMember)
+	st.Expect(t, result.Nodes[0].ItemType, ActionType)
+	st.Expect(t, result.Nodes[0].Metadata["groupID"], "g1")
+	st.Expect(t, result.Nodes[0].Metadata["objectID"], "u1")
+}
@@ -100,6 +100,14 @@ func (e *GraphExpander) Expand(ctx context.Context, currentItem *TreeNode) Expan
 		return e.listAppsOrSps(ctx, currentItem, "")
 	case itemTypeAppOrSp:
 		return e.showAppOrSp(ctx, currentItem)
+	case itemTypeUserList, itemTypeGroupList, itemTypeDirectoryRoleList, itemTypeGroupMembers, itemTypeDirectoryObjectList:
+		return e.listDirectoryObjects(ctx, currentItem)
+	case itemTypeUser, itemTypeGroup, itemTypeDirectoryRole, itemTypeDirectoryObject:
+		return e.showDirectoryObject(ctx, currentItem)
+	case itemTypeUserAppRoleAssignments:
+		return e.listAppRoleAssignments(ctx, currentItem)
+	case itemTypeCredentialReport:
+		return e.showCredentialReport(ctx, currentItem)
 	case "graph":
 		return e.listRootMenu(ctx, currentItem)
 	case "action":
@@ -135,6 +143,14 @@ func (e *GraphExpander) HasActions(context context.Context, currentItem *TreeNod
 	if currentItem.ItemType == itemTypeAppOrSp || currentItem.ItemType == itemTypeAppList || currentItem.ItemType == itemTypeSpList {
 		return true, nil
 	}
+	if currentItem.ItemType == itemTypeUserList || currentItem.ItemType == itemTypeGroupList ||
+		currentItem.ItemType == itemTypeGroup || currentItem.ItemType == itemTypeGroupMembers ||
+		currentItem.ItemType == itemTypeCredentialReport {
+		return true, nil
+	}
+	if currentItem.Metadata["groupID"] != "" {
+		return true, nil
+	}
 
 	return false, nil
 }
@@ -224,6 +240,75 @@ func (e *GraphExpander) ListActions(context context.Context, currentItem *TreeNo
 			})
 	}
 
+	if currentItem.ItemType == itemTypeUserList {
+		nodes = append(nodes,
+			&TreeNode{
+				Parentid:               currentItem.ID,
+				ID:                     actionSearchUsers,
+				Namespace:              namespace,
+				Name:                   "Search By UPN or Name",
+				Display:                "Search By UPN or Name",
+				ItemType:               ActionType,
+				SuppressGenericExpand:  true,
+				TimeoutOverrideSeconds: &graphPromptTimeoutSeconds,
+			})
+	}
+
+	if currentItem.ItemType == itemTypeGroupList {
+		nodes = append(nodes,
+			&TreeNode{
+				Parentid:               currentItem.ID,
+				ID:                     actionSearchGroups,
+				Namespace:              namespace,
+				Name:                   "Search By Name",
+				Display:                "Search By Name",
+				ItemType:               ActionType,
+				SuppressGenericExpand:  true,
+				TimeoutOverrideSeconds: &graphPromptTimeoutSeconds,
+			})
+	}
+
+	if currentItem.ItemType == itemTypeGroup || currentItem.ItemType == itemTypeGroupMembers {
+		nodes = append(nodes,
+			&TreeNode{
+				Parentid:               currentItem.ID,
+				ID:                     actionAddGroupMember,
+				Namespace:              namespace,
+				Name:                   "Add Member",
+				Display:                "Add Member",
+				ItemType:               ActionType,
+				SuppressGenericExpand:  true,
+				TimeoutOverrideSeconds: &graphPromptTimeoutSeconds,
+			})
+	}
+
+	if currentItem.ItemType == itemTypeCredentialReport {
+		nodes = append(nodes,
+			&TreeNode{
+				Parentid:               currentItem.ID,
+				ID:                     actionExportCredentialReport,
+				Namespace:              namespace,
+				Name:                   "Export to CSV",
+				Display:                "Export to CSV",
+				ItemType:               ActionType,
+				SuppressGenericExpand:  true,
+				TimeoutOverrideSeconds: &graphCredentialScanTimeoutSeconds,
+			})
+	}
+
+	if currentItem.Metadata["groupID"] != "" {
+		nodes = append(nodes,
+			&TreeNode{
+				Parentid:              currentItem.ID,
+				ID:                    actionRemoveGroupMember,
+				Namespace:             namespace,
+				Name:                  "Remove From Group",
+				Display:               "Remove From Group",
+				ItemType:              ActionType,
+				SuppressGenericExpand: true,
+			})
+	}
+
 	return ListActionsResult{
 		Nodes:             nodes,
 		SourceDescription: "GraphActionsExpander",
@@ -249,6 +334,18 @@ func (e *GraphExpander) ExecuteAction(ctx context.Context, currentItem *TreeNode
 		return e.listFilteredApps(ctx, currentItem, "/myorganization/me/ownedObjects/$/Microsoft.Graph.Application")
 	case actionListDeletedApps:
 		return e.listFilteredApps(ctx, currentItem, "/directory/deleteditems/Microsoft.Graph.Application")
+	case actionSearchUsers:
+		return e.searchDirectoryObjects(ctx, currentItem, "User principal name or display name:", []string{"userPrincipalName", "displayName"})
+	case actionSearchGroups:
+		return e.searchDirectoryObjects(ctx, currentItem, "Group name:", []string{"displayName", "mail"})
+	case actionAddGroupMember:
+		return e.addGroupMember(ctx, currentItem)
+	case actionRemoveGroupMember:
+		return e.previewRemoveGroupMember(ctx, currentItem)
+	case actionConfirmRemoveGroupMember:
+		return e.removeGroupMember(ctx, currentItem)
+	case actionExportCredentialReport:
+		return e.exportCredentialReport(ctx, currentItem)
 
 	}
 
@@ -295,6 +392,8 @@ func (e *GraphExpander) listRootMenu(ctx context.Context, currentItem *TreeNode)
 			SuppressGenericExpand: true,
 		},
 	}
+	baseItems = append(baseItems, e.getDirectoryRootNodes(currentItem)...)
+	baseItems = append(baseItems, e.getCredentialReportNode(currentItem))
 
 	return ExpanderResult{
 		Err:               nil,
@@ -3,11 +3,14 @@ package expanders
 import (
 	"context"
 	"encoding/json"
+	"fmt"
+	"math"
 	"net/url"
+	"strconv"
 	"strings"
 	"time"
 
-	"github.com/guptarohit/asciigraph"
+	"github.com/awesome-gocui/gocui"
 	"github.com/lawrencegripper/azbrowse/internal/pkg/interfaces"
 	"github.com/lawrencegripper/azbrowse/internal/pkg/style"
 
@@ -31,7 +34,9 @@ var _ Expander = &MetricsExpander{}
 // MetricsExpander expands the data-plane aspects of the Microsoft.Insights RP
 type MetricsExpander struct {
 	ExpanderBase
-	client *armclient.Client
+	client       *armclient.Client
+	gui          *gocui.Gui
+	commandPanel interfaces.CommandPanel
 }
 
 func (e *MetricsExpander) setClient(c *armclient.Client) {
@@ -134,29 +139,49 @@ func (e *MetricsExpander) expandMetricDefinition(ctx context.Context, currentIte
 		}
 	}
 
+	availableMetrics := []string{}
+	for _, metric := range metricsListResponse.Value {
+		availableMetrics = append(availableMetrics, metric.Name.Value)
+	}
+
 	newItems := []*TreeNode{}
 
 	for _, metric := range metricsListResponse.Value {
+		dimensions := []string{}
+		for _, dimension := range metric.Dimensions {
+			dimensions = append(dimensions, dimension.Value)
+		}
+		settings := metricsSettings{
+			ResourceID:            currentItem.Metadata["ResourceID"],
+			MetricNamespace:       metric.Namespace,
+			MetricNames:           []string{metric.Name.Value},
+			Timespan:              metricsDefaultTimespan,
+			Interval:              metricsDefaultInterval,
+			AggregationType:       strings.ToLower(metric.PrimaryAggregationType),
+			SupportedAggregations: metric.SupportedAggregationTypes,
+			Dimensions:            dimensions,
+			AvailableMetrics:      availableMetrics,
+			Units:                 strings.ToLower(metric.Unit),
+		}
+		expandURL, err := buildMetricsURL(settings, time.Now())
+		if err != nil {
+			return ExpanderResult{
+				Err:               err,
+				SourceDescription: "MetricsExpander build metrics URL",
+			}
+		}
 		newItems = append(newItems, &TreeNode{
-			Name:     metric.Name.Value,
-			Display:  metric.Name.Value + "\n  " + style.Subtle("Unit: "+metric.Unit),
-			ID:       currentItem.Metadata["ResourceID"] + "/providers/microsoft.Insights/metrics",
-			Parentid: currentItem.ID,
-			ExpandURL: currentItem.Metadata["ResourceID"] + "/providers/microsoft.Insights/metrics?timespan=" +
-				time.Now().UTC().Add(-4*time.Hour).Format("2006-01-02T15:04:05.000Z") + "/" +
-				time.Now().UTC().Format("2006-01-02T15:04:05.000Z") + "&interval=PT1M&metricnames=" +
-				url.QueryEscape(metric.Name.Value) + "&aggregation=" +
-				url.QueryEscape(metric.PrimaryAggregationType) +
-				"&metricNamespace=" + url.QueryEscape(metric.Namespace) +
-				"&autoadjusttimegrain=true&validatedimensions=false&api-version=2018-01-01",
+			Name:                  metric.Name.Value,
+			Display:               metric.Name.Value + "\n  " + style.Subtle("Unit: "+metric.Unit),
+			ID:                    currentItem.Metadata["ResourceID"] + "/providers/microsoft.Insights/metrics",
+			Parentid:              currentItem.ID,
+			ExpandURL:             expandURL,
 			ItemType:              "metrics.graph",
 			SubscriptionID:        currentItem.SubscriptionID,
 			SuppressSwaggerExpand: true,
 			SuppressGenericExpand: true,
-			Metadata: map[string]string{
-				"AggregationType": strings.ToLower(metric.PrimaryAggregationType),
-				"Units":           strings.ToLower(metric.Unit),
-			},
+			WatchIntervalSeconds:  metricsWatchIntervalSeconds,
+			Metadata:              settings.toMetadata(),
 		})
 	}
 
@@ -169,11 +194,25 @@ func (e *MetricsExpander) expandMetricDefinition(ctx context.Context, currentIte
 }
 
 func (e *MetricsExpander) expandGraph(ctx context.Context, currentItem *TreeNode) ExpanderResult {
+	settings := metricsSettingsFromMetadata(currentItem.Metadata)
+
+	// Build the URL when expanding so that the timespan is relative to now
+	expandURL, err := buildMetricsURL(settings, time.Now())
+	if err != nil {
+		return ExpanderResult{
+			Err:               err,
+			SourceDescription: "MetricsExpander build metrics URL",
+			IsPrimaryResponse: true,
+		}
+	}
+	currentItem.ExpandURL = expandURL
+
 	data, err := e.client.DoRequest(ctx, "GET", currentItem.ExpandURL)
 	if err != nil {
 		return ExpanderResult{
 			Err:               err,
 			SourceDescription: "MetricsExpander request metricDefinitions",
+			IsPrimaryResponse: true,
 		}
 	}
 
@@ -183,40 +222,395 @@ func (e *MetricsExpander) expandGraph(ctx context.Context, currentItem *TreeNode
 		return ExpanderResult{
 			Err:               err,
 			SourceDescription: "MetricsExpander graphdata failed to deserialise",
+			IsPrimaryResponse: true,
 		}
 	}
 
-	caption := style.Title(currentItem.Name) +
-		style.Subtle(" (Aggregate: '"+currentItem.Metadata["AggregationType"]+"' Unit: '"+
-			currentItem.Metadata["Units"]+"')")
-
-	graphData := []float64{}
+	series := getMetricSeries(metricResponse, settings.AggregationType)
 
 	// handle empty response
-	if len(metricResponse.Value) < 1 || len(metricResponse.Value[0].Timeseries) < 1 {
+	if len(series) < 1 {
 		return ExpanderResult{
-			Err:               err,
+			Err:               fmt.Errorf("No data returned for metric(s) %s", strings.Join(settings.MetricNames, ", ")),
 			SourceDescription: "MetricsExpander graphdata failed to deserialise",
+			IsPrimaryResponse: true,
 		}
 	}
 
-	for _, datapoint := range metricResponse.Value[0].Timeseries[0].Data {
-		value, success := datapoint[currentItem.Metadata["AggregationType"]].(float64)
-		if success {
-			graphData = append(graphData, value)
-		} else {
-			graphData = append(graphData, float64(0))
-		}
+	title := style.Title(strings.Join(settings.MetricNames, ", "))
+	if settings.SplitBy != "" {
+		title += style.Subtle(" split by " + settings.SplitBy)
+	}
+	interval := settings.Interval
+	if metricResponse.Interval != "" {
+		interval = metricResponse.Interval
 	}
+	title += style.Subtle(" (Aggregate: '" + settings.AggregationType + "' Unit: '" + settings.Units + "' Interval: '" + interval + "')")
 
-	graph := asciigraph.Plot(graphData,
-		asciigraph.Height(ItemWidgetHeight-6),
-		asciigraph.Width(ItemWidgetWidth-15),
-		asciigraph.Caption("time: 4hrs ago ----> now"))
+	chart := renderMetricsChart(series,
+		ItemWidgetWidth-15,
+		ItemWidgetHeight-8-len(series),
+		"time: "+settings.Timespan+" ago ----> now")
 
 	return ExpanderResult{
-		Response:          ExpanderResponse{Response: "\n\n" + caption + "\n\n" + style.Graph(graph)},
+		Response:          ExpanderResponse{Response: "\n\n" + title + "\n\n" + chart},
 		IsPrimaryResponse: true,
 		SourceDescription: "MetricsExpander build graph",
 	}
 }
+
+// getMetricSeries converts the response into a series per metric and dimension value
+func getMetricSeries(metricResponse armclient.MetricResponse, aggregationType string) []metricSeries {
+	series := []metricSeries{}
+	for _, metric := range metricResponse.Value {
+		for _, timeseries := range metric.Timeseries {
+			name := metric.Name.Value
+			dimensionValues := []string{}
+			for _, metadataValue := range timeseries.Metadatavalues {
+				dimensionValues = append(dimensionValues, metadataValue.Value)
+			}
+			if len(dimensionValues) > 0 {
+				name += " [" + strings.Join(dimensionValues, ", ") + "]"
+			}
+
+			values := []float64{}
+			for _, datapoint := range timeseries.Data {
+				value, success := datapoint[aggregationType].(float64)
+				if success {
+					values = append(values, value)
+				} else {
+					values = append(values, math.NaN())
+				}
+			}
+			series = append(series, metricSeries{Name: name, Values: values})
+		}
+	}
+	return series
+}
+
+// HasActions returns true for metric graphs
+func (e *MetricsExpander) HasActions(ctx context.Context, item *TreeNode) (bool, error) {
+	return item.ItemType == "metrics.graph", nil
+}
+
+// ListActions returns the actions to change what the graph shows
+func (e *MetricsExpander) ListActions(ctx context.Context, item *TreeNode) ListActionsResult {
+	newAction := func(actionID string, name string) *TreeNode {
+		return &TreeNode{
+			Parentid:               item.ID,
+			ID:                     item.ID + "?" + actionID,
+			Name:                   name,
+			Display:                name,
+			ItemType:               ActionType,
+			SuppressGenericExpand:  true,
+			TimeoutOverrideSeconds: &metricsPromptTimeoutSeconds,
+			Metadata: map[string]string{
+				"ActionID": actionID,
+			},
+		}
+	}
+
+	nodes := []*TreeNode{
+		newAction(metricsActionTimeRange, "Time range & aggregation"),
+	}
+	if item.Metadata["Dimensions"] != "" {
+		nodes = append(nodes, newAction(metricsActionSplit, "Split by dimension"))
+	}
+	nodes = append(nodes, newAction(metricsActionOverlay, "Overlay metrics"))
+
+	return ListActionsResult{
+		Nodes:             nodes,
+		SourceDescription: "MetricsExpander",
+		IsPrimaryResponse: true,
+	}
+}
+
+// ExecuteAction prompts for the new graph settings and redraws the graph
+func (e *MetricsExpander) ExecuteAction(ctx context.Context, item *TreeNode) ExpanderResult {
+	actionID := item.Metadata["ActionID"]
+
+	if item.Parent == nil {
+		return ExpanderResult{
+			SourceDescription: "MetricsExpander",
+			IsPrimaryResponse: true,
+			Err:               fmt.Errorf("Graph not set on action: %q", item.ID),
+		}
+	}
+
+	settings := metricsSettingsFromMetadata(item.Parent.Metadata)
+	var err error
+	switch actionID {
+	case metricsActionTimeRange:
+		err = e.promptForTimeRange(ctx, &settings)
+	case metricsActionSplit:
+		err = e.promptForSplit(ctx, &settings)
+	case metricsActionOverlay:
+		err = e.promptForOverlay(ctx, &settings)
+	case "":
+		err = fmt.Errorf("ActionID metadata not set: %q", item.ID)
+	default:
+		err = fmt.Errorf("Unhandled ActionID: %q", actionID)
+	}
+	if err != nil {
+		return ExpanderResult{
+			SourceDescription: "MetricsExpander",
+			IsPrimaryResponse: true,
+			Err:               err,
+		}
+	}
+
+	for key, value := range settings.toMetadata() {
+		item.Parent.Metadata[key] = value
+	}
+	return e.expandGraph(ctx, item.Parent)
+}
+
+func (e *MetricsExpander) promptForTimeRange(ctx context.Context, settings *metricsSettings) error {
+	timespan, err := promptForInput(ctx, e.gui, e.commandPanel, "Timespan (e.g. 30m, 4h, 7d)", settings.Timespan)
+	if err != nil {
+		return err
+	}
+	timespan = strings.TrimSpace(timespan)
+	if _, err := parseMetricsTimespan(timespan); err != nil {
+		return err
+	}
+
+	interval, err := promptForInput(ctx, e.gui, e.commandPanel,
+		"Interval ("+strings.Join(append([]string{metricsDefaultInterval}, metricsIntervals...), ", ")+")", settings.Interval)
+	if err != nil {
+		return err
+	}
+	interval = strings.ToUpper(strings.TrimSpace(interval))
+	if strings.EqualFold(interval, metricsDefaultInterval) {
+		interval = metricsDefaultInterval
+	} else if !containsString(metricsIntervals, interval) {
+		return fmt.Errorf("Unsupported interval %q, expected one of %s", interval, strings.Join(metricsIntervals, ", "))
+	}
+
+	aggregationTitle := "Aggregation"
+	if len(settings.SupportedAggregations) > 0 {
+		aggregationTitle += " (" + strings.Join(settings.SupportedAggregations, ", ") + ")"
+	}
+	aggregation, err := promptForInput(ctx, e.gui, e.commandPanel, aggregationTitle, settings.AggregationType)
+	if err != nil {
+		return err
+	}
+	aggregation = strings.ToLower(strings.TrimSpace(aggregation))
+	if len(settings.SupportedAggregations) > 0 && !containsStringIgnoreCase(settings.SupportedAggregations, aggregation) {
+		return fmt.Errorf("Unsupported aggregation %q, expected one of %s", aggregation, strings.Join(settings.SupportedAggregations, ", "))
+	}
+
+	settings.Timespan = timespan
+	settings.Interval = interval
+	settings.AggregationType = aggregation
+	return nil
+}
+
+func (e *MetricsExpander) promptForSplit(ctx context.Context, settings *metricsSettings) error {
+	dimension, err := promptForInput(ctx, e.gui, e.commandPanel,
+		"Split by dimension ("+strings.Join(settings.Dimensions, ", ")+") - leave empty to remove the split", settings.SplitBy)
+	if err != nil {
+		return err
+	}
+	dimension = strings.TrimSpace(dimension)
+	if dimension == "" {
+		settings.SplitBy = ""
+		return nil
+	}
+	for _, available := range settings.Dimensions {
+		if strings.EqualFold(available, dimension) {
+			settings.SplitBy = available
+			return nil
+		}
+	}
+	return fmt.Errorf("Unknown dimension %q, expected one of %s", dimension, strings.Join(settings.Dimensions, ", "))
+}
+
+func (e *MetricsExpander) promptForOverlay(ctx context.Context, settings *metricsSettings) error {
+	input, err := promptForInput(ctx, e.gui, e.commandPanel,
+		"Metrics to show (comma separated)", strings.Join(settings.MetricNames, ","))
+	if err != nil {
+		return err
+	}
+	metricNames := []string{}
+	for _, name := range strings.Split(input, ",") {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			continue
+		}
+		found := false
+		for _, available := range settings.AvailableMetrics {
+			if strings.EqualFold(available, name) {
+				metricNames = append(metricNames, available)
+				found = true
+				break
+			}
+		}
+		if !found && len(settings.AvailableMetrics) > 0 {
+			return fmt.Errorf("Unknown metric %q in namespace %q", name, settings.MetricNamespace)
+		}
+		if !found {
+			metricNames = append(metricNames, name)
+		}
+	}
+	if len(metricNames) == 0 {
+		return fmt.Errorf("No metrics specified")
+	}
+	settings.MetricNames = metricNames
+	return nil
+}
+
+const (
+	metricsActionTimeRange = "metrics-time-range"
+	metricsActionSplit     = "metrics-split"
+	metricsActionOverlay   = "metrics-overlay"
+
+	metricsDefaultTimespan = "4h"
+	metricsDefaultInterval = "auto"
+)
+
+// metricsPromptTimeoutSeconds allows time for the user to respond to the prompts
+var metricsPromptTimeoutSeconds = 300
+
+// metricsIntervals are the time grains supported by the metrics API
+var metricsIntervals = []string{"PT1M", "PT5M", "PT15M", "PT30M", "PT1H", "PT6H", "PT12H", "P1D"}
+
+// metricsSettings controls what is shown on a metrics graph. It is stored in the node metadata
+type metricsSettings struct {
+	ResourceID            string
+	MetricNamespace       string
+	MetricNames           []string
+	Timespan              string
+	Interval              string
+	AggregationType       string
+	SupportedAggregations []string
+	Dimensions            []string
+	AvailableMetrics      []string
+	SplitBy               string
+	Units                 string
+}
+
+func metricsSettingsFromMetadata(metadata map[string]string) metricsSettings {
+	split := func(value string) []string {
+		if value == "" {
+			return []string{}
+		}
+		return strings.Split(value, ",")
+	}
+	settings := metricsSettings{
+		ResourceID:            metadata["ResourceID"],
+		MetricNamespace:       metadata["MetricNamespace"],
+		MetricNames:           split(metadata["MetricNames"]),
+		Timespan:              metadata["Timespan"],
+		Interval:              metadata["Interval"],
+		AggregationType:       metadata["AggregationType"],
+		SupportedAggregations: split(metadata["SupportedAggregations"]),
+		Dimensions:            split(metadata["Dimensions"]),
+		AvailableMetrics:      split(metadata["AvailableMetrics"]),
+		SplitBy:               metadata["SplitBy"],
+		Units:                 metadata["Units"],
+	}
+	if settings.Timespan == "" {
+		settings.Timespan = metricsDefaultTimespan
+	}
+	if settings.Interval == "" {
+		settings.Interval = metricsDefaultInterval
+	}
+	return settings
+}
+
+func (s metricsSettings) toMetadata() map[string]string {
+	return map[string]string{
+		"ResourceID":            s.ResourceID,
+		"MetricNamespace":       s.MetricNamespace,
+		"MetricNames":           strings.Join(s.MetricNames, ","),
+		"Timespan":              s.Timespan,
+		"Interval":              s.Interval,
+		"AggregationType":       s.AggregationType,
+		"SupportedAggregations": strings.Join(s.SupportedAggregations, ","),
+		"Dimensions":            strings.Join(s.Dimensions, ","),
+		"AvailableMetrics":      strings.Join(s.AvailableMetrics, ","),
+		"SplitBy":               s.SplitBy,
+		"Units":                 s.Units,
+	}
+}
+
+// parseMetricsTimespan parses durations such as 30m, 4h or 7d
+func parseMetricsTimespan(timespan string) (time.Duration, error) {
+	if len(timespan) < 2 {
+		return 0, fmt.Errorf("Invalid timespan %q, expected a number followed by m, h or d (e.g. 4h)", timespan)
+	}
+	value, err := strconv.Atoi(timespan[:len(timespan)-1])
+	if err != nil || value <= 0 {
+		return 0, fmt.Errorf("Invalid timespan %q, expected a number followed by m, h or d (e.g. 4h)", timespan)
+	}
+	switch strings.ToLower(timespan[len(timespan)-1:]) {
+	case "m":
+		return time.Duration(value) * time.Minute, nil
+	case "h":
+		return time.Duration(value) * time.Hour, nil
+	case "d":
+		return time.Duration(value) * 24 * time.Hour, nil
+	}
+	return 0, fmt.Errorf("Invalid timespan %q, expected a number followed by m, h or d (e.g. 4h)", timespan)
+}
+
+// getAutoMetricsInterval picks an interval that gives a reasonable number of points for the timespan
+func getAutoMetricsInterval(duration time.Duration) string {
+	switch {
+	case duration <= 6*time.Hour:
+		return "PT1M"
+	case duration <= 24*time.Hour:
+		return "PT5M"
+	case duration <= 3*24*time.Hour:
+		return "PT15M"
+	case duration <= 7*24*time.Hour:
+		return "PT30M"
+	case duration <= 14*24*time.Hour:
+		return "PT1H"
+	default:
+		return "PT6H"
+	}
+}
+
+// buildMetricsURL builds the URL to query the metrics for the graph ending at now
+func buildMetricsURL(settings metricsSettings, now time.Time) (string, error) {
+	duration, err := parseMetricsTimespan(settings.Timespan)
+	if err != nil {
+		return "", err
+	}
+	interval := settings.Interval
+	if interval == metricsDefaultInterval {
+		interval = getAutoMetricsInterval(duration)
+	}
+	now = now.UTC()
+
+	metricsURL := settings.ResourceID + "/providers/microsoft.Insights/metrics?timespan=" +
+		now.Add(-duration).Format("2006-01-02T15:04:05.000Z") + "/" +
+		now.Format("2006-01-02T15:04:05.000Z") + "&interval=" + interval +
+		"&metricnames=" + url.QueryEscape(strings.Join(settings.MetricNames, ",")) +
+		"&aggregation=" + url.QueryEscape(settings.AggregationType) +
+		"&metricNamespace=" + url.QueryEscape(settings.MetricNamespace)
+	if settings.SplitBy != "" {
+		metricsURL += "&$filter=" + url.QueryEscape(settings.SplitBy+" eq '*'")
+	}
+	return metricsURL + "&autoadjusttimegrain=true&validatedimensions=false&api-version=2018-01-01", nil
+}
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+func containsStringIgnoreCase(values []string, value string) bool {
+	for _, v := range values {
+		if strings.EqualFold(v, value) {
+			return true
+		}
+	}
+	return false
+}
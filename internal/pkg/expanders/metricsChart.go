@@ -0,0 +1,186 @@
+package expanders
+
+import (
+	"fmt"
+	"math"
+	"strings"
+
+	"github.com/guptarohit/asciigraph"
+	"github.com/lawrencegripper/azbrowse/internal/pkg/style"
+)
+
+// metricSeries is a single line on a metrics chart. Missing data points are NaN
+type metricSeries struct {
+	Name   string
+	Values []float64
+}
+
+// metricSeriesStats summarises the data points in a series, ignoring missing points
+type metricSeriesStats struct {
+	Min   float64
+	Max   float64
+	Avg   float64
+	Count int
+}
+
+func getMetricSeriesStats(values []float64) metricSeriesStats {
+	stats := metricSeriesStats{
+		Min: math.Inf(1),
+		Max: math.Inf(-1),
+	}
+	total := 0.0
+	for _, value := range values {
+		if math.IsNaN(value) {
+			continue
+		}
+		stats.Min = math.Min(stats.Min, value)
+		stats.Max = math.Max(stats.Max, value)
+		total += value
+		stats.Count++
+	}
+	if stats.Count == 0 {
+		return metricSeriesStats{Min: math.NaN(), Max: math.NaN(), Avg: math.NaN()}
+	}
+	stats.Avg = total / float64(stats.Count)
+	return stats
+}
+
+// renderMetricsChart plots the series with a legend showing the min/max/avg for each series
+func renderMetricsChart(series []metricSeries, width int, height int, caption string) string {
+	var chart string
+	if len(series) == 1 {
+		// asciigraph gives a smoother line but (in the version we use) can only plot a single series
+		values := make([]float64, len(series[0].Values))
+		for i, value := range series[0].Values {
+			if math.IsNaN(value) {
+				value = 0
+			}
+			values[i] = value
+		}
+		chart = style.Graph(asciigraph.Plot(values,
+			asciigraph.Height(height),
+			asciigraph.Width(width),
+			asciigraph.Caption(caption)))
+	} else {
+		chart = plotMultipleSeries(series, width, height) + "\n" + strings.Repeat(" ", 10) + caption
+	}
+
+	nameWidth := 0
+	for _, s := range series {
+		if len(s.Name) > nameWidth {
+			nameWidth = len(s.Name)
+		}
+	}
+
+	var sb strings.Builder
+	sb.WriteString(chart)
+	sb.WriteString("\n\n")
+	for i, s := range series {
+		stats := getMetricSeriesStats(s.Values)
+		sb.WriteString(fmt.Sprintf("  %s %-*s   min %s   max %s   avg %s\n",
+			style.Series(i, "■"),
+			nameWidth, s.Name,
+			formatMetricValue(stats.Min),
+			formatMetricValue(stats.Max),
+			formatMetricValue(stats.Avg)))
+	}
+	return sb.String()
+}
+
+func formatMetricValue(value float64) string {
+	if math.IsNaN(value) {
+		return fmt.Sprintf("%10s", "-")
+	}
+	return fmt.Sprintf("%10.2f", value)
+}
+
+// plotMultipleSeries draws the series on a shared y-axis, one color per series
+func plotMultipleSeries(series []metricSeries, width int, height int) string {
+	if width < 2 {
+		width = 2
+	}
+	if height < 2 {
+		height = 2
+	}
+
+	minValue := math.Inf(1)
+	maxValue := math.Inf(-1)
+	for _, s := range series {
+		stats := getMetricSeriesStats(s.Values)
+		if stats.Count == 0 {
+			continue
+		}
+		minValue = math.Min(minValue, stats.Min)
+		maxValue = math.Max(maxValue, stats.Max)
+	}
+	if math.IsInf(minValue, 1) {
+		minValue, maxValue = 0, 1
+	}
+	if maxValue == minValue {
+		maxValue = minValue + 1
+	}
+
+	type cell struct {
+		char   rune
+		series int
+	}
+	grid := make([][]cell, height)
+	for row := range grid {
+		grid[row] = make([]cell, width)
+		for column := range grid[row] {
+			grid[row][column] = cell{char: ' ', series: -1}
+		}
+	}
+
+	getRow := func(value float64) int {
+		return int(math.Round((maxValue - value) / (maxValue - minValue) * float64(height-1)))
+	}
+
+	for seriesIndex, s := range series {
+		if len(s.Values) == 0 {
+			continue
+		}
+		previousRow := -1
+		for column := 0; column < width; column++ {
+			// Stretch or squash the series to fit the width
+			index := int(math.Round(float64(column) * float64(len(s.Values)-1) / float64(width-1)))
+			value := s.Values[index]
+			if math.IsNaN(value) {
+				previousRow = -1
+				continue
+			}
+			row := getRow(value)
+			if previousRow >= 0 && previousRow != row {
+				// Join to the previous point with a vertical line
+				step := 1
+				if row < previousRow {
+					step = -1
+				}
+				for r := previousRow + step; r != row; r += step {
+					if grid[r][column].series < 0 {
+						grid[r][column] = cell{char: '│', series: seriesIndex}
+					}
+				}
+			}
+			grid[row][column] = cell{char: '•', series: seriesIndex}
+			previousRow = row
+		}
+	}
+
+	var sb strings.Builder
+	for row := 0; row < height; row++ {
+		label := maxValue - float64(row)*(maxValue-minValue)/float64(height-1)
+		sb.WriteString(fmt.Sprintf("%10.2f ┤", label))
+		for _, c := range grid[row] {
+			if c.series < 0 {
+				sb.WriteRune(c.char)
+				continue
+			}
+			sb.WriteString(style.Series(c.series, string(c.char)))
+		}
+		if row < height-1 {
+			sb.WriteString("\n")
+		}
+	}
+	return sb.String()
+}
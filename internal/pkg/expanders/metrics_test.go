@@ -0,0 +1,73 @@
+package expanders
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/nbio/st"
+)
+
+func Test_Metrics_ParseTimespan(t *testing.T) {
+	duration, err := parseMetricsTimespan("30m")
+	st.Expect(t, err, nil)
+	st.Expect(t, duration, 30*time.Minute)
+
+	duration, err = parseMetricsTimespan("7d")
+	st.Expect(t, err, nil)
+	st.Expect(t, duration, 7*24*time.Hour)
+
+	_, err = parseMetricsTimespan("4x")
+	st.Reject(t, err, nil)
+	_, err = parseMetricsTimespan("h")
+	st.Reject(t, err, nil)
+}
+
+func Test_Metrics_BuildURL(t *testing.T) {
+	now, _ := time.Parse(time.RFC3339, "2020-06-01T12:00:00Z")
+	settings := metricsSettings{
+		ResourceID:      "/subscriptions/1/resourceGroups/rg/providers/Microsoft.Storage/storageAccounts/sa",
+		MetricNamespace: "Microsoft.Storage/storageAccounts",
+		MetricNames:     []string{"Ingress", "Egress"},
+		Timespan:        "1d",
+		Interval:        metricsDefaultInterval,
+		AggregationType: "total",
+		SplitBy:         "ApiName",
+	}
+
+	metricsURL, err := buildMetricsURL(settings, now)
+
+	st.Expect(t, err, nil)
+	st.Expect(t, metricsURL, "/subscriptions/1/resourceGroups/rg/providers/Microsoft.Storage/storageAccounts/sa/providers/microsoft.Insights/metrics"+
+		"?timespan=2020-05-31T12:00:00.000Z/2020-06-01T12:00:00.000Z&interval=PT5M&metricnames=Ingress%2CEgress&aggregation=total"+
+		"&metricNamespace=Microsoft.Storage%2FstorageAccounts&$filter=ApiName+eq+%27%2A%27"+
+		"&autoadjusttimegrain=true&validatedimensions=false&api-version=2018-01-01")
+}
+
+func Test_Metrics_SettingsRoundTrip(t *testing.T) {
+	settings := metricsSettings{
+		MetricNames: []string{"a", "b"},
+		Timespan:    "2h",
+		Interval:    "PT5M",
+		Dimensions:  []string{},
+	}
+
+	result := metricsSettingsFromMetadata(settings.toMetadata())
+
+	st.Expect(t, result.MetricNames, []string{"a", "b"})
+	st.Expect(t, result.Timespan, "2h")
+	st.Expect(t, result.Interval, "PT5M")
+	st.Expect(t, len(result.Dimensions), 0)
+}
+
+func Test_Metrics_SeriesStatsIgnoreMissingValues(t *testing.T) {
+	stats := getMetricSeriesStats([]float64{2, math.NaN(), 4, 6})
+
+	st.Expect(t, stats.Count, 3)
+	st.Expect(t, stats.Min, 2.0)
+	st.Expect(t, stats.Max, 6.0)
+	st.Expect(t, stats.Avg, 4.0)
+
+	empty := getMetricSeriesStats([]float64{math.NaN()})
+	st.Assert(t, math.IsNaN(empty.Avg), true)
+}
@@ -0,0 +1,611 @@
+package expanders
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/lawrencegripper/azbrowse/internal/pkg/eventing"
+	"github.com/lawrencegripper/azbrowse/internal/pkg/interfaces"
+	"github.com/lawrencegripper/azbrowse/internal/pkg/style"
+	"github.com/lawrencegripper/azbrowse/pkg/armclient"
+)
+
+const (
+	networkInterfaceTemplate = "/subscriptions/{subscriptionId}/resourceGroups/{resourceGroupName}/providers/Microsoft.Network/networkInterfaces/{networkInterfaceName}"
+	networkNamespace         = "network"
+	networkAPIVersion        = "2020-11-01"
+
+	networkEffectiveSecurityRulesType = "network.effectiveSecurityRules"
+	networkEffectiveRoutesType        = "network.effectiveRoutes"
+	networkTopologyType               = "network.topology"
+	networkResourceLinkType           = "network.resourceLink"
+)
+
+// networkEffectiveTimeoutSeconds allows time for the long-running effective rules/routes operations
+// (these are computed by the host so can take a minute or so)
+var networkEffectiveTimeoutSeconds = 300
+
+// Check interface
+var _ Expander = &NetworkExpander{}
+
+// NetworkExpander adds the effective security rules, effective routes and network topology to virtual machines and network interfaces
+type NetworkExpander struct {
+	ExpanderBase
+	client *armclient.Client
+}
+
+func (e *NetworkExpander) setClient(c *armclient.Client) {
+	e.client = c
+}
+
+// Name returns the name of the expander
+func (e *NetworkExpander) Name() string {
+	return "NetworkExpander"
+}
+
+func isNetworkInterfaceNode(item *TreeNode) bool {
+	return item.ItemType == ResourceType &&
+		item.SwaggerResourceType != nil &&
+		item.SwaggerResourceType.Endpoint.TemplateURL == networkInterfaceTemplate
+}
+
+// DoesExpand checks if this is a virtual machine, network interface or a network node
+func (e *NetworkExpander) DoesExpand(ctx context.Context, currentItem *TreeNode) (bool, error) {
+	if isVirtualMachineNode(currentItem) || isNetworkInterfaceNode(currentItem) {
+		return true, nil
+	}
+	if currentItem.Namespace == networkNamespace {
+		return true, nil
+	}
+	return false, nil
+}
+
+// Expand returns the network nodes
+func (e *NetworkExpander) Expand(ctx context.Context, currentItem *TreeNode) ExpanderResult {
+	switch currentItem.ItemType {
+	case networkEffectiveSecurityRulesType:
+		return e.expandEffectiveSecurityRules(ctx, currentItem)
+	case networkEffectiveRoutesType:
+		return e.expandEffectiveRoutes(ctx, currentItem)
+	case networkTopologyType:
+		return e.expandTopology(ctx, currentItem)
+	case networkResourceLinkType:
+		resourceID := currentItem.Metadata["ResourceID"]
+		// The list handles this event by opening a new tab and navigating from the root of the tree to the resource
+		eventing.Publish("list.navigateto", resourceID)
+		return ExpanderResult{
+			Response:          ExpanderResponse{Response: "Navigating to " + resourceID, ResponseType: interfaces.ResponsePlainText},
+			SourceDescription: "NetworkExpander",
+			IsPrimaryResponse: true,
+		}
+	}
+
+	newNode := func(name string, suffix string, itemType string, timeoutOverride *int) *TreeNode {
+		return &TreeNode{
+			Parentid:               currentItem.ID,
+			Namespace:              networkNamespace,
+			Display:                style.Subtle("[Microsoft.Network]") + "\n  " + name,
+			Name:                   name,
+			ID:                     currentItem.ID + "/<" + suffix + ">",
+			ExpandURL:              ExpandURLNotSupported,
+			ItemType:               itemType,
+			SubscriptionID:         currentItem.SubscriptionID,
+			SuppressSwaggerExpand:  true,
+			SuppressGenericExpand:  true,
+			TimeoutOverrideSeconds: timeoutOverride,
+			Metadata: map[string]string{
+				"ResourceID": currentItem.ID,
+			},
+		}
+	}
+	return ExpanderResult{
+		Nodes: []*TreeNode{
+			newNode("Effective NSG rules", "effectiveSecurityRules", networkEffectiveSecurityRulesType, &networkEffectiveTimeoutSeconds),
+			newNode("Effective routes", "effectiveRoutes", networkEffectiveRoutesType, &networkEffectiveTimeoutSeconds),
+			newNode("Network topology", "topology", networkTopologyType, nil),
+		},
+		SourceDescription: "NetworkExpander",
+		IsPrimaryResponse: false,
+	}
+}
+
+// getNetworkInterfaceIDs returns the network interfaces for the VM or NIC that the node was created for
+func (e *NetworkExpander) getNetworkInterfaceIDs(ctx context.Context, resourceID string) ([]string, error) {
+	if !strings.Contains(strings.ToLower(resourceID), "/providers/microsoft.compute/virtualmachines/") {
+		return []string{resourceID}, nil
+	}
+	data, err := e.client.DoRequest(ctx, "GET", resourceID+"?api-version="+virtualMachineAPIVersion)
+	if err != nil {
+		return nil, fmt.Errorf("Error getting virtual machine: %s", err)
+	}
+	var vm VirtualMachineResponse
+	if err := json.Unmarshal([]byte(data), &vm); err != nil {
+		return nil, fmt.Errorf("Error parsing virtual machine: %s", err)
+	}
+	nicIDs := []string{}
+	for _, nic := range vm.Properties.NetworkProfile.NetworkInterfaces {
+		nicIDs = append(nicIDs, nic.ID)
+	}
+	if len(nicIDs) == 0 {
+		return nil, fmt.Errorf("No network interfaces found for %s", resourceID)
+	}
+	return nicIDs, nil
+}
+
+// doEffectiveRequest calls the long-running effective rules/routes operation for each network interface
+// and renders the results with the render func
+func (e *NetworkExpander) doEffectiveRequest(ctx context.Context, currentItem *TreeNode, operation string, render func(data string) (string, error)) ExpanderResult {
+	nicIDs, err := e.getNetworkInterfaceIDs(ctx, currentItem.Metadata["ResourceID"])
+	if err != nil {
+		return ExpanderResult{
+			Err:               err,
+			SourceDescription: "NetworkExpander request",
+			IsPrimaryResponse: true,
+		}
+	}
+
+	var sb strings.Builder
+	for _, nicID := range nicIDs {
+		nicName := getResourceNameFromID(nicID)
+		message := currentItem.Name + " for " + nicName
+		event, _ := eventing.SendStatusEvent(&eventing.StatusEvent{
+			InProgress: true,
+			Message:    message,
+			Timeout:    time.Duration(networkEffectiveTimeoutSeconds) * time.Second,
+		})
+		data, err := e.client.DoLongRunningRequestWithBody(ctx, "POST", nicID+"/"+operation+"?api-version="+networkAPIVersion, "", func(status string) {
+			event.Message = message + ": " + status
+			event.Update()
+		})
+		event.Done()
+
+		if len(nicIDs) > 1 {
+			sb.WriteString(style.Title("NIC "+nicName) + "\n\n")
+		}
+		if err != nil {
+			if len(nicIDs) == 1 {
+				return ExpanderResult{
+					Err:               fmt.Errorf("Error getting %s (is the VM running?): %s %s", strings.ToLower(currentItem.Name), err, data),
+					SourceDescription: "NetworkExpander request",
+					IsPrimaryResponse: true,
+				}
+			}
+			// Show the error for this NIC and carry on with the others
+			sb.WriteString(style.Warning("Error: "+err.Error()) + "\n\n")
+			continue
+		}
+		rendered, err := render(data)
+		if err != nil {
+			return ExpanderResult{
+				Err:               err,
+				SourceDescription: "NetworkExpander request",
+				IsPrimaryResponse: true,
+			}
+		}
+		sb.WriteString(rendered + "\n")
+	}
+
+	return ExpanderResult{
+		Response:          ExpanderResponse{Response: sb.String(), ResponseType: interfaces.ResponsePlainText},
+		SourceDescription: "NetworkExpander request",
+		IsPrimaryResponse: true,
+	}
+}
+
+func (e *NetworkExpander) expandEffectiveSecurityRules(ctx context.Context, currentItem *TreeNode) ExpanderResult {
+	return e.doEffectiveRequest(ctx, currentItem, "effectiveNetworkSecurityGroups", renderEffectiveSecurityRules)
+}
+
+func (e *NetworkExpander) expandEffectiveRoutes(ctx context.Context, currentItem *TreeNode) ExpanderResult {
+	return e.doEffectiveRequest(ctx, currentItem, "effectiveRouteTable", renderEffectiveRoutes)
+}
+
+// EffectiveNetworkSecurityGroupListResult is returned by the effectiveNetworkSecurityGroups operation
+type EffectiveNetworkSecurityGroupListResult struct {
+	Value []EffectiveNetworkSecurityGroup `json:"value"`
+}
+
+// EffectiveNetworkSecurityGroup is an NSG applied to a network interface (either directly or via the subnet)
+type EffectiveNetworkSecurityGroup struct {
+	NetworkSecurityGroup *NetworkSubResource `json:"networkSecurityGroup"`
+	Association          struct {
+		NetworkInterface *NetworkSubResource `json:"networkInterface"`
+		Subnet           *NetworkSubResource `json:"subnet"`
+	} `json:"association"`
+	EffectiveSecurityRules []EffectiveSecurityRule `json:"effectiveSecurityRules"`
+}
+
+// EffectiveSecurityRule is a security rule from an effective NSG
+type EffectiveSecurityRule struct {
+	Name                       string   `json:"name"`
+	Protocol                   string   `json:"protocol"`
+	SourcePortRange            string   `json:"sourcePortRange"`
+	DestinationPortRange       string   `json:"destinationPortRange"`
+	SourcePortRanges           []string `json:"sourcePortRanges"`
+	DestinationPortRanges      []string `json:"destinationPortRanges"`
+	SourceAddressPrefix        string   `json:"sourceAddressPrefix"`
+	DestinationAddressPrefix   string   `json:"destinationAddressPrefix"`
+	SourceAddressPrefixes      []string `json:"sourceAddressPrefixes"`
+	DestinationAddressPrefixes []string `json:"destinationAddressPrefixes"`
+	Access                     string   `json:"access"`
+	Priority                   int      `json:"priority"`
+	Direction                  string   `json:"direction"`
+}
+
+// EffectiveRouteListResult is returned by the effectiveRouteTable operation
+type EffectiveRouteListResult struct {
+	Value []EffectiveRoute `json:"value"`
+}
+
+// EffectiveRoute is a route applied to a network interface
+type EffectiveRoute struct {
+	Name             string   `json:"name"`
+	Source           string   `json:"source"`
+	State            string   `json:"state"`
+	AddressPrefix    []string `json:"addressPrefix"`
+	NextHopIPAddress []string `json:"nextHopIpAddress"`
+	NextHopType      string   `json:"nextHopType"`
+}
+
+// NetworkSubResource is a reference to another network resource
+type NetworkSubResource struct {
+	ID string `json:"id"`
+}
+
+// joinNetworkValues returns the list of values if set, otherwise the single value
+func joinNetworkValues(values []string, value string) string {
+	if len(values) > 0 {
+		return strings.Join(values, ",")
+	}
+	return value
+}
+
+func renderEffectiveSecurityRules(data string) (string, error) {
+	var result EffectiveNetworkSecurityGroupListResult
+	if err := json.Unmarshal([]byte(data), &result); err != nil {
+		return "", fmt.Errorf("Error parsing effective security rules: %s", err)
+	}
+	if len(result.Value) == 0 {
+		return "No network security groups are applied\n", nil
+	}
+
+	var sb strings.Builder
+	for _, nsg := range result.Value {
+		nsgName := "(none)"
+		if nsg.NetworkSecurityGroup != nil {
+			nsgName = getResourceNameFromID(nsg.NetworkSecurityGroup.ID)
+		}
+		association := ""
+		switch {
+		case nsg.Association.Subnet != nil:
+			association = "subnet " + getResourceNameFromID(nsg.Association.Subnet.ID)
+		case nsg.Association.NetworkInterface != nil:
+			association = "network interface " + getResourceNameFromID(nsg.Association.NetworkInterface.ID)
+		}
+		sb.WriteString(style.Title("NSG "+nsgName) + " " + style.Subtle("associated with "+association) + "\n")
+
+		rules := append([]EffectiveSecurityRule{}, nsg.EffectiveSecurityRules...)
+		sort.SliceStable(rules, func(i, j int) bool {
+			if rules[i].Direction != rules[j].Direction {
+				// Inbound before Outbound
+				return rules[i].Direction < rules[j].Direction
+			}
+			return rules[i].Priority < rules[j].Priority
+		})
+		direction := ""
+		for _, rule := range rules {
+			if rule.Direction != direction {
+				direction = rule.Direction
+				sb.WriteString("\n  " + style.Title(direction) + "\n")
+				sb.WriteString(style.Subtle(fmt.Sprintf("  %-8s %-40s %-6s %-8s %-24s %-12s %-24s %s", "PRIORITY", "NAME", "ACCESS", "PROTOCOL", "SOURCE", "SOURCE PORT", "DESTINATION", "DEST PORT")) + "\n")
+			}
+			access := fmt.Sprintf("%-6s", rule.Access)
+			if strings.EqualFold(rule.Access, "Deny") {
+				access = style.Warning(access)
+			}
+			sb.WriteString(fmt.Sprintf("  %-8d %-40s %s %-8s %-24s %-12s %-24s %s\n",
+				rule.Priority,
+				rule.Name,
+				access,
+				rule.Protocol,
+				joinNetworkValues(rule.SourceAddressPrefixes, rule.SourceAddressPrefix),
+				joinNetworkValues(rule.SourcePortRanges, rule.SourcePortRange),
+				joinNetworkValues(rule.DestinationAddressPrefixes, rule.DestinationAddressPrefix),
+				joinNetworkValues(rule.DestinationPortRanges, rule.DestinationPortRange)))
+		}
+		sb.WriteString("\n")
+	}
+	return sb.String(), nil
+}
+
+func renderEffectiveRoutes(data string) (string, error) {
+	var result EffectiveRouteListResult
+	if err := json.Unmarshal([]byte(data), &result); err != nil {
+		return "", fmt.Errorf("Error parsing effective routes: %s", err)
+	}
+	if len(result.Value) == 0 {
+		return "No routes found\n", nil
+	}
+
+	var sb strings.Builder
+	sb.WriteString(style.Title(fmt.Sprintf("%-10s %-8s %-20s %-24s %s", "SOURCE", "STATE", "ADDRESS PREFIX", "NEXT HOP TYPE", "NEXT HOP IP")) + "\n")
+	for _, route := range result.Value {
+		line := fmt.Sprintf("%-10s %-8s %-20s %-24s %s",
+			route.Source,
+			route.State,
+			strings.Join(route.AddressPrefix, ","),
+			route.NextHopType,
+			strings.Join(route.NextHopIPAddress, ","))
+		if !strings.EqualFold(route.State, "Active") {
+			line = style.Subtle(line)
+		}
+		sb.WriteString(line + "\n")
+	}
+	return sb.String(), nil
+}
+
+// NetworkInterface is returned by a request for a network interface
+type NetworkInterface struct {
+	ID         string `json:"id"`
+	Name       string `json:"name"`
+	Properties struct {
+		IPConfigurations []struct {
+			ID         string `json:"id"`
+			Name       string `json:"name"`
+			Properties struct {
+				PrivateIPAddress string              `json:"privateIPAddress"`
+				Subnet           *NetworkSubResource `json:"subnet"`
+				PublicIPAddress  *NetworkSubResource `json:"publicIPAddress"`
+			} `json:"properties"`
+		} `json:"ipConfigurations"`
+		NetworkSecurityGroup *NetworkSubResource `json:"networkSecurityGroup"`
+		VirtualMachine       *NetworkSubResource `json:"virtualMachine"`
+	} `json:"properties"`
+}
+
+// NetworkSubnet is returned by a request for a subnet
+type NetworkSubnet struct {
+	ID         string `json:"id"`
+	Name       string `json:"name"`
+	Properties struct {
+		AddressPrefix        string              `json:"addressPrefix"`
+		AddressPrefixes      []string            `json:"addressPrefixes"`
+		NetworkSecurityGroup *NetworkSubResource `json:"networkSecurityGroup"`
+		RouteTable           *NetworkSubResource `json:"routeTable"`
+	} `json:"properties"`
+}
+
+// VirtualNetwork is returned by a request for a virtual network
+type VirtualNetwork struct {
+	ID         string `json:"id"`
+	Name       string `json:"name"`
+	Properties struct {
+		AddressSpace struct {
+			AddressPrefixes []string `json:"addressPrefixes"`
+		} `json:"addressSpace"`
+		VirtualNetworkPeerings []struct {
+			ID         string `json:"id"`
+			Name       string `json:"name"`
+			Properties struct {
+				PeeringState         string              `json:"peeringState"`
+				RemoteVirtualNetwork *NetworkSubResource `json:"remoteVirtualNetwork"`
+			} `json:"properties"`
+		} `json:"virtualNetworkPeerings"`
+	} `json:"properties"`
+}
+
+// PublicIPAddress is returned by a request for a public IP address
+type PublicIPAddress struct {
+	ID         string `json:"id"`
+	Name       string `json:"name"`
+	Properties struct {
+		IPAddress                string `json:"ipAddress"`
+		PublicIPAllocationMethod string `json:"publicIPAllocationMethod"`
+	} `json:"properties"`
+}
+
+// networkTopologyEntry is a resource in the topology tree
+type networkTopologyEntry struct {
+	Depth      int
+	Kind       string
+	Name       string
+	Detail     string
+	ResourceID string
+}
+
+// networkTopology holds the resources referenced by a network interface
+type networkTopology struct {
+	NetworkInterface NetworkInterface
+	Subnets          map[string]NetworkSubnet   // keyed on lower case ID
+	VirtualNetworks  map[string]VirtualNetwork  // keyed on lower case ID
+	PublicIPs        map[string]PublicIPAddress // keyed on lower case ID
+}
+
+// getVirtualNetworkIDFromSubnetID returns the ID of the VNet containing the subnet
+func getVirtualNetworkIDFromSubnetID(subnetID string) string {
+	index := strings.Index(strings.ToLower(subnetID), "/subnets/")
+	if index < 0 {
+		return ""
+	}
+	return subnetID[:index]
+}
+
+// buildNetworkTopology walks NIC -> IP configuration -> public IP / subnet -> NSG / route table / VNet -> peerings
+func buildNetworkTopology(topology networkTopology) []networkTopologyEntry {
+	nic := topology.NetworkInterface
+	entries := []networkTopologyEntry{
+		{Depth: 0, Kind: "NIC", Name: nic.Name, ResourceID: nic.ID},
+	}
+	if nic.Properties.VirtualMachine != nil {
+		entries = append(entries, networkTopologyEntry{Depth: 1, Kind: "VM", Name: getResourceNameFromID(nic.Properties.VirtualMachine.ID), ResourceID: nic.Properties.VirtualMachine.ID})
+	}
+	if nic.Properties.NetworkSecurityGroup != nil {
+		entries = append(entries, networkTopologyEntry{Depth: 1, Kind: "NSG", Name: getResourceNameFromID(nic.Properties.NetworkSecurityGroup.ID), ResourceID: nic.Properties.NetworkSecurityGroup.ID})
+	}
+	for _, ipConfig := range nic.Properties.IPConfigurations {
+		entries = append(entries, networkTopologyEntry{Depth: 1, Kind: "IP config", Name: ipConfig.Name, Detail: ipConfig.Properties.PrivateIPAddress, ResourceID: ipConfig.ID})
+
+		if ipConfig.Properties.PublicIPAddress != nil {
+			publicIPID := ipConfig.Properties.PublicIPAddress.ID
+			publicIP := topology.PublicIPs[strings.ToLower(publicIPID)]
+			detail := publicIP.Properties.IPAddress
+			if detail == "" {
+				detail = publicIP.Properties.PublicIPAllocationMethod
+			}
+			entries = append(entries, networkTopologyEntry{Depth: 2, Kind: "Public IP", Name: getResourceNameFromID(publicIPID), Detail: detail, ResourceID: publicIPID})
+		}
+
+		if ipConfig.Properties.Subnet == nil {
+			continue
+		}
+		subnetID := ipConfig.Properties.Subnet.ID
+		subnet := topology.Subnets[strings.ToLower(subnetID)]
+		entries = append(entries, networkTopologyEntry{Depth: 2, Kind: "Subnet", Name: getResourceNameFromID(subnetID), Detail: joinNetworkValues(subnet.Properties.AddressPrefixes, subnet.Properties.AddressPrefix), ResourceID: subnetID})
+		if subnet.Properties.NetworkSecurityGroup != nil {
+			entries = append(entries, networkTopologyEntry{Depth: 3, Kind: "NSG", Name: getResourceNameFromID(subnet.Properties.NetworkSecurityGroup.ID), ResourceID: subnet.Properties.NetworkSecurityGroup.ID})
+		}
+		if subnet.Properties.RouteTable != nil {
+			entries = append(entries, networkTopologyEntry{Depth: 3, Kind: "Route table", Name: getResourceNameFromID(subnet.Properties.RouteTable.ID), ResourceID: subnet.Properties.RouteTable.ID})
+		}
+
+		vnetID := getVirtualNetworkIDFromSubnetID(subnetID)
+		vnet := topology.VirtualNetworks[strings.ToLower(vnetID)]
+		entries = append(entries, networkTopologyEntry{Depth: 3, Kind: "VNet", Name: getResourceNameFromID(vnetID), Detail: strings.Join(vnet.Properties.AddressSpace.AddressPrefixes, ","), ResourceID: vnetID})
+		for _, peering := range vnet.Properties.VirtualNetworkPeerings {
+			entry := networkTopologyEntry{Depth: 4, Kind: "Peering", Name: peering.Name, Detail: peering.Properties.PeeringState, ResourceID: peering.ID}
+			if peering.Properties.RemoteVirtualNetwork != nil {
+				// link to the peered network as that is more useful than the peering itself
+				entry.Detail = "→ " + getResourceNameFromID(peering.Properties.RemoteVirtualNetwork.ID) + " (" + peering.Properties.PeeringState + ")"
+				entry.ResourceID = peering.Properties.RemoteVirtualNetwork.ID
+			}
+			entries = append(entries, entry)
+		}
+	}
+	return entries
+}
+
+func (entry networkTopologyEntry) getDisplay() string {
+	display := strings.Repeat("  ", entry.Depth) + entry.Kind + ": " + entry.Name
+	if entry.Detail != "" {
+		display += " " + style.Subtle(entry.Detail)
+	}
+	return display
+}
+
+func renderNetworkTopology(entries []networkTopologyEntry) string {
+	var sb strings.Builder
+	for _, entry := range entries {
+		sb.WriteString(entry.getDisplay() + "\n")
+	}
+	return sb.String()
+}
+
+// getNetworkResource gets a network resource, caching it to avoid repeated requests for shared resources (e.g. the VNet)
+func (e *NetworkExpander) getNetworkResource(ctx context.Context, resourceID string, cache map[string]string, result interface{}) error {
+	key := strings.ToLower(resourceID)
+	data, ok := cache[key]
+	if !ok {
+		var err error
+		data, err = e.client.DoRequest(ctx, "GET", resourceID+"?api-version="+networkAPIVersion)
+		if err != nil {
+			return fmt.Errorf("Error getting %s: %s", resourceID, err)
+		}
+		cache[key] = data
+	}
+	if err := json.Unmarshal([]byte(data), result); err != nil {
+		return fmt.Errorf("Error parsing %s: %s", resourceID, err)
+	}
+	return nil
+}
+
+func (e *NetworkExpander) getNetworkTopology(ctx context.Context, nicID string, cache map[string]string) (networkTopology, error) {
+	topology := networkTopology{
+		Subnets:         map[string]NetworkSubnet{},
+		VirtualNetworks: map[string]VirtualNetwork{},
+		PublicIPs:       map[string]PublicIPAddress{},
+	}
+	if err := e.getNetworkResource(ctx, nicID, cache, &topology.NetworkInterface); err != nil {
+		return topology, err
+	}
+	for _, ipConfig := range topology.NetworkInterface.Properties.IPConfigurations {
+		if ipConfig.Properties.PublicIPAddress != nil {
+			var publicIP PublicIPAddress
+			if err := e.getNetworkResource(ctx, ipConfig.Properties.PublicIPAddress.ID, cache, &publicIP); err != nil {
+				return topology, err
+			}
+			topology.PublicIPs[strings.ToLower(ipConfig.Properties.PublicIPAddress.ID)] = publicIP
+		}
+		if ipConfig.Properties.Subnet != nil {
+			subnetID := ipConfig.Properties.Subnet.ID
+			var subnet NetworkSubnet
+			if err := e.getNetworkResource(ctx, subnetID, cache, &subnet); err != nil {
+				return topology, err
+			}
+			topology.Subnets[strings.ToLower(subnetID)] = subnet
+
+			vnetID := getVirtualNetworkIDFromSubnetID(subnetID)
+			var vnet VirtualNetwork
+			if err := e.getNetworkResource(ctx, vnetID, cache, &vnet); err != nil {
+				return topology, err
+			}
+			topology.VirtualNetworks[strings.ToLower(vnetID)] = vnet
+		}
+	}
+	return topology, nil
+}
+
+func (e *NetworkExpander) expandTopology(ctx context.Context, currentItem *TreeNode) ExpanderResult {
+	nicIDs, err := e.getNetworkInterfaceIDs(ctx, currentItem.Metadata["ResourceID"])
+	if err != nil {
+		return ExpanderResult{
+			Err:               err,
+			SourceDescription: "NetworkExpander request",
+			IsPrimaryResponse: true,
+		}
+	}
+
+	cache := map[string]string{}
+	entries := []networkTopologyEntry{}
+	for _, nicID := range nicIDs {
+		topology, err := e.getNetworkTopology(ctx, nicID, cache)
+		if err != nil {
+			return ExpanderResult{
+				Err:               err,
+				SourceDescription: "NetworkExpander request",
+				IsPrimaryResponse: true,
+			}
+		}
+		entries = append(entries, buildNetworkTopology(topology)...)
+	}
+
+	nodes := []*TreeNode{}
+	for index, entry := range entries {
+		nodes = append(nodes, &TreeNode{
+			Parentid:              currentItem.ID,
+			Namespace:             networkNamespace,
+			Name:                  entry.Kind + ": " + entry.Name,
+			Display:               entry.getDisplay(),
+			ID:                    currentItem.ID + "/" + strconv.Itoa(index),
+			ExpandURL:             ExpandURLNotSupported,
+			ItemType:              networkResourceLinkType,
+			SubscriptionID:        currentItem.SubscriptionID,
+			SuppressSwaggerExpand: true,
+			SuppressGenericExpand: true,
+			Metadata: map[string]string{
+				"ResourceID": entry.ResourceID,
+			},
+		})
+	}
+
+	return ExpanderResult{
+		Response:          ExpanderResponse{Response: renderNetworkTopology(entries), ResponseType: interfaces.ResponsePlainText},
+		SourceDescription: "NetworkExpander request",
+		Nodes:             nodes,
+		IsPrimaryResponse: true,
+	}
+}
@@ -0,0 +1,96 @@
+package expanders
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/nbio/st"
+)
+
+func Test_Network_RenderEffectiveSecurityRules(t *testing.T) {
+	data := `{
+		"value": [{
+			"networkSecurityGroup": {"id": "/subscriptions/1/resourceGroups/rg/providers/Microsoft.Network/networkSecurityGroups/nsg1"},
+			"association": {"subnet": {"id": "/subscriptions/1/resourceGroups/rg/providers/Microsoft.Network/virtualNetworks/vnet1/subnets/default"}},
+			"effectiveSecurityRules": [
+				{"name": "defaultSecurityRules/DenyAllOutBound", "protocol": "All", "sourcePortRange": "0-65535", "destinationPortRange": "0-65535", "sourceAddressPrefix": "0.0.0.0/0", "destinationAddressPrefix": "0.0.0.0/0", "access": "Deny", "priority": 65500, "direction": "Outbound"},
+				{"name": "securityRules/AllowSSH", "protocol": "Tcp", "sourcePortRange": "0-65535", "destinationPortRanges": ["22-22", "2222-2222"], "sourceAddressPrefix": "10.0.0.0/8", "destinationAddressPrefix": "0.0.0.0/0", "access": "Allow", "priority": 100, "direction": "Inbound"}
+			]
+		}]
+	}`
+	rendered, err := renderEffectiveSecurityRules(data)
+	st.Expect(t, err, nil)
+	st.Assert(t, strings.Contains(rendered, "nsg1"), true)
+	st.Assert(t, strings.Contains(rendered, "associated with subnet default"), true)
+	st.Assert(t, strings.Contains(rendered, "22-22,2222-2222"), true)
+	// Inbound rules are listed before outbound
+	st.Assert(t, strings.Index(rendered, "AllowSSH") < strings.Index(rendered, "DenyAllOutBound"), true)
+}
+
+func Test_Network_RenderEffectiveRoutes(t *testing.T) {
+	data := `{"value": [
+		{"source": "Default", "state": "Active", "addressPrefix": ["10.0.0.0/16"], "nextHopType": "VnetLocal", "nextHopIpAddress": []},
+		{"source": "User", "state": "Active", "addressPrefix": ["0.0.0.0/0"], "nextHopType": "VirtualAppliance", "nextHopIpAddress": ["10.1.0.4"]}
+	]}`
+	rendered, err := renderEffectiveRoutes(data)
+	st.Expect(t, err, nil)
+	st.Assert(t, strings.Contains(rendered, "VirtualAppliance"), true)
+	st.Assert(t, strings.Contains(rendered, "10.1.0.4"), true)
+
+	_, err = renderEffectiveRoutes("not json")
+	st.Reject(t, err, nil)
+}
+
+func Test_Network_BuildTopology(t *testing.T) {
+	const rgID = "/subscriptions/1/resourceGroups/rg/providers/Microsoft.Network"
+	var nic NetworkInterface
+	err := json.Unmarshal([]byte(`{
+		"id": "`+rgID+`/networkInterfaces/nic1",
+		"name": "nic1",
+		"properties": {
+			"ipConfigurations": [{
+				"id": "`+rgID+`/networkInterfaces/nic1/ipConfigurations/ipconfig1",
+				"name": "ipconfig1",
+				"properties": {
+					"privateIPAddress": "10.0.0.4",
+					"subnet": {"id": "`+rgID+`/virtualNetworks/vnet1/subnets/default"},
+					"publicIPAddress": {"id": "`+rgID+`/publicIPAddresses/pip1"}
+				}
+			}]
+		}
+	}`), &nic)
+	st.Expect(t, err, nil)
+
+	var subnet NetworkSubnet
+	subnet.Properties.AddressPrefix = "10.0.0.0/24"
+	subnet.Properties.RouteTable = &NetworkSubResource{ID: rgID + "/routeTables/rt1"}
+	var vnet VirtualNetwork
+	err = json.Unmarshal([]byte(`{
+		"properties": {
+			"addressSpace": {"addressPrefixes": ["10.0.0.0/16"]},
+			"virtualNetworkPeerings": [{"name": "to-hub", "properties": {"peeringState": "Connected", "remoteVirtualNetwork": {"id": "`+rgID+`/virtualNetworks/hub"}}}]
+		}
+	}`), &vnet)
+	st.Expect(t, err, nil)
+	var publicIP PublicIPAddress
+	publicIP.Properties.IPAddress = "20.1.2.3"
+
+	entries := buildNetworkTopology(networkTopology{
+		NetworkInterface: nic,
+		Subnets:          map[string]NetworkSubnet{strings.ToLower(rgID + "/virtualNetworks/vnet1/subnets/default"): subnet},
+		VirtualNetworks:  map[string]VirtualNetwork{strings.ToLower(rgID + "/virtualNetworks/vnet1"): vnet},
+		PublicIPs:        map[string]PublicIPAddress{strings.ToLower(rgID + "/publicIPAddresses/pip1"): publicIP},
+	})
+
+	kinds := []string{}
+	for _, entry := range entries {
+		kinds = append(kinds, entry.Kind)
+	}
+	st.Expect(t, kinds, []string{"NIC", "IP config", "Public IP", "Subnet", "Route table", "VNet", "Peering"})
+	st.Expect(t, entries[2].Detail, "20.1.2.3")
+	st.Expect(t, entries[3].Detail, "10.0.0.0/24")
+	st.Expect(t, entries[5].ResourceID, rgID+"/virtualNetworks/vnet1")
+	st.Expect(t, entries[6].Depth, 4)
+	st.Expect(t, entries[6].ResourceID, rgID+"/virtualNetworks/hub")
+}
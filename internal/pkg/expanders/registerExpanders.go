@@ -27,7 +27,7 @@ var register []Expander
 // InitializeExpanders create instances of all the expanders
 // needed by the app
 func InitializeExpanders(client *armclient.Client, graphClient *armclient.Client, gui *gocui.Gui, commandPanel interfaces.CommandPanel, contentPanel interfaces.ItemWidget) {
-	swaggerResourceExpander = NewSwaggerResourcesExpander()
+	swaggerResourceExpander = NewSwaggerResourcesExpander(gui, commandPanel)
 	swaggerResourceExpander.AddAPISet(NewSwaggerAPISetARMResources(client))
 
 	defaultExpander = &DefaultExpander{
@@ -53,20 +53,36 @@ func InitializeExpanders(client *armclient.Client, graphClient *armclient.Client
 			gui:    gui,
 		},
 		&MetricsExpander{
-			client: client,
+			client:       client,
+			gui:          gui,
+			commandPanel: commandPanel,
 		},
 		swaggerResourceExpander,
 		&DeploymentsExpander{
 			client: client,
 		},
 		&ActivityLogExpander{
+			client:       client,
+			gui:          gui,
+			commandPanel: commandPanel,
+		},
+		&ResourceHealthExpander{
+			client: client,
+		},
+		&RelatedResourcesExpander{
 			client: client,
 		},
+		&ChangeHistoryExpander{
+			client: client,
+		},
+		NewAPIVersionsExpander(client, gui, commandPanel),
 		&JSONExpander{},
-		&StorageManagementPoliciesExpander{},                         // Needs to be registered after SwaggerResourceExpander as it depends on SwaggerResourceType being set
-		NewContainerRegistryExpander(client),                         // Needs to be registered after SwaggerResourceExpander as it depends on SwaggerResourceType being set
-		NewStorageBlobExpander(client),                               // Needs to be registered after SwaggerResourceExpander as it depends on SwaggerResourceType being set
-		NewCosmosDbExpander(client, gui, commandPanel, contentPanel), // Needs to be registered after SwaggerResourceExpander as it depends on SwaggerResourceType being set
+		&StorageManagementPoliciesExpander{}, // Needs to be registered after SwaggerResourceExpander as it depends on SwaggerResourceType being set
+		NewContainerRegistryExpander(client, gui, commandPanel, contentPanel), // Needs to be registered after SwaggerResourceExpander as it depends on SwaggerResourceType being set
+		NewStorageBlobExpander(client),                                        // Needs to be registered after SwaggerResourceExpander as it depends on SwaggerResourceType being set
+		NewCosmosDbExpander(client, gui, commandPanel, contentPanel),          // Needs to be registered after SwaggerResourceExpander as it depends on SwaggerResourceType being set
+		NewVirtualMachineExpander(client, gui),                                // Needs to be registered after SwaggerResourceExpander as it depends on SwaggerResourceType being set
+		&NetworkExpander{client: client},                                      // Needs to be registered after SwaggerResourceExpander as it depends on SwaggerResourceType being set
 		&ContainerInstanceExpander{
 			client: client,
 		},
@@ -77,11 +93,15 @@ func InitializeExpanders(client *armclient.Client, graphClient *armclient.Client
 			client: client,
 		},
 		&AzureSearchServiceExpander{
-			client: client,
+			client:       client,
+			gui:          gui,
+			commandPanel: commandPanel,
 		},
 		&AzureDatabricksExpander{
 			client: client,
+			gui:    gui,
 		},
+		NewCustomSwaggerExpander(client),
 		&DiagnosticSettingsExpander{
 			client: client,
 		},
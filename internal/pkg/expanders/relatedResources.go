@@ -0,0 +1,295 @@
+package expanders
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/lawrencegripper/azbrowse/internal/pkg/eventing"
+	"github.com/lawrencegripper/azbrowse/internal/pkg/interfaces"
+	"github.com/lawrencegripper/azbrowse/internal/pkg/storage"
+	"github.com/lawrencegripper/azbrowse/internal/pkg/style"
+	"github.com/lawrencegripper/azbrowse/pkg/armclient"
+)
+
+const (
+	relatedResourcesType             = "relatedResources"
+	relatedResourcesReferencedByType = "relatedResources.referencedBy"
+	relatedResourcesReferencesType   = "relatedResources.references"
+	relatedResourcesLinkType         = "relatedResources.link"
+)
+
+// armResourceIDRegex matches resource IDs embedded in JSON (either at subscription or resource group scope)
+var armResourceIDRegex = regexp.MustCompile(`(?i)/subscriptions/[0-9a-f]{8}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{12}(/resourceGroups/[^/"\s\\]+)?/providers/[^"\s\\?]+`)
+
+// Check interface
+var _ Expander = &RelatedResourcesExpander{}
+
+// RelatedResourcesExpander finds the resources that reference a resource (via Resource Graph) and the resources that it references
+type RelatedResourcesExpander struct {
+	ExpanderBase
+	client *armclient.Client
+}
+
+func (e *RelatedResourcesExpander) setClient(c *armclient.Client) {
+	e.client = c
+}
+
+// Name returns the name of the expander
+func (e *RelatedResourcesExpander) Name() string {
+	return "RelatedResourcesExpander"
+}
+
+// DoesExpand checks if this is a resource or a related resources node
+func (e *RelatedResourcesExpander) DoesExpand(ctx context.Context, currentItem *TreeNode) (bool, error) {
+	switch currentItem.ItemType {
+	case ResourceType, relatedResourcesType, relatedResourcesReferencedByType, relatedResourcesReferencesType, relatedResourcesLinkType:
+		return true, nil
+	}
+	return false, nil
+}
+
+// Expand returns the related resources nodes
+func (e *RelatedResourcesExpander) Expand(ctx context.Context, currentItem *TreeNode) ExpanderResult {
+	switch currentItem.ItemType {
+	case ResourceType:
+		return ExpanderResult{
+			Nodes: []*TreeNode{
+				newRelatedResourcesNode(currentItem, "Related resources", "<related>", relatedResourcesType),
+			},
+			SourceDescription: "RelatedResourcesExpander",
+			IsPrimaryResponse: false,
+		}
+	case relatedResourcesType:
+		return ExpanderResult{
+			Nodes: []*TreeNode{
+				newRelatedResourcesNode(currentItem, "Referenced by", "referencedBy", relatedResourcesReferencedByType),
+				newRelatedResourcesNode(currentItem, "References", "references", relatedResourcesReferencesType),
+			},
+			Response: ExpanderResponse{
+				Response:     "Referenced by: resources whose properties contain the ID of " + currentItem.Metadata["ResourceID"] + "\nReferences: resources whose IDs are in the properties of " + currentItem.Metadata["ResourceID"],
+				ResponseType: interfaces.ResponsePlainText,
+			},
+			SourceDescription: "RelatedResourcesExpander",
+			IsPrimaryResponse: true,
+		}
+	case relatedResourcesReferencedByType:
+		return e.expandReferencedBy(ctx, currentItem)
+	case relatedResourcesReferencesType:
+		return e.expandReferences(ctx, currentItem)
+	case relatedResourcesLinkType:
+		resourceID := currentItem.Metadata["ResourceID"]
+		// The list handles this event by opening a new tab and navigating from the root of the tree to the resource
+		eventing.Publish("list.navigateto", resourceID)
+		return ExpanderResult{
+			Response:          ExpanderResponse{Response: "Navigating to " + resourceID, ResponseType: interfaces.ResponsePlainText},
+			SourceDescription: "RelatedResourcesExpander",
+			IsPrimaryResponse: true,
+		}
+	}
+
+	return ExpanderResult{
+		Err:               fmt.Errorf("Unhandled ItemType: %q", currentItem.ItemType),
+		SourceDescription: "RelatedResourcesExpander",
+		IsPrimaryResponse: true,
+	}
+}
+
+func newRelatedResourcesNode(parent *TreeNode, name string, suffix string, itemType string) *TreeNode {
+	resourceID := parent.ID
+	resourceExpandURL := parent.ExpandURL
+	if parent.ItemType != ResourceType {
+		resourceID = parent.Metadata["ResourceID"]
+		resourceExpandURL = parent.Metadata["ResourceExpandURL"]
+	}
+	return &TreeNode{
+		Parentid:              parent.ID,
+		Namespace:             "None",
+		Display:               name,
+		Name:                  name,
+		ID:                    parent.ID + "/" + suffix,
+		ExpandURL:             ExpandURLNotSupported,
+		ItemType:              itemType,
+		SubscriptionID:        parent.SubscriptionID,
+		SuppressSwaggerExpand: true,
+		SuppressGenericExpand: true,
+		Metadata: map[string]string{
+			"ResourceID":        resourceID,
+			"ResourceExpandURL": resourceExpandURL,
+		},
+	}
+}
+
+// relatedResource is a resource that references or is referenced by another resource
+type relatedResource struct {
+	ID             string `json:"id"`
+	Name           string `json:"name"`
+	Type           string `json:"type"`
+	SubscriptionID string `json:"subscriptionId"`
+}
+
+func newRelatedResourceLinkNode(parent *TreeNode, index int, resource relatedResource) *TreeNode {
+	return &TreeNode{
+		Parentid:              parent.ID,
+		Namespace:             "None",
+		Display:               style.Subtle("["+resource.Type+"]") + "\n  " + resource.Name,
+		Name:                  resource.Name,
+		ID:                    parent.ID + "/" + strconv.Itoa(index),
+		ExpandURL:             ExpandURLNotSupported,
+		ItemType:              relatedResourcesLinkType,
+		SubscriptionID:        resource.SubscriptionID,
+		SuppressSwaggerExpand: true,
+		SuppressGenericExpand: true,
+		Metadata: map[string]string{
+			"ResourceID": resource.ID,
+		},
+	}
+}
+
+// buildReferencedByQuery returns the Resource Graph query to find resources whose properties contain the resource ID
+func buildReferencedByQuery(resourceID string) string {
+	escapedID := strings.ReplaceAll(resourceID, "'", "\\'")
+	// `contains` is case-insensitive which is needed as resource IDs are not consistently cased
+	return fmt.Sprintf("Resources | where id !~ '%s' and tostring(properties) contains '%s' | project id, name, type, subscriptionId | order by type asc, name asc", escapedID, escapedID)
+}
+
+// getResourceTypeFromID returns the resource type for an ID, e.g. Microsoft.Network/virtualNetworks/subnets
+func getResourceTypeFromID(resourceID string) string {
+	index := strings.LastIndex(strings.ToLower(resourceID), "/providers/")
+	if index < 0 {
+		return ""
+	}
+	segments := strings.Split(strings.Trim(resourceID[index+len("/providers/"):], "/"), "/")
+	if len(segments) == 0 {
+		return ""
+	}
+	// namespace followed by type/name pairs
+	parts := []string{segments[0]}
+	for i := 1; i < len(segments); i += 2 {
+		parts = append(parts, segments[i])
+	}
+	return strings.Join(parts, "/")
+}
+
+// extractResourceIDReferences returns the distinct resource IDs in the JSON, excluding the resource and its children
+func extractResourceIDReferences(data string, resourceID string) []relatedResource {
+	selfID := strings.ToLower(strings.TrimSuffix(resourceID, "/"))
+	seen := map[string]bool{}
+	resources := []relatedResource{}
+	for _, match := range armResourceIDRegex.FindAllString(data, -1) {
+		id := strings.TrimSuffix(match, "/")
+		key := strings.ToLower(id)
+		if key == selfID || strings.HasPrefix(key, selfID+"/") || seen[key] {
+			continue
+		}
+		// Skip provider-level IDs such as /subscriptions/{id}/providers/Microsoft.Network
+		if strings.Count(id[strings.LastIndex(strings.ToLower(id), "/providers/"):], "/") < 4 {
+			continue
+		}
+		seen[key] = true
+		resources = append(resources, relatedResource{
+			ID:             id,
+			Name:           getResourceNameFromID(id),
+			Type:           getResourceTypeFromID(id),
+			SubscriptionID: strings.Split(id, "/")[2], // IDs matched by armResourceIDRegex start /subscriptions/{id}
+		})
+	}
+	sort.SliceStable(resources, func(i, j int) bool {
+		if resources[i].Type != resources[j].Type {
+			return resources[i].Type < resources[j].Type
+		}
+		return resources[i].Name < resources[j].Name
+	})
+	return resources
+}
+
+func renderRelatedResources(title string, resources []relatedResource) string {
+	if len(resources) == 0 {
+		return "No " + strings.ToLower(title) + " resources found"
+	}
+	var sb strings.Builder
+	sb.WriteString(style.Title(fmt.Sprintf("%s (%d)", title, len(resources))) + "\n\n")
+	for _, resource := range resources {
+		sb.WriteString(style.Subtle("["+resource.Type+"]") + " " + resource.Name + "\n  " + resource.ID + "\n")
+	}
+	return sb.String()
+}
+
+// getQuerySubscriptions returns the subscriptions to search for references, falling back to the resource's subscription
+func getQuerySubscriptions(subscriptionID string) []string {
+	subNameMapJSON, err := storage.GetCache(subNameMapCacheKey)
+	if err == nil {
+		subNameMap := map[string]string{}
+		if err := json.Unmarshal([]byte(subNameMapJSON), &subNameMap); err == nil && len(subNameMap) > 0 {
+			subscriptions := []string{}
+			for subscription := range subNameMap {
+				subscriptions = append(subscriptions, subscription)
+			}
+			sort.Strings(subscriptions)
+			return subscriptions
+		}
+	}
+	return []string{subscriptionID}
+}
+
+func (e *RelatedResourcesExpander) expandReferencedBy(ctx context.Context, currentItem *TreeNode) ExpanderResult {
+	resourceID := currentItem.Metadata["ResourceID"]
+	subscriptions := getQuerySubscriptions(armclient.GetSubscriptionIDFromResourceID(resourceID))
+	data, err := e.client.DoResourceGraphQueryReturningObjectArray(ctx, subscriptions, buildReferencedByQuery(resourceID))
+	if err != nil {
+		return ExpanderResult{
+			Err:               fmt.Errorf("Error querying Resource Graph: %s %s", err, data),
+			SourceDescription: "RelatedResourcesExpander request",
+			IsPrimaryResponse: true,
+		}
+	}
+	var response struct {
+		Data []relatedResource `json:"data"`
+	}
+	if err := json.Unmarshal([]byte(data), &response); err != nil {
+		return ExpanderResult{
+			Err:               fmt.Errorf("Error unmarshalling Resource Graph response: %s", err),
+			SourceDescription: "RelatedResourcesExpander request",
+			IsPrimaryResponse: true,
+		}
+	}
+
+	nodes := []*TreeNode{}
+	for index, resource := range response.Data {
+		nodes = append(nodes, newRelatedResourceLinkNode(currentItem, index, resource))
+	}
+	return ExpanderResult{
+		Response:          ExpanderResponse{Response: renderRelatedResources("Referenced by", response.Data), ResponseType: interfaces.ResponsePlainText},
+		SourceDescription: "RelatedResourcesExpander request",
+		Nodes:             nodes,
+		IsPrimaryResponse: true,
+	}
+}
+
+func (e *RelatedResourcesExpander) expandReferences(ctx context.Context, currentItem *TreeNode) ExpanderResult {
+	resourceID := currentItem.Metadata["ResourceID"]
+	data, err := e.client.DoRequest(ctx, "GET", currentItem.Metadata["ResourceExpandURL"])
+	if err != nil {
+		return ExpanderResult{
+			Err:               fmt.Errorf("Error getting resource: %s", err),
+			SourceDescription: "RelatedResourcesExpander request",
+			IsPrimaryResponse: true,
+		}
+	}
+
+	resources := extractResourceIDReferences(data, resourceID)
+	nodes := []*TreeNode{}
+	for index, resource := range resources {
+		nodes = append(nodes, newRelatedResourceLinkNode(currentItem, index, resource))
+	}
+	return ExpanderResult{
+		Response:          ExpanderResponse{Response: renderRelatedResources("References", resources), ResponseType: interfaces.ResponsePlainText},
+		SourceDescription: "RelatedResourcesExpander request",
+		Nodes:             nodes,
+		IsPrimaryResponse: true,
+	}
+}
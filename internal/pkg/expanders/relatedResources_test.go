@@ -0,0 +1,42 @@
+package expanders
+
+import (
+	"testing"
+
+	"github.com/nbio/st"
+)
+
+func Test_RelatedResources_ExtractReferences(t *testing.T) {
+	const subID = "00000000-0000-0000-0000-000000000000"
+	const selfID = "/subscriptions/" + subID + "/resourceGroups/rg1/providers/Microsoft.Web/sites/app1"
+	data := `{
+		"id": "` + selfID + `",
+		"properties": {
+			"serverFarmId": "/subscriptions/` + subID + `/resourceGroups/rg1/providers/Microsoft.Web/serverfarms/plan1",
+			"virtualNetworkSubnetId": "/subscriptions/` + subID + `/resourceGroups/rg2/providers/Microsoft.Network/virtualNetworks/vnet1/subnets/default",
+			"keyVaultReferenceIdentity": "/subscriptions/` + subID + `/resourcegroups/RG1/providers/Microsoft.Web/serverFarms/PLAN1",
+			"slot": "` + selfID + `/slots/staging",
+			"provider": "/subscriptions/` + subID + `/providers/Microsoft.Web",
+			"roleDefinitionId": "/subscriptions/` + subID + `/providers/Microsoft.Authorization/roleDefinitions/abc"
+		}
+	}`
+
+	resources := extractResourceIDReferences(data, selfID)
+	st.Expect(t, len(resources), 3)
+	st.Expect(t, resources[0].Type, "Microsoft.Authorization/roleDefinitions")
+	st.Expect(t, resources[1].Type, "Microsoft.Network/virtualNetworks/subnets")
+	st.Expect(t, resources[1].Name, "default")
+	st.Expect(t, resources[1].SubscriptionID, subID)
+	st.Expect(t, resources[2].Type, "Microsoft.Web/serverfarms")
+	st.Expect(t, resources[2].Name, "plan1")
+}
+
+func Test_RelatedResources_ReferencedByQuery(t *testing.T) {
+	query := buildReferencedByQuery("/subscriptions/1/resourceGroups/rg1/providers/Microsoft.KeyVault/vaults/kv1")
+	st.Expect(t, query, "Resources | where id !~ '/subscriptions/1/resourceGroups/rg1/providers/Microsoft.KeyVault/vaults/kv1' and tostring(properties) contains '/subscriptions/1/resourceGroups/rg1/providers/Microsoft.KeyVault/vaults/kv1' | project id, name, type, subscriptionId | order by type asc, name asc")
+}
+
+func Test_RelatedResources_ResourceTypeFromID(t *testing.T) {
+	st.Expect(t, getResourceTypeFromID("/subscriptions/1/resourceGroups/rg1/providers/Microsoft.Network/virtualNetworks/vnet1/subnets/default"), "Microsoft.Network/virtualNetworks/subnets")
+	st.Expect(t, getResourceTypeFromID("/subscriptions/1/resourceGroups/rg1"), "")
+}
@@ -0,0 +1,495 @@
+package expanders
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/lawrencegripper/azbrowse/internal/pkg/interfaces"
+	"github.com/lawrencegripper/azbrowse/internal/pkg/style"
+	"github.com/lawrencegripper/azbrowse/pkg/armclient"
+)
+
+const (
+	resourceHealthType              = "resourceHealth"
+	subResourceHealthType           = "subResourceHealth"
+	serviceHealthType               = "serviceHealth"
+	serviceHealthCategoryType       = "serviceHealth.category"
+	serviceHealthEventType          = "serviceHealth.event"
+	resourceHealthAPIVersion        = "2020-05-01"
+	serviceHealthAPIVersion         = "2022-10-01"
+	serviceHealthCategoryIncidents  = "Active incidents"
+	serviceHealthCategoryMaintenace = "Planned maintenance"
+	serviceHealthCategoryAdvisories = "Health advisories"
+)
+
+// Check interface
+var _ Expander = &ResourceHealthExpander{}
+
+// ResourceHealthExpander expands the Resource Health for resources and the Service Health for subscriptions
+type ResourceHealthExpander struct {
+	ExpanderBase
+	client *armclient.Client
+}
+
+func (e *ResourceHealthExpander) setClient(c *armclient.Client) {
+	e.client = c
+}
+
+// Name returns the name of the expander
+func (e *ResourceHealthExpander) Name() string {
+	return "ResourceHealthExpander"
+}
+
+// DoesExpand checks if this is a resource or a health node
+func (e *ResourceHealthExpander) DoesExpand(ctx context.Context, currentItem *TreeNode) (bool, error) {
+	switch currentItem.ItemType {
+	case ResourceType, resourceHealthType, serviceHealthType, serviceHealthCategoryType:
+		return true, nil
+	}
+	return false, nil
+}
+
+// Expand returns the health nodes
+func (e *ResourceHealthExpander) Expand(ctx context.Context, currentItem *TreeNode) ExpanderResult {
+	switch currentItem.ItemType {
+	case ResourceType:
+		return ExpanderResult{
+			Nodes: []*TreeNode{
+				{
+					Parentid:              currentItem.ID,
+					Namespace:             "None",
+					Display:               style.Subtle("[Microsoft.ResourceHealth]") + "\n  Health",
+					Name:                  "Health",
+					ID:                    currentItem.ID + "/providers/Microsoft.ResourceHealth/availabilityStatuses",
+					ExpandURL:             currentItem.ID + "/providers/Microsoft.ResourceHealth/availabilityStatuses?api-version=" + resourceHealthAPIVersion,
+					ItemType:              resourceHealthType,
+					SubscriptionID:        currentItem.SubscriptionID,
+					SuppressSwaggerExpand: true,
+					SuppressGenericExpand: true,
+				},
+			},
+			SourceDescription: "ResourceHealthExpander",
+			IsPrimaryResponse: false,
+		}
+	case resourceHealthType:
+		return e.expandResourceHealth(ctx, currentItem)
+	case serviceHealthType:
+		return e.expandServiceHealth(ctx, currentItem)
+	case serviceHealthCategoryType:
+		return e.expandServiceHealthCategory(currentItem)
+	}
+	return ExpanderResult{
+		SourceDescription: "ResourceHealthExpander",
+		IsPrimaryResponse: true,
+		Err:               fmt.Errorf("Unhandled ItemType: %q", currentItem.ItemType),
+	}
+}
+
+// newServiceHealthNode creates the "Service Health" node for a subscription
+func newServiceHealthNode(parent *TreeNode) *TreeNode {
+	return &TreeNode{
+		Parentid:              parent.ID,
+		Namespace:             "None",
+		Display:               style.Subtle("[Microsoft.ResourceHealth]") + "\n  Service Health",
+		Name:                  "Service Health",
+		ID:                    parent.ID + "/providers/Microsoft.ResourceHealth/events",
+		ExpandURL:             parent.ID + "/providers/Microsoft.ResourceHealth/events?api-version=" + serviceHealthAPIVersion,
+		ItemType:              serviceHealthType,
+		SubscriptionID:        parent.SubscriptionID,
+		SuppressSwaggerExpand: true,
+		SuppressGenericExpand: true,
+	}
+}
+
+// AvailabilityStatus is the health of a resource
+type AvailabilityStatus struct {
+	ID         string `json:"id"`
+	Name       string `json:"name"`
+	Properties struct {
+		AvailabilityState  string `json:"availabilityState"`
+		Title              string `json:"title"`
+		Summary            string `json:"summary"`
+		DetailedStatus     string `json:"detailedStatus"`
+		ReasonType         string `json:"reasonType"`
+		ReasonChronicity   string `json:"reasonChronicity"`
+		HealthEventCause   string `json:"healthEventCause"`
+		OccurredTime       string `json:"occuredTime"` // sic
+		ReportedTime       string `json:"reportedTime"`
+		ResolutionETA      string `json:"resolutionETA"`
+		RecommendedActions []struct {
+			Action        string `json:"action"`
+			ActionURL     string `json:"actionUrl"`
+			ActionURLText string `json:"actionUrlText"`
+		} `json:"recommendedActions"`
+	} `json:"properties"`
+}
+
+// AvailabilityStatusListResponse is the response when listing availability statuses
+type AvailabilityStatusListResponse struct {
+	Value    []json.RawMessage `json:"value"`
+	NextLink string            `json:"nextLink"`
+}
+
+func (e *ResourceHealthExpander) expandResourceHealth(ctx context.Context, currentItem *TreeNode) ExpanderResult {
+	data, err := e.client.DoRequest(ctx, "GET", currentItem.ExpandURL)
+	if err != nil {
+		return ExpanderResult{
+			Err:               err,
+			SourceDescription: "ResourceHealthExpander request",
+			IsPrimaryResponse: true,
+		}
+	}
+
+	var response AvailabilityStatusListResponse
+	if err := json.Unmarshal([]byte(data), &response); err != nil {
+		return ExpanderResult{
+			Err:               fmt.Errorf("Error unmarshalling availability statuses: %s", err),
+			SourceDescription: "ResourceHealthExpander request",
+			IsPrimaryResponse: true,
+		}
+	}
+
+	statuses := []AvailabilityStatus{}
+	newItems := []*TreeNode{}
+	for _, raw := range response.Value {
+		var status AvailabilityStatus
+		if err := json.Unmarshal(raw, &status); err != nil {
+			return ExpanderResult{
+				Err:               fmt.Errorf("Error unmarshalling availability status: %s", err),
+				SourceDescription: "ResourceHealthExpander request",
+				IsPrimaryResponse: true,
+			}
+		}
+		statuses = append(statuses, status)
+
+		properties := status.Properties
+		newItems = append(newItems, &TreeNode{
+			Parentid: currentItem.ID,
+			ID:       status.ID,
+			Name:     properties.AvailabilityState,
+			Display: properties.AvailabilityState + "\n  " +
+				style.Subtle("At: "+properties.OccurredTime) + "\n  " +
+				style.Subtle(properties.Summary),
+			ExpandURL:             ExpandURLNotSupported,
+			ItemType:              subResourceHealthType,
+			SubscriptionID:        currentItem.SubscriptionID,
+			StatusIndicator:       DrawStatus(properties.AvailabilityState),
+			SuppressSwaggerExpand: true,
+			SuppressGenericExpand: true,
+			Metadata: map[string]string{
+				"jsonItem": string(raw),
+			},
+		})
+	}
+
+	return ExpanderResult{
+		Response:          ExpanderResponse{Response: renderResourceHealth(statuses), ResponseType: interfaces.ResponsePlainText},
+		Nodes:             newItems,
+		SourceDescription: "ResourceHealthExpander request",
+		IsPrimaryResponse: true,
+	}
+}
+
+// renderResourceHealth shows the current status (the first in the list) followed by the history
+func renderResourceHealth(statuses []AvailabilityStatus) string {
+	if len(statuses) == 0 {
+		return "No health information is available for this resource\n"
+	}
+
+	current := statuses[0].Properties
+	var sb strings.Builder
+	sb.WriteString(style.Title("Current health") + "\n\n")
+	sb.WriteString(fmt.Sprintf("State:       %s %s\n", DrawStatus(current.AvailabilityState), formatAvailabilityState(current.AvailabilityState)))
+	if current.Title != "" {
+		sb.WriteString("Title:       " + current.Title + "\n")
+	}
+	sb.WriteString("Summary:     " + current.Summary + "\n")
+	if current.DetailedStatus != "" {
+		sb.WriteString("Details:     " + current.DetailedStatus + "\n")
+	}
+	if current.ReasonType != "" {
+		sb.WriteString("Reason:      " + current.ReasonType + "\n")
+	}
+	if current.OccurredTime != "" {
+		sb.WriteString("Since:       " + current.OccurredTime + "\n")
+	}
+	if current.ResolutionETA != "" {
+		sb.WriteString("Resolution:  " + current.ResolutionETA + "\n")
+	}
+	sb.WriteString("Reported:    " + current.ReportedTime + "\n")
+	for _, action := range current.RecommendedActions {
+		text := action.Action
+		if action.ActionURLText != "" && action.ActionURL != "" {
+			text = strings.Replace(text, action.ActionURLText, action.ActionURLText+" ("+action.ActionURL+")", 1)
+		}
+		sb.WriteString(style.Subtle("  - "+text) + "\n")
+	}
+
+	if len(statuses) > 1 {
+		sb.WriteString("\n" + style.Title("History") + "\n\n")
+		for _, status := range statuses[1:] {
+			properties := status.Properties
+			sb.WriteString(fmt.Sprintf("%-30s %-12s %s\n", properties.OccurredTime, properties.AvailabilityState, properties.Summary))
+		}
+	}
+	return sb.String()
+}
+
+func formatAvailabilityState(state string) string {
+	switch state {
+	case "Available":
+		return style.Completed(state)
+	case "Degraded":
+		return style.Warning(state)
+	case "Unavailable":
+		return style.Removed(state)
+	}
+	return state
+}
+
+// getResourceGroupHealth returns the availability state for each resource in the resource group, keyed by lower case resource ID
+func getResourceGroupHealth(ctx context.Context, client *armclient.Client, resourceGroupID string) (map[string]string, error) {
+	data, err := client.DoRequest(ctx, "GET", resourceGroupID+"/providers/Microsoft.ResourceHealth/availabilityStatuses?api-version="+resourceHealthAPIVersion)
+	if err != nil {
+		return nil, err
+	}
+	var response struct {
+		Value []AvailabilityStatus `json:"value"`
+	}
+	if err := json.Unmarshal([]byte(data), &response); err != nil {
+		return nil, fmt.Errorf("Error unmarshalling availability statuses: %s", err)
+	}
+	healthMap := map[string]string{}
+	for _, status := range response.Value {
+		resourceID := strings.TrimSuffix(strings.ToLower(status.ID), "/providers/microsoft.resourcehealth/availabilitystatuses/current")
+		healthMap[resourceID] = status.Properties.AvailabilityState
+	}
+	return healthMap, nil
+}
+
+// ServiceHealthEvent is a service health event from Microsoft.ResourceHealth/events
+type ServiceHealthEvent struct {
+	ID         string `json:"id"`
+	Name       string `json:"name"`
+	Properties struct {
+		EventType       string `json:"eventType"`
+		Status          string `json:"status"`
+		Title           string `json:"title"`
+		Summary         string `json:"summary"`
+		Level           string `json:"level"`
+		ImpactStartTime string `json:"impactStartTime"`
+		LastUpdateTime  string `json:"lastUpdateTime"`
+		Impact          []struct {
+			ImpactedService string `json:"impactedService"`
+			ImpactedRegions []struct {
+				ImpactedRegion string `json:"impactedRegion"`
+				Status         string `json:"status"`
+			} `json:"impactedRegions"`
+		} `json:"impact"`
+	} `json:"properties"`
+}
+
+// normalizeRegion converts region display names (West Europe) and names (westeurope) to the same form
+func normalizeRegion(region string) string {
+	return strings.ToLower(strings.ReplaceAll(region, " ", ""))
+}
+
+// affectsRegions returns true if the event impacts one of the regions (or is global, or we don't know the regions)
+func (event ServiceHealthEvent) affectsRegions(regions map[string]bool) bool {
+	if len(regions) == 0 {
+		return true
+	}
+	hasRegions := false
+	for _, impact := range event.Properties.Impact {
+		for _, region := range impact.ImpactedRegions {
+			hasRegions = true
+			normalized := normalizeRegion(region.ImpactedRegion)
+			if normalized == "global" || regions[normalized] {
+				return true
+			}
+		}
+	}
+	return !hasRegions
+}
+
+// getServiceHealthCategory returns the category the event is listed under, or "" for events which aren't shown
+func getServiceHealthCategory(event ServiceHealthEvent) string {
+	if !strings.EqualFold(event.Properties.Status, "Active") {
+		return ""
+	}
+	switch event.Properties.EventType {
+	case "ServiceIssue", "EmergingIssues":
+		return serviceHealthCategoryIncidents
+	case "PlannedMaintenance":
+		return serviceHealthCategoryMaintenace
+	case "HealthAdvisory", "SecurityAdvisory":
+		return serviceHealthCategoryAdvisories
+	}
+	return ""
+}
+
+func (e *ResourceHealthExpander) getSubscriptionRegions(ctx context.Context, subscriptionID string) (map[string]bool, error) {
+	data, err := e.client.DoResourceGraphQueryReturningObjectArray(ctx, []string{subscriptionID}, "Resources | summarize count() by location")
+	if err != nil {
+		return nil, err
+	}
+	var response struct {
+		Data []struct {
+			Location string `json:"location"`
+		} `json:"data"`
+	}
+	if err := json.Unmarshal([]byte(data), &response); err != nil {
+		return nil, fmt.Errorf("Error unmarshalling resource graph response: %s", err)
+	}
+	regions := map[string]bool{}
+	for _, row := range response.Data {
+		if row.Location != "" {
+			regions[normalizeRegion(row.Location)] = true
+		}
+	}
+	return regions, nil
+}
+
+func (e *ResourceHealthExpander) expandServiceHealth(ctx context.Context, currentItem *TreeNode) ExpanderResult {
+	data, err := e.client.DoRequest(ctx, "GET", currentItem.ExpandURL)
+	if err != nil {
+		return ExpanderResult{
+			Err:               err,
+			SourceDescription: "ResourceHealthExpander request",
+			IsPrimaryResponse: true,
+		}
+	}
+	var response struct {
+		Value []json.RawMessage `json:"value"`
+	}
+	if err := json.Unmarshal([]byte(data), &response); err != nil {
+		return ExpanderResult{
+			Err:               fmt.Errorf("Error unmarshalling service health events: %s", err),
+			SourceDescription: "ResourceHealthExpander request",
+			IsPrimaryResponse: true,
+		}
+	}
+
+	// Only show events for the regions we have resources in. If we can't get the regions show everything
+	regions, regionsErr := e.getSubscriptionRegions(ctx, currentItem.SubscriptionID)
+
+	categories := map[string][]json.RawMessage{}
+	for _, raw := range response.Value {
+		var event ServiceHealthEvent
+		if err := json.Unmarshal(raw, &event); err != nil {
+			return ExpanderResult{
+				Err:               fmt.Errorf("Error unmarshalling service health event: %s", err),
+				SourceDescription: "ResourceHealthExpander request",
+				IsPrimaryResponse: true,
+			}
+		}
+		category := getServiceHealthCategory(event)
+		if category == "" || !event.affectsRegions(regions) {
+			continue
+		}
+		categories[category] = append(categories[category], raw)
+	}
+
+	var sb strings.Builder
+	sb.WriteString(style.Title("Service Health") + "\n\n")
+	if regionsErr != nil {
+		sb.WriteString(style.Warning("Unable to get the regions used by the subscription, showing events for all regions: "+regionsErr.Error()) + "\n\n")
+	} else {
+		regionNames := []string{}
+		for region := range regions {
+			regionNames = append(regionNames, region)
+		}
+		sort.Strings(regionNames)
+		sb.WriteString("Regions:  " + strings.Join(regionNames, ", ") + "\n\n")
+	}
+
+	newItems := []*TreeNode{}
+	for _, category := range []string{serviceHealthCategoryIncidents, serviceHealthCategoryMaintenace, serviceHealthCategoryAdvisories} {
+		events := categories[category]
+		sb.WriteString(fmt.Sprintf("%-22s %d\n", category+":", len(events)))
+		eventsJSON, _ := json.Marshal(events)
+		statusIndicator := ""
+		if category == serviceHealthCategoryIncidents && len(events) > 0 {
+			statusIndicator = DrawStatus("Degraded")
+		}
+		newItems = append(newItems, &TreeNode{
+			Parentid:              currentItem.ID,
+			ID:                    currentItem.ID + "/<" + strings.ReplaceAll(strings.ToLower(category), " ", "-") + ">",
+			Name:                  category,
+			Display:               category + "\n  " + style.Subtle(fmt.Sprintf("%d event(s)", len(events))),
+			ExpandURL:             ExpandURLNotSupported,
+			ItemType:              serviceHealthCategoryType,
+			SubscriptionID:        currentItem.SubscriptionID,
+			StatusIndicator:       statusIndicator,
+			SuppressSwaggerExpand: true,
+			SuppressGenericExpand: true,
+			Metadata: map[string]string{
+				"Events": string(eventsJSON),
+			},
+		})
+	}
+
+	return ExpanderResult{
+		Response:          ExpanderResponse{Response: sb.String(), ResponseType: interfaces.ResponsePlainText},
+		Nodes:             newItems,
+		SourceDescription: "ResourceHealthExpander request",
+		IsPrimaryResponse: true,
+	}
+}
+
+func (e *ResourceHealthExpander) expandServiceHealthCategory(currentItem *TreeNode) ExpanderResult {
+	var rawEvents []json.RawMessage
+	if err := json.Unmarshal([]byte(currentItem.Metadata["Events"]), &rawEvents); err != nil {
+		return ExpanderResult{
+			Err:               fmt.Errorf("Error unmarshalling service health events: %s", err),
+			SourceDescription: "ResourceHealthExpander request",
+			IsPrimaryResponse: true,
+		}
+	}
+
+	newItems := []*TreeNode{}
+	for _, raw := range rawEvents {
+		var event ServiceHealthEvent
+		if err := json.Unmarshal(raw, &event); err != nil {
+			return ExpanderResult{
+				Err:               fmt.Errorf("Error unmarshalling service health event: %s", err),
+				SourceDescription: "ResourceHealthExpander request",
+				IsPrimaryResponse: true,
+			}
+		}
+		services := []string{}
+		for _, impact := range event.Properties.Impact {
+			services = append(services, impact.ImpactedService)
+		}
+		newItems = append(newItems, &TreeNode{
+			Parentid: currentItem.ID,
+			ID:       event.ID,
+			Name:     event.Properties.Title,
+			Display: event.Properties.Title + "\n  " +
+				style.Subtle("Services: "+strings.Join(services, ", ")) + "\n  " +
+				style.Subtle("Started: "+event.Properties.ImpactStartTime+"  Updated: "+event.Properties.LastUpdateTime),
+			ExpandURL:             ExpandURLNotSupported,
+			ItemType:              serviceHealthEventType,
+			SubscriptionID:        currentItem.SubscriptionID,
+			SuppressSwaggerExpand: true,
+			SuppressGenericExpand: true,
+			Metadata: map[string]string{
+				"jsonItem": string(raw),
+			},
+		})
+	}
+
+	content := currentItem.Metadata["Events"]
+	if len(rawEvents) == 0 {
+		content = "[]"
+	}
+	return ExpanderResult{
+		Response:          ExpanderResponse{Response: content, ResponseType: interfaces.ResponseJSON},
+		Nodes:             newItems,
+		SourceDescription: "ResourceHealthExpander request",
+		IsPrimaryResponse: true,
+	}
+}
@@ -0,0 +1,66 @@
+package expanders
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/nbio/st"
+)
+
+func newTestServiceHealthEvent(t *testing.T, eventType string, status string, regions ...string) ServiceHealthEvent {
+	impactedRegions := []map[string]string{}
+	for _, region := range regions {
+		impactedRegions = append(impactedRegions, map[string]string{"impactedRegion": region})
+	}
+	data, _ := json.Marshal(map[string]interface{}{
+		"properties": map[string]interface{}{
+			"eventType": eventType,
+			"status":    status,
+			"impact": []interface{}{
+				map[string]interface{}{
+					"impactedService": "Virtual Machines",
+					"impactedRegions": impactedRegions,
+				},
+			},
+		},
+	})
+	var event ServiceHealthEvent
+	err := json.Unmarshal(data, &event)
+	st.Expect(t, err, nil)
+	return event
+}
+
+func Test_ServiceHealth_AffectsRegions(t *testing.T) {
+	regions := map[string]bool{"westeurope": true}
+
+	st.Expect(t, newTestServiceHealthEvent(t, "ServiceIssue", "Active", "West Europe").affectsRegions(regions), true)
+	st.Expect(t, newTestServiceHealthEvent(t, "ServiceIssue", "Active", "East US", "North Europe").affectsRegions(regions), false)
+	st.Expect(t, newTestServiceHealthEvent(t, "ServiceIssue", "Active", "Global").affectsRegions(regions), true)
+	st.Expect(t, newTestServiceHealthEvent(t, "ServiceIssue", "Active").affectsRegions(regions), true)
+	st.Expect(t, newTestServiceHealthEvent(t, "ServiceIssue", "Active", "East US").affectsRegions(map[string]bool{}), true)
+}
+
+func Test_ServiceHealth_Category(t *testing.T) {
+	st.Expect(t, getServiceHealthCategory(newTestServiceHealthEvent(t, "ServiceIssue", "Active")), serviceHealthCategoryIncidents)
+	st.Expect(t, getServiceHealthCategory(newTestServiceHealthEvent(t, "PlannedMaintenance", "Active")), serviceHealthCategoryMaintenace)
+	st.Expect(t, getServiceHealthCategory(newTestServiceHealthEvent(t, "SecurityAdvisory", "Active")), serviceHealthCategoryAdvisories)
+	st.Expect(t, getServiceHealthCategory(newTestServiceHealthEvent(t, "ServiceIssue", "Resolved")), "")
+	st.Expect(t, getServiceHealthCategory(newTestServiceHealthEvent(t, "RCA", "Active")), "")
+}
+
+func Test_ResourceHealth_Render(t *testing.T) {
+	statuses := []AvailabilityStatus{}
+	err := json.Unmarshal([]byte(`[
+		{"id": "current", "properties": {"availabilityState": "Unavailable", "summary": "The VM is stopping", "occuredTime": "2020-06-01T12:00:00Z"}},
+		{"id": "old", "properties": {"availabilityState": "Available", "summary": "There aren't any known problems", "occuredTime": "2020-05-01T12:00:00Z"}}
+	]`), &statuses)
+	st.Expect(t, err, nil)
+
+	result := renderResourceHealth(statuses)
+
+	st.Expect(t, strings.Contains(result, "The VM is stopping"), true)
+	st.Expect(t, strings.Contains(result, "Since:       2020-06-01T12:00:00Z"), true)
+	st.Expect(t, strings.Contains(result, "2020-05-01T12:00:00Z"), true)
+	st.Expect(t, renderResourceHealth([]AvailabilityStatus{}), "No health information is available for this resource\n")
+}
@@ -116,17 +116,25 @@ func (e *ResourceGroupResourceExpander) Expand(ctx context.Context, currentItem
 	})
 
 	// Add Activity Log item
-	newItems = append(newItems, &TreeNode{
-		Parentid:       currentItem.ID,
-		Namespace:      "None",
-		Display:        style.Subtle("[Microsoft.Insights]") + "\n  Activity Log",
-		Name:           "Activity Log",
-		ID:             currentItem.ID + "/<activitylog>",
-		ExpandURL:      GetActivityLogExpandURL(currentItem.SubscriptionID, currentItem.Name),
-		ItemType:       activityLogType,
-		DeleteURL:      "",
-		SubscriptionID: currentItem.SubscriptionID,
-	})
+	newItems = append(newItems, newActivityLogNode(currentItem, currentItem.Name, ""))
+
+	// Add Change History item
+	newItems = append(newItems, newChangeHistoryNode(currentItem, currentItem.Name, ""))
+
+	// Get the resource health so unhealthy resources stand out
+	healthDoneChan := make(chan map[string]string, 1)
+	go func() {
+		// recover from panic, if one occurrs, and leave terminal usable
+		defer errorhandling.RecoveryWithCleanup()
+
+		healthMap, err := getResourceGroupHealth(ctx, e.client, currentItem.ID)
+		span.SetTag("healthError", err)
+		if err != nil {
+			// Health is a value add so don't fail if it isn't available
+			healthMap = map[string]string{}
+		}
+		healthDoneChan <- healthMap
+	}()
 
 	// Get the latest from the ARM API
 	method := "GET"
@@ -165,6 +173,14 @@ func (e *ResourceGroupResourceExpander) Expand(ctx context.Context, currentItem
 	wg.Wait()
 	// .....
 
+	// As with the graph query, don't hold up browsing waiting for health
+	healthMap := map[string]string{}
+	select {
+	case healthMap = <-healthDoneChan:
+	case <-time.After(time.Second):
+		span.SetTag("healthQueryTimedout", true)
+	}
+
 	err := armResponse.Error
 
 	if err != nil {
@@ -217,6 +233,11 @@ func (e *ResourceGroupResourceExpander) Expand(ctx context.Context, currentItem
 		if exists {
 			item.StatusIndicator = DrawStatus(state)
 		}
+		// Show unhealthy resources in preference to the provisioning state
+		health := healthMap[strings.ToLower(item.ID)]
+		if health == "Degraded" || health == "Unavailable" {
+			item.StatusIndicator = DrawStatus(health)
+		}
 
 		resourceTreeItems = append(resourceTreeItems, item)
 	}
@@ -281,13 +302,13 @@ func (e *ResourceGroupResourceExpander) testCases() (bool, *[]expanderTestCase)
 			treeNodeCheckerFunc: func(t *testing.T, r ExpanderResult) {
 				st.Expect(t, r.Err, nil)
 
-				// Logs, Diagnostic settings and deployment always added to an RG
-				additionalItemsAddedToRG := 3
+				// Logs, Change history, Diagnostic settings and deployment always added to an RG
+				additionalItemsAddedToRG := 4
 
 				st.Expect(t, len(r.Nodes), 10+additionalItemsAddedToRG)
 
 				// Validate content
-				st.Expect(t, r.Nodes[3].Name, "1teststorageaccount")
+				st.Expect(t, r.Nodes[4].Name, "1teststorageaccount")
 			},
 		},
 	}
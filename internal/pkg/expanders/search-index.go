@@ -0,0 +1,532 @@
+package expanders
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/lawrencegripper/azbrowse/internal/pkg/editor"
+	"github.com/lawrencegripper/azbrowse/internal/pkg/interfaces"
+	"github.com/lawrencegripper/azbrowse/internal/pkg/style"
+)
+
+// SearchIndexField is a field in an index schema. Complex fields (Edm.ComplexType) contain sub-fields
+type SearchIndexField struct {
+	Name           string             `json:"name"`
+	Type           string             `json:"type"`
+	Key            bool               `json:"key"`
+	Searchable     *bool              `json:"searchable"`
+	Filterable     *bool              `json:"filterable"`
+	Sortable       *bool              `json:"sortable"`
+	Facetable      *bool              `json:"facetable"`
+	Retrievable    *bool              `json:"retrievable"`
+	Analyzer       string             `json:"analyzer"`
+	SearchAnalyzer string             `json:"searchAnalyzer"`
+	IndexAnalyzer  string             `json:"indexAnalyzer"`
+	SynonymMaps    []string           `json:"synonymMaps"`
+	Fields         []SearchIndexField `json:"fields"`
+}
+
+// SearchIndexSchema is the index definition returned by the indexes API
+type SearchIndexSchema struct {
+	Name            string                   `json:"name"`
+	Fields          []SearchIndexField       `json:"fields"`
+	Analyzers       []map[string]interface{} `json:"analyzers"`
+	Tokenizers      []map[string]interface{} `json:"tokenizers"`
+	TokenFilters    []map[string]interface{} `json:"tokenFilters"`
+	CharFilters     []map[string]interface{} `json:"charFilters"`
+	ScoringProfiles []struct {
+		Name string `json:"name"`
+	} `json:"scoringProfiles"`
+	DefaultScoringProfile string `json:"defaultScoringProfile"`
+	Suggesters            []struct {
+		Name         string   `json:"name"`
+		SourceFields []string `json:"sourceFields"`
+	} `json:"suggesters"`
+}
+
+// SearchIndexingResult is the result for a single document in an indexing batch
+type SearchIndexingResult struct {
+	Key          string `json:"key"`
+	Status       bool   `json:"status"`
+	ErrorMessage string `json:"errorMessage"`
+	StatusCode   int    `json:"statusCode"`
+}
+
+func newSearchIndexSchemaNode(indexItem *TreeNode) *TreeNode {
+	return &TreeNode{
+		Parentid:              indexItem.ID,
+		ID:                    indexItem.ID + "/schema",
+		Namespace:             searchNamespace,
+		Name:                  "Schema",
+		Display:               "Schema",
+		ItemType:              searchIndexSchemaType,
+		ExpandURL:             ExpandURLNotSupported,
+		SuppressSwaggerExpand: true,
+		SuppressGenericExpand: true,
+		Metadata: map[string]string{
+			"SwaggerAPISetID": indexItem.Metadata["SwaggerAPISetID"],
+			"IndexURL":        indexItem.ExpandURL,
+		},
+	}
+}
+
+func newSearchDocumentsPageNode(parentID string, apiSetID string, indexKey string, documentsURL string, skip int, count int) *TreeNode {
+	return &TreeNode{
+		Parentid:              parentID,
+		ID:                    parentID + "/...more" + strconv.Itoa(skip),
+		Namespace:             searchNamespace,
+		Name:                  "More...",
+		Display:               "More...",
+		ItemType:              searchDocumentsPageType,
+		ExpandURL:             ExpandURLNotSupported,
+		ExpandInPlace:         true,
+		SuppressSwaggerExpand: true,
+		SuppressGenericExpand: true,
+		Metadata: map[string]string{
+			"SwaggerAPISetID": apiSetID,
+			"IndexKey":        indexKey,
+			"DocumentsURL":    documentsURL,
+			"Skip":            strconv.Itoa(skip),
+			"Count":           strconv.Itoa(count),
+		},
+	}
+}
+
+// expandDocuments adds a "More..." node to the documents node when the index has more documents than the first page
+func (e *AzureSearchServiceExpander) expandDocuments(ctx context.Context, currentItem *TreeNode) ExpanderResult {
+	apiSet, err := e.getAPISetForNode(currentItem)
+	if err != nil {
+		return ExpanderResult{
+			Err:               err,
+			SourceDescription: "AzureSearchServiceExpander request",
+		}
+	}
+
+	indexName := getSearchTemplateValue(currentItem, "indexName")
+	data, err := apiSet.DoRequest("GET", "/indexes('"+indexName+"')/docs/$count?api-version="+currentItem.SwaggerResourceType.Endpoint.APIVersion)
+	if err != nil {
+		return ExpanderResult{
+			Err:               fmt.Errorf("Failed to get document count: %s", err),
+			SourceDescription: "AzureSearchServiceExpander request",
+		}
+	}
+	// The count is returned as plain text (with a byte order mark)
+	count, err := strconv.Atoi(strings.TrimSpace(strings.TrimPrefix(data, "\ufeff")))
+	if err != nil {
+		return ExpanderResult{
+			Err:               fmt.Errorf("Error parsing document count %q: %s", data, err),
+			SourceDescription: "AzureSearchServiceExpander request",
+		}
+	}
+
+	nodes := []*TreeNode{}
+	if count > searchDocumentsPageSize {
+		nodes = append(nodes, newSearchDocumentsPageNode(currentItem.ID, apiSet.ID(), currentItem.Metadata["IndexKey"], currentItem.ExpandURL, searchDocumentsPageSize, count))
+	}
+	return ExpanderResult{
+		Response:          ExpanderResponse{Response: ""}, // Swagger expander will supply the response
+		SourceDescription: "AzureSearchServiceExpander request",
+		Nodes:             nodes,
+	}
+}
+
+// expandDocumentsPage loads the next page of documents using $skip/$top
+func (e *AzureSearchServiceExpander) expandDocumentsPage(ctx context.Context, currentItem *TreeNode) ExpanderResult {
+	apiSet, err := e.getAPISetForNode(currentItem)
+	if err != nil {
+		return ExpanderResult{
+			Err:               err,
+			SourceDescription: "AzureSearchServiceExpander request",
+			IsPrimaryResponse: true,
+		}
+	}
+
+	documentsURL := currentItem.Metadata["DocumentsURL"]
+	skip, _ := strconv.Atoi(currentItem.Metadata["Skip"])
+	count, _ := strconv.Atoi(currentItem.Metadata["Count"])
+	resourceType := apiSet.GetResourceTypeMatcher().GetResourceTypeForURL(ctx, documentsURL)
+	if resourceType == nil {
+		return ExpanderResult{
+			Err:               fmt.Errorf("Resource type not found for %q", documentsURL),
+			SourceDescription: "AzureSearchServiceExpander request",
+			IsPrimaryResponse: true,
+		}
+	}
+
+	pageURL := addQueryStringParameter(documentsURL, "$skip", strconv.Itoa(skip))
+	pageURL = addQueryStringParameter(pageURL, "$top", strconv.Itoa(searchDocumentsPageSize))
+	pageItem := &TreeNode{
+		ID:                  currentItem.ID,
+		ExpandURL:           pageURL,
+		SwaggerResourceType: resourceType,
+		Metadata: map[string]string{
+			"IndexKey": currentItem.Metadata["IndexKey"],
+		},
+	}
+	expandResult, err := apiSet.ExpandResource(ctx, pageItem, *resourceType)
+	if err != nil {
+		return ExpanderResult{
+			Err:               err,
+			SourceDescription: "AzureSearchServiceExpander request",
+			IsPrimaryResponse: true,
+		}
+	}
+
+	// The nodes replace the "More..." node so are added to the documents node
+	nodes := []*TreeNode{}
+	for _, subResource := range expandResult.SubResources {
+		nodes = append(nodes, GetSwaggerResourceExpander().newSubResourceNode(currentItem.Parentid, apiSet.ID(), subResource))
+	}
+	nextSkip := skip + len(expandResult.SubResources)
+	if len(expandResult.SubResources) > 0 && nextSkip < count {
+		nodes = append(nodes, newSearchDocumentsPageNode(currentItem.Parentid, apiSet.ID(), currentItem.Metadata["IndexKey"], documentsURL, nextSkip, count))
+	}
+
+	return ExpanderResult{
+		Response:          ExpanderResponse{Response: expandResult.Response, ResponseType: expandResult.ResponseType},
+		SourceDescription: "AzureSearchServiceExpander request",
+		Nodes:             nodes,
+		IsPrimaryResponse: true,
+	}
+}
+
+func (e *AzureSearchServiceExpander) expandIndexSchema(ctx context.Context, currentItem *TreeNode) ExpanderResult {
+	apiSet, err := e.getAPISetForNode(currentItem)
+	if err != nil {
+		return ExpanderResult{
+			Err:               err,
+			SourceDescription: "AzureSearchServiceExpander request",
+			IsPrimaryResponse: true,
+		}
+	}
+
+	data, err := apiSet.DoRequest("GET", currentItem.Metadata["IndexURL"])
+	if err != nil {
+		return ExpanderResult{
+			Err:               fmt.Errorf("Failed to get index: %s", err),
+			SourceDescription: "AzureSearchServiceExpander request",
+			IsPrimaryResponse: true,
+		}
+	}
+	var schema SearchIndexSchema
+	if err := json.Unmarshal([]byte(data), &schema); err != nil {
+		return ExpanderResult{
+			Err:               fmt.Errorf("Error unmarshalling index: %s", err),
+			SourceDescription: "AzureSearchServiceExpander request",
+			IsPrimaryResponse: true,
+		}
+	}
+
+	return ExpanderResult{
+		Response:          ExpanderResponse{Response: renderSearchIndexSchema(schema), ResponseType: interfaces.ResponsePlainText},
+		SourceDescription: "AzureSearchServiceExpander request",
+		IsPrimaryResponse: true,
+	}
+}
+
+// renderSearchIndexSchema renders the fields (with their attributes and analyzers) and the custom analysis components
+func renderSearchIndexSchema(schema SearchIndexSchema) string {
+	var sb strings.Builder
+	sb.WriteString(style.Title("Index: "+schema.Name) + "\n\n")
+
+	flag := func(value *bool) string {
+		if value != nil && *value {
+			return "✓"
+		}
+		return ""
+	}
+	sb.WriteString(style.Title(fmt.Sprintf("%-32s %-28s %-4s %-5s %-5s %-5s %-5s %-5s %s", "FIELD", "TYPE", "KEY", "SRCH", "FILT", "SORT", "FACET", "RETR", "ANALYZER")) + "\n")
+	var writeFields func(fields []SearchIndexField, indent string)
+	writeFields = func(fields []SearchIndexField, indent string) {
+		for _, field := range fields {
+			key := ""
+			if field.Key {
+				key = "✓"
+			}
+			analyzer := field.Analyzer
+			if field.IndexAnalyzer != "" || field.SearchAnalyzer != "" {
+				analyzer = "index: " + field.IndexAnalyzer + ", search: " + field.SearchAnalyzer
+			}
+			if len(field.SynonymMaps) > 0 {
+				analyzer += " " + style.Subtle("(synonyms: "+strings.Join(field.SynonymMaps, ", ")+")")
+			}
+			sb.WriteString(fmt.Sprintf("%-32s %-28s %-4s %-5s %-5s %-5s %-5s %-5s %s\n",
+				indent+field.Name,
+				field.Type,
+				key,
+				flag(field.Searchable),
+				flag(field.Filterable),
+				flag(field.Sortable),
+				flag(field.Facetable),
+				flag(field.Retrievable),
+				analyzer))
+			writeFields(field.Fields, indent+"  ")
+		}
+	}
+	writeFields(schema.Fields, "")
+
+	writeComponents := func(title string, components []map[string]interface{}) {
+		if len(components) == 0 {
+			return
+		}
+		sb.WriteString("\n" + style.Title(title) + "\n")
+		for _, component := range components {
+			sb.WriteString(fmt.Sprintf("  %s %s\n", component["name"], style.Subtle(summarizeSearchAnalysisComponent(component))))
+		}
+	}
+	writeComponents("Analyzers", schema.Analyzers)
+	writeComponents("Tokenizers", schema.Tokenizers)
+	writeComponents("Token filters", schema.TokenFilters)
+	writeComponents("Char filters", schema.CharFilters)
+
+	if len(schema.Suggesters) > 0 {
+		sb.WriteString("\n" + style.Title("Suggesters") + "\n")
+		for _, suggester := range schema.Suggesters {
+			sb.WriteString(fmt.Sprintf("  %s %s\n", suggester.Name, style.Subtle("("+strings.Join(suggester.SourceFields, ", ")+")")))
+		}
+	}
+	if len(schema.ScoringProfiles) > 0 {
+		sb.WriteString("\n" + style.Title("Scoring profiles") + "\n")
+		for _, profile := range schema.ScoringProfiles {
+			name := profile.Name
+			if name == schema.DefaultScoringProfile {
+				name += " " + style.Subtle("(default)")
+			}
+			sb.WriteString("  " + name + "\n")
+		}
+	}
+	return sb.String()
+}
+
+// summarizeSearchAnalysisComponent returns the type and settings for a custom analyzer, tokenizer or filter
+func summarizeSearchAnalysisComponent(component map[string]interface{}) string {
+	componentType := strings.TrimPrefix(fmt.Sprintf("%v", component["@odata.type"]), "#Microsoft.Azure.Search.")
+	settings := []string{}
+	for _, key := range sortedMapKeys(component) {
+		if key == "name" || key == "@odata.type" {
+			continue
+		}
+		switch value := component[key].(type) {
+		case []interface{}:
+			items := []string{}
+			for _, item := range value {
+				items = append(items, fmt.Sprintf("%v", item))
+			}
+			settings = append(settings, key+"=["+strings.Join(items, ", ")+"]")
+		case nil:
+			continue
+		default:
+			settings = append(settings, fmt.Sprintf("%s=%v", key, value))
+		}
+	}
+	if len(settings) == 0 {
+		return "(" + componentType + ")"
+	}
+	return "(" + componentType + ") " + strings.Join(settings, " ")
+}
+
+// parseSearchDocuments parses a JSON array of documents, or an indexing batch ({"value": [...]})
+func parseSearchDocuments(content string) ([]map[string]interface{}, error) {
+	content = strings.TrimSpace(content)
+	if content == "" {
+		return []map[string]interface{}{}, nil
+	}
+	documents := []map[string]interface{}{}
+	if strings.HasPrefix(content, "{") {
+		var batch struct {
+			Value []map[string]interface{} `json:"value"`
+		}
+		if err := json.Unmarshal([]byte(content), &batch); err != nil {
+			return nil, fmt.Errorf("Error parsing documents: %s", err)
+		}
+		if batch.Value == nil {
+			return nil, fmt.Errorf("Error parsing documents: expected an array of documents or an object with a 'value' array")
+		}
+		return batch.Value, nil
+	}
+	if err := json.Unmarshal([]byte(content), &documents); err != nil {
+		return nil, fmt.Errorf("Error parsing documents: %s", err)
+	}
+	return documents, nil
+}
+
+// buildSearchIndexBatch builds the body for the docs/index API, applying the action to each document
+func buildSearchIndexBatch(documents []map[string]interface{}, action string) (string, error) {
+	for _, document := range documents {
+		document["@search.action"] = action
+	}
+	body := struct {
+		Value []map[string]interface{} `json:"value"`
+	}{
+		Value: documents,
+	}
+	buf, err := json.Marshal(body)
+	if err != nil {
+		return "", fmt.Errorf("Error marshalling documents: %s", err)
+	}
+	return string(buf), nil
+}
+
+// renderSearchIndexingResults summarises the results from the docs/index API, listing any failed documents
+func renderSearchIndexingResults(action string, results []SearchIndexingResult) string {
+	succeeded := 0
+	failed := []SearchIndexingResult{}
+	for _, result := range results {
+		if result.Status {
+			succeeded++
+		} else {
+			failed = append(failed, result)
+		}
+	}
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("%s: %d of %d documents succeeded\n", action, succeeded, len(results)))
+	if len(failed) > 0 {
+		sb.WriteString("\n" + style.Title(fmt.Sprintf("%-32s %-6s %s", "KEY", "STATUS", "ERROR")) + "\n")
+		for _, result := range failed {
+			sb.WriteString(fmt.Sprintf("%-32s %-6d %s\n", result.Key, result.StatusCode, result.ErrorMessage))
+		}
+	}
+	return sb.String()
+}
+
+// indexDocuments prompts for a JSON file of documents, opens them in the editor to review
+// and then uploads or merges them into the index
+func (e *AzureSearchServiceExpander) indexDocuments(ctx context.Context, actionItem *TreeNode, action string) ExpanderResult {
+	item := actionItem.Parent
+	apiSet, err := e.getAPISetForNode(item)
+	if err != nil {
+		return ExpanderResult{
+			Err:               err,
+			SourceDescription: "AzureSearchServiceExpander request",
+			IsPrimaryResponse: true,
+		}
+	}
+	indexName := getSearchTemplateValue(item, "indexName")
+	apiVersion := item.SwaggerResourceType.Endpoint.APIVersion
+
+	path, err := promptForInput(ctx, e.gui, e.commandPanel, "Path to JSON file of documents to "+action+" (leave empty for a blank document)", "")
+	if err != nil {
+		return ExpanderResult{
+			Err:               err,
+			SourceDescription: "AzureSearchServiceExpander request",
+			IsPrimaryResponse: true,
+		}
+	}
+
+	var documents []map[string]interface{}
+	path = strings.TrimSpace(path)
+	if path != "" {
+		if strings.HasPrefix(path, "~/") {
+			if home, err := os.UserHomeDir(); err == nil {
+				path = filepath.Join(home, path[2:])
+			}
+		}
+		buf, err := os.ReadFile(path)
+		if err != nil {
+			return ExpanderResult{
+				Err:               fmt.Errorf("Error reading %q: %s", path, err),
+				SourceDescription: "AzureSearchServiceExpander request",
+				IsPrimaryResponse: true,
+			}
+		}
+		documents, err = parseSearchDocuments(string(buf))
+		if err != nil {
+			return ExpanderResult{
+				Err:               err,
+				SourceDescription: "AzureSearchServiceExpander request",
+				IsPrimaryResponse: true,
+			}
+		}
+	} else {
+		data, err := apiSet.DoRequest("GET", "/indexes('"+indexName+"')?api-version="+apiVersion)
+		if err != nil {
+			return ExpanderResult{
+				Err:               fmt.Errorf("Failed to get index: %s", err),
+				SourceDescription: "AzureSearchServiceExpander request",
+				IsPrimaryResponse: true,
+			}
+		}
+		indexKey, err := apiSet.getIndexKey(data)
+		if err != nil {
+			return ExpanderResult{
+				Err:               err,
+				SourceDescription: "AzureSearchServiceExpander request",
+				IsPrimaryResponse: true,
+			}
+		}
+		documents = []map[string]interface{}{{indexKey: ""}}
+	}
+
+	buf, err := json.MarshalIndent(documents, "", "  ")
+	if err != nil {
+		return ExpanderResult{
+			Err:               fmt.Errorf("Error marshalling documents: %s", err),
+			SourceDescription: "AzureSearchServiceExpander request",
+			IsPrimaryResponse: true,
+		}
+	}
+	content, err := editor.OpenForContent(string(buf), ".json")
+	if err != nil {
+		return ExpanderResult{
+			Err:               err,
+			SourceDescription: "AzureSearchServiceExpander request",
+			IsPrimaryResponse: true,
+		}
+	}
+	documents, err = parseSearchDocuments(content)
+	if err != nil {
+		return ExpanderResult{
+			Err:               err,
+			SourceDescription: "AzureSearchServiceExpander request",
+			IsPrimaryResponse: true,
+		}
+	}
+	if len(documents) == 0 {
+		return ExpanderResult{
+			Err:               fmt.Errorf("User canceled"),
+			SourceDescription: "AzureSearchServiceExpander request",
+			IsPrimaryResponse: true,
+		}
+	}
+
+	body, err := buildSearchIndexBatch(documents, action)
+	if err != nil {
+		return ExpanderResult{
+			Err:               err,
+			SourceDescription: "AzureSearchServiceExpander request",
+			IsPrimaryResponse: true,
+		}
+	}
+	headers := map[string]string{
+		"Content-Type": "application/json",
+	}
+	data, err := apiSet.DoRequestWithBodyAndHeaders("POST", "/indexes('"+indexName+"')/docs/index?api-version="+apiVersion, body, headers)
+	if err != nil {
+		return ExpanderResult{
+			Err:               fmt.Errorf("Failed to %s documents: %s", action, err),
+			SourceDescription: "AzureSearchServiceExpander request",
+			IsPrimaryResponse: true,
+		}
+	}
+	var response struct {
+		Value []SearchIndexingResult `json:"value"`
+	}
+	if err := json.Unmarshal([]byte(data), &response); err != nil {
+		return ExpanderResult{
+			Err:               fmt.Errorf("Error unmarshalling indexing response: %s", err),
+			SourceDescription: "AzureSearchServiceExpander request",
+			IsPrimaryResponse: true,
+		}
+	}
+
+	return ExpanderResult{
+		Response:          ExpanderResponse{Response: renderSearchIndexingResults(action, response.Value), ResponseType: interfaces.ResponsePlainText},
+		SourceDescription: "AzureSearchServiceExpander request",
+		IsPrimaryResponse: true,
+	}
+}
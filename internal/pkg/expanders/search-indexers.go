@@ -0,0 +1,324 @@
+package expanders
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/awesome-gocui/gocui"
+	"github.com/lawrencegripper/azbrowse/internal/pkg/errorhandling"
+	"github.com/lawrencegripper/azbrowse/internal/pkg/eventing"
+	"github.com/lawrencegripper/azbrowse/internal/pkg/interfaces"
+	"github.com/lawrencegripper/azbrowse/internal/pkg/style"
+)
+
+const (
+	searchIndexerPollInterval    = time.Second * 5
+	searchIndexerTrackingTimeout = time.Hour
+)
+
+// SearchIndexerExecutionResult is the result of an indexer run
+type SearchIndexerExecutionResult struct {
+	Status         string     `json:"status"`
+	ErrorMessage   string     `json:"errorMessage"`
+	StartTime      *time.Time `json:"startTime"`
+	EndTime        *time.Time `json:"endTime"`
+	ItemsProcessed int        `json:"itemsProcessed"`
+	ItemsFailed    int        `json:"itemsFailed"`
+	Errors         []struct {
+		Key          string `json:"key"`
+		ErrorMessage string `json:"errorMessage"`
+	} `json:"errors"`
+	Warnings []struct {
+		Key     string `json:"key"`
+		Message string `json:"message"`
+	} `json:"warnings"`
+}
+
+// SearchIndexerStatus is returned by the indexer search.status API
+type SearchIndexerStatus struct {
+	Status           string                         `json:"status"`
+	LastResult       *SearchIndexerExecutionResult  `json:"lastResult"`
+	ExecutionHistory []SearchIndexerExecutionResult `json:"executionHistory"`
+}
+
+func (r SearchIndexerExecutionResult) getDuration() time.Duration {
+	if r.StartTime == nil || r.EndTime == nil {
+		return 0
+	}
+	return r.EndTime.Sub(*r.StartTime)
+}
+
+func drawSearchIndexerExecutionStatus(status string) string {
+	switch status {
+	case "success":
+		return DrawStatus("Succeeded")
+	case "inProgress":
+		return DrawStatus("Running")
+	case "transientFailure", "persistentFailure":
+		return DrawStatus("Failed")
+	case "reset":
+		return "⟲"
+	}
+	return ""
+}
+
+// isSearchIndexerRunComplete checks whether the run submitted at the specified time has completed.
+// The last result is ignored if it started before the run was submitted (allowing for clock skew) as
+// it can take a few seconds for the new run to show up
+func isSearchIndexerRunComplete(status SearchIndexerStatus, submitted time.Time) (completed bool, failed bool) {
+	lastResult := status.LastResult
+	if lastResult == nil || lastResult.StartTime == nil || lastResult.StartTime.Before(submitted.Add(-time.Minute)) {
+		return false, false
+	}
+	switch lastResult.Status {
+	case "success", "reset":
+		return true, false
+	case "transientFailure", "persistentFailure":
+		return true, true
+	}
+	return false, false
+}
+
+// renderSearchIndexerStatus renders the indexer status and a table of executions (most recent first)
+func renderSearchIndexerStatus(status SearchIndexerStatus) string {
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("Indexer status: %s\n\n", status.Status))
+	if len(status.ExecutionHistory) == 0 {
+		sb.WriteString("No executions found\n")
+		return sb.String()
+	}
+	formatTime := func(value *time.Time) string {
+		if value == nil {
+			return "-"
+		}
+		return value.Local().Format("2006-01-02 15:04:05")
+	}
+	sb.WriteString(style.Title(fmt.Sprintf("%-18s %-20s %-10s %-10s %-8s %s", "STATUS", "STARTED", "DURATION", "PROCESSED", "FAILED", "ERROR")) + "\n")
+	for _, execution := range status.ExecutionHistory {
+		sb.WriteString(fmt.Sprintf("%-18s %-20s %-10s %-10d %-8d %s\n",
+			execution.Status,
+			formatTime(execution.StartTime),
+			formatDuration(execution.getDuration()),
+			execution.ItemsProcessed,
+			execution.ItemsFailed,
+			execution.ErrorMessage))
+		for _, executionError := range execution.Errors {
+			sb.WriteString(style.Subtle(fmt.Sprintf("  └ %s: %s", executionError.Key, executionError.ErrorMessage)) + "\n")
+		}
+	}
+	return sb.String()
+}
+
+func newSearchIndexerHistoryNode(indexerItem *TreeNode) *TreeNode {
+	return &TreeNode{
+		Parentid:              indexerItem.ID,
+		ID:                    indexerItem.ID + "/history",
+		Namespace:             searchNamespace,
+		Name:                  "Execution history",
+		Display:               "Execution history",
+		ItemType:              searchIndexerHistoryType,
+		ExpandURL:             ExpandURLNotSupported,
+		SuppressSwaggerExpand: true,
+		SuppressGenericExpand: true,
+		Metadata: map[string]string{
+			"SwaggerAPISetID": indexerItem.Metadata["SwaggerAPISetID"],
+			"indexerName":     getSearchTemplateValue(indexerItem, "indexerName"),
+			"APIVersion":      indexerItem.SwaggerResourceType.Endpoint.APIVersion,
+		},
+	}
+}
+
+func getSearchIndexerURL(indexerName string, operation string, apiVersion string) string {
+	return "/indexers('" + indexerName + "')/" + operation + "?api-version=" + apiVersion
+}
+
+func (e *AzureSearchServiceExpander) getIndexerStatus(apiSet *SwaggerAPISetSearch, indexerName string, apiVersion string) (string, SearchIndexerStatus, error) {
+	data, err := apiSet.DoRequest("GET", getSearchIndexerURL(indexerName, "search.status", apiVersion))
+	if err != nil {
+		return "", SearchIndexerStatus{}, fmt.Errorf("Failed to get indexer status: %s", err)
+	}
+	var status SearchIndexerStatus
+	if err := json.Unmarshal([]byte(data), &status); err != nil {
+		return "", SearchIndexerStatus{}, fmt.Errorf("Error unmarshalling indexer status: %s", err)
+	}
+	return data, status, nil
+}
+
+func (e *AzureSearchServiceExpander) expandIndexerHistory(ctx context.Context, currentItem *TreeNode) ExpanderResult {
+	apiSet, err := e.getAPISetForNode(currentItem)
+	if err != nil {
+		return ExpanderResult{
+			Err:               err,
+			SourceDescription: "AzureSearchServiceExpander request",
+			IsPrimaryResponse: true,
+		}
+	}
+
+	data, status, err := e.getIndexerStatus(apiSet, currentItem.Metadata["indexerName"], currentItem.Metadata["APIVersion"])
+	if err != nil {
+		return ExpanderResult{
+			Err:               err,
+			SourceDescription: "AzureSearchServiceExpander request",
+			IsPrimaryResponse: true,
+		}
+	}
+	// Keep the JSON for each execution to show when the node is expanded
+	var rawStatus struct {
+		ExecutionHistory []json.RawMessage `json:"executionHistory"`
+	}
+	if err := json.Unmarshal([]byte(data), &rawStatus); err != nil {
+		return ExpanderResult{
+			Err:               fmt.Errorf("Error unmarshalling indexer status: %s", err),
+			SourceDescription: "AzureSearchServiceExpander request",
+			IsPrimaryResponse: true,
+		}
+	}
+
+	nodes := []*TreeNode{}
+	for index, execution := range status.ExecutionHistory {
+		started := "-"
+		if execution.StartTime != nil {
+			started = execution.StartTime.Local().Format("2006-01-02 15:04:05")
+		}
+		nodes = append(nodes, &TreeNode{
+			Parentid: currentItem.ID,
+			ID:       currentItem.ID + "/" + strconv.Itoa(index),
+			Name:     started,
+			Display: started + "\n   " +
+				style.Subtle(fmt.Sprintf("Status: %s  Processed: %d  Failed: %d", execution.Status, execution.ItemsProcessed, execution.ItemsFailed)),
+			ItemType:              searchIndexerExecutionType,
+			ExpandURL:             ExpandURLNotSupported,
+			StatusIndicator:       drawSearchIndexerExecutionStatus(execution.Status),
+			SuppressSwaggerExpand: true,
+			SuppressGenericExpand: true,
+			Metadata: map[string]string{
+				"jsonItem": string(rawStatus.ExecutionHistory[index]),
+			},
+		})
+	}
+
+	return ExpanderResult{
+		Response:          ExpanderResponse{Response: renderSearchIndexerStatus(status), ResponseType: interfaces.ResponsePlainText},
+		SourceDescription: "AzureSearchServiceExpander request",
+		Nodes:             nodes,
+		IsPrimaryResponse: true,
+	}
+}
+
+// runIndexer starts the indexer and tracks the run in the background until it completes
+func (e *AzureSearchServiceExpander) runIndexer(ctx context.Context, actionItem *TreeNode) ExpanderResult {
+	indexerItem := actionItem.Parent
+	apiSet, err := e.getAPISetForNode(indexerItem)
+	if err != nil {
+		return ExpanderResult{
+			Err:               err,
+			SourceDescription: "AzureSearchServiceExpander request",
+			IsPrimaryResponse: true,
+		}
+	}
+	indexerName := getSearchTemplateValue(indexerItem, "indexerName")
+	apiVersion := indexerItem.SwaggerResourceType.Endpoint.APIVersion
+
+	submitted := time.Now()
+	_, err = apiSet.DoRequest("POST", getSearchIndexerURL(indexerName, "search.run", apiVersion))
+	if err != nil {
+		return ExpanderResult{
+			Err:               fmt.Errorf("Failed to run indexer: %s", err),
+			SourceDescription: "AzureSearchServiceExpander request",
+			IsPrimaryResponse: true,
+		}
+	}
+
+	go func() {
+		// recover from panic, if one occurrs, and leave terminal usable
+		defer errorhandling.RecoveryWithCleanup()
+
+		message := "Run indexer " + indexerName
+		event, _ := eventing.SendStatusEvent(&eventing.StatusEvent{
+			InProgress: true,
+			IsToast:    true,
+			Message:    message,
+			Timeout:    searchIndexerTrackingTimeout,
+		})
+
+		deadline := time.Now().Add(searchIndexerTrackingTimeout)
+		completed, failed := false, false
+		var status SearchIndexerStatus
+		var err error
+		for !completed && time.Now().Before(deadline) {
+			time.Sleep(searchIndexerPollInterval)
+			_, status, err = e.getIndexerStatus(apiSet, indexerName, apiVersion)
+			if err != nil {
+				break
+			}
+			completed, failed = isSearchIndexerRunComplete(status, submitted)
+			if status.LastResult != nil {
+				actionItem.StatusIndicator = drawSearchIndexerExecutionStatus(status.LastResult.Status)
+				event.Message = fmt.Sprintf("%s: %s (%d processed)", message, status.LastResult.Status, status.LastResult.ItemsProcessed)
+				event.Update()
+			}
+			if e.gui != nil {
+				// Force UI to re-render to pickup
+				e.gui.Update(func(g *gocui.Gui) error {
+					return nil
+				})
+			}
+		}
+
+		event.InProgress = false
+		switch {
+		case err != nil:
+			event.Failure = true
+			event.Message = message + " failed: " + err.Error()
+		case !completed:
+			event.Failure = true
+			event.Message = message + " still running - stopped tracking"
+		case failed:
+			event.Failure = true
+			event.Message = message + " failed: " + status.LastResult.ErrorMessage
+		default:
+			event.Message = fmt.Sprintf("%s COMPLETED (%d processed, %d failed)", message, status.LastResult.ItemsProcessed, status.LastResult.ItemsFailed)
+		}
+		event.SetTimeout(time.Second * 15)
+		event.Update()
+	}()
+
+	return ExpanderResult{
+		Response:          ExpanderResponse{Response: "Run submitted for indexer " + indexerName + ". Progress is shown in the status bar and next to the action.", ResponseType: interfaces.ResponsePlainText},
+		SourceDescription: "AzureSearchServiceExpander request",
+		IsPrimaryResponse: true,
+	}
+}
+
+// resetIndexer resets the change tracking state so that the next run re-indexes all documents
+func (e *AzureSearchServiceExpander) resetIndexer(ctx context.Context, actionItem *TreeNode) ExpanderResult {
+	indexerItem := actionItem.Parent
+	apiSet, err := e.getAPISetForNode(indexerItem)
+	if err != nil {
+		return ExpanderResult{
+			Err:               err,
+			SourceDescription: "AzureSearchServiceExpander request",
+			IsPrimaryResponse: true,
+		}
+	}
+	indexerName := getSearchTemplateValue(indexerItem, "indexerName")
+
+	_, err = apiSet.DoRequest("POST", getSearchIndexerURL(indexerName, "search.reset", indexerItem.SwaggerResourceType.Endpoint.APIVersion))
+	if err != nil {
+		return ExpanderResult{
+			Err:               fmt.Errorf("Failed to reset indexer: %s", err),
+			SourceDescription: "AzureSearchServiceExpander request",
+			IsPrimaryResponse: true,
+		}
+	}
+
+	return ExpanderResult{
+		Response:          ExpanderResponse{Response: "Indexer " + indexerName + " has been reset. The next run will re-index all documents.", ResponseType: interfaces.ResponsePlainText},
+		SourceDescription: "AzureSearchServiceExpander request",
+		IsPrimaryResponse: true,
+	}
+}
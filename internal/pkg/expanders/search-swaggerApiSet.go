@@ -7,6 +7,8 @@ import (
 	"fmt"
 	"io/ioutil"
 	"net/http"
+	"strconv"
+	"strings"
 
 	"github.com/lawrencegripper/azbrowse/internal/pkg/interfaces"
 	"github.com/lawrencegripper/azbrowse/pkg/swagger"
@@ -34,17 +36,19 @@ var _ SwaggerAPISet = SwaggerAPISetSearch{}
 
 // SwaggerAPISetSearch holds the config for working with an Azure Search Service
 type SwaggerAPISetSearch struct {
-	resourceTypes  []swagger.ResourceType
-	httpClient     http.Client
-	searchID       string // ARM resource ID for the search service (/subscriptions/....)
-	searchEndpoint string // https://<name>.search.windows.net/
-	adminKey       string
+	resourceTypes       []swagger.ResourceType
+	resourceTypeMatcher *swagger.ResourceTypeMatcher
+	httpClient          http.Client
+	searchID            string // ARM resource ID for the search service (/subscriptions/....)
+	searchEndpoint      string // https://<name>.search.windows.net/
+	adminKey            string
 }
 
 // NewSwaggerAPISetSearch creates a new SwaggerAPISetSearch
 func NewSwaggerAPISetSearch(resourceTypes []swagger.ResourceType, searchID string, searchEndpoint string, adminKey string) SwaggerAPISetSearch {
 	c := SwaggerAPISetSearch{}
 	c.resourceTypes = resourceTypes
+	c.resourceTypeMatcher = swagger.NewResourceTypeMatcher(c.resourceTypes)
 	c.httpClient = http.Client{}
 	c.searchID = searchID
 	c.searchEndpoint = searchEndpoint
@@ -74,6 +78,11 @@ func (c SwaggerAPISetSearch) GetResourceTypes() []swagger.ResourceType {
 	return c.resourceTypes
 }
 
+// GetResourceTypeMatcher returns the ResourceTypeMatcher for the ResourceTypes in the API Set
+func (c SwaggerAPISetSearch) GetResourceTypeMatcher() *swagger.ResourceTypeMatcher {
+	return c.resourceTypeMatcher
+}
+
 // DoRequest makes a request against the search endpoint
 func (c SwaggerAPISetSearch) DoRequest(verb string, url string) (string, error) {
 	return c.DoRequestWithBody(verb, url, "")
@@ -115,21 +124,34 @@ func (c SwaggerAPISetSearch) DoRequestWithBodyAndHeaders(verb string, url string
 	return "", fmt.Errorf("Response failed with %s (%s): %s", response.Status, url, data)
 }
 
+// addQueryStringParameter appends a query string parameter to the URL
+func addQueryStringParameter(url string, name string, value string) string {
+	separator := "?"
+	if strings.Contains(url, "?") {
+		separator = "&"
+	}
+	return url + separator + name + "=" + value
+}
+
 // ExpandResource returns metadata about child resources of the specified resource node
 func (c SwaggerAPISetSearch) ExpandResource(ctx context.Context, currentItem *TreeNode, resourceType swagger.ResourceType) (APISetExpandResponse, error) {
 
 	subResources := []SubResource{}
+	currentItemTemplateURL := currentItem.SwaggerResourceType.Endpoint.TemplateURL
+
 	url := currentItem.ExpandURL
+	if currentItemTemplateURL == searchDocumentsTemplateURL && !strings.Contains(url, "$top=") {
+		// limit the first page of documents, subsequent pages are loaded by "More..." nodes
+		url = addQueryStringParameter(url, "$top", strconv.Itoa(searchDocumentsPageSize))
+	}
 	data, err := c.DoRequest("GET", url)
 	if err != nil {
 		err = fmt.Errorf("Failed to make request: %s", err)
 		return APISetExpandResponse{}, err
 	}
 
-	currentItemTemplateURL := currentItem.SwaggerResourceType.Endpoint.TemplateURL
-
 	indexKey := ""
-	if currentItemTemplateURL == "/indexes('{indexName}')" {
+	if currentItemTemplateURL == searchIndexTemplateURL {
 		indexKey, err = c.getIndexKey(data)
 		if err != nil {
 			return APISetExpandResponse{Response: data}, err
@@ -155,7 +177,7 @@ func (c SwaggerAPISetSearch) ExpandResource(ctx context.Context, currentItem *Tr
 
 		var extraIDs []string
 		var err error
-		if currentItemTemplateURL == "/indexes('{indexName}')/docs" {
+		if currentItemTemplateURL == searchDocumentsTemplateURL {
 			extraIDs, err = c.getKeys(data, indexKey)
 		} else {
 			extraIDs, err = c.getNames(data)
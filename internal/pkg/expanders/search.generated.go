@@ -1,6 +1,8 @@
 package expanders
 
 import (
+	_ "embed"
+
 	"github.com/lawrencegripper/azbrowse/pkg/endpoints"
 	"github.com/lawrencegripper/azbrowse/pkg/swagger"
 )
@@ -8,66 +10,121 @@ import (
 func (e *AzureSearchServiceExpander) loadResourceTypes() []swagger.ResourceType {
 	return []swagger.ResourceType{
 		{
-			Display:  "datasources",
-			Endpoint: endpoints.MustGetEndpointInfoFromURL("/datasources", "2019-05-06"),
+			Display:        "datasources",
+			Endpoint:       endpoints.MustGetEndpointInfoFromURL("/datasources", "2019-05-06"),
+			ResponseSchema: "{\"$\":\"2822c4bc5dd4f16b\"}",
 			SubResources: []swagger.ResourceType{
 				{
 					Display:        "{dataSourceName}",
 					Endpoint:       endpoints.MustGetEndpointInfoFromURL("/datasources('{dataSourceName}')", "2019-05-06"),
+					ResponseSchema: "{\"$\":\"308490f7da9b33ac\"}",
 					DeleteEndpoint: endpoints.MustGetEndpointInfoFromURL("/datasources('{dataSourceName}')", "2019-05-06"),
 					PutEndpoint:    endpoints.MustGetEndpointInfoFromURL("/datasources('{dataSourceName}')", "2019-05-06"),
+					PutBodySchema:  "{\"$\":\"308490f7da9b33ac\"}",
 				}},
 		},
 		{
-			Display:  "indexers",
-			Endpoint: endpoints.MustGetEndpointInfoFromURL("/indexers", "2019-05-06"),
+			Display:        "indexers",
+			Endpoint:       endpoints.MustGetEndpointInfoFromURL("/indexers", "2019-05-06"),
+			ResponseSchema: "{\"$\":\"ee74c8505645a5a3\"}",
 			SubResources: []swagger.ResourceType{
 				{
 					Display:        "{indexerName}",
 					Endpoint:       endpoints.MustGetEndpointInfoFromURL("/indexers('{indexerName}')", "2019-05-06"),
+					ResponseSchema: "{\"$\":\"638769bee9ee38e1\"}",
 					DeleteEndpoint: endpoints.MustGetEndpointInfoFromURL("/indexers('{indexerName}')", "2019-05-06"),
 					PutEndpoint:    endpoints.MustGetEndpointInfoFromURL("/indexers('{indexerName}')", "2019-05-06"),
+					PutBodySchema:  "{\"$\":\"638769bee9ee38e1\"}",
+					PostActions: []swagger.PostAction{
+						{
+							Name:     "search.reset",
+							Endpoint: endpoints.MustGetEndpointInfoFromURL("/indexers('{indexerName}')/search.reset", "2019-05-06"),
+						},
+						{
+							Name:     "search.run",
+							Endpoint: endpoints.MustGetEndpointInfoFromURL("/indexers('{indexerName}')/search.run", "2019-05-06"),
+						},
+					},
 					Children: []swagger.ResourceType{
 						{
-							Display:  "search.status",
-							Endpoint: endpoints.MustGetEndpointInfoFromURL("/indexers('{indexerName}')/search.status", "2019-05-06"),
+							Display:        "search.status",
+							Endpoint:       endpoints.MustGetEndpointInfoFromURL("/indexers('{indexerName}')/search.status", "2019-05-06"),
+							ResponseSchema: "{\"$\":\"e2d520b4b207391\"}",
 						}},
 				}},
 		},
 		{
-			Display:  "indexes",
-			Endpoint: endpoints.MustGetEndpointInfoFromURL("/indexes", "2019-05-06"),
+			Display:        "indexes",
+			Endpoint:       endpoints.MustGetEndpointInfoFromURL("/indexes", "2019-05-06"),
+			ResponseSchema: "{\"$\":\"618d1f0cba4b0687\"}",
 			SubResources: []swagger.ResourceType{
 				{
 					Display:        "{indexName}",
 					Endpoint:       endpoints.MustGetEndpointInfoFromURL("/indexes('{indexName}')", "2019-05-06"),
+					ResponseSchema: "{\"$\":\"f9d8ea597e8dc1c5\"}",
 					DeleteEndpoint: endpoints.MustGetEndpointInfoFromURL("/indexes('{indexName}')", "2019-05-06"),
 					PutEndpoint:    endpoints.MustGetEndpointInfoFromURL("/indexes('{indexName}')", "2019-05-06"),
+					PutBodySchema:  "{\"$\":\"f9d8ea597e8dc1c5\"}",
+					PostActions: []swagger.PostAction{
+						{
+							Name:         "search.analyze",
+							Endpoint:     endpoints.MustGetEndpointInfoFromURL("/indexes('{indexName}')/search.analyze", "2019-05-06"),
+							BodySkeleton: "{\"analyzer\":\"ar.microsoft\",\"charFilters\":[\"html_strip\"],\"text\":\"\",\"tokenFilters\":[\"arabic_normalization\"],\"tokenizer\":\"classic\"}",
+						},
+					},
 					Children: []swagger.ResourceType{
 						{
-							Display:  "search.stats",
-							Endpoint: endpoints.MustGetEndpointInfoFromURL("/indexes('{indexName}')/search.stats", "2019-05-06"),
+							Display:        "search.stats",
+							Endpoint:       endpoints.MustGetEndpointInfoFromURL("/indexes('{indexName}')/search.stats", "2019-05-06"),
+							ResponseSchema: "{\"$\":\"4d7f0d98b7b84361\"}",
 						},
 						{
-							Display:  "docs",
-							Endpoint: endpoints.MustGetEndpointInfoFromURL("/indexes('{indexName}')/docs", "2019-05-06"),
+							Display:        "docs",
+							Endpoint:       endpoints.MustGetEndpointInfoFromURL("/indexes('{indexName}')/docs", "2019-05-06"),
+							ResponseSchema: "{\"$\":\"e5c7f4ac58731dfa\"}",
+							PostActions: []swagger.PostAction{
+								{
+									Name:         "search.index",
+									Endpoint:     endpoints.MustGetEndpointInfoFromURL("/indexes('{indexName}')/docs/search.index", "2019-05-06"),
+									BodySkeleton: "{\"value\":[{\"@search.action\":\"upload\"}]}",
+								},
+								{
+									Name:         "search.post.autocomplete",
+									Endpoint:     endpoints.MustGetEndpointInfoFromURL("/indexes('{indexName}')/docs/search.post.autocomplete", "2019-05-06"),
+									BodySkeleton: "{\"autocompleteMode\":\"oneTerm\",\"filter\":\"\",\"fuzzy\":false,\"highlightPostTag\":\"\",\"highlightPreTag\":\"\",\"minimumCoverage\":0,\"search\":\"\",\"searchFields\":\"\",\"suggesterName\":\"\",\"top\":0}",
+								},
+								{
+									Name:         "search.post.search",
+									Endpoint:     endpoints.MustGetEndpointInfoFromURL("/indexes('{indexName}')/docs/search.post.search", "2019-05-06"),
+									BodySkeleton: "{\"count\":false,\"facets\":[\"\"],\"filter\":\"\",\"highlight\":\"\",\"highlightPostTag\":\"\",\"highlightPreTag\":\"\",\"minimumCoverage\":0,\"orderby\":\"\",\"queryType\":\"simple\",\"scoringParameters\":[\"\"],\"scoringProfile\":\"\",\"search\":\"\",\"searchFields\":\"\",\"searchMode\":\"any\",\"select\":\"\",\"skip\":0,\"top\":0}",
+								},
+								{
+									Name:         "search.post.suggest",
+									Endpoint:     endpoints.MustGetEndpointInfoFromURL("/indexes('{indexName}')/docs/search.post.suggest", "2019-05-06"),
+									BodySkeleton: "{\"filter\":\"\",\"fuzzy\":false,\"highlightPostTag\":\"\",\"highlightPreTag\":\"\",\"minimumCoverage\":0,\"orderby\":\"\",\"search\":\"\",\"searchFields\":\"\",\"select\":\"\",\"suggesterName\":\"\",\"top\":0}",
+								},
+							},
 							Children: []swagger.ResourceType{
 								{
-									Display:  "$count",
-									Endpoint: endpoints.MustGetEndpointInfoFromURL("/indexes('{indexName}')/docs/$count", "2019-05-06"),
+									Display:        "$count",
+									Endpoint:       endpoints.MustGetEndpointInfoFromURL("/indexes('{indexName}')/docs/$count", "2019-05-06"),
+									ResponseSchema: "{\"t\":\"integer\"}",
 								},
 								{
-									Display:  "search.autocomplete",
-									Endpoint: endpoints.MustGetEndpointInfoFromURL("/indexes('{indexName}')/docs/search.autocomplete", "2019-05-06"),
+									Display:        "search.autocomplete",
+									Endpoint:       endpoints.MustGetEndpointInfoFromURL("/indexes('{indexName}')/docs/search.autocomplete", "2019-05-06"),
+									ResponseSchema: "{\"$\":\"3f7598688ff4514e\"}",
 								},
 								{
-									Display:  "search.suggest",
-									Endpoint: endpoints.MustGetEndpointInfoFromURL("/indexes('{indexName}')/docs/search.suggest", "2019-05-06"),
+									Display:        "search.suggest",
+									Endpoint:       endpoints.MustGetEndpointInfoFromURL("/indexes('{indexName}')/docs/search.suggest", "2019-05-06"),
+									ResponseSchema: "{\"$\":\"5b010b36695f28bf\"}",
 								}},
 							SubResources: []swagger.ResourceType{
 								{
 									Display:        "{key}",
 									Endpoint:       endpoints.MustGetEndpointInfoFromURL("/indexes('{indexName}')/docs('{key}')", "2019-05-06"),
+									ResponseSchema: "{\"t\":\"object\",\"a\":{}}",
 									DeleteEndpoint: endpoints.MustGetEndpointInfoFromURL("/indexes('{indexName}')/docs/index", "2019-05-06"),
 									PutEndpoint:    endpoints.MustGetEndpointInfoFromURL("/indexes('{indexName}')/docs/index", "2019-05-06"),
 								}},
@@ -75,30 +132,105 @@ func (e *AzureSearchServiceExpander) loadResourceTypes() []swagger.ResourceType
 				}},
 		},
 		{
-			Display:  "servicestats",
-			Endpoint: endpoints.MustGetEndpointInfoFromURL("/servicestats", "2019-05-06"),
+			Display:        "servicestats",
+			Endpoint:       endpoints.MustGetEndpointInfoFromURL("/servicestats", "2019-05-06"),
+			ResponseSchema: "{\"$\":\"e8aec8f8e5b0e668\"}",
 		},
 		{
-			Display:  "skillsets",
-			Endpoint: endpoints.MustGetEndpointInfoFromURL("/skillsets", "2019-05-06"),
+			Display:        "skillsets",
+			Endpoint:       endpoints.MustGetEndpointInfoFromURL("/skillsets", "2019-05-06"),
+			ResponseSchema: "{\"$\":\"3dbae2fb590f998e\"}",
 			SubResources: []swagger.ResourceType{
 				{
 					Display:        "{skillsetName}",
 					Endpoint:       endpoints.MustGetEndpointInfoFromURL("/skillsets('{skillsetName}')", "2019-05-06"),
+					ResponseSchema: "{\"$\":\"e3efe39929d88d4e\"}",
 					DeleteEndpoint: endpoints.MustGetEndpointInfoFromURL("/skillsets('{skillsetName}')", "2019-05-06"),
 					PutEndpoint:    endpoints.MustGetEndpointInfoFromURL("/skillsets('{skillsetName}')", "2019-05-06"),
+					PutBodySchema:  "{\"$\":\"e3efe39929d88d4e\"}",
 				}},
 		},
 		{
-			Display:  "synonymmaps",
-			Endpoint: endpoints.MustGetEndpointInfoFromURL("/synonymmaps", "2019-05-06"),
+			Display:        "synonymmaps",
+			Endpoint:       endpoints.MustGetEndpointInfoFromURL("/synonymmaps", "2019-05-06"),
+			ResponseSchema: "{\"$\":\"94beedcda00e2ec0\"}",
 			SubResources: []swagger.ResourceType{
 				{
 					Display:        "{synonymMapName}",
 					Endpoint:       endpoints.MustGetEndpointInfoFromURL("/synonymmaps('{synonymMapName}')", "2019-05-06"),
+					ResponseSchema: "{\"$\":\"b1d24a87769801a1\"}",
 					DeleteEndpoint: endpoints.MustGetEndpointInfoFromURL("/synonymmaps('{synonymMapName}')", "2019-05-06"),
 					PutEndpoint:    endpoints.MustGetEndpointInfoFromURL("/synonymmaps('{synonymMapName}')", "2019-05-06"),
+					PutBodySchema:  "{\"$\":\"b1d24a87769801a1\"}",
 				}},
 		}}
 
 }
+
+//go:embed search.descriptions.json
+var schemaDescriptionsAzureSearchServiceExpander []byte
+
+func init() {
+	swagger.RegisterSchemaDefinitions(map[string]string{
+		"2822c4bc5dd4f16b": "{\"t\":\"object\",\"k\":\"e40d9fc67a7a7dfb\",\"p\":{\"value\":{\"t\":\"array\",\"k\":\"4c9accbf3b83512d\",\"r\":true,\"i\":{\"$\":\"308490f7da9b33ac\"}}}}",
+		"2a24975f191672b2": "{\"t\":\"object\",\"k\":\"f1bef44371f670ca\",\"q\":[\"allowedOrigins\"],\"p\":{\"allowedOrigins\":{\"t\":\"array\",\"k\":\"1a4f66a6f450cb77\",\"i\":{\"t\":\"string\"}},\"maxAgeInSeconds\":{\"t\":\"integer\",\"k\":\"c432a11e87f4b965\"}}}",
+		"2a2b9bfd557210e9": "{\"t\":\"string\",\"k\":\"3f55f46d11e14334\",\"e\":[\"transientFailure\",\"success\",\"inProgress\",\"reset\"],\"r\":true}",
+		"2cf0a0883c3f03ab": "{\"t\":\"object\",\"k\":\"139f4f8369ea2b92\",\"q\":[\"name\"],\"p\":{\"inputs\":{\"t\":\"array\",\"k\":\"de3ce7ec13811984\",\"i\":{}},\"name\":{\"t\":\"string\",\"k\":\"323d384eba6af3ab\"},\"source\":{\"t\":\"string\",\"k\":\"f9f347a5641dc3bd\"},\"sourceContext\":{\"t\":\"string\",\"k\":\"ef212b51ce2a9ba5\"}}}",
+		"308490f7da9b33ac": "{\"t\":\"object\",\"k\":\"67972ba5416af245\",\"q\":[\"name\",\"type\",\"credentials\",\"container\"],\"p\":{\"@odata.etag\":{\"t\":\"string\",\"k\":\"864daf4e16581950\"},\"container\":{\"$\":\"3a09f59df66c57a4\"},\"credentials\":{\"$\":\"879622f47a297eac\"},\"dataChangeDetectionPolicy\":{\"t\":\"object\",\"k\":\"7cc36fa423b8b87e\",\"q\":[\"@odata.type\"],\"p\":{\"@odata.type\":{\"t\":\"string\"}}},\"dataDeletionDetectionPolicy\":{\"t\":\"object\",\"k\":\"2c063ca77cabbe68\",\"q\":[\"@odata.type\"],\"p\":{\"@odata.type\":{\"t\":\"string\"}}},\"description\":{\"t\":\"string\",\"k\":\"3a27cce70dbb0d99\"},\"name\":{\"t\":\"string\",\"k\":\"6c9f5cbce202bfd6\"},\"type\":{\"t\":\"string\",\"k\":\"df2827929435e017\",\"e\":[\"azuresql\",\"cosmosdb\",\"azureblob\",\"azuretable\"]}}}",
+		"3a09f59df66c57a4": "{\"t\":\"object\",\"k\":\"b98408f793381aba\",\"q\":[\"name\"],\"p\":{\"name\":{\"t\":\"string\",\"k\":\"b7491900dbbe7f79\"},\"query\":{\"t\":\"string\",\"k\":\"4108ba1c42d1b689\"}}}",
+		"3dbae2fb590f998e": "{\"t\":\"object\",\"k\":\"e23c2b0649cef8c8\",\"p\":{\"value\":{\"t\":\"array\",\"k\":\"9865617a337fe45c\",\"r\":true,\"i\":{\"$\":\"e3efe39929d88d4e\"}}}}",
+		"3f7598688ff4514e": "{\"t\":\"object\",\"k\":\"fd8bd40fcd43eb8\",\"p\":{\"value\":{\"t\":\"array\",\"k\":\"93528bd5c118bbba\",\"r\":true,\"i\":{\"$\":\"c5aaca2f86bdf710\"}}}}",
+		"3fe8b2fbab41e6eb": "{\"t\":\"object\",\"k\":\"69fa6307c2bb84b6\",\"q\":[\"@odata.type\"],\"p\":{\"@odata.type\":{\"t\":\"string\"},\"description\":{\"t\":\"string\"}}}",
+		"41ce04688bd1e9c6": "{\"t\":\"object\",\"k\":\"18461ad8b856a0f2\",\"p\":{\"count\":{\"t\":\"integer\",\"k\":\"5f4f2f24696990e8\",\"r\":true}},\"a\":{}}",
+		"41d2f89b059b62b2": "{\"t\":\"object\",\"k\":\"13d1e23bf2d3350f\",\"r\":true,\"p\":{\"endTime\":{\"t\":\"string\",\"k\":\"fb59333871b0b993\",\"r\":true},\"errorMessage\":{\"t\":\"string\",\"k\":\"f9e7ec5952dac293\",\"r\":true},\"errors\":{\"t\":\"array\",\"k\":\"5634b415a4008777\",\"r\":true,\"i\":{\"$\":\"c20bfe0dca3b1262\"}},\"finalTrackingState\":{\"t\":\"string\",\"k\":\"e986e0d031737c4e\",\"r\":true},\"initialTrackingState\":{\"t\":\"string\",\"k\":\"285dd23f801df83f\",\"r\":true},\"itemsFailed\":{\"t\":\"integer\",\"k\":\"bead7b6a7b741189\",\"r\":true},\"itemsProcessed\":{\"t\":\"integer\",\"k\":\"f51675edc93154ca\",\"r\":true},\"startTime\":{\"t\":\"string\",\"k\":\"962a06a3ff3f2043\",\"r\":true},\"status\":{\"$\":\"2a2b9bfd557210e9\"},\"warnings\":{\"t\":\"array\",\"k\":\"48e14ee31b0e87fd\",\"r\":true,\"i\":{\"$\":\"47533c9a167423ed\"}}}}",
+		"47533c9a167423ed": "{\"t\":\"object\",\"k\":\"c5829af9b0e9e371\",\"p\":{\"details\":{\"t\":\"string\",\"k\":\"16b2e20c6b6cd2d8\",\"r\":true},\"documentationLink\":{\"t\":\"string\",\"k\":\"b1791a6c73436f59\",\"r\":true},\"key\":{\"t\":\"string\",\"k\":\"778dbd2bdb371035\",\"r\":true},\"message\":{\"t\":\"string\",\"k\":\"7dd8351da13fbef4\",\"r\":true},\"name\":{\"t\":\"string\",\"k\":\"880e3e79bab70173\",\"r\":true}}}",
+		"4a29cf67a62399ad": "{\"t\":\"object\",\"k\":\"146883be9e3ad3d7\",\"q\":[\"name\"],\"p\":{\"functionAggregation\":{\"t\":\"string\",\"k\":\"38e7487c51a8fc45\",\"e\":[\"sum\",\"average\",\"minimum\",\"maximum\",\"firstMatching\"]},\"functions\":{\"t\":\"array\",\"k\":\"b637fc198d7969cf\",\"i\":{\"$\":\"85f76b2f22ca974f\"}},\"name\":{\"t\":\"string\",\"k\":\"6cc5599dd7a80997\"},\"text\":{\"$\":\"cda5ed9642ebcd19\"}}}",
+		"4b2a5facbfc90d0c": "{\"t\":\"object\",\"k\":\"f014724b321106c9\",\"p\":{\"quota\":{\"t\":\"integer\",\"k\":\"962fd9f25b715c6e\"},\"usage\":{\"t\":\"integer\",\"k\":\"423dc63f7a68518d\"}}}",
+		"4d7f0d98b7b84361": "{\"t\":\"object\",\"k\":\"efb259edf26029f7\",\"p\":{\"documentCount\":{\"t\":\"integer\",\"k\":\"49f35eb02f9b19da\",\"r\":true},\"storageSize\":{\"t\":\"integer\",\"k\":\"e3b142356deba672\",\"r\":true}}}",
+		"53d02173116b24b8": "{\"t\":\"object\",\"k\":\"e388dd0c4e4380da\",\"q\":[\"name\",\"type\"],\"p\":{\"analyzer\":{\"$\":\"f96eb56ef10987ab\"},\"facetable\":{\"t\":\"boolean\",\"k\":\"87c9679aca25d3ec\"},\"fields\":{\"t\":\"array\",\"k\":\"4fe85cfc3b0cd10b\",\"i\":{}},\"filterable\":{\"t\":\"boolean\",\"k\":\"838fb25dd5f87ff1\"},\"indexAnalyzer\":{\"$\":\"74660c351442cbd9\"},\"key\":{\"t\":\"boolean\",\"k\":\"cae4212f4ae81b9c\"},\"name\":{\"t\":\"string\",\"k\":\"76a88080abc8cc49\"},\"retrievable\":{\"t\":\"boolean\",\"k\":\"702c0eafa88ebb06\"},\"searchAnalyzer\":{\"$\":\"e834c3b1295161ff\"},\"searchable\":{\"t\":\"boolean\",\"k\":\"6c4189162d5eaab2\"},\"sortable\":{\"t\":\"boolean\",\"k\":\"6fb1ce8b21ab4a1f\"},\"synonymMaps\":{\"t\":\"array\",\"k\":\"3db1d662c296be19\",\"i\":{\"t\":\"string\"}},\"type\":{\"$\":\"a3f03c325ae11199\"}}}",
+		"5b010b36695f28bf": "{\"t\":\"object\",\"k\":\"e377c5290d9f136b\",\"p\":{\"@search.coverage\":{\"t\":\"number\",\"k\":\"7407354033674298\",\"r\":true},\"value\":{\"t\":\"array\",\"k\":\"190748816584b3bf\",\"r\":true,\"i\":{\"$\":\"fd21338f94d78513\"}}}}",
+		"618d1f0cba4b0687": "{\"t\":\"object\",\"k\":\"736af3bf29f402b7\",\"p\":{\"value\":{\"t\":\"array\",\"k\":\"a0e211756c2b6523\",\"r\":true,\"i\":{\"$\":\"f9d8ea597e8dc1c5\"}}}}",
+		"638769bee9ee38e1": "{\"t\":\"object\",\"k\":\"67ef0777e03ac43a\",\"q\":[\"name\",\"dataSourceName\",\"targetIndexName\"],\"p\":{\"@odata.etag\":{\"t\":\"string\",\"k\":\"6575a3bd1198cf24\"},\"dataSourceName\":{\"t\":\"string\",\"k\":\"808cd66453b15ec3\"},\"description\":{\"t\":\"string\",\"k\":\"262df157fc80a88f\"},\"disabled\":{\"t\":\"boolean\",\"k\":\"98bf14f5e0761366\"},\"fieldMappings\":{\"t\":\"array\",\"k\":\"94b6bcb40ec97316\",\"i\":{\"$\":\"67327ff72c7667ce\"}},\"name\":{\"t\":\"string\",\"k\":\"58f2a070ee843216\"},\"outputFieldMappings\":{\"t\":\"array\",\"k\":\"4827675e38ca0ccd\",\"i\":{\"$\":\"67327ff72c7667ce\"}},\"parameters\":{\"$\":\"e75e0ca0c770fadf\"},\"schedule\":{\"$\":\"745bd2c60b58546\"},\"skillsetName\":{\"t\":\"string\",\"k\":\"2cebd16039e80681\"},\"targetIndexName\":{\"t\":\"string\",\"k\":\"a5606cfe3b51b71e\"}}}",
+		"67327ff72c7667ce": "{\"t\":\"object\",\"k\":\"2278aa28af684123\",\"q\":[\"sourceFieldName\"],\"p\":{\"mappingFunction\":{\"$\":\"88c5404d49b58965\"},\"sourceFieldName\":{\"t\":\"string\",\"k\":\"9b624a0e69445f9a\"},\"targetFieldName\":{\"t\":\"string\",\"k\":\"c4b3b902f9382c49\"}}}",
+		"745bd2c60b58546":  "{\"t\":\"object\",\"k\":\"603c487ed5f8a5c1\",\"q\":[\"interval\"],\"p\":{\"interval\":{\"t\":\"string\",\"k\":\"eecf4f9ab66b2b7b\"},\"startTime\":{\"t\":\"string\",\"k\":\"7b8a17dc6ccf8a93\"}}}",
+		"74660c351442cbd9": "{\"t\":\"string\",\"k\":\"c3e248e2e56de875\",\"e\":[\"ar.microsoft\",\"ar.lucene\",\"hy.lucene\",\"bn.microsoft\",\"eu.lucene\",\"bg.microsoft\",\"bg.lucene\",\"ca.microsoft\",\"ca.lucene\",\"zh-Hans.microsoft\",\"zh-Hans.lucene\",\"zh-Hant.microsoft\",\"zh-Hant.lucene\",\"hr.microsoft\",\"cs.microsoft\",\"cs.lucene\",\"da.microsoft\",\"da.lucene\",\"nl.microsoft\",\"nl.lucene\",\"en.microsoft\",\"en.lucene\",\"et.microsoft\",\"fi.microsoft\",\"fi.lucene\",\"fr.microsoft\",\"fr.lucene\",\"gl.lucene\",\"de.microsoft\",\"de.lucene\",\"el.microsoft\",\"el.lucene\",\"gu.microsoft\",\"he.microsoft\",\"hi.microsoft\",\"hi.lucene\",\"hu.microsoft\",\"hu.lucene\",\"is.microsoft\",\"id.microsoft\",\"id.lucene\",\"ga.lucene\",\"it.microsoft\",\"it.lucene\",\"ja.microsoft\",\"ja.lucene\",\"kn.microsoft\",\"ko.microsoft\",\"ko.lucene\",\"lv.microsoft\",\"lv.lucene\",\"lt.microsoft\",\"ml.microsoft\",\"ms.microsoft\",\"mr.microsoft\",\"nb.microsoft\",\"no.lucene\",\"fa.lucene\",\"pl.microsoft\",\"pl.lucene\",\"pt-BR.microsoft\",\"pt-BR.lucene\",\"pt-PT.microsoft\",\"pt-PT.lucene\",\"pa.microsoft\",\"ro.microsoft\",\"ro.lucene\",\"ru.microsoft\",\"ru.lucene\",\"sr-cyrillic.microsoft\",\"sr-latin.microsoft\",\"sk.microsoft\",\"sl.microsoft\",\"es.microsoft\",\"es.lucene\",\"sv.microsoft\",\"sv.lucene\",\"ta.microsoft\",\"te.microsoft\",\"th.microsoft\",\"th.lucene\",\"tr.microsoft\",\"tr.lucene\",\"uk.microsoft\",\"ur.microsoft\",\"vi.microsoft\",\"standard.lucene\",\"standardasciifolding.lucene\",\"keyword\",\"pattern\",\"simple\",\"stop\",\"whitespace\"]}",
+		"814ce922ff5ca53b": "{\"t\":\"object\",\"k\":\"7c5e3ae77c5b7db3\",\"q\":[\"@odata.type\",\"name\"],\"p\":{\"@odata.type\":{\"t\":\"string\"},\"name\":{\"t\":\"string\",\"k\":\"2939a41aed840073\"}}}",
+		"85f76b2f22ca974f": "{\"t\":\"object\",\"k\":\"a73f2b5a215d2a86\",\"q\":[\"type\",\"fieldName\",\"boost\"],\"p\":{\"boost\":{\"t\":\"number\",\"k\":\"7a300f10cffc4395\"},\"fieldName\":{\"t\":\"string\",\"k\":\"7d187c5f1a815bf9\"},\"interpolation\":{\"t\":\"string\",\"k\":\"b2b53d8546eb76fe\",\"e\":[\"linear\",\"constant\",\"quadratic\",\"logarithmic\"]},\"type\":{\"t\":\"string\"}}}",
+		"866c924d59004400": "{\"t\":\"object\",\"k\":\"2049da503630dad1\",\"p\":{\"quota\":{\"t\":\"integer\",\"k\":\"962fd9f25b715c6e\"},\"usage\":{\"t\":\"integer\",\"k\":\"423dc63f7a68518d\"}}}",
+		"879622f47a297eac": "{\"t\":\"object\",\"k\":\"d42b721c75eb1d60\",\"p\":{\"connectionString\":{\"t\":\"string\",\"k\":\"38ac2b44d839bb7c\"}}}",
+		"88c5404d49b58965": "{\"t\":\"object\",\"k\":\"4123d2813c83c416\",\"q\":[\"name\"],\"p\":{\"name\":{\"t\":\"string\",\"k\":\"d003f271049778c7\"},\"parameters\":{\"t\":\"object\",\"k\":\"58d795e15e33392b\",\"a\":{\"t\":\"object\",\"a\":{}}}}}",
+		"89846cced99c1b6d": "{\"t\":\"object\",\"k\":\"fed36e84283b7f5b\",\"q\":[\"@odata.type\",\"name\"],\"p\":{\"@odata.type\":{\"t\":\"string\"},\"name\":{\"t\":\"string\",\"k\":\"3ae59d7a23f0cad2\"}}}",
+		"8b840d8d0a3e5804": "{\"t\":\"object\",\"k\":\"dfa6122b4d4b5d4c\",\"q\":[\"name\",\"searchMode\",\"sourceFields\"],\"p\":{\"name\":{\"t\":\"string\",\"k\":\"939c0e4355bee6c\"},\"searchMode\":{\"t\":\"string\",\"k\":\"887763417cf5d9c1\",\"e\":[\"analyzingInfixMatching\"]},\"sourceFields\":{\"t\":\"array\",\"k\":\"903958229fa5ff16\",\"i\":{\"t\":\"string\"}}}}",
+		"8d8c2aab80d10cb0": "{\"t\":\"object\",\"k\":\"28b4bc2c7e240c29\",\"p\":{\"@search.highlights\":{\"t\":\"object\",\"k\":\"6b05945ae9ba0d1d\",\"r\":true,\"a\":{\"t\":\"array\",\"i\":{\"t\":\"string\"}}},\"@search.score\":{\"t\":\"number\",\"k\":\"c57a5c9c41279caf\",\"r\":true}},\"a\":{}}",
+		"940872338be5f27a": "{\"t\":\"object\",\"k\":\"1ac0bf4b15a8bf9f\",\"q\":[\"name\"],\"p\":{\"name\":{\"t\":\"string\",\"k\":\"6388560640ca579e\"},\"targetName\":{\"t\":\"string\",\"k\":\"4fd711539bfd7c76\"}}}",
+		"94beedcda00e2ec0": "{\"t\":\"object\",\"k\":\"59a338c798e07ebb\",\"p\":{\"value\":{\"t\":\"array\",\"k\":\"e2a71354b6400de3\",\"r\":true,\"i\":{\"$\":\"b1d24a87769801a1\"}}}}",
+		"95ab144668e4c20d": "{\"t\":\"object\",\"k\":\"ddb98557e0cba7e0\",\"p\":{\"maxComplexCollectionFieldsPerIndex\":{\"t\":\"integer\",\"k\":\"f33fe21e061b7e89\"},\"maxComplexObjectsInCollectionsPerDocument\":{\"t\":\"integer\",\"k\":\"2706eda6854fc1f0\"},\"maxFieldNestingDepthPerIndex\":{\"t\":\"integer\",\"k\":\"907dcc34c585e06e\"},\"maxFieldsPerIndex\":{\"t\":\"integer\",\"k\":\"4f9435f075fa70de\"}}}",
+		"a3f03c325ae11199": "{\"t\":\"string\",\"k\":\"f5a9869db22bfadc\",\"e\":[\"Edm.String\",\"Edm.Int32\",\"Edm.Int64\",\"Edm.Double\",\"Edm.Boolean\",\"Edm.DateTimeOffset\",\"Edm.GeographyPoint\",\"Edm.ComplexType\"]}",
+		"b1d24a87769801a1": "{\"t\":\"object\",\"k\":\"1ef86bcd9e39be41\",\"q\":[\"name\",\"format\",\"synonyms\"],\"p\":{\"@odata.etag\":{\"t\":\"string\",\"k\":\"e6c007b7edf1ed0c\"},\"format\":{\"t\":\"string\",\"k\":\"dbc8a57ebbb4f20\",\"e\":[\"solr\"]},\"name\":{\"t\":\"string\",\"k\":\"3018cecbc69299e\"},\"synonyms\":{\"t\":\"string\",\"k\":\"1b124ad357f3d195\"}}}",
+		"bc79da8906fe55f6": "{\"t\":\"object\",\"k\":\"49e48c5625ea4524\",\"p\":{\"endTime\":{\"t\":\"string\",\"k\":\"fb59333871b0b993\",\"r\":true},\"errorMessage\":{\"t\":\"string\",\"k\":\"f9e7ec5952dac293\",\"r\":true},\"errors\":{\"t\":\"array\",\"k\":\"5634b415a4008777\",\"r\":true,\"i\":{\"$\":\"c20bfe0dca3b1262\"}},\"finalTrackingState\":{\"t\":\"string\",\"k\":\"e986e0d031737c4e\",\"r\":true},\"initialTrackingState\":{\"t\":\"string\",\"k\":\"285dd23f801df83f\",\"r\":true},\"itemsFailed\":{\"t\":\"integer\",\"k\":\"bead7b6a7b741189\",\"r\":true},\"itemsProcessed\":{\"t\":\"integer\",\"k\":\"f51675edc93154ca\",\"r\":true},\"startTime\":{\"t\":\"string\",\"k\":\"962a06a3ff3f2043\",\"r\":true},\"status\":{\"$\":\"2a2b9bfd557210e9\"},\"warnings\":{\"t\":\"array\",\"k\":\"48e14ee31b0e87fd\",\"r\":true,\"i\":{\"$\":\"47533c9a167423ed\"}}}}",
+		"c11e5154f2dc8a97": "{\"t\":\"object\",\"k\":\"be32e9dc665f9479\",\"r\":true,\"p\":{\"maxDocumentContentCharactersToExtract\":{\"t\":\"number\",\"k\":\"c10e0421ec171d80\",\"r\":true},\"maxDocumentExtractionSize\":{\"t\":\"number\",\"k\":\"f30adf499b791e51\",\"r\":true},\"maxRunTime\":{\"t\":\"string\",\"k\":\"4d36d87cc41af1b8\",\"r\":true}}}",
+		"c20bfe0dca3b1262": "{\"t\":\"object\",\"k\":\"2fb322a54e0233fc\",\"p\":{\"details\":{\"t\":\"string\",\"k\":\"89918bc7b0c1f1a6\",\"r\":true},\"documentationLink\":{\"t\":\"string\",\"k\":\"ad4a49582b3553e3\",\"r\":true},\"errorMessage\":{\"t\":\"string\",\"k\":\"73b2699df21faca\",\"r\":true},\"key\":{\"t\":\"string\",\"k\":\"998e3d99f8df8371\",\"r\":true},\"name\":{\"t\":\"string\",\"k\":\"c440d02198620c69\",\"r\":true},\"statusCode\":{\"t\":\"integer\",\"k\":\"6df1a06384a23bf3\",\"r\":true}}}",
+		"c3bf72c5ed61c76f": "{\"t\":\"object\",\"k\":\"ad2ca50e66b889f6\",\"p\":{\"quota\":{\"t\":\"integer\",\"k\":\"962fd9f25b715c6e\"},\"usage\":{\"t\":\"integer\",\"k\":\"423dc63f7a68518d\"}}}",
+		"c5aaca2f86bdf710": "{\"t\":\"object\",\"k\":\"f42dc96c0b112506\",\"p\":{\"queryPlusText\":{\"t\":\"string\",\"k\":\"30cc2d69ecf05841\",\"r\":true},\"text\":{\"t\":\"string\",\"k\":\"c59f76478bcd2f9\",\"r\":true}}}",
+		"c6cd5bad75fffde0": "{\"t\":\"object\",\"k\":\"9f5ced225d196c44\",\"r\":true,\"p\":{\"count\":{\"t\":\"boolean\",\"k\":\"8a15e263f8095f52\"},\"facets\":{\"t\":\"array\",\"k\":\"c81ec43e15d7407f\",\"i\":{\"t\":\"string\"}},\"filter\":{\"t\":\"string\",\"k\":\"84c7655953ae53a8\"},\"highlight\":{\"t\":\"string\",\"k\":\"9d663aa23ff8505c\"},\"highlightPostTag\":{\"t\":\"string\",\"k\":\"4bdcf029ee3eedca\"},\"highlightPreTag\":{\"t\":\"string\",\"k\":\"1f9c103c2f9d15fe\"},\"minimumCoverage\":{\"t\":\"number\",\"k\":\"2f2c9fe91bf81810\"},\"orderby\":{\"t\":\"string\",\"k\":\"9acc8cd781a8ad89\"},\"queryType\":{\"t\":\"string\",\"k\":\"20753d648baa3771\",\"e\":[\"simple\",\"full\"]},\"scoringParameters\":{\"t\":\"array\",\"k\":\"29c8df9259bf0e70\",\"i\":{\"t\":\"string\"}},\"scoringProfile\":{\"t\":\"string\",\"k\":\"5fa89f7f5e2d5e16\"},\"search\":{\"t\":\"string\",\"k\":\"e54feb4afadbc5\"},\"searchFields\":{\"t\":\"string\",\"k\":\"b19317577a4b1bdb\"},\"searchMode\":{\"t\":\"string\",\"k\":\"f759419fe472de20\",\"e\":[\"any\",\"all\"]},\"select\":{\"t\":\"string\",\"k\":\"e52715a3b475752\"},\"skip\":{\"t\":\"integer\",\"k\":\"aa9704596e83a9c9\"},\"top\":{\"t\":\"integer\",\"k\":\"524916ec88472f43\"}}}",
+		"c73d3ad3a866a96a": "{\"t\":\"object\",\"k\":\"a013644df96a7a5f\",\"p\":{\"dataSourcesCount\":{\"$\":\"d8d2af58db9159e5\"},\"documentCount\":{\"$\":\"c3bf72c5ed61c76f\"},\"indexersCount\":{\"$\":\"866c924d59004400\"},\"indexesCount\":{\"$\":\"fd661e20572449f8\"},\"storageSize\":{\"$\":\"cf5486cd333b2221\"},\"synonymMaps\":{\"$\":\"4b2a5facbfc90d0c\"}}}",
+		"cda5ed9642ebcd19": "{\"t\":\"object\",\"k\":\"e239051bcbd69eb6\",\"q\":[\"weights\"],\"p\":{\"weights\":{\"t\":\"object\",\"k\":\"2da365c6f58d8c21\",\"a\":{\"t\":\"number\"}}}}",
+		"cf5486cd333b2221": "{\"t\":\"object\",\"k\":\"265378e86e243c3\",\"p\":{\"quota\":{\"t\":\"integer\",\"k\":\"962fd9f25b715c6e\"},\"usage\":{\"t\":\"integer\",\"k\":\"423dc63f7a68518d\"}}}",
+		"d8d2af58db9159e5": "{\"t\":\"object\",\"k\":\"66bd265e2323033b\",\"p\":{\"quota\":{\"t\":\"integer\",\"k\":\"962fd9f25b715c6e\"},\"usage\":{\"t\":\"integer\",\"k\":\"423dc63f7a68518d\"}}}",
+		"d972c9900d6e3e0":  "{\"t\":\"object\",\"k\":\"b2b72a7c672ccbf7\",\"q\":[\"@odata.type\",\"inputs\",\"outputs\"],\"p\":{\"@odata.type\":{\"t\":\"string\"},\"context\":{\"t\":\"string\",\"k\":\"7ad1e86139b8e042\"},\"description\":{\"t\":\"string\",\"k\":\"7556f2f9a76f71c9\"},\"inputs\":{\"t\":\"array\",\"k\":\"2c27da400009029\",\"i\":{\"$\":\"2cf0a0883c3f03ab\"}},\"name\":{\"t\":\"string\",\"k\":\"d60d1862a1ca731c\"},\"outputs\":{\"t\":\"array\",\"k\":\"5de5ff3dc53e74f5\",\"i\":{\"$\":\"940872338be5f27a\"}}}}",
+		"e13a8a069065b810": "{\"t\":\"object\",\"k\":\"546387b49134712e\",\"q\":[\"@odata.type\",\"name\"],\"p\":{\"@odata.type\":{\"t\":\"string\"},\"name\":{\"t\":\"string\",\"k\":\"eb655238c654c36d\"}}}",
+		"e2d520b4b207391":  "{\"t\":\"object\",\"k\":\"5fe3ceb12d7a35a2\",\"p\":{\"executionHistory\":{\"t\":\"array\",\"k\":\"5774f533209187b6\",\"r\":true,\"i\":{\"$\":\"bc79da8906fe55f6\"}},\"lastResult\":{\"$\":\"41d2f89b059b62b2\"},\"limits\":{\"$\":\"c11e5154f2dc8a97\"},\"status\":{\"t\":\"string\",\"k\":\"a485960ec86cb825\",\"e\":[\"unknown\",\"error\",\"running\"],\"r\":true}}}",
+		"e3efe39929d88d4e": "{\"t\":\"object\",\"k\":\"6841c9b001d2bcc1\",\"q\":[\"name\",\"description\",\"skills\"],\"p\":{\"@odata.etag\":{\"t\":\"string\",\"k\":\"e2fbf424f08ceffa\"},\"cognitiveServices\":{\"$\":\"3fe8b2fbab41e6eb\"},\"description\":{\"t\":\"string\",\"k\":\"efa217fc8f8bef0f\"},\"name\":{\"t\":\"string\",\"k\":\"6d0fd9666b1786ac\"},\"skills\":{\"t\":\"array\",\"k\":\"8492b1ccf1a64f18\",\"i\":{\"$\":\"d972c9900d6e3e0\"}}}}",
+		"e5c7f4ac58731dfa": "{\"t\":\"object\",\"k\":\"3a1011ebeab77c67\",\"p\":{\"@odata.count\":{\"t\":\"integer\",\"k\":\"456f7b405354c372\",\"r\":true},\"@odata.nextLink\":{\"t\":\"string\",\"k\":\"dbf6749264035933\",\"r\":true},\"@search.coverage\":{\"t\":\"number\",\"k\":\"676edf75f2239c16\",\"r\":true},\"@search.facets\":{\"t\":\"object\",\"k\":\"ec162a4f4d1ac5de\",\"r\":true,\"a\":{\"t\":\"array\",\"i\":{\"$\":\"41ce04688bd1e9c6\"}}},\"@search.nextPageParameters\":{\"$\":\"c6cd5bad75fffde0\"},\"value\":{\"t\":\"array\",\"k\":\"190748816584b3bf\",\"r\":true,\"i\":{\"$\":\"8d8c2aab80d10cb0\"}}}}",
+		"e75e0ca0c770fadf": "{\"t\":\"object\",\"k\":\"8cffdd42213b48df\",\"p\":{\"base64EncodeKeys\":{\"t\":\"boolean\",\"k\":\"9825a97a873f1f3f\"},\"batchSize\":{\"t\":\"integer\",\"k\":\"12f5e8de7cd2aa10\"},\"configuration\":{\"t\":\"object\",\"k\":\"f8ec0cb5266a1838\",\"a\":{\"t\":\"object\",\"a\":{}}},\"maxFailedItems\":{\"t\":\"integer\",\"k\":\"58215a2d9fc60d2a\"},\"maxFailedItemsPerBatch\":{\"t\":\"integer\",\"k\":\"40302d3283e74bbc\"}}}",
+		"e834c3b1295161ff": "{\"t\":\"string\",\"k\":\"2edd3a63729e49b3\",\"e\":[\"ar.microsoft\",\"ar.lucene\",\"hy.lucene\",\"bn.microsoft\",\"eu.lucene\",\"bg.microsoft\",\"bg.lucene\",\"ca.microsoft\",\"ca.lucene\",\"zh-Hans.microsoft\",\"zh-Hans.lucene\",\"zh-Hant.microsoft\",\"zh-Hant.lucene\",\"hr.microsoft\",\"cs.microsoft\",\"cs.lucene\",\"da.microsoft\",\"da.lucene\",\"nl.microsoft\",\"nl.lucene\",\"en.microsoft\",\"en.lucene\",\"et.microsoft\",\"fi.microsoft\",\"fi.lucene\",\"fr.microsoft\",\"fr.lucene\",\"gl.lucene\",\"de.microsoft\",\"de.lucene\",\"el.microsoft\",\"el.lucene\",\"gu.microsoft\",\"he.microsoft\",\"hi.microsoft\",\"hi.lucene\",\"hu.microsoft\",\"hu.lucene\",\"is.microsoft\",\"id.microsoft\",\"id.lucene\",\"ga.lucene\",\"it.microsoft\",\"it.lucene\",\"ja.microsoft\",\"ja.lucene\",\"kn.microsoft\",\"ko.microsoft\",\"ko.lucene\",\"lv.microsoft\",\"lv.lucene\",\"lt.microsoft\",\"ml.microsoft\",\"ms.microsoft\",\"mr.microsoft\",\"nb.microsoft\",\"no.lucene\",\"fa.lucene\",\"pl.microsoft\",\"pl.lucene\",\"pt-BR.microsoft\",\"pt-BR.lucene\",\"pt-PT.microsoft\",\"pt-PT.lucene\",\"pa.microsoft\",\"ro.microsoft\",\"ro.lucene\",\"ru.microsoft\",\"ru.lucene\",\"sr-cyrillic.microsoft\",\"sr-latin.microsoft\",\"sk.microsoft\",\"sl.microsoft\",\"es.microsoft\",\"es.lucene\",\"sv.microsoft\",\"sv.lucene\",\"ta.microsoft\",\"te.microsoft\",\"th.microsoft\",\"th.lucene\",\"tr.microsoft\",\"tr.lucene\",\"uk.microsoft\",\"ur.microsoft\",\"vi.microsoft\",\"standard.lucene\",\"standardasciifolding.lucene\",\"keyword\",\"pattern\",\"simple\",\"stop\",\"whitespace\"]}",
+		"e8aec8f8e5b0e668": "{\"t\":\"object\",\"k\":\"5dacd9bc841360a2\",\"p\":{\"counters\":{\"$\":\"c73d3ad3a866a96a\"},\"limits\":{\"$\":\"95ab144668e4c20d\"}}}",
+		"ec04355c07e09530": "{\"t\":\"object\",\"k\":\"dd6d5602aeb83fe1\",\"q\":[\"@odata.type\",\"name\"],\"p\":{\"@odata.type\":{\"t\":\"string\"},\"name\":{\"t\":\"string\",\"k\":\"616517ef12fffdc8\"}}}",
+		"ee74c8505645a5a3": "{\"t\":\"object\",\"k\":\"45ae9956a42a6ad7\",\"p\":{\"value\":{\"t\":\"array\",\"k\":\"1cd2912b5488275b\",\"r\":true,\"i\":{\"$\":\"638769bee9ee38e1\"}}}}",
+		"f96eb56ef10987ab": "{\"t\":\"string\",\"k\":\"2c131b4c5ece3fd1\",\"e\":[\"ar.microsoft\",\"ar.lucene\",\"hy.lucene\",\"bn.microsoft\",\"eu.lucene\",\"bg.microsoft\",\"bg.lucene\",\"ca.microsoft\",\"ca.lucene\",\"zh-Hans.microsoft\",\"zh-Hans.lucene\",\"zh-Hant.microsoft\",\"zh-Hant.lucene\",\"hr.microsoft\",\"cs.microsoft\",\"cs.lucene\",\"da.microsoft\",\"da.lucene\",\"nl.microsoft\",\"nl.lucene\",\"en.microsoft\",\"en.lucene\",\"et.microsoft\",\"fi.microsoft\",\"fi.lucene\",\"fr.microsoft\",\"fr.lucene\",\"gl.lucene\",\"de.microsoft\",\"de.lucene\",\"el.microsoft\",\"el.lucene\",\"gu.microsoft\",\"he.microsoft\",\"hi.microsoft\",\"hi.lucene\",\"hu.microsoft\",\"hu.lucene\",\"is.microsoft\",\"id.microsoft\",\"id.lucene\",\"ga.lucene\",\"it.microsoft\",\"it.lucene\",\"ja.microsoft\",\"ja.lucene\",\"kn.microsoft\",\"ko.microsoft\",\"ko.lucene\",\"lv.microsoft\",\"lv.lucene\",\"lt.microsoft\",\"ml.microsoft\",\"ms.microsoft\",\"mr.microsoft\",\"nb.microsoft\",\"no.lucene\",\"fa.lucene\",\"pl.microsoft\",\"pl.lucene\",\"pt-BR.microsoft\",\"pt-BR.lucene\",\"pt-PT.microsoft\",\"pt-PT.lucene\",\"pa.microsoft\",\"ro.microsoft\",\"ro.lucene\",\"ru.microsoft\",\"ru.lucene\",\"sr-cyrillic.microsoft\",\"sr-latin.microsoft\",\"sk.microsoft\",\"sl.microsoft\",\"es.microsoft\",\"es.lucene\",\"sv.microsoft\",\"sv.lucene\",\"ta.microsoft\",\"te.microsoft\",\"th.microsoft\",\"th.lucene\",\"tr.microsoft\",\"tr.lucene\",\"uk.microsoft\",\"ur.microsoft\",\"vi.microsoft\",\"standard.lucene\",\"standardasciifolding.lucene\",\"keyword\",\"pattern\",\"simple\",\"stop\",\"whitespace\"]}",
+		"f9d8ea597e8dc1c5": "{\"t\":\"object\",\"k\":\"9aaefea8f9aad692\",\"q\":[\"name\",\"fields\"],\"p\":{\"@odata.etag\":{\"t\":\"string\",\"k\":\"527cee5cb9b7885\"},\"analyzers\":{\"t\":\"array\",\"k\":\"ac13fadac30a030b\",\"i\":{\"$\":\"e13a8a069065b810\"}},\"charFilters\":{\"t\":\"array\",\"k\":\"ca536cb30088dbe2\",\"i\":{\"$\":\"814ce922ff5ca53b\"}},\"corsOptions\":{\"$\":\"2a24975f191672b2\"},\"defaultScoringProfile\":{\"t\":\"string\",\"k\":\"f4698d01acae4941\"},\"fields\":{\"t\":\"array\",\"k\":\"c3f1b83ff05be645\",\"i\":{\"$\":\"53d02173116b24b8\"}},\"name\":{\"t\":\"string\",\"k\":\"6bbaa8f54eb00f4b\"},\"scoringProfiles\":{\"t\":\"array\",\"k\":\"210eb2cb91f7e3d1\",\"i\":{\"$\":\"4a29cf67a62399ad\"}},\"suggesters\":{\"t\":\"array\",\"k\":\"a46cab10006d6c70\",\"i\":{\"$\":\"8b840d8d0a3e5804\"}},\"tokenFilters\":{\"t\":\"array\",\"k\":\"864c6d23b87efd94\",\"i\":{\"$\":\"ec04355c07e09530\"}},\"tokenizers\":{\"t\":\"array\",\"k\":\"d123f8aae169e438\",\"i\":{\"$\":\"89846cced99c1b6d\"}}}}",
+		"fd21338f94d78513": "{\"t\":\"object\",\"k\":\"b1fd3db0a9c84496\",\"p\":{\"@search.text\":{\"t\":\"string\",\"k\":\"698467983a3d2a30\",\"r\":true}},\"a\":{}}",
+		"fd661e20572449f8": "{\"t\":\"object\",\"k\":\"31216ea29cae30ab\",\"p\":{\"quota\":{\"t\":\"integer\",\"k\":\"962fd9f25b715c6e\"},\"usage\":{\"t\":\"integer\",\"k\":\"423dc63f7a68518d\"}}}",
+	})
+	swagger.RegisterSchemaDescriptions(schemaDescriptionsAzureSearchServiceExpander)
+}
@@ -4,12 +4,32 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"strings"
 
+	"github.com/awesome-gocui/gocui"
+	"github.com/lawrencegripper/azbrowse/internal/pkg/interfaces"
 	"github.com/lawrencegripper/azbrowse/pkg/armclient"
 )
 
 const azureSearchTemplateURL string = "/subscriptions/{subscriptionId}/resourceGroups/{resourceGroupName}/providers/Microsoft.Search/searchServices/{searchServiceName}"
 
+const (
+	searchNamespace = "AzureSearchServiceExpander"
+
+	searchDocumentsPageType     = "search.documentsPage"
+	searchIndexSchemaType       = "search.indexSchema"
+	searchIndexerHistoryType    = "search.indexerHistory"
+	searchIndexerExecutionType  = "search.indexerExecution"
+	searchIndexTemplateURL      = "/indexes('{indexName}')"
+	searchDocumentsTemplateURL  = "/indexes('{indexName}')/docs"
+	searchIndexerTemplateURL    = "/indexers('{indexerName}')"
+	searchDocumentsPageSize     = 50
+	searchActionRunIndexer      = "run-indexer"
+	searchActionResetIndexer    = "reset-indexer"
+	searchActionUploadDocuments = "upload-documents"
+	searchActionMergeDocuments  = "merge-documents"
+)
+
 type searchServiceResponse struct {
 	Name string `json:"name"`
 }
@@ -21,7 +41,9 @@ type adminKeysResponse struct {
 // AzureSearchServiceExpander expands the kubernetes aspects of AKS
 type AzureSearchServiceExpander struct {
 	ExpanderBase
-	client *armclient.Client
+	client       *armclient.Client
+	gui          *gocui.Gui
+	commandPanel interfaces.CommandPanel
 }
 
 func (e *AzureSearchServiceExpander) setClient(c *armclient.Client) {
@@ -41,24 +63,42 @@ func (e *AzureSearchServiceExpander) DoesExpand(ctx context.Context, currentItem
 			return true, nil
 		}
 	}
-	if currentItem.Namespace == "AzureSearchServiceExpander" {
+	if currentItem.Namespace == searchNamespace {
+		return true, nil
+	}
+	if getSearchTemplateURL(currentItem) != "" {
 		return true, nil
 	}
 	return false, nil
 }
 
+// getSearchTemplateURL returns the TemplateURL for nodes added by the swagger expander for the Search API set
+// that this expander adds schema, paging and history nodes to (or "" for other nodes)
+func getSearchTemplateURL(item *TreeNode) string {
+	if item.Namespace != "swagger" || item.SwaggerResourceType == nil ||
+		!strings.HasSuffix(item.Metadata["SwaggerAPISetID"], "/<service>") {
+		return ""
+	}
+	templateURL := item.SwaggerResourceType.Endpoint.TemplateURL
+	switch templateURL {
+	case searchIndexTemplateURL, searchDocumentsTemplateURL, searchIndexerTemplateURL:
+		return templateURL
+	}
+	return ""
+}
+
 // Expand returns ManagementPolicies in the StorageAccount
 func (e *AzureSearchServiceExpander) Expand(ctx context.Context, currentItem *TreeNode) ExpanderResult {
 
 	swaggerResourceType := currentItem.SwaggerResourceType
-	if currentItem.Namespace != "AzureSearchServiceExpander" &&
+	if currentItem.Namespace != searchNamespace &&
 		swaggerResourceType != nil &&
 		swaggerResourceType.Endpoint.TemplateURL == azureSearchTemplateURL {
 		newItems := []*TreeNode{}
 		newItems = append(newItems, &TreeNode{
 			ID:                    currentItem.ID + "/<service>",
 			Parentid:              currentItem.ID,
-			Namespace:             "AzureSearchServiceExpander",
+			Namespace:             searchNamespace,
 			Name:                  "Search Service",
 			Display:               "Search Service",
 			ItemType:              SubResourceType,
@@ -79,8 +119,34 @@ func (e *AzureSearchServiceExpander) Expand(ctx context.Context, currentItem *Tr
 		}
 	}
 
-	if currentItem.Namespace == "AzureSearchServiceExpander" && currentItem.ItemType == SubResourceType {
-		return e.expandSearchRoot(ctx, currentItem)
+	if currentItem.Namespace == searchNamespace {
+		switch currentItem.ItemType {
+		case SubResourceType:
+			return e.expandSearchRoot(ctx, currentItem)
+		case searchDocumentsPageType:
+			return e.expandDocumentsPage(ctx, currentItem)
+		case searchIndexSchemaType:
+			return e.expandIndexSchema(ctx, currentItem)
+		case searchIndexerHistoryType:
+			return e.expandIndexerHistory(ctx, currentItem)
+		}
+	}
+
+	switch getSearchTemplateURL(currentItem) {
+	case searchIndexTemplateURL:
+		return ExpanderResult{
+			Response:          ExpanderResponse{Response: ""}, // Swagger expander will supply the response
+			SourceDescription: "AzureSearchServiceExpander request",
+			Nodes:             []*TreeNode{newSearchIndexSchemaNode(currentItem)},
+		}
+	case searchDocumentsTemplateURL:
+		return e.expandDocuments(ctx, currentItem)
+	case searchIndexerTemplateURL:
+		return ExpanderResult{
+			Response:          ExpanderResponse{Response: ""}, // Swagger expander will supply the response
+			SourceDescription: "AzureSearchServiceExpander request",
+			Nodes:             []*TreeNode{newSearchIndexerHistoryNode(currentItem)},
+		}
 	}
 
 	return ExpanderResult{
@@ -173,6 +239,107 @@ func (e *AzureSearchServiceExpander) getAPISetForCluster(searchID string) *Swagg
 	return &apiSet
 }
 
+// getAPISetForNode returns the Search APISet for nodes under a search service
+func (e *AzureSearchServiceExpander) getAPISetForNode(item *TreeNode) (*SwaggerAPISetSearch, error) {
+	swaggerAPISet := GetSwaggerResourceExpander().GetAPISet(item.Metadata["SwaggerAPISetID"])
+	if swaggerAPISet == nil {
+		return nil, fmt.Errorf("Search service connection not found for %q", item.ID)
+	}
+	apiSet, ok := (*swaggerAPISet).(SwaggerAPISetSearch)
+	if !ok {
+		return nil, fmt.Errorf("Unexpected APISet type for %q", item.ID)
+	}
+	return &apiSet, nil
+}
+
+// HasActions returns true for indexes, documents and indexers
+func (e *AzureSearchServiceExpander) HasActions(ctx context.Context, item *TreeNode) (bool, error) {
+	return getSearchTemplateURL(item) != "", nil
+}
+
+// ListActions returns the upload/merge actions for indexes and the run/reset actions for indexers
+func (e *AzureSearchServiceExpander) ListActions(ctx context.Context, item *TreeNode) ListActionsResult {
+	// Uploading documents waits for the file prompt and editor
+	uploadTimeout := 600
+	newAction := func(actionID string, name string, timeoutOverride *int) *TreeNode {
+		return &TreeNode{
+			Parentid:               item.ID,
+			ID:                     item.ID + "?" + actionID,
+			Namespace:              searchNamespace,
+			Name:                   name,
+			Display:                name,
+			ItemType:               ActionType,
+			SuppressGenericExpand:  true,
+			TimeoutOverrideSeconds: timeoutOverride,
+			Metadata: map[string]string{
+				"ActionID": actionID,
+			},
+		}
+	}
+
+	nodes := []*TreeNode{}
+	switch getSearchTemplateURL(item) {
+	case searchIndexTemplateURL, searchDocumentsTemplateURL:
+		nodes = append(nodes,
+			newAction(searchActionUploadDocuments, "Upload documents from file", &uploadTimeout),
+			newAction(searchActionMergeDocuments, "Merge documents from file", &uploadTimeout))
+	case searchIndexerTemplateURL:
+		nodes = append(nodes,
+			newAction(searchActionRunIndexer, "Run indexer", nil),
+			newAction(searchActionResetIndexer, "Reset indexer", nil))
+	}
+
+	return ListActionsResult{
+		Nodes:             nodes,
+		SourceDescription: "AzureSearchServiceExpander",
+		IsPrimaryResponse: true,
+	}
+}
+
+// ExecuteAction runs the selected index or indexer action
+func (e *AzureSearchServiceExpander) ExecuteAction(ctx context.Context, item *TreeNode) ExpanderResult {
+	actionID := item.Metadata["ActionID"]
+
+	if item.Parent == nil {
+		return ExpanderResult{
+			SourceDescription: "AzureSearchServiceExpander",
+			IsPrimaryResponse: true,
+			Err:               fmt.Errorf("Index or indexer not set on action: %q", item.ID),
+		}
+	}
+
+	switch actionID {
+	case searchActionUploadDocuments:
+		return e.indexDocuments(ctx, item, "upload")
+	case searchActionMergeDocuments:
+		return e.indexDocuments(ctx, item, "merge")
+	case searchActionRunIndexer:
+		return e.runIndexer(ctx, item)
+	case searchActionResetIndexer:
+		return e.resetIndexer(ctx, item)
+	case "":
+		return ExpanderResult{
+			SourceDescription: "AzureSearchServiceExpander",
+			IsPrimaryResponse: true,
+			Err:               fmt.Errorf("ActionID metadata not set: %q", item.ID),
+		}
+	default:
+		return ExpanderResult{
+			SourceDescription: "AzureSearchServiceExpander",
+			IsPrimaryResponse: true,
+			Err:               fmt.Errorf("Unhandled ActionID: %q", actionID),
+		}
+	}
+}
+
+// getSearchTemplateValue returns the named value (e.g. indexName) from the node's ExpandURL
+func getSearchTemplateValue(item *TreeNode, name string) string {
+	if item.SwaggerResourceType == nil {
+		return item.Metadata[name]
+	}
+	return item.SwaggerResourceType.Endpoint.Match(item.ExpandURL).Values[name]
+}
+
 func (e *AzureSearchServiceExpander) getAdminKey(ctx context.Context, searchID string) (string, error) {
 	data, err := e.client.DoRequest(ctx, "POST", searchID+"/listAdminKeys?api-version=2015-08-19")
 	if err != nil {
@@ -0,0 +1,101 @@
+package expanders
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/nbio/st"
+)
+
+func Test_Search_ParseDocuments(t *testing.T) {
+	documents, err := parseSearchDocuments(`[{"id": "1"}, {"id": "2"}]`)
+	st.Expect(t, err, nil)
+	st.Expect(t, len(documents), 2)
+
+	documents, err = parseSearchDocuments(`{"value": [{"id": "1", "@search.action": "delete"}]}`)
+	st.Expect(t, err, nil)
+	st.Expect(t, len(documents), 1)
+
+	documents, err = parseSearchDocuments("  ")
+	st.Expect(t, err, nil)
+	st.Expect(t, len(documents), 0)
+
+	_, err = parseSearchDocuments(`{"id": "1"}`)
+	st.Reject(t, err, nil)
+}
+
+func Test_Search_BuildIndexBatch(t *testing.T) {
+	documents := []map[string]interface{}{
+		{"id": "1", "@search.action": "delete"},
+		{"id": "2"},
+	}
+	body, err := buildSearchIndexBatch(documents, "merge")
+	st.Expect(t, err, nil)
+
+	var batch struct {
+		Value []map[string]interface{} `json:"value"`
+	}
+	err = json.Unmarshal([]byte(body), &batch)
+	st.Expect(t, err, nil)
+	st.Expect(t, len(batch.Value), 2)
+	for _, document := range batch.Value {
+		st.Expect(t, document["@search.action"], "merge")
+	}
+}
+
+func Test_Search_RenderIndexSchema(t *testing.T) {
+	schemaJSON := `{
+		"name": "hotels",
+		"fields": [
+			{"name": "id", "type": "Edm.String", "key": true, "filterable": true},
+			{"name": "description", "type": "Edm.String", "searchable": true, "analyzer": "en.lucene"},
+			{"name": "address", "type": "Edm.ComplexType", "fields": [
+				{"name": "city", "type": "Edm.String", "searchable": true, "indexAnalyzer": "prefix", "searchAnalyzer": "standard"}
+			]}
+		],
+		"analyzers": [
+			{"name": "prefix", "@odata.type": "#Microsoft.Azure.Search.CustomAnalyzer", "tokenizer": "standard_v2", "tokenFilters": ["lowercase", "edgeNGram"]}
+		]
+	}`
+	var schema SearchIndexSchema
+	err := json.Unmarshal([]byte(schemaJSON), &schema)
+	st.Expect(t, err, nil)
+
+	rendered := renderSearchIndexSchema(schema)
+	st.Assert(t, strings.Contains(rendered, "en.lucene"), true)
+	st.Assert(t, strings.Contains(rendered, "  city"), true)
+	st.Assert(t, strings.Contains(rendered, "index: prefix, search: standard"), true)
+	st.Assert(t, strings.Contains(rendered, "(CustomAnalyzer) tokenFilters=[lowercase, edgeNGram] tokenizer=standard_v2"), true)
+}
+
+func Test_Search_IndexerRunComplete(t *testing.T) {
+	submitted := time.Date(2021, 1, 1, 12, 0, 0, 0, time.UTC)
+	newStatus := func(status string, started time.Time) SearchIndexerStatus {
+		return SearchIndexerStatus{LastResult: &SearchIndexerExecutionResult{Status: status, StartTime: &started}}
+	}
+
+	completed, _ := isSearchIndexerRunComplete(SearchIndexerStatus{}, submitted)
+	st.Expect(t, completed, false)
+
+	// previous run
+	completed, _ = isSearchIndexerRunComplete(newStatus("success", submitted.Add(-time.Hour)), submitted)
+	st.Expect(t, completed, false)
+
+	completed, _ = isSearchIndexerRunComplete(newStatus("inProgress", submitted), submitted)
+	st.Expect(t, completed, false)
+
+	completed, failed := isSearchIndexerRunComplete(newStatus("success", submitted.Add(time.Second)), submitted)
+	st.Expect(t, completed, true)
+	st.Expect(t, failed, false)
+
+	completed, failed = isSearchIndexerRunComplete(newStatus("persistentFailure", submitted.Add(time.Second)), submitted)
+	st.Expect(t, completed, true)
+	st.Expect(t, failed, true)
+}
+
+func Test_Search_AddQueryStringParameter(t *testing.T) {
+	st.Expect(t, addQueryStringParameter("/indexes('a')/docs?api-version=2019-05-06", "$top", "50"), "/indexes('a')/docs?api-version=2019-05-06&$top=50")
+	st.Expect(t, addQueryStringParameter("/indexes('a')/docs", "$skip", "50"), "/indexes('a')/docs?$skip=50")
+}
@@ -51,6 +51,8 @@ func (e *SubscriptionExpander) Expand(ctx context.Context, currentItem *TreeNode
 		DeleteURL:      "",
 		SubscriptionID: currentItem.SubscriptionID,
 	})
+	newItems = append(newItems, newActivityLogNode(currentItem, "", ""))
+	newItems = append(newItems, newServiceHealthNode(currentItem))
 
 	//    \/ It's not the usual ... look out
 	if err == nil {
@@ -118,12 +120,14 @@ func (e *SubscriptionExpander) testCases() (bool, *[]expanderTestCase) {
 			statusCode:   200,
 			treeNodeCheckerFunc: func(t *testing.T, r ExpanderResult) {
 				st.Expect(t, r.Err, nil)
-				st.Expect(t, len(r.Nodes), 7)
+				st.Expect(t, len(r.Nodes), 9)
 
 				// Validate content
 				st.Expect(t, r.Nodes[0].Name, "Deployments")
-				st.Expect(t, r.Nodes[1].Name, "1testrg")
-				st.Expect(t, r.Nodes[1].ExpandURL, "/subscriptions/00000000-0000-0000-0000-000000000000/resourceGroups/1testrg/resources?api-version=2017-05-10")
+				st.Expect(t, r.Nodes[1].Name, "Activity Log")
+				st.Expect(t, r.Nodes[2].Name, "Service Health")
+				st.Expect(t, r.Nodes[3].Name, "1testrg")
+				st.Expect(t, r.Nodes[3].ExpandURL, "/subscriptions/00000000-0000-0000-0000-000000000000/resourceGroups/1testrg/resources?api-version=2017-05-10")
 			},
 		},
 		{
@@ -5,24 +5,29 @@ import (
 	"encoding/json"
 	"fmt"
 	"strings"
+	"time"
 
+	"github.com/lawrencegripper/azbrowse/internal/pkg/eventing"
 	"github.com/lawrencegripper/azbrowse/internal/pkg/interfaces"
 	"github.com/lawrencegripper/azbrowse/pkg/armclient"
 	"github.com/lawrencegripper/azbrowse/pkg/swagger"
 )
 
 var _ SwaggerAPISet = SwaggerAPISetARMResources{}
+var _ SwaggerAPISetActions = SwaggerAPISetARMResources{}
 
 // SwaggerAPISetARMResources holds the config for working with ARM resources as per the published Swagger specs
 type SwaggerAPISetARMResources struct {
-	resourceTypes []swagger.ResourceType
-	client        *armclient.Client
+	resourceTypes       []swagger.ResourceType
+	resourceTypeMatcher *swagger.ResourceTypeMatcher
+	client              *armclient.Client
 }
 
 // NewSwaggerAPISetARMResources creates a new SwaggerAPISetARMResources
 func NewSwaggerAPISetARMResources(client *armclient.Client) SwaggerAPISetARMResources {
 	c := SwaggerAPISetARMResources{}
 	c.resourceTypes = c.loadResourceTypes()
+	c.resourceTypeMatcher = swagger.NewResourceTypeMatcher(c.resourceTypes)
 	c.client = client
 	return c
 }
@@ -50,6 +55,11 @@ func (c SwaggerAPISetARMResources) GetResourceTypes() []swagger.ResourceType {
 	return c.resourceTypes
 }
 
+// GetResourceTypeMatcher returns the ResourceTypeMatcher for the ResourceTypes in the API Set
+func (c SwaggerAPISetARMResources) GetResourceTypeMatcher() *swagger.ResourceTypeMatcher {
+	return c.resourceTypeMatcher
+}
+
 // ExpandResource returns metadata about child resources of the specified resource node
 func (c SwaggerAPISetARMResources) ExpandResource(ctx context.Context, currentItem *TreeNode, resourceType swagger.ResourceType) (APISetExpandResponse, error) {
 
@@ -99,8 +109,8 @@ func (c SwaggerAPISetARMResources) ExpandResource(ctx context.Context, currentIt
 				ID:           subResourceURL,
 				Name:         name,
 				ResourceType: *subResourceType,
-				ExpandURL:    subResourceURL + "?api-version=" + subResourceType.Endpoint.APIVersion,
-				DeleteURL:    deleteURL,
+				ExpandURL:    applyAPIVersionOverride(subResourceURL + "?api-version=" + subResourceType.Endpoint.APIVersion),
+				DeleteURL:    applyAPIVersionOverride(deleteURL),
 			}
 			subResources = append(subResources, subResource)
 		}
@@ -139,6 +149,8 @@ func (c SwaggerAPISetARMResources) Update(ctx context.Context, item *TreeNode, c
 		return fmt.Errorf("Failed to build PUT URL '%s': %s", item.SwaggerResourceType.PutEndpoint.TemplateURL, err)
 	}
 
+	putURL = applyAPIVersionOverride(putURL)
+
 	data, err := c.client.DoRequestWithBody(ctx, "PUT", putURL, content)
 	if err != nil {
 		return fmt.Errorf("Error making PUT request: %s", err)
@@ -154,6 +166,22 @@ func (c SwaggerAPISetARMResources) Update(ctx context.Context, item *TreeNode, c
 	return nil
 }
 
+// DoPostAction performs a POST action, polling the status if it is a long-running operation
+func (c SwaggerAPISetARMResources) DoPostAction(ctx context.Context, item *TreeNode, url string, body string) (string, error) {
+	message := item.Name + " @ " + item.Parentid
+	event, _ := eventing.SendStatusEvent(&eventing.StatusEvent{
+		InProgress: true,
+		Message:    message,
+		Timeout:    time.Duration(swaggerActionTimeoutSeconds) * time.Second,
+	})
+	defer event.Done()
+
+	return c.client.DoLongRunningRequestWithBody(ctx, "POST", url, body, func(status string) {
+		event.Message = message + ": " + status
+		event.Update()
+	})
+}
+
 func getAPIErrorMessage(responseString string) (string, error) {
 	var response map[string]interface{}
 
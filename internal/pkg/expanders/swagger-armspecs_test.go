@@ -1,8 +1,11 @@
 package expanders
 
 import (
+	"context"
 	"fmt"
 	"testing"
+
+	"github.com/lawrencegripper/azbrowse/pkg/swagger"
 )
 
 // This test ensures that all the `mustGetEndpointInfoFromURL` calls in the swagger generated code succeed.
@@ -14,3 +17,41 @@ func TestGeneratedCodeInitialises(t *testing.T) {
 
 	t.Log(fmt.Printf("Generated swagger resources found: %v", len(resources)))
 }
+
+// This test ensures that the schemas in the swagger generated code can be parsed (i.e. all shared definitions are registered)
+func TestGeneratedSchemasParse(t *testing.T) {
+
+	config := SwaggerAPISetARMResources{}
+	resources := config.loadResourceTypes()
+
+	var checkSchemas func(resourceTypes []swagger.ResourceType)
+	checkSchemas = func(resourceTypes []swagger.ResourceType) {
+		for _, resourceType := range resourceTypes {
+			for _, schemaJSON := range []string{resourceType.ResponseSchema, resourceType.PutBodySchema} {
+				if schemaJSON == "" {
+					continue
+				}
+				if _, err := swagger.ParseSchema(schemaJSON); err != nil {
+					t.Errorf("Failed to parse schema for %s: %s", resourceType.Endpoint.TemplateURL, err)
+				}
+			}
+			checkSchemas(resourceType.Children)
+			checkSchemas(resourceType.SubResources)
+		}
+	}
+	checkSchemas(resources)
+}
+
+func BenchmarkGetResourceTypeForURL(b *testing.B) {
+	config := NewSwaggerAPISetARMResources(nil)
+	matcher := config.GetResourceTypeMatcher()
+	ctx := context.Background()
+	url := "/subscriptions/sub1/resourceGroups/rg1/providers/Microsoft.Web/sites/site1/config/web?api-version=2020-06-01"
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if resourceType := matcher.GetResourceTypeForURL(ctx, url); resourceType == nil {
+			b.Fatal("Expected a resource type")
+		}
+	}
+}
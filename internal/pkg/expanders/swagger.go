@@ -1,16 +1,29 @@
 package expanders
 
 import (
+	"bytes"
 	"context"
+	"encoding/json"
 	"fmt"
+	"net/url"
 	"strings"
+	"time"
 
+	"github.com/awesome-gocui/gocui"
 	"github.com/lawrencegripper/azbrowse/pkg/swagger"
 
+	"github.com/lawrencegripper/azbrowse/internal/pkg/editor"
+	"github.com/lawrencegripper/azbrowse/internal/pkg/eventing"
 	"github.com/lawrencegripper/azbrowse/internal/pkg/interfaces"
 	"github.com/lawrencegripper/azbrowse/internal/pkg/tracing"
 )
 
+// Editing the body or waiting for a long-running operation can take a while
+var swaggerActionTimeoutSeconds = 600
+
+// swaggerCreateActionID is the ActionID for creating an item in a collection (uses angle brackets to avoid clashing with POST action names)
+const swaggerCreateActionID = "<create>"
+
 // Check interface
 var _ Expander = &SwaggerResourceExpander{}
 
@@ -18,6 +31,7 @@ var _ Expander = &SwaggerResourceExpander{}
 type SwaggerAPISet interface {
 	ID() string
 	GetResourceTypes() []swagger.ResourceType
+	GetResourceTypeMatcher() *swagger.ResourceTypeMatcher
 	AppliesToNode(node *TreeNode) bool
 	ExpandResource(context context.Context, node *TreeNode, resourceType swagger.ResourceType) (APISetExpandResponse, error)
 	MatchChildNodesByName() bool
@@ -25,6 +39,11 @@ type SwaggerAPISet interface {
 	Update(context context.Context, node *TreeNode, content string) error
 }
 
+// SwaggerAPISetActions is implemented by SwaggerAPISets that can perform the POST actions for their resource types
+type SwaggerAPISetActions interface {
+	DoPostAction(context context.Context, node *TreeNode, url string, body string) (string, error)
+}
+
 // SubResource is used to pass sub resource information from SwaggerAPISet to the expander
 type SubResource struct {
 	ID           string
@@ -46,13 +65,17 @@ type APISetExpandResponse struct {
 // SwaggerResourceExpander expands resource under an AppService
 type SwaggerResourceExpander struct {
 	ExpanderBase
-	apiSets map[string]*SwaggerAPISet
+	apiSets      map[string]*SwaggerAPISet
+	gui          *gocui.Gui
+	commandPanel interfaces.CommandPanel
 }
 
 // NewSwaggerResourcesExpander creates a new SwaggerResourceExpander
-func NewSwaggerResourcesExpander() *SwaggerResourceExpander {
+func NewSwaggerResourcesExpander(gui *gocui.Gui, commandPanel interfaces.CommandPanel) *SwaggerResourceExpander {
 	return &SwaggerResourceExpander{
-		apiSets: map[string]*SwaggerAPISet{},
+		apiSets:      map[string]*SwaggerAPISet{},
+		gui:          gui,
+		commandPanel: commandPanel,
 	}
 }
 
@@ -103,7 +126,7 @@ func (e *SwaggerResourceExpander) DoesExpand(ctx context.Context, currentItem *T
 	if currentItem.SwaggerResourceType != nil {
 		return true, nil
 	}
-	resourceType := swagger.GetResourceTypeForURL(ctx, currentItem.ExpandURL, apiSet.GetResourceTypes())
+	resourceType := apiSet.GetResourceTypeMatcher().GetResourceTypeForURL(ctx, currentItem.ExpandURL)
 	if resourceType != nil {
 		currentItem.SwaggerResourceType = resourceType // cache to avoid looking up in Expand
 		return true, nil
@@ -147,22 +170,7 @@ func (e *SwaggerResourceExpander) Expand(ctx context.Context, currentItem *TreeN
 
 	if len(expandResult.SubResources) > 0 {
 		for _, subResource := range expandResult.SubResources {
-			metadata := map[string]string{
-				"SwaggerAPISetID": apiSet.ID(),
-			}
-			e.copyMetadata(metadata, subResource.Metadata)
-			newItems = append(newItems, &TreeNode{
-				Parentid:            currentItem.ID,
-				Namespace:           "swagger",
-				Name:                subResource.Name,
-				Display:             subResource.Name,
-				ID:                  subResource.ID,
-				ExpandURL:           subResource.ExpandURL,
-				ItemType:            SubResourceType,
-				DeleteURL:           subResource.DeleteURL,
-				SwaggerResourceType: &subResource.ResourceType,
-				Metadata:            metadata,
-			})
+			newItems = append(newItems, e.newSubResourceNode(currentItem.ID, apiSet.ID(), subResource))
 		}
 	}
 	childMetadata := expandResult.ChildMetadata
@@ -222,7 +230,7 @@ func (e *SwaggerResourceExpander) Expand(ctx context.Context, currentItem *TreeN
 				Namespace:           "swagger",
 				Name:                display,
 				Display:             display,
-				ExpandURL:           url,
+				ExpandURL:           applyAPIVersionOverride(url),
 				ItemType:            SubResourceType,
 				DeleteURL:           deleteURL,
 				SwaggerResourceType: &loopChild,
@@ -238,6 +246,26 @@ func (e *SwaggerResourceExpander) Expand(ctx context.Context, currentItem *TreeN
 	}
 }
 
+// newSubResourceNode creates the node for a SubResource returned from SwaggerAPISet.ExpandResource
+func (e *SwaggerResourceExpander) newSubResourceNode(parentID string, apiSetID string, subResource SubResource) *TreeNode {
+	metadata := map[string]string{
+		"SwaggerAPISetID": apiSetID,
+	}
+	e.copyMetadata(metadata, subResource.Metadata)
+	return &TreeNode{
+		Parentid:            parentID,
+		Namespace:           "swagger",
+		Name:                subResource.Name,
+		Display:             subResource.Name,
+		ID:                  subResource.ID,
+		ExpandURL:           subResource.ExpandURL,
+		ItemType:            SubResourceType,
+		DeleteURL:           subResource.DeleteURL,
+		SwaggerResourceType: &subResource.ResourceType,
+		Metadata:            metadata,
+	}
+}
+
 func (e *SwaggerResourceExpander) copyMetadata(target map[string]string, source map[string]string) {
 	for key, value := range source {
 		target[key] = value
@@ -284,6 +312,205 @@ func (e *SwaggerResourceExpander) Delete(context context.Context, item *TreeNode
 	return apiSet.Delete(context, item)
 }
 
+// getActionResourceType returns the SwaggerAPISet and ResourceType for an item that can have swagger actions
+func (e *SwaggerResourceExpander) getActionResourceType(ctx context.Context, item *TreeNode) (SwaggerAPISet, *swagger.ResourceType) {
+	if item.SuppressSwaggerExpand || item.ItemType == ActionType {
+		return nil, nil
+	}
+	apiSetPtr := e.getAPISetForItem(item)
+	if apiSetPtr == nil {
+		return nil, nil
+	}
+	resourceType := item.SwaggerResourceType
+	if resourceType == nil {
+		resourceType = (*apiSetPtr).GetResourceTypeMatcher().GetResourceTypeForURL(ctx, item.ExpandURL)
+	}
+	if resourceType == nil {
+		return nil, nil
+	}
+	return *apiSetPtr, resourceType
+}
+
+// getPostActions returns the SwaggerAPISet and ResourceType for an item when it has POST actions that the APISet can perform
+func (e *SwaggerResourceExpander) getPostActions(ctx context.Context, item *TreeNode) (SwaggerAPISetActions, *swagger.ResourceType) {
+	apiSet, resourceType := e.getActionResourceType(ctx, item)
+	if apiSet == nil || len(resourceType.PostActions) == 0 {
+		return nil, nil
+	}
+	actionAPISet, ok := apiSet.(SwaggerAPISetActions)
+	if !ok {
+		return nil, nil
+	}
+	return actionAPISet, resourceType
+}
+
+// HasActions returns true if the item's ResourceType has POST actions or is a collection that items can be created in
+func (e *SwaggerResourceExpander) HasActions(ctx context.Context, item *TreeNode) (bool, error) {
+	apiSet, resourceType := e.getActionResourceType(ctx, item)
+	if apiSet == nil {
+		return false, nil
+	}
+	actionAPISet, _ := e.getPostActions(ctx, item)
+	return actionAPISet != nil || resourceType.GetCreatableSubResourceType() != nil, nil
+}
+
+// ListActions returns an action for each POST operation on the item's ResourceType and a "Create new..." action for collections
+func (e *SwaggerResourceExpander) ListActions(ctx context.Context, item *TreeNode) ListActionsResult {
+	apiSet, resourceType := e.getActionResourceType(ctx, item)
+	if apiSet == nil {
+		return ListActionsResult{
+			SourceDescription: "SwaggerResourceExpander",
+			Err:               fmt.Errorf("No swagger actions found for %q", item.ID),
+		}
+	}
+
+	newActionNode := func(actionID string, display string) *TreeNode {
+		return &TreeNode{
+			Parentid:               item.ID,
+			ID:                     item.ID + "?" + actionID,
+			Namespace:              "swagger",
+			Name:                   display,
+			Display:                display,
+			ItemType:               ActionType,
+			SwaggerResourceType:    resourceType,
+			SuppressSwaggerExpand:  true,
+			SuppressGenericExpand:  true,
+			TimeoutOverrideSeconds: &swaggerActionTimeoutSeconds,
+			Metadata: map[string]string{
+				"ActionID":          actionID,
+				"SwaggerAPISetID":   item.Metadata["SwaggerAPISetID"],
+				"ResourceID":        item.ID,
+				"ResourceExpandURL": item.ExpandURL,
+			},
+		}
+	}
+
+	nodes := []*TreeNode{}
+	if subResourceType := resourceType.GetCreatableSubResourceType(); subResourceType != nil {
+		nodes = append(nodes, newActionNode(swaggerCreateActionID, "Create new..."))
+	}
+	if actionAPISet, _ := e.getPostActions(ctx, item); actionAPISet != nil {
+		for _, action := range resourceType.PostActions {
+			nodes = append(nodes, newActionNode(action.Name, action.Name))
+		}
+	}
+	return ListActionsResult{
+		Nodes:             nodes,
+		SourceDescription: "SwaggerResourceExpander",
+	}
+}
+
+// ExecuteAction performs the POST action, prompting for the body in the editor if the operation takes one
+func (e *SwaggerResourceExpander) ExecuteAction(ctx context.Context, currentItem *TreeNode) ExpanderResult {
+	actionID := currentItem.Metadata["ActionID"]
+	if actionID == "" {
+		return ExpanderResult{
+			Err:               fmt.Errorf("ActionID metadata not set: %q", currentItem.ID),
+			SourceDescription: "SwaggerResourceExpander",
+			IsPrimaryResponse: true,
+		}
+	}
+	if actionID == swaggerCreateActionID {
+		return e.executeCreateAction(ctx, currentItem)
+	}
+	var action *swagger.PostAction
+	for _, postAction := range currentItem.SwaggerResourceType.PostActions {
+		if postAction.Name == actionID {
+			loopAction := postAction
+			action = &loopAction
+			break
+		}
+	}
+	if action == nil {
+		return ExpanderResult{
+			Err:               fmt.Errorf("Unhandled ActionID: %q", actionID),
+			SourceDescription: "SwaggerResourceExpander",
+			IsPrimaryResponse: true,
+		}
+	}
+
+	apiSetPtr := e.GetAPISet(currentItem.Metadata["SwaggerAPISetID"])
+	if apiSetPtr == nil {
+		return ExpanderResult{
+			Err:               fmt.Errorf("SwaggerAPISet not found for %q", currentItem.ID),
+			SourceDescription: "SwaggerResourceExpander",
+			IsPrimaryResponse: true,
+		}
+	}
+	actionAPISet, ok := (*apiSetPtr).(SwaggerAPISetActions)
+	if !ok {
+		return ExpanderResult{
+			Err:               fmt.Errorf("SwaggerAPISet %q doesn't support actions", (*apiSetPtr).ID()),
+			SourceDescription: "SwaggerResourceExpander",
+			IsPrimaryResponse: true,
+		}
+	}
+
+	matchResult := currentItem.SwaggerResourceType.Endpoint.Match(currentItem.Metadata["ResourceExpandURL"])
+	if !matchResult.IsMatch {
+		return ExpanderResult{
+			Err:               fmt.Errorf("Resource URL didn't match the current Endpoint"),
+			SourceDescription: "SwaggerResourceExpander",
+			IsPrimaryResponse: true,
+		}
+	}
+	url, err := action.Endpoint.BuildURL(matchResult.Values)
+	if err != nil {
+		return ExpanderResult{
+			Err:               fmt.Errorf("Failed to build POST URL '%s': %s", action.Endpoint.TemplateURL, err),
+			SourceDescription: "SwaggerResourceExpander",
+			IsPrimaryResponse: true,
+		}
+	}
+
+	body := ""
+	if action.BodySkeleton != "" {
+		var skeleton bytes.Buffer
+		if err := json.Indent(&skeleton, []byte(action.BodySkeleton), "", "  "); err != nil {
+			skeleton.Reset()
+			skeleton.WriteString(action.BodySkeleton)
+		}
+		content, err := editor.OpenForContent(skeleton.String(), ".json")
+		if err != nil {
+			return ExpanderResult{
+				Err:               err,
+				SourceDescription: "SwaggerResourceExpander",
+				IsPrimaryResponse: true,
+			}
+		}
+		if strings.TrimSpace(content) == "" {
+			return ExpanderResult{
+				Err:               fmt.Errorf("User canceled"),
+				SourceDescription: "SwaggerResourceExpander",
+				IsPrimaryResponse: true,
+			}
+		}
+		body = content
+	}
+
+	data, err := actionAPISet.DoPostAction(ctx, currentItem, url, body)
+	if err != nil {
+		return ExpanderResult{
+			Err:               fmt.Errorf("Error performing %s: %s %s", action.Name, err, data),
+			Response:          ExpanderResponse{Response: data, ResponseType: interfaces.ResponseJSON},
+			SourceDescription: "SwaggerResourceExpander",
+			IsPrimaryResponse: true,
+		}
+	}
+	if strings.TrimSpace(data) == "" {
+		return ExpanderResult{
+			Response:          ExpanderResponse{Response: action.Name + " completed (no response content)", ResponseType: interfaces.ResponsePlainText},
+			SourceDescription: "SwaggerResourceExpander",
+			IsPrimaryResponse: true,
+		}
+	}
+	return ExpanderResult{
+		Response:          ExpanderResponse{Response: data, ResponseType: interfaces.ResponseJSON},
+		SourceDescription: "SwaggerResourceExpander",
+		IsPrimaryResponse: true,
+	}
+}
+
 // substituteValues applies a value map to strings such as "Name: {name}"
 func substituteValues(fmtString string, values map[string]string) string {
 	for name, value := range values {
@@ -291,3 +518,155 @@ func substituteValues(fmtString string, values map[string]string) string {
 	}
 	return fmtString
 }
+
+// executeCreateAction prompts for the name and content of a new item in the collection, PUTs it and then navigates to it
+func (e *SwaggerResourceExpander) executeCreateAction(ctx context.Context, currentItem *TreeNode) ExpanderResult {
+	subResourceType := currentItem.SwaggerResourceType.GetCreatableSubResourceType()
+	apiSetPtr := e.GetAPISet(currentItem.Metadata["SwaggerAPISetID"])
+	if subResourceType == nil || apiSetPtr == nil {
+		return ExpanderResult{
+			Err:               fmt.Errorf("Unable to create items in %q", currentItem.Metadata["ResourceID"]),
+			SourceDescription: "SwaggerResourceExpander",
+			IsPrimaryResponse: true,
+		}
+	}
+	apiSet := *apiSetPtr
+
+	matchResult := currentItem.SwaggerResourceType.Endpoint.Match(currentItem.Metadata["ResourceExpandURL"])
+	if !matchResult.IsMatch {
+		return ExpanderResult{
+			Err:               fmt.Errorf("Resource URL didn't match the current Endpoint"),
+			SourceDescription: "SwaggerResourceExpander",
+			IsPrimaryResponse: true,
+		}
+	}
+
+	segments := subResourceType.PutEndpoint.URLSegments
+	nameSegment := segments[len(segments)-1].Name
+	name, err := promptForInput(ctx, e.gui, e.commandPanel, "Name for new item ("+nameSegment+"):", "")
+	if err != nil {
+		return ExpanderResult{
+			Err:               err,
+			SourceDescription: "SwaggerResourceExpander",
+			IsPrimaryResponse: true,
+		}
+	}
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return ExpanderResult{
+			Err:               fmt.Errorf("User canceled"),
+			SourceDescription: "SwaggerResourceExpander",
+			IsPrimaryResponse: true,
+		}
+	}
+
+	if strings.Contains(name, "/") {
+		return ExpanderResult{
+			Err:               fmt.Errorf("Invalid name %q: names can't contain '/'", name),
+			SourceDescription: "SwaggerResourceExpander",
+			IsPrimaryResponse: true,
+		}
+	}
+
+	templateValues := matchResult.Values
+	templateValues[nameSegment] = url.PathEscape(name)
+	expandURL, err := subResourceType.Endpoint.BuildURL(templateValues)
+	if err != nil {
+		return ExpanderResult{
+			Err:               fmt.Errorf("Failed to build URL '%s': %s", subResourceType.Endpoint.TemplateURL, err),
+			SourceDescription: "SwaggerResourceExpander",
+			IsPrimaryResponse: true,
+		}
+	}
+
+	skeletonJSON := "{}"
+	if subResourceType.PutBodySchema != "" {
+		schema, err := swagger.ParseSchema(subResourceType.PutBodySchema)
+		if err == nil {
+			skeletonJSON = schema.GetSkeleton()
+		}
+	}
+	var skeleton bytes.Buffer
+	if err := json.Indent(&skeleton, []byte(skeletonJSON), "", "  "); err != nil {
+		skeleton.Reset()
+		skeleton.WriteString(skeletonJSON)
+	}
+	content, err := editCreateContent(skeleton.String(), subResourceType.PutBodySchema)
+	if err != nil {
+		return ExpanderResult{
+			Err:               err,
+			SourceDescription: "SwaggerResourceExpander",
+			IsPrimaryResponse: true,
+		}
+	}
+	if strings.TrimSpace(content) == "" {
+		return ExpanderResult{
+			Err:               fmt.Errorf("User canceled"),
+			SourceDescription: "SwaggerResourceExpander",
+			IsPrimaryResponse: true,
+		}
+	}
+
+	// Update PUTs to the templated PUT endpoint for the node's ExpandURL
+	newItem := &TreeNode{
+		Name:                name,
+		ExpandURL:           applyAPIVersionOverride(expandURL),
+		SwaggerResourceType: subResourceType,
+		Metadata: map[string]string{
+			"SwaggerAPISetID": apiSet.ID(),
+		},
+	}
+	err = apiSet.Update(ctx, newItem, content)
+	if err != nil {
+		return ExpanderResult{
+			Err:               fmt.Errorf("Error creating %q: %s", name, err),
+			SourceDescription: "SwaggerResourceExpander",
+			IsPrimaryResponse: true,
+		}
+	}
+
+	// The list handles this event by opening a new tab and navigating from the root of the tree to the new node
+	newItemID := strings.TrimSuffix(currentItem.Metadata["ResourceID"], "/") + "/" + name
+	eventing.Publish("list.navigateto", newItemID)
+	return ExpanderResult{
+		Response:          ExpanderResponse{Response: "Created " + name + ", navigating to " + newItemID, ResponseType: interfaces.ResponsePlainText},
+		SourceDescription: "SwaggerResourceExpander",
+		IsPrimaryResponse: true,
+	}
+}
+
+// editCreateContent opens the editor with the skeleton body for a new item. If the edited content fails
+// validation against the PUT body schema then the errors are shown as a status message and the editor
+// is reopened at the first offending line. Saving the content unchanged at that point returns it anyway
+func editCreateContent(skeleton string, putBodySchema string) (string, error) {
+	content := skeleton
+	line := 0
+	rejectedContent := ""
+	for {
+		updatedContent, err := editor.OpenForContentAtLine(content, ".json", line)
+		if err != nil {
+			return "", err
+		}
+		if strings.TrimSpace(updatedContent) == "" || updatedContent == rejectedContent {
+			return updatedContent, nil
+		}
+
+		validationErrors := swagger.ValidateEditedContent(putBodySchema, "", updatedContent)
+		if len(validationErrors) == 0 {
+			return updatedContent, nil
+		}
+		messages := []string{}
+		for _, validationError := range validationErrors {
+			messages = append(messages, validationError.String())
+		}
+		eventing.SendStatusEvent(&eventing.StatusEvent{
+			Failure: true,
+			Message: fmt.Sprintf("Create failed validation (%d issues) - fix the content, save it unchanged to create anyway or clear it to cancel: %s", len(validationErrors), strings.Join(messages, "; ")),
+			Timeout: time.Second * 15,
+		})
+
+		content = updatedContent
+		line = validationErrors[0].Line
+		rejectedContent = updatedContent
+	}
+}
@@ -130,8 +130,16 @@ type TreeNode struct {
 	SuppressGenericExpand  bool                  // Prevent the DefaultExpander (aka GenericExpander) attempting to expand the node
 	TimeoutOverrideSeconds *int                  // Override the default expand timeout for a node
 	ExpandInPlace          bool                  // Indicates that the node is a "More..." node. Must be the last in the list and will be removed and replaced with the expanded nodes
+	WatchIntervalSeconds   int                   // If set, the content is re-expanded on this interval while the node is open (watch mode)
 }
 
+const (
+	// logWatchIntervalSeconds is the watch interval for nodes showing logs
+	logWatchIntervalSeconds = 10
+	// metricsWatchIntervalSeconds is the watch interval for metric graphs. Metrics are aggregated per minute so refreshing more often isn't useful
+	metricsWatchIntervalSeconds = 60
+)
+
 const (
 	// SubscriptionType defines a sub
 	SubscriptionType = "subscription"
@@ -3,7 +3,9 @@ package expanders
 import (
 	"github.com/valyala/fastjson"
 
+	"sort"
 	"strings"
+	"time"
 )
 
 var fastJSONParser fastjson.Parser
@@ -41,6 +43,11 @@ func DrawStatus(s string) string {
 		return "⛔"
 	case "Succeeded":
 		return "☼"
+	// Resource health availability states
+	case "Degraded":
+		return "⚠"
+	case "Unavailable":
+		return "✖"
 	}
 	return ""
 }
@@ -48,3 +55,26 @@ func DrawStatus(s string) string {
 func getNamespaceFromARMType(s string) string {
 	return strings.Split(s, "/")[0]
 }
+
+// getResourceNameFromID returns the last segment of a resource ID
+func getResourceNameFromID(resourceID string) string {
+	return resourceID[strings.LastIndex(resourceID, "/")+1:]
+}
+
+// sortedMapKeys returns the keys of the map in sorted order
+func sortedMapKeys(values map[string]interface{}) []string {
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// formatDuration formats the duration to the nearest second, or "-" if there is no duration
+func formatDuration(duration time.Duration) string {
+	if duration <= 0 {
+		return "-"
+	}
+	return duration.Round(time.Second).String()
+}
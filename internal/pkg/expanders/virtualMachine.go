@@ -0,0 +1,633 @@
+package expanders
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/awesome-gocui/gocui"
+	"github.com/lawrencegripper/azbrowse/internal/pkg/editor"
+	"github.com/lawrencegripper/azbrowse/internal/pkg/errorhandling"
+	"github.com/lawrencegripper/azbrowse/internal/pkg/eventing"
+	"github.com/lawrencegripper/azbrowse/internal/pkg/interfaces"
+	"github.com/lawrencegripper/azbrowse/internal/pkg/style"
+	"github.com/lawrencegripper/azbrowse/pkg/armclient"
+	"github.com/nbio/st"
+)
+
+const (
+	virtualMachineTemplate   = "/subscriptions/{subscriptionId}/resourceGroups/{resourceGroupName}/providers/Microsoft.Compute/virtualMachines/{vmName}"
+	virtualMachineNamespace  = "virtualMachine"
+	virtualMachineAPIVersion = "2020-12-01"
+
+	virtualMachinePowerStateType = "virtualMachine.powerState"
+	virtualMachineSerialLogType  = "virtualMachine.serialLog"
+	virtualMachineScreenshotType = "virtualMachine.screenshot"
+
+	virtualMachineActionRunCommand = "run-command"
+	virtualMachineActionStart      = "start"
+	virtualMachineActionPowerOff   = "powerOff"
+	virtualMachineActionDeallocate = "deallocate"
+	virtualMachineActionRestart    = "restart"
+)
+
+// Check interface
+var _ Expander = &VirtualMachineExpander{}
+
+// NewVirtualMachineExpander creates a new instance of VirtualMachineExpander
+func NewVirtualMachineExpander(armclient *armclient.Client, gui *gocui.Gui) *VirtualMachineExpander {
+	return &VirtualMachineExpander{
+		client: armclient,
+		gui:    gui,
+	}
+}
+
+// VirtualMachineExpander expands the instance view, boot diagnostics and power actions of a Virtual Machine
+type VirtualMachineExpander struct {
+	ExpanderBase
+	client *armclient.Client
+	gui    *gocui.Gui
+}
+
+func (e *VirtualMachineExpander) setClient(c *armclient.Client) {
+	e.client = c
+}
+
+// Name returns the name of the expander
+func (e *VirtualMachineExpander) Name() string {
+	return "VirtualMachineExpander"
+}
+
+func isVirtualMachineNode(item *TreeNode) bool {
+	return item.ItemType == ResourceType &&
+		item.SwaggerResourceType != nil &&
+		item.SwaggerResourceType.Endpoint.TemplateURL == virtualMachineTemplate
+}
+
+// DoesExpand checks if this is a virtual machine
+func (e *VirtualMachineExpander) DoesExpand(ctx context.Context, currentItem *TreeNode) (bool, error) {
+	if isVirtualMachineNode(currentItem) {
+		return true, nil
+	}
+	if currentItem.Namespace == virtualMachineNamespace {
+		return true, nil
+	}
+	return false, nil
+}
+
+// Expand adds items for virtual machine items to the list
+func (e *VirtualMachineExpander) Expand(ctx context.Context, currentItem *TreeNode) ExpanderResult {
+	switch currentItem.ItemType {
+	case virtualMachinePowerStateType:
+		return e.expandPowerState(ctx, currentItem)
+	case virtualMachineSerialLogType:
+		return e.expandSerialLog(ctx, currentItem)
+	case virtualMachineScreenshotType:
+		return e.expandScreenshot(ctx, currentItem)
+	}
+	return e.expandVirtualMachine(ctx, currentItem)
+}
+
+func (e *VirtualMachineExpander) expandVirtualMachine(ctx context.Context, currentItem *TreeNode) ExpanderResult {
+	instanceView, err := e.getInstanceView(ctx, currentItem.ID)
+	if err != nil {
+		return ExpanderResult{
+			Err:               err,
+			SourceDescription: "VirtualMachineExpander request",
+		}
+	}
+
+	powerState := instanceView.getStatus("PowerState/")
+	provisioningState := instanceView.getStatus("ProvisioningState/")
+
+	newItems := []*TreeNode{
+		{
+			Name:                  "Power State",
+			Display:               "Power State\n   " + style.Subtle("Power: "+powerState.DisplayStatus) + "\n   " + style.Subtle("Provisioning: "+provisioningState.DisplayStatus),
+			ID:                    currentItem.ID + "/powerState",
+			Parentid:              currentItem.ID,
+			Namespace:             virtualMachineNamespace,
+			ItemType:              virtualMachinePowerStateType,
+			ExpandURL:             ExpandURLNotSupported,
+			SubscriptionID:        currentItem.SubscriptionID,
+			StatusIndicator:       drawVMPowerState(powerState.Code),
+			SuppressSwaggerExpand: true,
+			SuppressGenericExpand: true,
+			Metadata: map[string]string{
+				"VirtualMachineID": currentItem.ID,
+			},
+		},
+		{
+			Name:                  "Serial Log",
+			Display:               "Serial Log\n   " + style.Subtle("Boot diagnostics serial console output"),
+			ID:                    currentItem.ID + "/serialLog",
+			Parentid:              currentItem.ID,
+			Namespace:             virtualMachineNamespace,
+			ItemType:              virtualMachineSerialLogType,
+			ExpandURL:             ExpandURLNotSupported,
+			SubscriptionID:        currentItem.SubscriptionID,
+			SuppressSwaggerExpand: true,
+			SuppressGenericExpand: true,
+			WatchIntervalSeconds:  logWatchIntervalSeconds,
+			Metadata: map[string]string{
+				"VirtualMachineID": currentItem.ID,
+			},
+		},
+		{
+			Name:                  "Screenshot",
+			Display:               "Screenshot\n   " + style.Subtle("Boot diagnostics screenshot URL"),
+			ID:                    currentItem.ID + "/screenshot",
+			Parentid:              currentItem.ID,
+			Namespace:             virtualMachineNamespace,
+			ItemType:              virtualMachineScreenshotType,
+			ExpandURL:             ExpandURLNotSupported,
+			SubscriptionID:        currentItem.SubscriptionID,
+			SuppressSwaggerExpand: true,
+			SuppressGenericExpand: true,
+			Metadata: map[string]string{
+				"VirtualMachineID": currentItem.ID,
+			},
+		},
+	}
+
+	return ExpanderResult{
+		Nodes:             newItems,
+		SourceDescription: "VirtualMachineExpander request",
+		IsPrimaryResponse: false,
+	}
+}
+
+func (e *VirtualMachineExpander) expandPowerState(ctx context.Context, currentItem *TreeNode) ExpanderResult {
+	instanceView, err := e.getInstanceView(ctx, currentItem.Metadata["VirtualMachineID"])
+	if err != nil {
+		return ExpanderResult{
+			Err:               err,
+			SourceDescription: "VirtualMachineExpander request",
+			IsPrimaryResponse: true,
+		}
+	}
+
+	currentItem.StatusIndicator = drawVMPowerState(instanceView.getStatus("PowerState/").Code)
+
+	buf, err := json.Marshal(instanceView.Statuses)
+	if err != nil {
+		return ExpanderResult{
+			Err:               err,
+			SourceDescription: "VirtualMachineExpander request",
+			IsPrimaryResponse: true,
+		}
+	}
+	return ExpanderResult{
+		Response:          ExpanderResponse{Response: string(buf), ResponseType: interfaces.ResponseJSON},
+		SourceDescription: "VirtualMachineExpander request",
+		IsPrimaryResponse: true,
+	}
+}
+
+func (e *VirtualMachineExpander) expandSerialLog(ctx context.Context, currentItem *TreeNode) ExpanderResult {
+	bootDiagnostics, err := e.getBootDiagnostics(ctx, currentItem.Metadata["VirtualMachineID"])
+	if err != nil {
+		return ExpanderResult{
+			Err:               err,
+			SourceDescription: "VirtualMachineExpander request",
+			IsPrimaryResponse: true,
+		}
+	}
+
+	// The log is returned as a SAS URI to a blob so doesn't need ARM authentication
+	req, err := http.NewRequestWithContext(ctx, "GET", bootDiagnostics.SerialConsoleLogBlobURI, nil)
+	if err != nil {
+		return ExpanderResult{
+			Err:               fmt.Errorf("Error creating serial log request: %s", err),
+			SourceDescription: "VirtualMachineExpander request",
+			IsPrimaryResponse: true,
+		}
+	}
+	response, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return ExpanderResult{
+			Err:               fmt.Errorf("Error getting serial log: %s", err),
+			SourceDescription: "VirtualMachineExpander request",
+			IsPrimaryResponse: true,
+		}
+	}
+	defer response.Body.Close() //nolint: errcheck
+	buf, err := ioutil.ReadAll(response.Body)
+	if err != nil {
+		return ExpanderResult{
+			Err:               fmt.Errorf("Error reading serial log: %s", err),
+			SourceDescription: "VirtualMachineExpander request",
+			IsPrimaryResponse: true,
+		}
+	}
+	if response.StatusCode != http.StatusOK {
+		return ExpanderResult{
+			Err:               fmt.Errorf("Error getting serial log: %s %s", response.Status, string(buf)),
+			SourceDescription: "VirtualMachineExpander request",
+			IsPrimaryResponse: true,
+		}
+	}
+
+	return ExpanderResult{
+		Response:          ExpanderResponse{Response: string(buf), ResponseType: interfaces.ResponsePlainText},
+		SourceDescription: "VirtualMachineExpander request",
+		IsPrimaryResponse: true,
+	}
+}
+
+func (e *VirtualMachineExpander) expandScreenshot(ctx context.Context, currentItem *TreeNode) ExpanderResult {
+	bootDiagnostics, err := e.getBootDiagnostics(ctx, currentItem.Metadata["VirtualMachineID"])
+	if err != nil {
+		return ExpanderResult{
+			Err:               err,
+			SourceDescription: "VirtualMachineExpander request",
+			IsPrimaryResponse: true,
+		}
+	}
+
+	content := style.Title("Boot diagnostics screenshot") + "\n\n" +
+		"Open the URL below in a browser to view the screenshot. The URL is valid for a limited time.\n\n" +
+		bootDiagnostics.ConsoleScreenshotBlobURI + "\n"
+	return ExpanderResult{
+		Response:          ExpanderResponse{Response: content, ResponseType: interfaces.ResponsePlainText},
+		SourceDescription: "VirtualMachineExpander request",
+		IsPrimaryResponse: true,
+	}
+}
+
+// HasActions returns true for virtual machines
+func (e *VirtualMachineExpander) HasActions(ctx context.Context, item *TreeNode) (bool, error) {
+	return isVirtualMachineNode(item), nil
+}
+
+// ListActions returns the run command and power actions for a virtual machine
+func (e *VirtualMachineExpander) ListActions(ctx context.Context, item *TreeNode) ListActionsResult {
+	// Run command waits for the script to complete which can take a while
+	runCommandTimeout := 600
+	newAction := func(actionID string, name string, timeoutOverride *int) *TreeNode {
+		return &TreeNode{
+			Parentid:               item.ID,
+			ID:                     item.ID + "?" + actionID,
+			Namespace:              virtualMachineNamespace,
+			Name:                   name,
+			Display:                name,
+			ItemType:               ActionType,
+			SuppressGenericExpand:  true,
+			TimeoutOverrideSeconds: timeoutOverride,
+			Metadata: map[string]string{
+				"ActionID": actionID,
+			},
+		}
+	}
+
+	return ListActionsResult{
+		Nodes: []*TreeNode{
+			newAction(virtualMachineActionRunCommand, "Run Command", &runCommandTimeout),
+			newAction(virtualMachineActionStart, "Start VM", nil),
+			newAction(virtualMachineActionPowerOff, "Stop VM", nil),
+			newAction(virtualMachineActionDeallocate, "Deallocate VM", nil),
+			newAction(virtualMachineActionRestart, "Restart VM", nil),
+		},
+		SourceDescription: "VirtualMachineExpander",
+		IsPrimaryResponse: true,
+	}
+}
+
+// ExecuteAction runs the selected virtual machine action
+func (e *VirtualMachineExpander) ExecuteAction(ctx context.Context, item *TreeNode) ExpanderResult {
+	actionID := item.Metadata["ActionID"]
+
+	if item.Parent == nil {
+		return ExpanderResult{
+			SourceDescription: "VirtualMachineExpander",
+			IsPrimaryResponse: true,
+			Err:               fmt.Errorf("Virtual machine not set on action: %q", item.ID),
+		}
+	}
+
+	switch actionID {
+	case virtualMachineActionRunCommand:
+		return e.runCommand(ctx, item.Parent)
+	case virtualMachineActionStart, virtualMachineActionPowerOff, virtualMachineActionDeallocate, virtualMachineActionRestart:
+		return e.powerAction(ctx, item, actionID)
+	case "":
+		return ExpanderResult{
+			SourceDescription: "VirtualMachineExpander",
+			IsPrimaryResponse: true,
+			Err:               fmt.Errorf("ActionID metadata not set: %q", item.ID),
+		}
+	default:
+		return ExpanderResult{
+			SourceDescription: "VirtualMachineExpander",
+			IsPrimaryResponse: true,
+			Err:               fmt.Errorf("Unhandled ActionID: %q", actionID),
+		}
+	}
+}
+
+// powerAction submits the power operation and tracks it in the background, updating the
+// StatusIndicator of the action and the virtual machine as the power state changes
+func (e *VirtualMachineExpander) powerAction(ctx context.Context, actionItem *TreeNode, operation string) ExpanderResult {
+	vm := actionItem.Parent
+
+	go func() {
+		// recover from panic, if one occurrs, and leave terminal usable
+		defer errorhandling.RecoveryWithCleanup()
+
+		event, _ := eventing.SendStatusEvent(&eventing.StatusEvent{
+			InProgress: true,
+			IsToast:    true,
+			Message:    actionItem.Name + " " + vm.Name,
+			Timeout:    time.Minute * 15,
+		})
+
+		updateState := func() {
+			instanceView, err := e.getInstanceView(ctx, vm.ID)
+			if err != nil {
+				return
+			}
+			powerState := instanceView.getStatus("PowerState/")
+			statusIndicator := drawVMPowerState(powerState.Code)
+			actionItem.StatusIndicator = statusIndicator
+			vm.StatusIndicator = statusIndicator
+			event.Message = actionItem.Name + " " + vm.Name + ": " + powerState.DisplayStatus
+			event.Update()
+			if e.gui != nil {
+				// Force UI to re-render to pickup
+				e.gui.Update(func(g *gocui.Gui) error {
+					return nil
+				})
+			}
+		}
+
+		_, err := e.client.DoLongRunningRequestWithBody(ctx, "POST", vm.ID+"/"+operation+"?api-version="+virtualMachineAPIVersion, "", func(status string) {
+			updateState()
+		})
+		updateState()
+
+		event.InProgress = false
+		if err != nil {
+			event.Failure = true
+			event.Message = actionItem.Name + " " + vm.Name + " failed: " + err.Error()
+		} else {
+			event.Message = actionItem.Name + " " + vm.Name + " COMPLETED"
+		}
+		event.SetTimeout(time.Second * 5)
+		event.Update()
+	}()
+
+	return ExpanderResult{
+		Response:          ExpanderResponse{Response: actionItem.Name + " submitted for " + vm.Name + ". Progress is shown next to the action.", ResponseType: interfaces.ResponsePlainText},
+		SourceDescription: "VirtualMachineExpander request",
+		IsPrimaryResponse: true,
+	}
+}
+
+func (e *VirtualMachineExpander) runCommand(ctx context.Context, vm *TreeNode) ExpanderResult {
+	data, err := e.client.DoRequest(ctx, "GET", vm.ID+"?api-version="+virtualMachineAPIVersion)
+	if err != nil {
+		return ExpanderResult{
+			Err:               fmt.Errorf("Error getting virtual machine: %s", err),
+			SourceDescription: "VirtualMachineExpander request",
+			IsPrimaryResponse: true,
+		}
+	}
+	var vmResponse VirtualMachineResponse
+	if err := json.Unmarshal([]byte(data), &vmResponse); err != nil {
+		return ExpanderResult{
+			Err:               fmt.Errorf("Error parsing virtual machine: %s", err),
+			SourceDescription: "VirtualMachineExpander request",
+			IsPrimaryResponse: true,
+		}
+	}
+
+	commandID := "RunShellScript"
+	fileExtension := ".sh"
+	scriptType := "shell"
+	if strings.EqualFold(vmResponse.Properties.StorageProfile.OsDisk.OsType, "Windows") {
+		commandID = "RunPowerShellScript"
+		fileExtension = ".ps1"
+		scriptType = "PowerShell"
+	}
+
+	// '#' is a comment in both shell and PowerShell scripts
+	initialContent := fmt.Sprintf("# Enter the %s script to run on %s then save and exit. To cancel, leave the script as-is or delete the content\n", scriptType, vm.Name)
+	content, err := editor.OpenForContent(initialContent, fileExtension)
+	if err != nil {
+		return ExpanderResult{
+			Err:               err,
+			SourceDescription: "VirtualMachineExpander request",
+			IsPrimaryResponse: true,
+		}
+	}
+
+	if content == initialContent || strings.TrimSpace(content) == "" {
+		return ExpanderResult{
+			Err:               fmt.Errorf("User canceled"),
+			SourceDescription: "VirtualMachineExpander request",
+			IsPrimaryResponse: true,
+		}
+	}
+
+	if strings.HasPrefix(content, initialContent) {
+		// remove the comment line we added!
+		content = strings.TrimPrefix(content, initialContent)
+	}
+
+	body, err := json.Marshal(map[string]interface{}{
+		"commandId": commandID,
+		"script":    strings.Split(strings.ReplaceAll(content, "\r\n", "\n"), "\n"),
+	})
+	if err != nil {
+		return ExpanderResult{
+			Err:               err,
+			SourceDescription: "VirtualMachineExpander request",
+			IsPrimaryResponse: true,
+		}
+	}
+
+	event, _ := eventing.SendStatusEvent(&eventing.StatusEvent{
+		InProgress: true,
+		Message:    "Running command on " + vm.Name,
+		Timeout:    time.Minute * 10,
+	})
+	defer event.Done()
+
+	result, err := e.client.DoLongRunningRequestWithBody(ctx, "POST", vm.ID+"/runCommand?api-version="+virtualMachineAPIVersion, string(body), func(status string) {
+		event.Message = "Running command on " + vm.Name + ": " + status
+		event.Update()
+	})
+	if err != nil {
+		return ExpanderResult{
+			Err:               fmt.Errorf("Error running command: %s %s", err, result),
+			SourceDescription: "VirtualMachineExpander request",
+			IsPrimaryResponse: true,
+		}
+	}
+
+	output, err := renderRunCommandResult(result)
+	if err != nil {
+		return ExpanderResult{
+			Err:               err,
+			SourceDescription: "VirtualMachineExpander request",
+			IsPrimaryResponse: true,
+		}
+	}
+
+	return ExpanderResult{
+		Response:          ExpanderResponse{Response: output, ResponseType: interfaces.ResponsePlainText},
+		SourceDescription: "VirtualMachineExpander request",
+		IsPrimaryResponse: true,
+	}
+}
+
+// renderRunCommandResult formats the stdout and stderr returned by a run command
+func renderRunCommandResult(data string) (string, error) {
+	var result RunCommandResult
+	if err := json.Unmarshal([]byte(data), &result); err != nil {
+		return "", fmt.Errorf("Error parsing run command result: %s", err)
+	}
+
+	var sb strings.Builder
+	for _, status := range result.Value {
+		// Windows VMs return separate StdOut and StdErr statuses,
+		// Linux VMs return a single status containing both
+		switch {
+		case strings.Contains(status.Code, "/StdOut/"):
+			sb.WriteString(style.Title("[stdout]") + "\n" + status.Message + "\n\n")
+		case strings.Contains(status.Code, "/StdErr/"):
+			sb.WriteString(style.Title("[stderr]") + "\n" + status.Message + "\n\n")
+		default:
+			sb.WriteString(style.Subtle(status.Code) + "\n" + status.Message + "\n\n")
+		}
+	}
+	return sb.String(), nil
+}
+
+func (e *VirtualMachineExpander) getInstanceView(ctx context.Context, vmID string) (VirtualMachineInstanceView, error) {
+	var instanceView VirtualMachineInstanceView
+	data, err := e.client.DoRequest(ctx, "GET", vmID+"/instanceView?api-version="+virtualMachineAPIVersion)
+	if err != nil {
+		return instanceView, fmt.Errorf("Error getting instance view: %s", err)
+	}
+	if err := json.Unmarshal([]byte(data), &instanceView); err != nil {
+		return instanceView, fmt.Errorf("Error parsing instance view: %s", err)
+	}
+	return instanceView, nil
+}
+
+func (e *VirtualMachineExpander) getBootDiagnostics(ctx context.Context, vmID string) (BootDiagnosticsData, error) {
+	var bootDiagnostics BootDiagnosticsData
+	data, err := e.client.DoRequest(ctx, "POST", vmID+"/retrieveBootDiagnosticsData?api-version="+virtualMachineAPIVersion)
+	if err != nil {
+		return bootDiagnostics, fmt.Errorf("Error getting boot diagnostics (is boot diagnostics enabled?): %s %s", err, data)
+	}
+	if err := json.Unmarshal([]byte(data), &bootDiagnostics); err != nil {
+		return bootDiagnostics, fmt.Errorf("Error parsing boot diagnostics: %s", err)
+	}
+	return bootDiagnostics, nil
+}
+
+// drawVMPowerState converts a PowerState status code to an icon
+func drawVMPowerState(code string) string {
+	switch strings.ToLower(strings.TrimPrefix(code, "PowerState/")) {
+	case "running":
+		return "▶"
+	case "starting":
+		return "⛅"
+	case "stopping", "deallocating":
+		return "⌛"
+	case "stopped":
+		return "⏸"
+	case "deallocated":
+		return "⏹"
+	}
+	return ""
+}
+
+// VirtualMachineInstanceView is returned by a request for a virtual machine's instance view
+type VirtualMachineInstanceView struct {
+	ComputerName string                 `json:"computerName"`
+	OsName       string                 `json:"osName"`
+	OsVersion    string                 `json:"osVersion"`
+	Statuses     []VirtualMachineStatus `json:"statuses"`
+}
+
+// VirtualMachineStatus is a single status in a virtual machine's instance view
+type VirtualMachineStatus struct {
+	Code          string `json:"code"`
+	Level         string `json:"level"`
+	DisplayStatus string `json:"displayStatus"`
+	Message       string `json:"message,omitempty"`
+	Time          string `json:"time,omitempty"`
+}
+
+func (v VirtualMachineInstanceView) getStatus(codePrefix string) VirtualMachineStatus {
+	for _, status := range v.Statuses {
+		if strings.HasPrefix(status.Code, codePrefix) {
+			return status
+		}
+	}
+	return VirtualMachineStatus{DisplayStatus: "Unknown"}
+}
+
+// VirtualMachineResponse is returned by a request for a virtual machine
+type VirtualMachineResponse struct {
+	ID         string `json:"id"`
+	Name       string `json:"name"`
+	Properties struct {
+		StorageProfile struct {
+			OsDisk struct {
+				OsType string `json:"osType"`
+			} `json:"osDisk"`
+		} `json:"storageProfile"`
+		NetworkProfile struct {
+			NetworkInterfaces []struct {
+				ID string `json:"id"`
+			} `json:"networkInterfaces"`
+		} `json:"networkProfile"`
+	} `json:"properties"`
+}
+
+// BootDiagnosticsData is returned by a request to retrieve boot diagnostics data
+type BootDiagnosticsData struct {
+	ConsoleScreenshotBlobURI string `json:"consoleScreenshotBlobUri"`
+	SerialConsoleLogBlobURI  string `json:"serialConsoleLogBlobUri"`
+}
+
+// RunCommandResult is returned by a run command request
+type RunCommandResult struct {
+	Value []VirtualMachineStatus `json:"value"`
+}
+
+func (e *VirtualMachineExpander) testCases() (bool, *[]expanderTestCase) {
+	const vmID = "/subscriptions/00000000-0000-0000-0000-000000000000/resourceGroups/rg1/providers/Microsoft.Compute/virtualMachines/vm1"
+	return true, &[]expanderTestCase{
+		{
+			name:         "VirtualMachine->PowerState",
+			responseFile: "./testdata/armsamples/resource/vmInstanceView.json",
+			statusCode:   200,
+			urlPath:      vmID + "/instanceView",
+			nodeToExpand: &TreeNode{
+				ID:       vmID,
+				Name:     "vm1",
+				ItemType: ResourceType,
+			},
+			treeNodeCheckerFunc: func(t *testing.T, r ExpanderResult) {
+				st.Expect(t, r.Err, nil)
+				st.Expect(t, len(r.Nodes), 3)
+
+				st.Expect(t, r.Nodes[0].ItemType, virtualMachinePowerStateType)
+				st.Expect(t, r.Nodes[0].StatusIndicator, "⏹")
+				st.Expect(t, strings.Contains(r.Nodes[0].Display, "VM deallocated"), true)
+				st.Expect(t, r.Nodes[1].ItemType, virtualMachineSerialLogType)
+				st.Expect(t, r.Nodes[2].ItemType, virtualMachineScreenshotType)
+			},
+		},
+	}
+}
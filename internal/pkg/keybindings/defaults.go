@@ -23,6 +23,11 @@ var DefaultKeys = map[string]interface{}{
 	"listexpand":          gocui.KeyEnter,
 	"listopen":            gocui.KeyCtrlO,
 	"listrefresh":         gocui.KeyF5,
+	"listwatch":           gocui.KeyCtrlW,
+	"listnewtab":          gocui.KeyCtrlT,
+	"listnexttab":         rune(']'),
+	"listprevioustab":     rune('['),
+	"listclosetab":        gocui.KeyCtrlX,
 	"listupdate":          gocui.KeyCtrlU,
 	"listpagedown":        gocui.KeyPgdn,
 	"listpageup":          gocui.KeyPgup,
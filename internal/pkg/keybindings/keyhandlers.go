@@ -45,6 +45,11 @@ const (
 	HandlerIDAzureSearchQuery        HandlerID = "azuresearchquery"      //nolist:golint
 	HandlerIDToggleDemoMode          HandlerID = "toggledemomode"        //nolist:golint
 	HandlerIDListSort                HandlerID = "listsort"              //nolint:golint
+	HandlerIDListWatch               HandlerID = "listwatch"             //nolint:golint
+	HandlerIDListNewTab              HandlerID = "listnewtab"            //nolint:golint
+	HandlerIDListNextTab             HandlerID = "listnexttab"           //nolint:golint
+	HandlerIDListPreviousTab         HandlerID = "listprevioustab"       //nolint:golint
+	HandlerIDListCloseTab            HandlerID = "listclosetab"          //nolint:golint
 )
 
 // KeyHandler is an interface that all key handlers must implement
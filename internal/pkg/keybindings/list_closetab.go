@@ -0,0 +1,38 @@
+package keybindings
+
+import (
+	"github.com/awesome-gocui/gocui"
+	"github.com/lawrencegripper/azbrowse/internal/pkg/views"
+)
+
+type ListCloseTabHandler struct {
+	ListHandler
+	List *views.ListWidget
+}
+
+var _ Command = &ListCloseTabHandler{}
+
+func NewListCloseTabHandler(list *views.ListWidget) *ListCloseTabHandler {
+	handler := &ListCloseTabHandler{
+		List: list,
+	}
+	handler.id = HandlerIDListCloseTab
+	return handler
+}
+
+func (h ListCloseTabHandler) Fn() func(g *gocui.Gui, v *gocui.View) error {
+	return func(g *gocui.Gui, v *gocui.View) error {
+		return h.Invoke()
+	}
+}
+
+func (h *ListCloseTabHandler) DisplayText() string {
+	return "Close tab"
+}
+func (h *ListCloseTabHandler) IsEnabled() bool {
+	return h.List.TabCount() > 1
+}
+func (h *ListCloseTabHandler) Invoke() error {
+	h.List.CloseTab()
+	return nil
+}
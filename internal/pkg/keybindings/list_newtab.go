@@ -0,0 +1,38 @@
+package keybindings
+
+import (
+	"github.com/awesome-gocui/gocui"
+	"github.com/lawrencegripper/azbrowse/internal/pkg/views"
+)
+
+type ListNewTabHandler struct {
+	ListHandler
+	List *views.ListWidget
+}
+
+var _ Command = &ListNewTabHandler{}
+
+func NewListNewTabHandler(list *views.ListWidget) *ListNewTabHandler {
+	handler := &ListNewTabHandler{
+		List: list,
+	}
+	handler.id = HandlerIDListNewTab
+	return handler
+}
+
+func (h ListNewTabHandler) Fn() func(g *gocui.Gui, v *gocui.View) error {
+	return func(g *gocui.Gui, v *gocui.View) error {
+		return h.Invoke()
+	}
+}
+
+func (h *ListNewTabHandler) DisplayText() string {
+	return "Open in new tab"
+}
+func (h *ListNewTabHandler) IsEnabled() bool {
+	return h.List.HasCurrentItem()
+}
+func (h *ListNewTabHandler) Invoke() error {
+	h.List.OpenInNewTab()
+	return nil
+}
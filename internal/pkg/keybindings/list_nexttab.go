@@ -0,0 +1,38 @@
+package keybindings
+
+import (
+	"github.com/awesome-gocui/gocui"
+	"github.com/lawrencegripper/azbrowse/internal/pkg/views"
+)
+
+type ListNextTabHandler struct {
+	ListHandler
+	List *views.ListWidget
+}
+
+var _ Command = &ListNextTabHandler{}
+
+func NewListNextTabHandler(list *views.ListWidget) *ListNextTabHandler {
+	handler := &ListNextTabHandler{
+		List: list,
+	}
+	handler.id = HandlerIDListNextTab
+	return handler
+}
+
+func (h ListNextTabHandler) Fn() func(g *gocui.Gui, v *gocui.View) error {
+	return func(g *gocui.Gui, v *gocui.View) error {
+		return h.Invoke()
+	}
+}
+
+func (h *ListNextTabHandler) DisplayText() string {
+	return "Next tab"
+}
+func (h *ListNextTabHandler) IsEnabled() bool {
+	return h.List.TabCount() > 1
+}
+func (h *ListNextTabHandler) Invoke() error {
+	h.List.NextTab()
+	return nil
+}
@@ -0,0 +1,38 @@
+package keybindings
+
+import (
+	"github.com/awesome-gocui/gocui"
+	"github.com/lawrencegripper/azbrowse/internal/pkg/views"
+)
+
+type ListPreviousTabHandler struct {
+	ListHandler
+	List *views.ListWidget
+}
+
+var _ Command = &ListPreviousTabHandler{}
+
+func NewListPreviousTabHandler(list *views.ListWidget) *ListPreviousTabHandler {
+	handler := &ListPreviousTabHandler{
+		List: list,
+	}
+	handler.id = HandlerIDListPreviousTab
+	return handler
+}
+
+func (h ListPreviousTabHandler) Fn() func(g *gocui.Gui, v *gocui.View) error {
+	return func(g *gocui.Gui, v *gocui.View) error {
+		return h.Invoke()
+	}
+}
+
+func (h *ListPreviousTabHandler) DisplayText() string {
+	return "Previous tab"
+}
+func (h *ListPreviousTabHandler) IsEnabled() bool {
+	return h.List.TabCount() > 1
+}
+func (h *ListPreviousTabHandler) Invoke() error {
+	h.List.PreviousTab()
+	return nil
+}
@@ -15,6 +15,7 @@ import (
 	"github.com/lawrencegripper/azbrowse/internal/pkg/expanders"
 	"github.com/lawrencegripper/azbrowse/internal/pkg/interfaces"
 	"github.com/lawrencegripper/azbrowse/internal/pkg/views"
+	"github.com/lawrencegripper/azbrowse/pkg/swagger"
 )
 
 type ListUpdateHandler struct {
@@ -112,7 +113,15 @@ func (h *ListUpdateHandler) Invoke() error {
 
 	h.status.Status("Opening content in editor...", false)
 
-	updatedContent, err := editor.OpenForContent(formattedContent, fileExtension)
+	return h.editContent(item, contentType, fileExtension, formattedContent, formattedContent, 0, "")
+}
+
+// editContent opens the editor and updates the item with the edited content. If the edited content fails
+// validation against the swagger definitions then the errors are shown in the item view and the editor is
+// reopened at the first offending line. Saving the content unchanged at that point performs the update anyway
+func (h *ListUpdateHandler) editContent(item *expanders.TreeNode, contentType interfaces.ExpanderResponseType, fileExtension string, formattedContent string, content string, line int, rejectedContent string) error {
+
+	updatedContent, err := editor.OpenForContentAtLine(content, fileExtension, line)
 	if err != nil {
 		h.status.Status(fmt.Sprintf("Error opening editor: %s", err), false)
 		return err
@@ -127,6 +136,24 @@ func (h *ListUpdateHandler) Invoke() error {
 		return nil
 	}
 
+	if contentType == interfaces.ResponseJSON && updatedContent != rejectedContent {
+		validationErrors := getUpdateValidationErrors(item, formattedContent, updatedContent)
+		if len(validationErrors) > 0 {
+			messages := []string{}
+			for _, validationError := range validationErrors {
+				messages = append(messages, validationError.String())
+			}
+			h.Content.ShowValidationErrors(messages)
+			h.status.Status(fmt.Sprintf("Update failed validation (%d issues) - fix the content, save it unchanged to update anyway or clear it to cancel", len(validationErrors)), false)
+
+			// Reopen the editor once the UI has redrawn to show the errors
+			go h.Gui.Update(func(*gocui.Gui) error {
+				return h.editContent(item, contentType, fileExtension, formattedContent, updatedContent, validationErrors[0].Line, updatedContent)
+			})
+			return nil
+		}
+	}
+
 	// Handle the updating of the item asyncronously to allow tcell to update and redraw UI
 	go func() {
 		errorhandling.RecoveryWithCleanup()
@@ -155,3 +182,12 @@ func (h *ListUpdateHandler) Invoke() error {
 
 	return nil
 }
+
+// getUpdateValidationErrors validates the updated content against the PUT body schema for the item (if it has one)
+func getUpdateValidationErrors(item *expanders.TreeNode, originalContent string, updatedContent string) []swagger.ValidationError {
+	schemaJSON := ""
+	if item.SwaggerResourceType != nil {
+		schemaJSON = item.SwaggerResourceType.PutBodySchema
+	}
+	return swagger.ValidateEditedContent(schemaJSON, originalContent, updatedContent)
+}
@@ -0,0 +1,38 @@
+package keybindings
+
+import (
+	"github.com/awesome-gocui/gocui"
+	"github.com/lawrencegripper/azbrowse/internal/pkg/views"
+)
+
+type ListWatchHandler struct {
+	ListHandler
+	List *views.ListWidget
+}
+
+var _ Command = &ListWatchHandler{}
+
+func NewListWatchHandler(list *views.ListWidget) *ListWatchHandler {
+	handler := &ListWatchHandler{
+		List: list,
+	}
+	handler.id = HandlerIDListWatch
+	return handler
+}
+
+func (h ListWatchHandler) Fn() func(g *gocui.Gui, v *gocui.View) error {
+	return func(g *gocui.Gui, v *gocui.View) error {
+		return h.Invoke()
+	}
+}
+
+func (h *ListWatchHandler) DisplayText() string {
+	return "Pause/resume watch"
+}
+func (h *ListWatchHandler) IsEnabled() bool {
+	return h.List.IsWatching()
+}
+func (h *ListWatchHandler) Invoke() error {
+	h.List.ToggleWatchPaused()
+	return nil
+}
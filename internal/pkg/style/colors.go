@@ -83,3 +83,36 @@ func Highlight(s string) string {
 func Graph(s string) string {
 	return color.New(color.FgBlue).Sprint(s)
 }
+
+// Added make the text green, used for items being created
+func Added(s string) string {
+	return color.New(color.FgGreen).Sprint(s)
+}
+
+// Changed make the text yellow, used for items being modified
+func Changed(s string) string {
+	return color.New(color.FgYellow).Sprint(s)
+}
+
+// Removed make the text red, used for items being deleted
+func Removed(s string) string {
+	return color.New(color.FgRed).Sprint(s)
+}
+
+var seriesColors = []color.Attribute{
+	color.FgBlue,
+	color.FgGreen,
+	color.FgYellow,
+	color.FgMagenta,
+	color.FgCyan,
+	color.FgRed,
+	color.FgHiBlue,
+	color.FgHiGreen,
+	color.FgHiYellow,
+	color.FgHiMagenta,
+}
+
+// Series colors the text for the series at index, used to distinguish series in graphs
+func Series(index int, s string) string {
+	return color.New(seriesColors[index%len(seriesColors)]).Sprint(s)
+}
@@ -22,6 +22,10 @@ const tmplText = `
 | Go back                  | {{ index . "listback" }}
 | Expand/View resource     | {{ index . "listexpand" }}
 | Refresh                  | {{ index . "listrefresh" }}
+| Pause/resume watch       | {{ index . "listwatch" }}
+| Open in new tab          | {{ index . "listnewtab" }}
+| Next/previous tab        | {{ index . "listnexttab" }} / {{ index . "listprevioustab" }}
+| Close tab                | {{ index . "listclosetab" }}
 | Filter                   | {{ index . "filter" }}
 | Clear filter             | {{ index . "listclearfilter" }}
 | Open Command Panel       | {{ index . "commandpanelopen" }}
@@ -45,7 +49,8 @@ const tmplText = `
 # Status Icons
 
 Deleting:  ☠   Failed:  ⛈   Updating:  ⟳   Resuming/Starting:  ⛅   Provisioning:  ⌛                                                                                                                                  
-Creating\Preparing:  🏗   Scaling:  ⚖   Suspended/Suspending:  ⛔   Succeeded:  🌣                                                                                                                                        
+Creating\Preparing:  🏗   Scaling:  ⚖   Suspended/Suspending:  ⛔   Succeeded:  🌣
+Health - Degraded:  ⚠   Unavailable:  ✖                                                                                                                                        
 
 For bugs, issue or to contribute visit: https://github.com/lawrencegripper/azbrowse
 `
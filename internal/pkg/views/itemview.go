@@ -12,6 +12,8 @@ import (
 	"github.com/lawrencegripper/azbrowse/internal/pkg/eventing"
 	"github.com/lawrencegripper/azbrowse/internal/pkg/expanders"
 	"github.com/lawrencegripper/azbrowse/internal/pkg/interfaces"
+	"github.com/lawrencegripper/azbrowse/internal/pkg/style"
+	"github.com/lawrencegripper/azbrowse/pkg/swagger"
 	"github.com/stuartleeks/colorjson"
 
 	"github.com/alecthomas/chroma"
@@ -38,6 +40,9 @@ type ItemWidget struct {
 	filterHandler        func(s string) error
 	filterString         string
 	title                string
+	schema               *swagger.Schema // response schema for the node (if known), used to describe the property under the cursor
+	propertyCursor       string          // cursor position that propertyDescription was determined for
+	propertyDescription  string
 	// track if we need to re-render the layout or is it the same content?
 	hasChanged bool
 }
@@ -63,6 +68,23 @@ func (w *ItemWidget) Layout(g *gocui.Gui) error {
 
 	x0, y0, x1, y1 := getViewBounds(g, w.x, w.y, w.w, w.h)
 
+	// show the swagger definition for the property under the cursor below the content
+	currentView := g.CurrentView()
+	isCurrentView := currentView != nil && currentView.Name() == "itemWidget"
+	if propertyDescription := w.getPropertyDescription(); isCurrentView && propertyDescription != "" && y1-y0 > 3*propertyViewHeight {
+		pv, err := g.SetView("itemPropertyWidget", x0, y1-propertyViewHeight, x1, y1, 0)
+		if err != nil && err != gocui.ErrUnknownView {
+			return err
+		}
+		pv.Title = "Property"
+		pv.Wrap = true
+		pv.Clear()
+		fmt.Fprint(pv, propertyDescription)
+		y1 = y1 - propertyViewHeight - 1
+	} else if err := g.DeleteView("itemPropertyWidget"); err != nil && err != gocui.ErrUnknownView {
+		return err
+	}
+
 	v, err := g.SetView("itemWidget", x0, y0, x1, y1, 0)
 	if err != nil && err != gocui.ErrUnknownView {
 		return err
@@ -127,6 +149,44 @@ func (w *ItemWidget) Layout(g *gocui.Gui) error {
 	return nil
 }
 
+// getPropertyDescription returns the description of the swagger definition for the property under the cursor
+// (or "" if there isn't one)
+func (w *ItemWidget) getPropertyDescription() string {
+	if w.view == nil || w.schema == nil || w.filterString != "" || w.hasChanged {
+		return "" // the view buffer doesn't contain the content yet
+	}
+	originX, originY := w.view.Origin()
+	cursorX, cursorY := w.view.Cursor()
+	width, _ := w.view.Size()
+	propertyCursor := fmt.Sprintf("%d,%d,%d,%d,%d", originX, originY, cursorX, cursorY, width)
+	if propertyCursor == w.propertyCursor {
+		return w.propertyDescription
+	}
+	w.propertyCursor = propertyCursor
+	w.propertyDescription = ""
+
+	lines := w.view.BufferLines()
+	path, ok := getJSONPathAtLine(lines, getCursorBufferLine(w.view, lines))
+	if !ok || len(path) == 0 {
+		return ""
+	}
+	if schema := w.schema.GetPropertySchema(path); schema != nil {
+		w.propertyDescription = getPropertyDescription(getPropertyName(path), schema)
+	}
+	return w.propertyDescription
+}
+
+func getResponseSchema(node *expanders.TreeNode, contentType interfaces.ExpanderResponseType) *swagger.Schema {
+	if node == nil || node.SwaggerResourceType == nil || node.SwaggerResourceType.ResponseSchema == "" || contentType != interfaces.ResponseJSON {
+		return nil
+	}
+	schema, err := swagger.ParseSchema(node.SwaggerResourceType.ResponseSchema)
+	if err != nil {
+		return nil
+	}
+	return schema
+}
+
 // PageDown move the view down a page
 func (w *ItemWidget) PageDown() {
 	_, maxHeight := w.view.Size()
@@ -233,6 +293,8 @@ func (w *ItemWidget) SetContentWithNode(node *expanders.TreeNode, content string
 	w.content = content
 	w.contentType = contentType
 	w.title = title
+	w.schema = getResponseSchema(node, contentType)
+	w.propertyCursor = ""
 
 	if w.hideGuids {
 		w.content = StripSecretVals(w.content)
@@ -288,6 +350,33 @@ func (w *ItemWidget) SetContentWithNode(node *expanders.TreeNode, content string
 	w.g.Update(func(*gocui.Gui) error { return nil })
 }
 
+// ShowValidationErrors displays the validation errors above the current content.
+// The content returned by GetContent (used for copying and editing) is unchanged
+func (w *ItemWidget) ShowValidationErrors(validationErrors []string) {
+	w.SetContentWithNode(w.node, w.originalContent, w.contentType, w.title)
+
+	var header strings.Builder
+	header.WriteString(style.Warning("Validation failed:") + "\n")
+	for _, validationError := range validationErrors {
+		header.WriteString(style.Warning("  - "+validationError) + "\n")
+	}
+	w.content = header.String() + "\n" + w.content
+}
+
+// RefreshContent replaces the content for the current node, keeping the scroll position and filter.
+// This is used to update the content in place when watching a node
+func (w *ItemWidget) RefreshContent(content string, contentType interfaces.ExpanderResponseType) {
+	filterString := w.filterString
+	x, y := w.view.Origin()
+
+	w.SetContentWithNode(w.node, content, contentType, w.title)
+
+	if filterString != "" {
+		w.SetFilter(filterString)
+	}
+	w.view.SetOrigin(x, y) //nolint: errcheck
+}
+
 // GetContent returns the current content
 func (w *ItemWidget) GetContent() string {
 	return w.originalContent
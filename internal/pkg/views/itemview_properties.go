@@ -0,0 +1,175 @@
+package views
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/awesome-gocui/gocui"
+	"github.com/lawrencegripper/azbrowse/internal/pkg/style"
+	"github.com/lawrencegripper/azbrowse/pkg/swagger"
+)
+
+// propertyViewHeight is the height of the panel showing the swagger definition of the property under the cursor
+const propertyViewHeight = 5
+
+type jsonPathFrame struct {
+	segment string
+	isArray bool
+}
+
+// getJSONPathAtLine returns the path (property names, with "[]" for array items) of the property on the line
+// of pretty-printed JSON (as formatted for the item view). Lines before the start of the JSON (e.g. validation errors) are skipped.
+// Returns false if the line isn't part of the JSON
+func getJSONPathAtLine(lines []string, lineIndex int) ([]string, bool) {
+	if lineIndex < 0 || lineIndex >= len(lines) {
+		return nil, false
+	}
+	start := -1
+	for i, line := range lines {
+		trimmed := strings.TrimSpace(line)
+		if trimmed == "{" || trimmed == "[" {
+			start = i
+			break
+		}
+	}
+	if start < 0 || lineIndex < start {
+		return nil, false
+	}
+
+	stack := []jsonPathFrame{}
+	getPath := func(lineSegment string) []string {
+		path := []string{}
+		for _, frame := range stack[1:] {
+			path = append(path, frame.segment)
+		}
+		if lineSegment != "" {
+			path = append(path, lineSegment)
+		}
+		return path
+	}
+	for i := start; i <= lineIndex; i++ {
+		line := strings.TrimSuffix(strings.TrimSpace(lines[i]), ",")
+		if i == start {
+			stack = append(stack, jsonPathFrame{isArray: line == "["})
+			if i == lineIndex {
+				return []string{}, true
+			}
+			continue
+		}
+		if len(stack) == 0 {
+			return nil, false // past the end of the JSON
+		}
+		if line == "}" || line == "]" {
+			if i == lineIndex {
+				return getPath(""), true
+			}
+			stack = stack[:len(stack)-1]
+			continue
+		}
+
+		segment := "[]"
+		value := line
+		if !stack[len(stack)-1].isArray {
+			name, rest, ok := parseJSONKey(line)
+			if !ok {
+				return nil, false
+			}
+			segment = name
+			value = rest
+		}
+		if i == lineIndex {
+			return getPath(segment), true
+		}
+		if value == "{" || value == "[" {
+			stack = append(stack, jsonPathFrame{segment: segment, isArray: value == "["})
+		}
+	}
+	return nil, false
+}
+
+// parseJSONKey parses a line of the form `"name": value`, returning the name and value
+func parseJSONKey(line string) (string, string, bool) {
+	if !strings.HasPrefix(line, `"`) {
+		return "", "", false
+	}
+	for i := 1; i < len(line); i++ {
+		switch line[i] {
+		case '\\':
+			i++ // skip the escaped character
+		case '"':
+			name, err := strconv.Unquote(line[:i+1])
+			if err != nil {
+				return "", "", false
+			}
+			rest := strings.TrimSpace(line[i+1:])
+			if !strings.HasPrefix(rest, ":") {
+				return "", "", false
+			}
+			return name, strings.TrimSpace(rest[1:]), true
+		}
+	}
+	return "", "", false
+}
+
+// getPropertyName returns the name of the property for the path, e.g. "rules[]" for an item in the rules array
+func getPropertyName(path []string) string {
+	name := ""
+	for i := len(path) - 1; i >= 0; i-- {
+		name = path[i] + name
+		if path[i] != "[]" {
+			break
+		}
+	}
+	return name
+}
+
+// getCursorBufferLine returns the index of the buffer line under the cursor, allowing for wrapped lines
+func getCursorBufferLine(v *gocui.View, lines []string) int {
+	_, originY := v.Origin()
+	_, cursorY := v.Cursor()
+	viewY := originY + cursorY
+	width, _ := v.Size()
+
+	row := 0
+	for i, line := range lines {
+		rows := 1
+		if length := utf8.RuneCountInString(line); v.Wrap && width > 0 && length > width {
+			rows = (length + width - 1) / width
+		}
+		if viewY < row+rows {
+			return i
+		}
+		row += rows
+	}
+	return -1
+}
+
+// getPropertyDescription returns the text describing the swagger definition of the property
+func getPropertyDescription(name string, schema *swagger.Schema) string {
+	var description strings.Builder
+	details := []string{}
+	if schema.Type != "" {
+		details = append(details, schema.Type)
+	}
+	if schema.ReadOnly {
+		details = append(details, "read-only")
+	}
+	description.WriteString(style.Title(name))
+	if len(details) > 0 {
+		description.WriteString(" (" + strings.Join(details, ", ") + ")")
+	}
+	description.WriteString("\n")
+	if schema.Description != "" {
+		description.WriteString(schema.Description + "\n")
+	}
+	if len(schema.Enum) > 0 {
+		values := []string{}
+		for _, value := range schema.Enum {
+			values = append(values, fmt.Sprintf("%v", value))
+		}
+		description.WriteString(style.Subtle("Values: ") + strings.Join(values, " | ") + "\n")
+	}
+	return description.String()
+}
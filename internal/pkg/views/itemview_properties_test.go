@@ -0,0 +1,74 @@
+package views
+
+import (
+	"testing"
+
+	"github.com/lawrencegripper/azbrowse/pkg/swagger"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_GetJSONPathAtLine(t *testing.T) {
+	lines := []string{
+		"Validation failed:",
+		"  - line 3: properties.colour: unknown property",
+		"",
+		"{",
+		`  "id": "/sites/s1/config/web",`,
+		`  "properties": {`,
+		`    "ftpsState": "AllAllowed",`,
+		`    "ipSecurityRestrictions": [`,
+		`      {`,
+		`        "action": "Allow",`,
+		`        "name": "a \"quoted\": name"`,
+		`      },`,
+		`      "text"`,
+		`    ],`,
+		`    "empty": {},`,
+		`    "with \"escaped\" key": 1`,
+		`  }`,
+		"}",
+	}
+	expected := map[int][]string{
+		3:  {},
+		4:  {"id"},
+		5:  {"properties"},
+		6:  {"properties", "ftpsState"},
+		7:  {"properties", "ipSecurityRestrictions"},
+		8:  {"properties", "ipSecurityRestrictions", "[]"},
+		9:  {"properties", "ipSecurityRestrictions", "[]", "action"},
+		10: {"properties", "ipSecurityRestrictions", "[]", "name"},
+		11: {"properties", "ipSecurityRestrictions", "[]"},
+		12: {"properties", "ipSecurityRestrictions", "[]"},
+		13: {"properties", "ipSecurityRestrictions"},
+		14: {"properties", "empty"},
+		15: {"properties", "with \"escaped\" key"},
+		16: {"properties"},
+		17: {},
+	}
+	for lineIndex, expectedPath := range expected {
+		path, ok := getJSONPathAtLine(lines, lineIndex)
+		assert.True(t, ok, "line %d", lineIndex)
+		assert.Equal(t, expectedPath, path, "line %d", lineIndex)
+	}
+
+	for _, lineIndex := range []int{-1, 0, 1, 2, len(lines)} {
+		_, ok := getJSONPathAtLine(lines, lineIndex)
+		assert.False(t, ok, "line %d", lineIndex)
+	}
+}
+
+func Test_GetPropertyName(t *testing.T) {
+	assert.Equal(t, "ftpsState", getPropertyName([]string{"properties", "ftpsState"}))
+	assert.Equal(t, "ipSecurityRestrictions[]", getPropertyName([]string{"properties", "ipSecurityRestrictions", "[]"}))
+}
+
+func Test_GetPropertyDescription(t *testing.T) {
+	schema := &swagger.Schema{
+		Type:        "string",
+		Description: "State of FTP / FTPS service",
+		Enum:        []interface{}{"AllAllowed", "FtpsOnly", "Disabled"},
+		ReadOnly:    true,
+	}
+	description := getPropertyDescription("ftpsState", schema) // colors are disabled when not writing to a terminal
+	assert.Equal(t, "ftpsState (string, read-only)\nState of FTP / FTPS service\nValues: AllAllowed | FtpsOnly | Disabled\n", description)
+}
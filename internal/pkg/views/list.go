@@ -43,6 +43,15 @@ type ListWidget struct {
 	isNavigating bool
 	refreshLock  sync.Mutex
 	isRefreshing bool
+	// Watch mode re-expands the node shown in the item view on an interval, see watch.go
+	watchLock   sync.Mutex
+	watchNode   *expanders.TreeNode
+	watchCancel context.CancelFunc
+	watchPaused bool
+	// Tabs hold independent navigation sessions, see list_tabs.go. The current tab's state is held in the fields above
+	tabs       []*listTab
+	currentTab int
+	nodeID     string // the ID of the current node (as per ListNavigatedEventState.NodeID), used to resume tabs
 }
 
 // ListNavigatedEventState captures the state when raising a `list.navigated` event
@@ -215,12 +224,13 @@ func (w *ListWidget) Layout(g *gocui.Gui) error {
 		if w.currentPage.FilterString != "" {
 			title += "[filter=" + w.currentPage.FilterString + "]"
 		}
-		if len(title) > width {
-			trimLength := len(title) - width + 5 // Add five for spacing and elipsis
+		tabStrip := w.getTabStrip()
+		if titleWidth := width - len(tabStrip); len(title) > titleWidth && titleWidth > 5 {
+			trimLength := len(title) - titleWidth + 5 // Add five for spacing and elipsis
 			title = ".." + title[trimLength:]
 		}
 
-		w.view.Title = title
+		w.view.Title = tabStrip + title
 	}
 
 	return nil
@@ -312,6 +322,7 @@ func (w *ListWidget) Refresh() {
 
 // GoBack takes the user back to preview view
 func (w *ListWidget) GoBack() {
+	w.StopWatch()
 	eventing.Publish("list.prenavigate", "GOBACK")
 
 	if w.currentPage == nil {
@@ -333,6 +344,7 @@ func (w *ListWidget) GoBack() {
 	if w.currentPage.ExpandedNodeItem == nil {
 		w.currentPage.ExpandedNodeItem = &expanders.TreeNode{}
 	}
+	w.nodeID = w.currentPage.ExpandedNodeItem.ID
 
 	eventing.Publish("list.navigated", ListNavigatedEventState{
 		Success:      true,
@@ -340,6 +352,31 @@ func (w *ListWidget) GoBack() {
 		ParentNodeID: w.currentPage.ExpandedNodeItem.Parentid,
 		IsBack:       true,
 	})
+	w.publishTabsChanged()
+}
+
+// ResetToRoot returns the list to the first page, waiting for any navigation in progress to complete.
+// Unlike GoBack this doesn't raise a `list.navigated` event for each page
+func (w *ListWidget) ResetToRoot() {
+	w.navLock.Lock()
+	defer w.navLock.Unlock()
+
+	w.StopWatch()
+	eventing.Publish("list.prenavigate", "GOBACK")
+
+	for {
+		previousPage := w.navStack.Pop()
+		if previousPage == nil {
+			break
+		}
+		w.currentPage = previousPage
+	}
+	if w.currentPage == nil {
+		return
+	}
+	w.ClearFilter()
+	w.currentPage.Selection = 0
+	w.contentView.SetContentWithNode(w.currentPage.ExpandedNodeItem, w.currentPage.Data, w.currentPage.DataType, "Response")
 }
 
 // ExpandCurrentSelection opens the resource Sub->RG for example
@@ -367,6 +404,7 @@ func (w *ListWidget) expandItem(item *expanders.TreeNode) {
 
 	newTitle := item.Name
 
+	w.StopWatch()
 	eventing.Publish("list.prenavigate", item.ID)
 
 	go func() {
@@ -382,6 +420,9 @@ func (w *ListWidget) expandItem(item *expanders.TreeNode) {
 			newTitle = ""
 		}
 		w.Navigate(newItems, newContent, newTitle, suppressPreviousTitle)
+		if err == nil && item.WatchIntervalSeconds > 0 {
+			w.startWatch(item)
+		}
 
 		// Force UI to re-render to pickup
 		w.g.Update(func(g *gocui.Gui) error {
@@ -430,6 +471,7 @@ func (w *ListWidget) Navigate(nodes []*expanders.TreeNode, content *expanders.Ex
 		parentNodeID = w.currentPage.ExpandedNodeItem.ID
 		nodeID = currentItem.ID
 	}
+	w.nodeID = nodeID
 
 	eventing.Publish("list.navigated", ListNavigatedEventState{
 		Success:      true,
@@ -437,6 +479,7 @@ func (w *ListWidget) Navigate(nodes []*expanders.TreeNode, content *expanders.Ex
 		ParentNodeID: parentNodeID,
 		NodeID:       nodeID,
 	})
+	w.publishTabsChanged()
 }
 
 // GetNodes returns the currently listed nodes
@@ -460,6 +503,7 @@ func (w *ListWidget) replaceNode(nodeToReplace *expanders.TreeNode, nodes []*exp
 
 // SetNewNodes allows others to set the list nodes
 func (w *ListWidget) SetNewNodes(nodes []*expanders.TreeNode) {
+	w.StopWatch()
 
 	// Capture current view to navstack
 	if w.HasCurrentItem() {
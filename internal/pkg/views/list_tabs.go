@@ -0,0 +1,258 @@
+package views
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/awesome-gocui/gocui"
+	"github.com/lawrencegripper/azbrowse/internal/pkg/eventing"
+	"github.com/lawrencegripper/azbrowse/internal/pkg/expanders"
+	"github.com/lawrencegripper/azbrowse/internal/pkg/interfaces"
+)
+
+// maxTabLabelLength limits the length of the labels in the tab strip
+const maxTabLabelLength = 20
+
+// listTab holds the navigation state for a tab in the ListWidget.
+// The state for the current tab is held in the ListWidget (navStack, currentPage, ...) and saved when switching tabs
+type listTab struct {
+	navStack    Stack
+	currentPage *Page
+	nodeID      string
+	content     listTabContent
+}
+
+// listTabContent holds the item view content for a tab
+type listTabContent struct {
+	node        *expanders.TreeNode
+	content     string
+	contentType interfaces.ExpanderResponseType
+	title       string
+}
+
+// ListTabsState captures the state when raising a `list.tabschanged` event and is used to restore tabs with `--resume`
+type ListTabsState struct {
+	NodeIDs    []string `json:"nodeIds"`    // NodeIDs are the IDs of the current node for each tab
+	CurrentTab int      `json:"currentTab"` // CurrentTab is the index of the selected tab
+}
+
+func (w *ListWidget) saveTab() *listTab {
+	return &listTab{
+		navStack:    w.navStack,
+		currentPage: w.currentPage,
+		nodeID:      w.nodeID,
+		content: listTabContent{
+			node:        w.contentView.node,
+			content:     w.contentView.originalContent,
+			contentType: w.contentView.contentType,
+			title:       w.contentView.title,
+		},
+	}
+}
+
+func (w *ListWidget) loadTab(tab *listTab) {
+	w.navStack = tab.navStack
+	w.currentPage = tab.currentPage
+	w.nodeID = tab.nodeID
+	w.contentView.SetContentWithNode(tab.content.node, tab.content.content, tab.content.contentType, tab.content.title)
+}
+
+// TabCount returns the number of tabs
+func (w *ListWidget) TabCount() int {
+	if len(w.tabs) == 0 {
+		return 1
+	}
+	return len(w.tabs)
+}
+
+// AddTab opens a new tab with a copy of the navigation history of the current tab, waiting for any navigation
+// in progress to complete
+func (w *ListWidget) AddTab() {
+	w.navLock.Lock()
+	defer w.navLock.Unlock()
+
+	w.addTab()
+}
+
+// addTab opens a new tab. The caller must hold navLock
+func (w *ListWidget) addTab() {
+	w.StopWatch()
+	if len(w.tabs) == 0 {
+		w.tabs = []*listTab{nil}
+		w.currentTab = 0
+	}
+	w.tabs[w.currentTab] = w.saveTab()
+
+	// the new tab starts as a copy of the current tab (including the item content)
+	w.navStack = w.navStack.clone()
+	w.currentPage = w.currentPage.clone()
+	w.tabs = append(w.tabs, w.saveTab())
+	w.currentTab = len(w.tabs) - 1
+
+	w.publishTabsChanged()
+}
+
+// lockTabsForKeyPress takes navLock for a tab key press. The key handlers run on the UI loop so rather than waiting
+// for a navigation in progress (which may be a long-running action) this shows a status message and returns false
+func (w *ListWidget) lockTabsForKeyPress() bool {
+	if w.isNavigating {
+		eventing.SendStatusEvent(&eventing.StatusEvent{
+			InProgress: false,
+			Message:    "Navigation in progress - try switching tabs once it completes",
+			Timeout:    time.Second * 3,
+		})
+		return false
+	}
+	w.navLock.Lock()
+	return true
+}
+
+// OpenInNewTab opens a new tab and expands the selected item in it (skipped if a navigation is in progress)
+func (w *ListWidget) OpenInNewTab() {
+	if !w.lockTabsForKeyPress() {
+		return
+	}
+	item := w.CurrentItem()
+	if item != nil {
+		w.addTab()
+	}
+	w.navLock.Unlock()
+
+	if item != nil {
+		w.expandItem(item)
+	}
+}
+
+// SelectTab switches to the tab with the specified index, waiting for any navigation in progress to complete
+func (w *ListWidget) SelectTab(index int) {
+	w.navLock.Lock()
+	defer w.navLock.Unlock()
+
+	w.selectTab(index)
+}
+
+// selectTab switches to the tab with the specified index. The caller must hold navLock
+func (w *ListWidget) selectTab(index int) {
+	if index < 0 || index >= len(w.tabs) || index == w.currentTab {
+		return
+	}
+	w.StopWatch()
+	w.tabs[w.currentTab] = w.saveTab()
+	w.currentTab = index
+	w.loadTab(w.tabs[index])
+
+	w.publishTabsChanged()
+	w.g.Update(func(g *gocui.Gui) error { return nil })
+}
+
+// NextTab switches to the next tab (wrapping around to the first tab). Skipped if a navigation is in progress
+func (w *ListWidget) NextTab() {
+	if !w.lockTabsForKeyPress() {
+		return
+	}
+	defer w.navLock.Unlock()
+
+	if len(w.tabs) < 2 {
+		return
+	}
+	w.selectTab((w.currentTab + 1) % len(w.tabs))
+}
+
+// PreviousTab switches to the previous tab (wrapping around to the last tab). Skipped if a navigation is in progress
+func (w *ListWidget) PreviousTab() {
+	if !w.lockTabsForKeyPress() {
+		return
+	}
+	defer w.navLock.Unlock()
+
+	if len(w.tabs) < 2 {
+		return
+	}
+	w.selectTab((w.currentTab + len(w.tabs) - 1) % len(w.tabs))
+}
+
+// CloseTab closes the current tab and switches to the next one. The last tab can't be closed and closing is skipped
+// if a navigation is in progress
+func (w *ListWidget) CloseTab() {
+	if !w.lockTabsForKeyPress() {
+		return
+	}
+	defer w.navLock.Unlock()
+
+	if len(w.tabs) < 2 {
+		return
+	}
+	w.StopWatch()
+	w.tabs = append(w.tabs[:w.currentTab], w.tabs[w.currentTab+1:]...)
+	if w.currentTab >= len(w.tabs) {
+		w.currentTab = len(w.tabs) - 1
+	}
+	w.loadTab(w.tabs[w.currentTab])
+	if len(w.tabs) == 1 {
+		w.tabs = nil
+		w.currentTab = 0
+	}
+
+	w.publishTabsChanged()
+	w.g.Update(func(g *gocui.Gui) error { return nil })
+}
+
+// GetTabsState returns the current node ID for each tab along with the index of the current tab
+func (w *ListWidget) GetTabsState() ListTabsState {
+	if len(w.tabs) == 0 {
+		return ListTabsState{NodeIDs: []string{w.nodeID}}
+	}
+	nodeIDs := []string{}
+	for i, tab := range w.tabs {
+		if i == w.currentTab {
+			nodeIDs = append(nodeIDs, w.nodeID)
+		} else {
+			nodeIDs = append(nodeIDs, tab.nodeID)
+		}
+	}
+	return ListTabsState{
+		NodeIDs:    nodeIDs,
+		CurrentTab: w.currentTab,
+	}
+}
+
+func (w *ListWidget) publishTabsChanged() {
+	eventing.Publish("list.tabschanged", w.GetTabsState())
+}
+
+// getTabStrip returns the tab strip to show in the list title, e.g. `1:mysite [2:mysite-staging] `
+// or "" if there is only a single tab
+func (w *ListWidget) getTabStrip() string {
+	if len(w.tabs) < 2 {
+		return ""
+	}
+	var tabStrip strings.Builder
+	for i, tab := range w.tabs {
+		page := w.currentPage
+		if i != w.currentTab {
+			page = tab.currentPage
+		}
+		label := fmt.Sprintf("%d:%s", i+1, getTabLabel(page))
+		if i == w.currentTab {
+			label = "[" + label + "]"
+		}
+		tabStrip.WriteString(label + " ")
+	}
+	return tabStrip.String()
+}
+
+// getTabLabel returns the last part of the page title, e.g. `mysite` for `Subscriptions>mysub>myrg>mysite`
+func getTabLabel(page *Page) string {
+	if page == nil {
+		return ""
+	}
+	label := page.Title
+	if i := strings.LastIndex(label, ">"); i >= 0 {
+		label = label[i+1:]
+	}
+	if runes := []rune(label); len(runes) > maxTabLabelLength {
+		label = string(runes[:maxTabLabelLength-2]) + ".."
+	}
+	return label
+}
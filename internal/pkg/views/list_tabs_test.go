@@ -0,0 +1,83 @@
+package views
+
+import (
+	"testing"
+
+	"github.com/lawrencegripper/azbrowse/internal/pkg/expanders"
+	"github.com/stretchr/testify/assert"
+)
+
+func getTabsTestListWidget() *ListWidget {
+	w := &ListWidget{contentView: &ItemWidget{}}
+	w.navStack.Push(&Page{
+		Title: "Subscriptions",
+		Items: []*expanders.TreeNode{{ID: "/subscriptions/1", Name: "sub1"}},
+	})
+	w.currentPage = &Page{
+		Title: "Subscriptions>sub1>rg1",
+		Items: []*expanders.TreeNode{
+			{ID: "/subscriptions/1/resourceGroups/rg1/providers/Microsoft.Web/sites/mysite", Name: "mysite"},
+			{ID: "/subscriptions/1/resourceGroups/rg1/providers/Microsoft.Web/sites/mysite-staging", Name: "mysite-staging"},
+		},
+	}
+	w.nodeID = "/subscriptions/1/resourceGroups/rg1"
+	return w
+}
+
+func Test_AddTab(t *testing.T) {
+	w := getTabsTestListWidget()
+	assert.Equal(t, 1, w.TabCount())
+	assert.Equal(t, "", w.getTabStrip())
+	assert.Equal(t, ListTabsState{NodeIDs: []string{"/subscriptions/1/resourceGroups/rg1"}}, w.GetTabsState())
+
+	w.AddTab()
+	assert.Equal(t, 2, w.TabCount())
+	assert.Equal(t, "1:rg1 [2:rg1] ", w.getTabStrip())
+
+	// changes to the new tab don't affect the original tab
+	w.ChangeSelection(1)
+	w.SortItems()
+	w.currentPage.Title = "Subscriptions>sub1>rg1>mysite-staging"
+	w.nodeID = "/subscriptions/1/resourceGroups/rg1/providers/Microsoft.Web/sites/mysite-staging"
+	w.navStack.Pop()
+
+	original := w.tabs[0]
+	assert.Equal(t, 0, original.currentPage.Selection)
+	assert.Equal(t, "Subscriptions>sub1>rg1", original.currentPage.Title)
+	assert.Equal(t, 1, original.navStack.count)
+	assert.Equal(t, "1:rg1 [2:mysite-staging] ", w.getTabStrip())
+	assert.Equal(t, ListTabsState{
+		NodeIDs: []string{
+			"/subscriptions/1/resourceGroups/rg1",
+			"/subscriptions/1/resourceGroups/rg1/providers/Microsoft.Web/sites/mysite-staging",
+		},
+		CurrentTab: 1,
+	}, w.GetTabsState())
+}
+
+func Test_TabKeysSkippedWhileNavigating(t *testing.T) {
+	w := getTabsTestListWidget()
+	w.AddTab()
+
+	// simulate an expansion in progress (expandItem holds navLock until the expansion completes)
+	w.navLock.Lock()
+	w.isNavigating = true
+
+	w.PreviousTab()
+	w.CloseTab()
+	w.OpenInNewTab()
+	assert.Equal(t, 2, w.TabCount())
+	assert.Equal(t, 1, w.currentTab)
+
+	w.isNavigating = false
+	w.navLock.Unlock()
+}
+
+func Test_GetTabLabel(t *testing.T) {
+	assert.Equal(t, "Subscriptions", getTabLabel(&Page{Title: "Subscriptions"}))
+	assert.Equal(t, "mysite", getTabLabel(&Page{Title: "Subscriptions>sub1>rg1>mysite"}))
+	assert.Equal(t, "a-very-long-resour..", getTabLabel(&Page{Title: "rg1>a-very-long-resource-name"}))
+	// labels are truncated by runes so multi-byte characters aren't split
+	assert.Equal(t, "ресурс-с-очень-дли..", getTabLabel(&Page{Title: "rg1>ресурс-с-очень-длинным-именем"}))
+	assert.Equal(t, "", getTabLabel(nil))
+}
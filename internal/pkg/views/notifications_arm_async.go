@@ -47,6 +47,10 @@ func StartWatchingAsyncARMRequests(ctx context.Context) (armclient.ResponseProce
 					// Not an async action, move on.
 					continue
 				}
+				if armclient.IsLongRunningRequest(request.httpResponse) {
+					// The operation is already being polled (and its status reported) by the caller
+					continue
+				}
 
 				var pollLocation []string
 				var exists bool
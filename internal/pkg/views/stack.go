@@ -38,3 +38,23 @@ func (s *Stack) Pop() *Page {
 	s.count--
 	return s.nodes[s.count]
 }
+
+// clone returns a copy of the page so that changes to the selection, filter and sort order don't affect the original
+func (p *Page) clone() *Page {
+	if p == nil {
+		return nil
+	}
+	page := *p
+	page.Items = append([]*expanders.TreeNode{}, p.Items...)
+	page.FilteredItems = append([]*expanders.TreeNode{}, p.FilteredItems...)
+	return &page
+}
+
+// clone returns a copy of the stack (and the pages in it)
+func (s *Stack) clone() Stack {
+	stack := Stack{}
+	for _, page := range s.nodes[:s.count] {
+		stack.Push(page.clone())
+	}
+	return stack
+}
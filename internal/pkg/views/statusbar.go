@@ -21,6 +21,7 @@ type StatusbarWidget struct {
 	messages        map[string]*eventing.StatusEvent
 	currentMessage  *eventing.StatusEvent
 	messageAddition string
+	watchStatus     string
 	HelpKeyBinding  string
 }
 
@@ -146,6 +147,9 @@ func (w *StatusbarWidget) Layout(g *gocui.Gui) error {
 	}
 	v.Clear()
 	v.Title = "Status"
+	if w.watchStatus != "" {
+		v.Title += " [" + w.watchStatus + "]"
+	}
 	v.Subtitle = fmt.Sprintf(`[%s -> Help]`, strings.ToUpper(w.HelpKeyBinding))
 	v.Wrap = true
 
@@ -174,6 +178,11 @@ func (w *StatusbarWidget) Status(message string, loading bool) func() {
 	return done
 }
 
+// SetWatchStatus sets the watch mode status shown in the title, an empty string clears it
+func (w *StatusbarWidget) SetWatchStatus(status string) {
+	w.watchStatus = status
+}
+
 // SetHideGuids sets the HideGuids option
 func (w *StatusbarWidget) SetHideGuids(value bool) {
 	w.hideGuids = value
@@ -0,0 +1,142 @@
+package views
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/awesome-gocui/gocui"
+	"github.com/lawrencegripper/azbrowse/internal/pkg/errorhandling"
+	"github.com/lawrencegripper/azbrowse/internal/pkg/eventing"
+	"github.com/lawrencegripper/azbrowse/internal/pkg/expanders"
+)
+
+// startWatch re-expands the node on its WatchIntervalSeconds and updates the item view in place
+// until the watch is stopped by navigating away
+func (w *ListWidget) startWatch(node *expanders.TreeNode) {
+	w.StopWatch()
+
+	w.watchLock.Lock()
+	ctx, cancel := context.WithCancel(w.ctx)
+	w.watchNode = node
+	w.watchCancel = cancel
+	w.watchPaused = false
+	w.watchLock.Unlock()
+
+	interval := time.Duration(node.WatchIntervalSeconds) * time.Second
+	w.updateWatchStatus()
+
+	go func() {
+		defer errorhandling.RecoveryWithCleanup()
+
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+
+			if w.IsWatchPaused() || w.isNavigating {
+				continue
+			}
+			if !w.isShowingNode(node) {
+				// Something else is being shown in the item view
+				w.StopWatch()
+				return
+			}
+
+			newContent, _, err := expanders.ExpandItem(ctx, node)
+			if ctx.Err() != nil {
+				return
+			}
+			if err != nil || newContent == nil {
+				// Expanders emit a status event on failure, try again on the next tick
+				continue
+			}
+
+			w.g.Update(func(g *gocui.Gui) error {
+				// Check the watch wasn't cancelled while expanding
+				if ctx.Err() == nil && w.isShowingNode(node) {
+					w.contentView.RefreshContent(newContent.Response, newContent.ResponseType)
+				}
+				return nil
+			})
+		}
+	}()
+}
+
+func (w *ListWidget) isShowingNode(node *expanders.TreeNode) bool {
+	current := w.contentView.GetNode()
+	return current != nil && current.ID == node.ID
+}
+
+// StopWatch cancels watching the current node (if any)
+func (w *ListWidget) StopWatch() {
+	w.watchLock.Lock()
+	if w.watchCancel == nil {
+		w.watchLock.Unlock()
+		return
+	}
+	w.watchCancel()
+	w.watchCancel = nil
+	w.watchNode = nil
+	w.watchPaused = false
+	w.watchLock.Unlock()
+
+	w.updateWatchStatus()
+}
+
+// IsWatching returns true if the item view content is being watched
+func (w *ListWidget) IsWatching() bool {
+	w.watchLock.Lock()
+	defer w.watchLock.Unlock()
+	return w.watchNode != nil
+}
+
+// IsWatchPaused returns true if watching is paused
+func (w *ListWidget) IsWatchPaused() bool {
+	w.watchLock.Lock()
+	defer w.watchLock.Unlock()
+	return w.watchPaused
+}
+
+// ToggleWatchPaused pauses or resumes watching the item view content
+func (w *ListWidget) ToggleWatchPaused() {
+	w.watchLock.Lock()
+	if w.watchNode == nil {
+		w.watchLock.Unlock()
+		eventing.SendStatusEvent(&eventing.StatusEvent{
+			Message: "Nothing to watch, open a metric graph or log to watch it",
+			Timeout: time.Second * 3,
+		})
+		return
+	}
+	w.watchPaused = !w.watchPaused
+	w.watchLock.Unlock()
+
+	w.updateWatchStatus()
+}
+
+func (w *ListWidget) updateWatchStatus() {
+	w.watchLock.Lock()
+	status := ""
+	if w.watchNode != nil {
+		if w.watchPaused {
+			status = "watch paused"
+		} else {
+			status = fmt.Sprintf("watching: refresh every %ds", w.watchNode.WatchIntervalSeconds)
+		}
+	}
+	w.watchLock.Unlock()
+
+	if w.statusView != nil {
+		w.statusView.SetWatchStatus(status)
+	}
+	if w.g != nil {
+		w.g.Update(func(g *gocui.Gui) error {
+			return nil
+		})
+	}
+}
@@ -8,7 +8,9 @@ import (
 	"fmt"
 	"io/ioutil"
 	"net/http"
+	"sort"
 	"strings"
+	"sync"
 	"time"
 
 	"golang.org/x/time/rate"
@@ -172,17 +174,24 @@ func (c *Client) DoRawRequest(ctx context.Context, req *http.Request) (*http.Res
 
 // DoRequestWithBody makes an ARM rest request
 func (c *Client) DoRequestWithBody(ctx context.Context, method, path, body string) (string, error) {
+	_, responseBody, err := c.doRequestWithBody(ctx, method, path, body)
+	return responseBody, err
+}
+
+// doRequestWithBody makes an ARM rest request, retrying once with a fresh token on a 401,
+// and returns the response alongside the body so callers can inspect status codes and headers
+func (c *Client) doRequestWithBody(ctx context.Context, method, path, body string) (*http.Response, string, error) {
 	span, _ := tracing.StartSpanFromContext(ctx, "request:"+method, tracing.SetTag("path", path))
 	defer span.Finish()
 
 	url, err := getRequestURL(path, c.clientType)
 	if err != nil {
-		return "", err
+		return nil, "", err
 	}
 
 	req, err := http.NewRequest(method, url, bytes.NewReader([]byte(body)))
 	if err != nil {
-		return "", errors.New("Failed to create request for body: " + err.Error())
+		return nil, "", errors.New("Failed to create request for body: " + err.Error())
 	}
 
 	response, err := c.DoRawRequest(ctx, req)
@@ -190,17 +199,23 @@ func (c *Client) DoRequestWithBody(ctx context.Context, method, path, body strin
 	if response != nil && response.StatusCode == 401 {
 		// This might be because the token we've cached has expired.
 		// Get a new token forcing it to clear cache
-		cliToken, err := c.acquireToken(true)
-		if err != nil {
-			return "", errors.New("Failed to acquire auth token: " + err.Error())
+		cliToken, tokenErr := c.acquireToken(true)
+		if tokenErr != nil {
+			return nil, "", errors.New("Failed to acquire auth token: " + tokenErr.Error())
 		}
 		c.tenantID = cliToken.Tenant
+		response.Body.Close() //nolint: errcheck
 
-		// Retry the request now we have a valid token
-		response, err = c.client.Do(req.WithContext(ctx)) //nolint:staticcheck
+		// Retry the request now we have a valid token. DoRawRequest sets the
+		// Authorization header from the refreshed token
+		req, err = http.NewRequest(method, url, bytes.NewReader([]byte(body)))
+		if err != nil {
+			return nil, "", errors.New("Failed to create request for body: " + err.Error())
+		}
+		response, err = c.DoRawRequest(ctx, req)
 	}
 	if err != nil {
-		return "", errors.New("Request failed: " + err.Error())
+		return nil, "", errors.New("Request failed: " + err.Error())
 	}
 
 	// Check response error but also return body as it may contain useful information
@@ -225,7 +240,7 @@ func (c *Client) DoRequestWithBody(ctx context.Context, method, path, body strin
 	if err != nil {
 		wrappedError := errors.New("Request failed: " + err.Error() + " ResponseErr:" + responseErr.Error())
 		span.SetTag("err", wrappedError)
-		return "", wrappedError
+		return response, "", wrappedError
 	}
 
 	if tracing.IsDebug() {
@@ -234,7 +249,7 @@ func (c *Client) DoRequestWithBody(ctx context.Context, method, path, body strin
 		span.SetTag("url", url)
 	}
 
-	return string(buf), responseErr
+	return response, string(buf), responseErr
 }
 
 // DoResourceGraphQuery performs an azure graph query
@@ -257,6 +272,16 @@ func (c *Client) DoResourceGraphQuery(ctx context.Context, subscription, query s
 
 // DoResourceGraphQueryReturningObjectArray performs an azure graph query on all subs you have access too
 func (c *Client) DoResourceGraphQueryReturningObjectArray(ctx context.Context, subscriptionGUIDs []string, query string) (string, error) {
+	return c.doResourceGraphQueryReturningObjectArray(ctx, subscriptionGUIDs, query, "2018-09-01-preview")
+}
+
+// DoResourceGraphChangesQueryReturningObjectArray performs an azure graph query against the resourcechanges table,
+// which requires a newer api-version than the general resources queries
+func (c *Client) DoResourceGraphChangesQueryReturningObjectArray(ctx context.Context, subscriptionGUIDs []string, query string) (string, error) {
+	return c.doResourceGraphQueryReturningObjectArray(ctx, subscriptionGUIDs, query, "2021-03-01")
+}
+
+func (c *Client) doResourceGraphQueryReturningObjectArray(ctx context.Context, subscriptionGUIDs []string, query string, apiVersion string) (string, error) {
 	resultFmt := "objectArray"
 	queryBody := QueryBody{
 		Subscriptions: subscriptionGUIDs,
@@ -273,16 +298,56 @@ func (c *Client) DoResourceGraphQueryReturningObjectArray(ctx context.Context, s
 	}
 
 	tracing.SetTagOnCtx(ctx, "query", messageBody)
-	return c.DoRequestWithBody(ctx, "POST", "/providers/Microsoft.ResourceGraph/resources?api-version=2018-09-01-preview", string(messageBody))
+	return c.DoRequestWithBody(ctx, "POST", "/providers/Microsoft.ResourceGraph/resources?api-version="+apiVersion, string(messageBody))
 }
 
 var resourceAPIVersionLookup map[string]string
 var resourceAPIVersionPreviewLookup map[string]string
+var resourceAPIVersionOverrides = map[string]string{}
+var resourceAPIVersionOverridesLock sync.RWMutex
+
+// SetAPIVersionOverrides sets the API versions to use in place of the most recent API versions, keyed on resource type
+func SetAPIVersionOverrides(overrides map[string]string) {
+	resourceAPIVersionOverridesLock.Lock()
+	defer resourceAPIVersionOverridesLock.Unlock()
+	resourceAPIVersionOverrides = map[string]string{}
+	for armType, apiVersion := range overrides {
+		setAPIVersionOverride(armType, apiVersion)
+	}
+}
 
-// GetAPIVersion returns the most recent API version for a resource
+// SetAPIVersionOverride sets the API version to use for a resource type. An empty apiVersion removes the override
+func SetAPIVersionOverride(armType string, apiVersion string) {
+	resourceAPIVersionOverridesLock.Lock()
+	defer resourceAPIVersionOverridesLock.Unlock()
+	setAPIVersionOverride(armType, apiVersion)
+}
+
+func setAPIVersionOverride(armType string, apiVersion string) {
+	armTypeKey := strings.ToLower(armType)
+	if apiVersion == "" {
+		delete(resourceAPIVersionOverrides, armTypeKey)
+		return
+	}
+	resourceAPIVersionOverrides[armTypeKey] = apiVersion
+}
+
+// GetAPIVersionOverride returns the API version override for a resource type if one is set
+func GetAPIVersionOverride(armType string) (string, bool) {
+	resourceAPIVersionOverridesLock.RLock()
+	defer resourceAPIVersionOverridesLock.RUnlock()
+	value, exists := resourceAPIVersionOverrides[strings.ToLower(armType)]
+	return value, exists
+}
+
+// GetAPIVersion returns the API version override for a resource if set, otherwise the most recent API version
 func GetAPIVersion(armType string) (string, error) {
+	value, exists := GetAPIVersionOverride(armType)
+	if exists {
+		return value, nil
+	}
 	armTypeKey := strings.ToLower(armType)
-	value, exists := resourceAPIVersionLookup[armTypeKey]
+	value, exists = resourceAPIVersionLookup[armTypeKey]
 	if exists {
 		return value, nil
 	}
@@ -293,6 +358,35 @@ func GetAPIVersion(armType string) (string, error) {
 	return "MISSING", fmt.Errorf("API not found for the resource: %s", armType)
 }
 
+// GetAPIVersions returns all of the API versions available for a resource type (newest first)
+func (c *Client) GetAPIVersions(ctx context.Context, armType string) ([]string, error) {
+	separatorIndex := strings.Index(armType, "/")
+	if separatorIndex < 0 {
+		return nil, fmt.Errorf("Invalid resource type: %q", armType)
+	}
+	data, err := c.DoRequest(ctx, "GET", "/providers/"+armType[:separatorIndex]+"?api-version=2017-05-10")
+	if err != nil {
+		return nil, fmt.Errorf("Failed to get provider for %q: %s", armType, err)
+	}
+	return getAPIVersionsFromProvider(data, armType[separatorIndex+1:])
+}
+
+func getAPIVersionsFromProvider(data string, resourceType string) ([]string, error) {
+	var providerResponse ProviderResponse
+	err := json.Unmarshal([]byte(data), &providerResponse)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to parse provider response: %s", err)
+	}
+	for _, providerResourceType := range providerResponse.ResourceTypes {
+		if strings.EqualFold(providerResourceType.ResourceType, resourceType) {
+			apiVersions := append([]string{}, providerResourceType.APIVersions...)
+			sort.Sort(sort.Reverse(sort.StringSlice(apiVersions)))
+			return apiVersions, nil
+		}
+	}
+	return nil, fmt.Errorf("Resource type %q not found in provider %q", resourceType, providerResponse.Namespace)
+}
+
 // PopulateResourceAPILookup is used to build a cache of resourcetypes -> api versions
 // this is needed when requesting details from a resource as APIVersion isn't known and is required
 func (c *Client) PopulateResourceAPILookup(ctx context.Context, msg *eventing.StatusEvent) {
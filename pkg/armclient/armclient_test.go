@@ -4,6 +4,8 @@ import (
 	"context"
 	"net/http"
 	"net/http/httptest"
+	"strings"
+	"sync"
 	"testing"
 	"time"
 )
@@ -59,3 +61,69 @@ func Test_ArmClient_AzCliToken_DontRefresh(t *testing.T) {
 		t.Error("Expected cache not to be cleared for azcli token")
 	}
 }
+
+func Test_GetAPIVersion_Overrides(t *testing.T) {
+	resourceAPIVersionLookup = map[string]string{"microsoft.web/sites": "2020-12-01"}
+	defer func() {
+		resourceAPIVersionLookup = nil
+		SetAPIVersionOverrides(nil)
+	}()
+
+	SetAPIVersionOverrides(map[string]string{"Microsoft.Web/sites": "2021-01-01-preview"})
+	apiVersion, err := GetAPIVersion("microsoft.web/Sites")
+	if err != nil || apiVersion != "2021-01-01-preview" {
+		t.Errorf("Expected override version, got %q (%v)", apiVersion, err)
+	}
+
+	SetAPIVersionOverride("Microsoft.Web/sites", "")
+	apiVersion, err = GetAPIVersion("Microsoft.Web/sites")
+	if err != nil || apiVersion != "2020-12-01" {
+		t.Errorf("Expected latest version after clearing override, got %q (%v)", apiVersion, err)
+	}
+}
+
+func Test_GetAPIVersion_OverridesConcurrentAccess(t *testing.T) {
+	defer SetAPIVersionOverrides(nil)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			SetAPIVersionOverride("Microsoft.Web/sites", "2021-01-01-preview")
+		}()
+		go func() {
+			defer wg.Done()
+			_, _ = GetAPIVersionOverride("Microsoft.Web/sites")
+		}()
+	}
+	wg.Wait()
+
+	apiVersion, exists := GetAPIVersionOverride("microsoft.web/sites")
+	if !exists || apiVersion != "2021-01-01-preview" {
+		t.Errorf("Expected override version, got %q (%v)", apiVersion, exists)
+	}
+}
+
+func Test_GetAPIVersionsFromProvider(t *testing.T) {
+	data := `{
+		"namespace": "Microsoft.Web",
+		"resourceTypes": [
+			{ "resourceType": "sites", "apiVersions": [ "2019-08-01", "2021-01-01-preview", "2020-12-01" ] },
+			{ "resourceType": "sites/slots", "apiVersions": [ "2020-12-01" ] }
+		]
+	}`
+	apiVersions, err := getAPIVersionsFromProvider(data, "Sites")
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	expected := []string{"2021-01-01-preview", "2020-12-01", "2019-08-01"}
+	if strings.Join(apiVersions, ",") != strings.Join(expected, ",") {
+		t.Errorf("Expected %v, got %v", expected, apiVersions)
+	}
+
+	_, err = getAPIVersionsFromProvider(data, "serverFarms")
+	if err == nil {
+		t.Error("Expected error for unknown resource type")
+	}
+}
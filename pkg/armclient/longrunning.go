@@ -0,0 +1,116 @@
+package armclient
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// LongRunningPollInterval is the default delay between polls of a long-running operation
+// when the service doesn't specify one with a Retry-After header
+var LongRunningPollInterval = time.Second * 5
+
+// LongRunningStatusFunc is called with the status of a long-running operation each time it is polled
+type LongRunningStatusFunc func(status string)
+
+// longRunningRequestKey marks the context of requests made by DoLongRunningRequestWithBody (see IsLongRunningRequest)
+type longRunningRequestKey struct{}
+
+// IsLongRunningRequest returns true if the response is for a request made by DoLongRunningRequestWithBody (including
+// the polls). The operation is already being polled so ResponseProcessors shouldn't start tracking it as well
+func IsLongRunningRequest(response *http.Response) bool {
+	return response != nil && response.Request != nil && response.Request.Context().Value(longRunningRequestKey{}) != nil
+}
+
+type asyncOperationStatus struct {
+	Status string          `json:"status"`
+	Error  json.RawMessage `json:"error"`
+}
+
+// DoLongRunningRequestWithBody makes an ARM rest request and, if the service responds with
+// 201 or 202, polls the Azure-AsyncOperation (or Location) header until the operation
+// reaches a terminal state. The body of the final response is returned.
+func (c *Client) DoLongRunningRequestWithBody(ctx context.Context, method, path, body string, statusFunc LongRunningStatusFunc) (string, error) {
+	ctx = context.WithValue(ctx, longRunningRequestKey{}, true)
+	response, responseBody, err := c.doRequestWithBody(ctx, method, path, body)
+	if err != nil {
+		return responseBody, err
+	}
+	if response.StatusCode != http.StatusCreated && response.StatusCode != http.StatusAccepted {
+		return responseBody, nil
+	}
+
+	asyncOperationURL := response.Header.Get("Azure-AsyncOperation")
+	locationURL := response.Header.Get("Location")
+	if asyncOperationURL == "" && locationURL == "" {
+		// Nothing to poll, e.g. a PUT returning 201 with the created resource
+		return responseBody, nil
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return "", ctx.Err()
+		case <-time.After(getRetryAfter(response)):
+		}
+
+		pollURL := asyncOperationURL
+		if pollURL == "" {
+			pollURL = locationURL
+		}
+		// Polls go through the standard request path so they pick up tracing and
+		// the token refresh on a 401 for operations that outlive the cached token.
+		// The context marks them so that ResponseProcessors can ignore them (see IsLongRunningRequest)
+		response, responseBody, err = c.doRequestWithBody(ctx, "GET", pollURL, "")
+		if err != nil {
+			return responseBody, err
+		}
+
+		if asyncOperationURL != "" {
+			var status asyncOperationStatus
+			if err := json.Unmarshal([]byte(responseBody), &status); err != nil {
+				return responseBody, fmt.Errorf("Error parsing async operation status: %s", err)
+			}
+			if statusFunc != nil {
+				statusFunc(status.Status)
+			}
+			switch strings.ToLower(status.Status) {
+			case "succeeded":
+				if locationURL == "" {
+					return responseBody, nil
+				}
+				// The result of the operation is available from the Location header
+				asyncOperationURL = ""
+				response.Header.Del("Retry-After")
+				continue
+			case "failed", "canceled", "cancelled":
+				return responseBody, fmt.Errorf("Long-running operation finished with status '%s': %s", status.Status, string(status.Error))
+			}
+			continue
+		}
+
+		if response.StatusCode == http.StatusAccepted {
+			if statusFunc != nil {
+				statusFunc("InProgress")
+			}
+			continue
+		}
+		if statusFunc != nil {
+			statusFunc("Succeeded")
+		}
+		return responseBody, nil
+	}
+}
+
+func getRetryAfter(response *http.Response) time.Duration {
+	if retryAfter := response.Header.Get("Retry-After"); retryAfter != "" {
+		if seconds, err := strconv.Atoi(retryAfter); err == nil && seconds > 0 {
+			return time.Duration(seconds) * time.Second
+		}
+	}
+	return LongRunningPollInterval
+}
@@ -0,0 +1,130 @@
+package armclient
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func Test_ArmClient_LongRunning_PollsAsyncOperation(t *testing.T) {
+	LongRunningPollInterval = time.Millisecond * 10
+
+	pollCount := 0
+	var ts *httptest.Server
+	ts = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/subscriptions/1/resourceGroups/rg1/providers/Microsoft.Resources/deployments/dep1/whatIf":
+			w.Header().Set("Azure-AsyncOperation", ts.URL+"/subscriptions/1/operationStatuses/op1")
+			w.WriteHeader(http.StatusAccepted)
+		case "/subscriptions/1/operationStatuses/op1":
+			pollCount++
+			if pollCount < 3 {
+				_, _ = w.Write([]byte(`{"status": "InProgress"}`))
+				return
+			}
+			_, _ = w.Write([]byte(`{"status": "Succeeded", "properties": {"changes": []}}`))
+		default:
+			t.Errorf("Unexpected request to %s", r.URL.Path)
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer ts.Close()
+
+	processedResponses := 0
+	client := NewClientFromConfig(ts.Client(), func(clearCache bool) (AzCLIToken, error) { return AzCLIToken{}, nil }, 5000,
+		func(requestPath string, response *http.Response, responseBody string) {
+			processedResponses++
+			if !IsLongRunningRequest(response) {
+				t.Errorf("Expected response for %s to be marked as long-running", requestPath)
+			}
+		})
+
+	statuses := []string{}
+	result, err := client.DoLongRunningRequestWithBody(context.Background(), "POST", ts.URL+"/subscriptions/1/resourceGroups/rg1/providers/Microsoft.Resources/deployments/dep1/whatIf", "{}", func(status string) {
+		statuses = append(statuses, status)
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	if pollCount != 3 {
+		t.Errorf("Expected 3 polls, got %d", pollCount)
+	}
+	if len(statuses) != 3 || statuses[2] != "Succeeded" {
+		t.Errorf("Unexpected statuses reported: %v", statuses)
+	}
+	if processedResponses != 4 {
+		t.Errorf("Expected the ResponseProcessor to see 4 responses, got %d", processedResponses)
+	}
+	if result != `{"status": "Succeeded", "properties": {"changes": []}}` {
+		t.Errorf("Unexpected result: %s", result)
+	}
+}
+
+func Test_ArmClient_LongRunning_ReturnsErrorOnFailedOperation(t *testing.T) {
+	LongRunningPollInterval = time.Millisecond * 10
+
+	var ts *httptest.Server
+	ts = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/subscriptions/1/resourceGroups/rg1/providers/Microsoft.Compute/virtualMachines/vm1/runCommand":
+			w.Header().Set("Location", ts.URL+"/subscriptions/1/operationResults/op1")
+			w.Header().Set("Azure-AsyncOperation", ts.URL+"/subscriptions/1/operationStatuses/op1")
+			w.WriteHeader(http.StatusAccepted)
+		case "/subscriptions/1/operationStatuses/op1":
+			_, _ = w.Write([]byte(`{"status": "Failed", "error": {"code": "Conflict"}}`))
+		default:
+			t.Errorf("Unexpected request to %s", r.URL.Path)
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer ts.Close()
+
+	client := NewClientFromConfig(ts.Client(), func(clearCache bool) (AzCLIToken, error) { return AzCLIToken{}, nil }, 5000)
+
+	_, err := client.DoLongRunningRequestWithBody(context.Background(), "POST", ts.URL+"/subscriptions/1/resourceGroups/rg1/providers/Microsoft.Compute/virtualMachines/vm1/runCommand", "{}", nil)
+	if err == nil {
+		t.Error("Expected an error for a failed operation")
+	}
+}
+
+func Test_ArmClient_LongRunning_RefreshesTokenWhilePolling(t *testing.T) {
+	LongRunningPollInterval = time.Millisecond * 10
+
+	var ts *httptest.Server
+	ts = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/subscriptions/1/resourceGroups/rg1/providers/Microsoft.Resources/deployments/dep1":
+			w.Header().Set("Azure-AsyncOperation", ts.URL+"/subscriptions/1/operationStatuses/op1")
+			w.WriteHeader(http.StatusCreated)
+		case "/subscriptions/1/operationStatuses/op1":
+			if r.Header.Get("Authorization") != "Bearer fresh" {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			_, _ = w.Write([]byte(`{"status": "Succeeded"}`))
+		default:
+			t.Errorf("Unexpected request to %s", r.URL.Path)
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer ts.Close()
+
+	// The cached token expires after the initial request has been accepted
+	token := "stale"
+	client := NewClientFromConfig(ts.Client(), func(clearCache bool) (AzCLIToken, error) {
+		if clearCache {
+			token = "fresh"
+		}
+		return AzCLIToken{TokenType: "Bearer", AccessToken: token}, nil
+	}, 5000)
+
+	result, err := client.DoLongRunningRequestWithBody(context.Background(), "PUT", ts.URL+"/subscriptions/1/resourceGroups/rg1/providers/Microsoft.Resources/deployments/dep1", "{}", nil)
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	if result != `{"status": "Succeeded"}` {
+		t.Errorf("Unexpected result: %s", result)
+	}
+}
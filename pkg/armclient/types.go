@@ -21,6 +21,16 @@ type Resource struct {
 	} `json:"properties"`
 }
 
+// ProviderResponse is the response from getting a single provider
+type ProviderResponse struct {
+	ID            string `json:"id"`
+	Namespace     string `json:"namespace"`
+	ResourceTypes []struct {
+		ResourceType string   `json:"resourceType"`
+		APIVersions  []string `json:"apiVersions"`
+	} `json:"resourceTypes"`
+}
+
 // ProvidersResponse providers list rest type
 type ProvidersResponse struct {
 	Providers []struct {
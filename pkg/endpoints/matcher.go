@@ -0,0 +1,114 @@
+package endpoints
+
+import (
+	"sort"
+	"strings"
+)
+
+// Matcher finds the endpoint that matches a URL from a set of endpoints. Endpoints are indexed in a trie of their
+// URL segments (case-insensitive literal segments and named segments) so that lookups only test the endpoints
+// whose segments fit the URL rather than testing every endpoint in turn
+type Matcher struct {
+	root     *matcherNode
+	fallback []matcherEntry // endpoints that can't be indexed by segment, e.g. /datasources('{name}')
+	count    int
+}
+
+type matcherNode struct {
+	literals map[string]*matcherNode // keyed by the lower-cased segment
+	named    *matcherNode
+	entries  []matcherEntry
+}
+
+type matcherEntry struct {
+	order    int
+	endpoint *EndpointInfo
+	value    interface{}
+}
+
+// NewMatcher creates an empty Matcher
+func NewMatcher() *Matcher {
+	return &Matcher{
+		root: &matcherNode{},
+	}
+}
+
+// Add adds an endpoint to the Matcher along with a value to return when the endpoint matches.
+// When multiple endpoints match a URL, the one that was added first wins
+func (m *Matcher) Add(endpoint *EndpointInfo, value interface{}) {
+	entry := matcherEntry{
+		order:    m.count,
+		endpoint: endpoint,
+		value:    value,
+	}
+	m.count++
+
+	for _, segment := range endpoint.URLSegments {
+		if segment.Prefix != "/" || segment.Suffix != "" {
+			m.fallback = append(m.fallback, entry)
+			return
+		}
+	}
+
+	node := m.root
+	for _, segment := range endpoint.URLSegments {
+		if segment.Match == "" {
+			// EndpointInfo.Match treats any segment without a Match value as a named segment
+			if node.named == nil {
+				node.named = &matcherNode{}
+			}
+			node = node.named
+			continue
+		}
+		key := strings.ToLower(segment.Match)
+		if node.literals == nil {
+			node.literals = map[string]*matcherNode{}
+		}
+		child, ok := node.literals[key]
+		if !ok {
+			child = &matcherNode{}
+			node.literals[key] = child
+		}
+		node = child
+	}
+	node.entries = append(node.entries, entry)
+}
+
+// Match returns the value for the endpoint that matches the URL (ignoring query string values) along with the MatchResult.
+// If no endpoint matches then the value is nil and MatchResult.IsMatch is false
+func (m *Matcher) Match(url string) (interface{}, MatchResult) {
+	path := url
+	if i := strings.Index(path, "?"); i >= 0 {
+		path = path[:i]
+	}
+
+	candidates := append([]matcherEntry{}, m.fallback...)
+	if strings.HasPrefix(path, "/") {
+		segments := strings.Split(strings.ToLower(path[1:]), "/")
+		candidates = m.root.appendCandidates(candidates, segments)
+	}
+	sort.Slice(candidates, func(i, j int) bool {
+		return candidates[i].order < candidates[j].order
+	})
+
+	// the trie only narrows down the candidates, the endpoint has the final say
+	for _, candidate := range candidates {
+		if matchResult := candidate.endpoint.Match(url); matchResult.IsMatch {
+			return candidate.value, matchResult
+		}
+	}
+	return nil, MatchResult{IsMatch: false}
+}
+
+func (n *matcherNode) appendCandidates(candidates []matcherEntry, segments []string) []matcherEntry {
+	if len(segments) == 0 {
+		return append(candidates, n.entries...)
+	}
+	if child, ok := n.literals[segments[0]]; ok {
+		candidates = child.appendCandidates(candidates, segments[1:])
+	}
+	if n.named != nil {
+		candidates = n.named.appendCandidates(candidates, segments[1:])
+	}
+	return candidates
+}
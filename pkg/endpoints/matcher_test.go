@@ -0,0 +1,159 @@
+package endpoints
+
+import (
+	"fmt"
+	"testing"
+)
+
+func getTestMatcher(templateURLs ...string) *Matcher {
+	matcher := NewMatcher()
+	for _, templateURL := range templateURLs {
+		matcher.Add(MustGetEndpointInfoFromURL(templateURL, ""), templateURL)
+	}
+	return matcher
+}
+
+func TestMatcherMatch(t *testing.T) {
+	matcher := getTestMatcher(
+		"/subscriptions/{subscriptionId}/resourceGroups/{resourceGroupName}/providers/Microsoft.Web/sites/{name}",
+		"/subscriptions/{subscriptionId}/resourceGroups/{resourceGroupName}/providers/Microsoft.Web/sites/{name}/config",
+		"/subscriptions/{subscriptionId}/resourceGroups/{resourceGroupName}/providers/Microsoft.Web/sites/{name}/{childName}",
+		"/subscriptions/{subscriptionId}/resourceGroups/{resourceGroupName}",
+	)
+
+	tests := map[string]string{
+		"/subscriptions/sub1/resourceGroups/rg1/providers/Microsoft.Web/sites/site1":               "/subscriptions/{subscriptionId}/resourceGroups/{resourceGroupName}/providers/Microsoft.Web/sites/{name}",
+		"/SUBSCRIPTIONS/sub1/resourcegroups/rg1/providers/microsoft.web/sites/site1?api-version=1": "/subscriptions/{subscriptionId}/resourceGroups/{resourceGroupName}/providers/Microsoft.Web/sites/{name}",
+		"/subscriptions/sub1/resourceGroups/rg1/providers/Microsoft.Web/sites/site1/config":        "/subscriptions/{subscriptionId}/resourceGroups/{resourceGroupName}/providers/Microsoft.Web/sites/{name}/config",
+		"/subscriptions/sub1/resourceGroups/rg1/providers/Microsoft.Web/sites/site1/slots":         "/subscriptions/{subscriptionId}/resourceGroups/{resourceGroupName}/providers/Microsoft.Web/sites/{name}/{childName}",
+		"/subscriptions/sub1/resourceGroups/rg1":                                                   "/subscriptions/{subscriptionId}/resourceGroups/{resourceGroupName}",
+	}
+	for url, expected := range tests {
+		value, matchResult := matcher.Match(url)
+		if !matchResult.IsMatch {
+			t.Errorf("Expected a match for '%s'", url)
+			continue
+		}
+		if value != expected {
+			t.Errorf("Expected '%s' to match '%s' but got '%s'", url, expected, value)
+		}
+	}
+
+	_, matchResult := matcher.Match("/subscriptions/sub1/resourceGroups/rg1/providers/Microsoft.Web/sites/site1")
+	verifyMap(t, map[string]string{"subscriptionId": "sub1", "resourceGroupName": "rg1", "name": "site1"}, matchResult.Values)
+}
+
+func TestMatcherNonMatch(t *testing.T) {
+	matcher := getTestMatcher(
+		"/subscriptions/{subscriptionId}/resourceGroups/{resourceGroupName}/providers/Microsoft.Web/sites/{name}/config",
+	)
+
+	for _, url := range []string{
+		"/subscriptions/sub1/resourceGroups/rg1/providers/Random/sites/site1/config",
+		"/subscriptions/sub1/resourceGroups/rg1/providers/Microsoft.Web/sites/site1",
+		"/subscriptions/sub1/resourceGroups/rg1/providers/Microsoft.Web/sites/site1/config/web",
+		"/subscriptions/sub1/resourceGroups/rg1/providers/Microsoft.Web/sites/site1/config/",
+		"subscriptions/sub1/resourceGroups/rg1/providers/Microsoft.Web/sites/site1/config",
+	} {
+		value, matchResult := matcher.Match(url)
+		if matchResult.IsMatch || value != nil {
+			t.Errorf("Expected no match for '%s' but got '%s'", url, value)
+		}
+	}
+}
+
+func TestMatcherFirstAddedWins(t *testing.T) {
+	matcher := getTestMatcher(
+		"/widgets/{name}",
+		"/widgets/default",
+	)
+
+	value, _ := matcher.Match("/widgets/default")
+	if value != "/widgets/{name}" {
+		t.Errorf("Expected the first endpoint added to match but got '%s'", value)
+	}
+}
+
+func TestMatcherParameterisedName(t *testing.T) {
+	matcher := getTestMatcher(
+		"/datasources",
+		"/datasources('{name}')",
+	)
+
+	value, matchResult := matcher.Match("/datasources('wibble')")
+	if value != "/datasources('{name}')" {
+		t.Errorf("Expected parameterised endpoint to match but got '%s'", value)
+	}
+	verifyMap(t, map[string]string{"name": "wibble"}, matchResult.Values)
+}
+
+// getBenchmarkTemplateURLs returns a set of template URLs similar in shape (and size) to the ARM specs
+func getBenchmarkTemplateURLs() []string {
+	templateURLs := []string{}
+	for provider := 0; provider < 200; provider++ {
+		resourceURL := fmt.Sprintf("/subscriptions/{subscriptionId}/resourceGroups/{resourceGroupName}/providers/Microsoft.Provider%d/resources/{resourceName}", provider)
+		templateURLs = append(templateURLs, resourceURL)
+		for child := 0; child < 10; child++ {
+			childURL := fmt.Sprintf("%s/children%d/{childName}", resourceURL, child)
+			templateURLs = append(templateURLs, childURL, childURL+"/config")
+		}
+	}
+	return templateURLs
+}
+
+const benchmarkURL = "/subscriptions/sub1/resourceGroups/rg1/providers/Microsoft.Provider150/resources/res1/children5/child1/config"
+
+func BenchmarkMatcherAdd(b *testing.B) {
+	endpoints := []*EndpointInfo{}
+	for _, templateURL := range getBenchmarkTemplateURLs() {
+		endpoints = append(endpoints, MustGetEndpointInfoFromURL(templateURL, ""))
+	}
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		matcher := NewMatcher()
+		for _, endpoint := range endpoints {
+			matcher.Add(endpoint, nil)
+		}
+	}
+}
+
+func BenchmarkMatcherMatch(b *testing.B) {
+	matcher := getTestMatcher(getBenchmarkTemplateURLs()...)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, matchResult := matcher.Match(benchmarkURL); !matchResult.IsMatch {
+			b.Fatal("Expected a match")
+		}
+	}
+}
+
+func BenchmarkMatcherNonMatch(b *testing.B) {
+	matcher := getTestMatcher(getBenchmarkTemplateURLs()...)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, matchResult := matcher.Match(benchmarkURL + "/unknown"); matchResult.IsMatch {
+			b.Fatal("Expected no match")
+		}
+	}
+}
+
+// BenchmarkLinearMatch tests each endpoint in turn for comparison with BenchmarkMatcherMatch
+func BenchmarkLinearMatch(b *testing.B) {
+	endpoints := []*EndpointInfo{}
+	for _, templateURL := range getBenchmarkTemplateURLs() {
+		endpoints = append(endpoints, MustGetEndpointInfoFromURL(templateURL, ""))
+	}
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		matched := false
+		for _, endpoint := range endpoints {
+			if endpoint.Match(benchmarkURL).IsMatch {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			b.Fatal("Expected a match")
+		}
+	}
+}
@@ -0,0 +1,253 @@
+package swagger
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// ResourceTypeSummary holds the details of a ResourceType that are compared by DiffResourceTypes
+type ResourceTypeSummary struct {
+	TemplateURL string `json:"templateUrl"`
+	APIVersion  string `json:"apiVersion"`
+	Get         bool   `json:"get"`
+	Put         bool   `json:"put"`
+	Delete      bool   `json:"delete"`
+}
+
+// APIVersionChange describes a change of API version for the resource types in a provider
+type APIVersionChange struct {
+	Provider          string `json:"provider"`
+	OldAPIVersion     string `json:"oldApiVersion"`
+	NewAPIVersion     string `json:"newApiVersion"`
+	ResourceTypeCount int    `json:"resourceTypeCount"`
+}
+
+// PermissionChange describes the operations that have been added or removed for a resource type
+type PermissionChange struct {
+	TemplateURL string   `json:"templateUrl"`
+	Added       []string `json:"added"`
+	Removed     []string `json:"removed"`
+}
+
+// ResourceTypeDiff holds the differences between two sets of ResourceTypes
+type ResourceTypeDiff struct {
+	Added             []ResourceTypeSummary `json:"added"`
+	Removed           []ResourceTypeSummary `json:"removed"`
+	APIVersionChanges []APIVersionChange    `json:"apiVersionChanges"`
+	PermissionChanges []PermissionChange    `json:"permissionChanges"`
+}
+
+// DiffResourceTypes compares two sets of ResourceTypes (including their Children and SubResources) by template URL
+func DiffResourceTypes(oldResourceTypes []ResourceType, newResourceTypes []ResourceType) ResourceTypeDiff {
+	return DiffResourceTypeSummaries(GetResourceTypeSummaries(oldResourceTypes), GetResourceTypeSummaries(newResourceTypes))
+}
+
+// DiffResourceTypeSummaries compares two sets of ResourceTypeSummaries (as returned by GetResourceTypeSummaries) by template URL
+func DiffResourceTypeSummaries(oldSummaryList []ResourceTypeSummary, newSummaryList []ResourceTypeSummary) ResourceTypeDiff {
+	oldSummaries := getResourceTypeSummaryMap(oldSummaryList)
+	newSummaries := getResourceTypeSummaryMap(newSummaryList)
+
+	diff := ResourceTypeDiff{
+		Added:             []ResourceTypeSummary{},
+		Removed:           []ResourceTypeSummary{},
+		APIVersionChanges: []APIVersionChange{},
+		PermissionChanges: []PermissionChange{},
+	}
+	apiVersionChanges := map[APIVersionChange]int{}
+	for templateURL, newSummary := range newSummaries {
+		oldSummary, ok := oldSummaries[templateURL]
+		if !ok {
+			diff.Added = append(diff.Added, newSummary)
+			continue
+		}
+		if oldSummary.APIVersion != newSummary.APIVersion {
+			key := APIVersionChange{
+				Provider:      getProvider(templateURL),
+				OldAPIVersion: oldSummary.APIVersion,
+				NewAPIVersion: newSummary.APIVersion,
+			}
+			apiVersionChanges[key]++
+		}
+		if permissionChange, changed := getPermissionChange(oldSummary, newSummary); changed {
+			diff.PermissionChanges = append(diff.PermissionChanges, permissionChange)
+		}
+	}
+	for templateURL, oldSummary := range oldSummaries {
+		if _, ok := newSummaries[templateURL]; !ok {
+			diff.Removed = append(diff.Removed, oldSummary)
+		}
+	}
+	for apiVersionChange, count := range apiVersionChanges {
+		apiVersionChange.ResourceTypeCount = count
+		diff.APIVersionChanges = append(diff.APIVersionChanges, apiVersionChange)
+	}
+
+	sort.Slice(diff.Added, func(i, j int) bool { return diff.Added[i].TemplateURL < diff.Added[j].TemplateURL })
+	sort.Slice(diff.Removed, func(i, j int) bool { return diff.Removed[i].TemplateURL < diff.Removed[j].TemplateURL })
+	sort.Slice(diff.PermissionChanges, func(i, j int) bool {
+		return diff.PermissionChanges[i].TemplateURL < diff.PermissionChanges[j].TemplateURL
+	})
+	sort.Slice(diff.APIVersionChanges, func(i, j int) bool {
+		a, b := diff.APIVersionChanges[i], diff.APIVersionChanges[j]
+		if a.Provider != b.Provider {
+			return a.Provider < b.Provider
+		}
+		return a.OldAPIVersion+a.NewAPIVersion < b.OldAPIVersion+b.NewAPIVersion
+	})
+	return diff
+}
+
+// GetResourceTypeSummaries returns the summaries for the ResourceTypes (including their Children and SubResources)
+// sorted by template URL. If a template URL appears more than once then the first is used
+func GetResourceTypeSummaries(resourceTypes []ResourceType) []ResourceTypeSummary {
+	summaries := map[string]ResourceTypeSummary{}
+	var addSummaries func(resourceTypes []ResourceType)
+	addSummaries = func(resourceTypes []ResourceType) {
+		for _, resourceType := range resourceTypes {
+			templateURL := resourceType.Endpoint.TemplateURL
+			if _, ok := summaries[templateURL]; !ok {
+				summaries[templateURL] = ResourceTypeSummary{
+					TemplateURL: templateURL,
+					APIVersion:  resourceType.Endpoint.APIVersion,
+					Get:         resourceType.FixedContent == "",
+					Put:         resourceType.PutEndpoint != nil,
+					Delete:      resourceType.DeleteEndpoint != nil,
+				}
+			}
+			addSummaries(resourceType.SubResources)
+			addSummaries(resourceType.Children)
+		}
+	}
+	addSummaries(resourceTypes)
+
+	result := make([]ResourceTypeSummary, 0, len(summaries))
+	for _, summary := range summaries {
+		result = append(result, summary)
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].TemplateURL < result[j].TemplateURL })
+	return result
+}
+
+// getResourceTypeSummaryMap returns the summaries keyed by template URL. If a template URL appears more than once then the first is used
+func getResourceTypeSummaryMap(summaryList []ResourceTypeSummary) map[string]ResourceTypeSummary {
+	summaries := map[string]ResourceTypeSummary{}
+	for _, summary := range summaryList {
+		if _, ok := summaries[summary.TemplateURL]; !ok {
+			summaries[summary.TemplateURL] = summary
+		}
+	}
+	return summaries
+}
+
+func getPermissionChange(oldSummary ResourceTypeSummary, newSummary ResourceTypeSummary) (PermissionChange, bool) {
+	permissionChange := PermissionChange{
+		TemplateURL: newSummary.TemplateURL,
+		Added:       []string{},
+		Removed:     []string{},
+	}
+	operations := []struct {
+		name     string
+		old, new bool
+	}{
+		{"GET", oldSummary.Get, newSummary.Get},
+		{"PUT", oldSummary.Put, newSummary.Put},
+		{"DELETE", oldSummary.Delete, newSummary.Delete},
+	}
+	for _, operation := range operations {
+		if operation.new && !operation.old {
+			permissionChange.Added = append(permissionChange.Added, operation.name)
+		} else if operation.old && !operation.new {
+			permissionChange.Removed = append(permissionChange.Removed, operation.name)
+		}
+	}
+	return permissionChange, len(permissionChange.Added) > 0 || len(permissionChange.Removed) > 0
+}
+
+// getProvider returns the resource provider namespace from the template URL, e.g. Microsoft.Web
+func getProvider(templateURL string) string {
+	segments := strings.Split(templateURL, "/")
+	provider := ""
+	for i := 0; i < len(segments)-1; i++ {
+		if strings.EqualFold(segments[i], "providers") {
+			provider = segments[i+1]
+		}
+	}
+	return provider
+}
+
+// IsEmpty returns true if there are no differences
+func (d ResourceTypeDiff) IsEmpty() bool {
+	return len(d.Added) == 0 && len(d.Removed) == 0 && len(d.APIVersionChanges) == 0 && len(d.PermissionChanges) == 0
+}
+
+// JSON returns the diff as indented JSON
+func (d ResourceTypeDiff) JSON() (string, error) {
+	buf, err := json.MarshalIndent(d, "", "  ")
+	if err != nil {
+		return "", err
+	}
+	return string(buf) + "\n", nil
+}
+
+// Markdown returns the diff as a Markdown report
+func (d ResourceTypeDiff) Markdown() string {
+	var report strings.Builder
+	report.WriteString("# Swagger resource type changes\n\n")
+	if d.IsEmpty() {
+		report.WriteString("No changes\n")
+		return report.String()
+	}
+	report.WriteString(fmt.Sprintf("- %d resource types added\n", len(d.Added)))
+	report.WriteString(fmt.Sprintf("- %d resource types removed\n", len(d.Removed)))
+	report.WriteString(fmt.Sprintf("- %d API version changes\n", len(d.APIVersionChanges)))
+	report.WriteString(fmt.Sprintf("- %d resource types with permission changes\n", len(d.PermissionChanges)))
+
+	writeSummaries := func(title string, summaries []ResourceTypeSummary) {
+		if len(summaries) == 0 {
+			return
+		}
+		report.WriteString("\n## " + title + "\n\n")
+		report.WriteString("| Template URL | API version | Operations |\n")
+		report.WriteString("| --- | --- | --- |\n")
+		for _, summary := range summaries {
+			report.WriteString(fmt.Sprintf("| `%s` | %s | %s |\n", summary.TemplateURL, summary.APIVersion, strings.Join(summary.getOperations(), ", ")))
+		}
+	}
+	writeSummaries("Added resource types", d.Added)
+	writeSummaries("Removed resource types", d.Removed)
+
+	if len(d.APIVersionChanges) > 0 {
+		report.WriteString("\n## API version changes\n\n")
+		report.WriteString("| Provider | Old API version | New API version | Resource types |\n")
+		report.WriteString("| --- | --- | --- | --- |\n")
+		for _, change := range d.APIVersionChanges {
+			report.WriteString(fmt.Sprintf("| %s | %s | %s | %d |\n", change.Provider, change.OldAPIVersion, change.NewAPIVersion, change.ResourceTypeCount))
+		}
+	}
+
+	if len(d.PermissionChanges) > 0 {
+		report.WriteString("\n## Permission changes\n\n")
+		report.WriteString("| Template URL | Added | Removed |\n")
+		report.WriteString("| --- | --- | --- |\n")
+		for _, change := range d.PermissionChanges {
+			report.WriteString(fmt.Sprintf("| `%s` | %s | %s |\n", change.TemplateURL, strings.Join(change.Added, ", "), strings.Join(change.Removed, ", ")))
+		}
+	}
+	return report.String()
+}
+
+func (s ResourceTypeSummary) getOperations() []string {
+	operations := []string{}
+	if s.Get {
+		operations = append(operations, "GET")
+	}
+	if s.Put {
+		operations = append(operations, "PUT")
+	}
+	if s.Delete {
+		operations = append(operations, "DELETE")
+	}
+	return operations
+}
@@ -0,0 +1,75 @@
+package swagger
+
+import (
+	"testing"
+
+	"github.com/lawrencegripper/azbrowse/pkg/endpoints"
+	"gotest.tools/assert"
+	is "gotest.tools/assert/cmp"
+)
+
+func getDiffTestResourceType(templateURL string, apiVersion string, put bool, delete bool, subResources ...ResourceType) ResourceType {
+	resourceType := ResourceType{
+		Endpoint:     endpoints.MustGetEndpointInfoFromURL(templateURL, apiVersion),
+		SubResources: subResources,
+	}
+	if put {
+		resourceType.PutEndpoint = endpoints.MustGetEndpointInfoFromURL(templateURL, apiVersion)
+	}
+	if delete {
+		resourceType.DeleteEndpoint = endpoints.MustGetEndpointInfoFromURL(templateURL, apiVersion)
+	}
+	return resourceType
+}
+
+func Test_DiffResourceTypes(t *testing.T) {
+	oldResourceTypes := []ResourceType{
+		getDiffTestResourceType("/providers/Microsoft.Web/sites", "2019-08-01", false, false,
+			getDiffTestResourceType("/providers/Microsoft.Web/sites/{name}", "2019-08-01", true, true),
+			getDiffTestResourceType("/providers/Microsoft.Web/sites/{name}/backups", "2019-08-01", false, false),
+		),
+		getDiffTestResourceType("/providers/Microsoft.Storage/storageAccounts/{name}", "2021-01-01", true, true),
+	}
+	newResourceTypes := []ResourceType{
+		getDiffTestResourceType("/providers/Microsoft.Web/sites", "2020-06-01", false, false,
+			getDiffTestResourceType("/providers/Microsoft.Web/sites/{name}", "2020-06-01", true, false),
+			getDiffTestResourceType("/providers/Microsoft.Web/sites/{name}/config", "2020-06-01", true, false),
+		),
+		getDiffTestResourceType("/providers/Microsoft.Storage/storageAccounts/{name}", "2021-01-01", true, true),
+	}
+
+	diff := DiffResourceTypes(oldResourceTypes, newResourceTypes)
+	assert.DeepEqual(t, diff.Added, []ResourceTypeSummary{
+		{TemplateURL: "/providers/Microsoft.Web/sites/{name}/config", APIVersion: "2020-06-01", Get: true, Put: true},
+	})
+	assert.DeepEqual(t, diff.Removed, []ResourceTypeSummary{
+		{TemplateURL: "/providers/Microsoft.Web/sites/{name}/backups", APIVersion: "2019-08-01", Get: true},
+	})
+	assert.DeepEqual(t, diff.APIVersionChanges, []APIVersionChange{
+		{Provider: "Microsoft.Web", OldAPIVersion: "2019-08-01", NewAPIVersion: "2020-06-01", ResourceTypeCount: 2},
+	})
+	assert.DeepEqual(t, diff.PermissionChanges, []PermissionChange{
+		{TemplateURL: "/providers/Microsoft.Web/sites/{name}", Added: []string{}, Removed: []string{"DELETE"}},
+	})
+	assert.Equal(t, diff.IsEmpty(), false)
+
+	markdown := diff.Markdown()
+	assert.Assert(t, is.Contains(markdown, "- 1 resource types added\n"))
+	assert.Assert(t, is.Contains(markdown, "| `/providers/Microsoft.Web/sites/{name}/config` | 2020-06-01 | GET, PUT |\n"))
+	assert.Assert(t, is.Contains(markdown, "| Microsoft.Web | 2019-08-01 | 2020-06-01 | 2 |\n"))
+	assert.Assert(t, is.Contains(markdown, "| `/providers/Microsoft.Web/sites/{name}` |  | DELETE |\n"))
+
+	json, err := diff.JSON()
+	assert.NilError(t, err)
+	assert.Assert(t, is.Contains(json, `"removed": [`))
+}
+
+func Test_DiffResourceTypes_NoChanges(t *testing.T) {
+	resourceTypes := []ResourceType{
+		getDiffTestResourceType("/providers/Microsoft.Web/sites/{name}", "2020-06-01", true, true),
+	}
+
+	diff := DiffResourceTypes(resourceTypes, resourceTypes)
+	assert.Equal(t, diff.IsEmpty(), true)
+	assert.Equal(t, diff.Markdown(), "# Swagger resource type changes\n\nNo changes\n")
+}
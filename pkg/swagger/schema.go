@@ -0,0 +1,599 @@
+package swagger
+
+import (
+	"encoding/json"
+	"fmt"
+	"hash/fnv"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/go-openapi/loads"
+	"github.com/go-openapi/spec"
+)
+
+// maxSchemaDepth limits how deep schemas are walked. Recursive schemas are cut when a $ref repeats, this
+// guards against deeply nested (but not recursive) schemas. Schemas beyond this depth are emitted without
+// a type so that they accept any value
+const maxSchemaDepth = 8
+
+// Schema is a compact form of a swagger schema that is captured at code-gen time for validating request bodies
+// and describing responses
+type Schema struct {
+	Type                 string             `json:"t,omitempty"`
+	Description          string             `json:"d,omitempty"`
+	DescriptionRef       string             `json:"k,omitempty"` // DescriptionRef is the key of a shared description (see SchemaTable)
+	Enum                 []interface{}      `json:"e,omitempty"`
+	ReadOnly             bool               `json:"r,omitempty"`
+	Required             []string           `json:"q,omitempty"`
+	Properties           map[string]*Schema `json:"p,omitempty"`
+	Items                *Schema            `json:"i,omitempty"`
+	AdditionalProperties *Schema            `json:"a,omitempty"`
+	Ref                  string             `json:"$,omitempty"` // Ref is the key of a shared schema definition (see SchemaTable)
+	Default              interface{}        `json:"-"`           // Default is only used for skeletons at code-gen time
+}
+
+// ParseSchema parses a Schema from the JSON produced by GetBodySchema or GetResponseSchema,
+// resolving any references to shared schema definitions and descriptions
+func ParseSchema(schemaJSON string) (*Schema, error) {
+	var schema Schema
+	if err := json.Unmarshal([]byte(schemaJSON), &schema); err != nil {
+		return nil, fmt.Errorf("Error parsing schema: %s", err)
+	}
+	return resolveSchema(&schema)
+}
+
+// GetBodySchema returns the compact JSON Schema for the body parameter of the operation
+// (or "" if the operation has no body)
+func GetBodySchema(doc *loads.Document, operation *spec.Operation) string {
+	return newSchemaResolver(doc).getBodySchema(operation)
+}
+
+// GetResponseSchema returns the compact JSON Schema for the 200 response of the operation
+// (or "" if the operation doesn't define one)
+func GetResponseSchema(doc *loads.Document, operation *spec.Operation) string {
+	return newSchemaResolver(doc).getResponseSchema(operation)
+}
+
+func getSchemaJSON(schema *Schema) string {
+	if schema == nil {
+		return ""
+	}
+	buf, err := json.Marshal(schema)
+	if err != nil {
+		return ""
+	}
+	return string(buf)
+}
+
+// schemaAtBase is a swagger schema along with the path of the doc that its $refs are relative to
+type schemaAtBase struct {
+	schema   *spec.Schema
+	basePath string
+}
+
+// schemaResolver resolves $refs as schemas are walked. The docs are loaded without expanding schemas
+// as the swagger library expands recursive schemas in map order, leaving the $ref at a different
+// point each time. Instead, refs are tracked by name on the current path so that recursive schemas
+// are always cut at the same point
+type schemaResolver struct {
+	root     *spec.Swagger
+	basePath string
+	visiting map[string]bool
+	resolved map[string]*spec.Schema
+	// refErrors records the $refs that couldn't be resolved (keyed on the ref) so that they can be reported (see getRefError)
+	refErrors map[string]error
+}
+
+func newSchemaResolver(doc *loads.Document) *schemaResolver {
+	basePath := doc.SpecFilePath()
+	if basePath != "" {
+		if absBasePath, err := filepath.Abs(basePath); err == nil {
+			basePath = absBasePath
+		}
+	}
+	return &schemaResolver{
+		root:      doc.Spec(),
+		basePath:  basePath,
+		visiting:  map[string]bool{},
+		resolved:  map[string]*spec.Schema{},
+		refErrors: map[string]error{},
+	}
+}
+
+func (r *schemaResolver) getBodySchemaAtBase(operation *spec.Operation) *schemaAtBase {
+	if operation == nil {
+		return nil
+	}
+	for _, parameter := range operation.Parameters {
+		if parameter.In != "body" || parameter.Schema == nil {
+			continue
+		}
+		return &schemaAtBase{schema: parameter.Schema, basePath: r.basePath}
+	}
+	return nil
+}
+
+func (r *schemaResolver) getBodySchema(operation *spec.Operation) string {
+	body := r.getBodySchemaAtBase(operation)
+	if body == nil {
+		return ""
+	}
+	return getSchemaJSON(r.getSchema(*body, 0))
+}
+
+func (r *schemaResolver) getResponseSchema(operation *spec.Operation) string {
+	if operation == nil || operation.Responses == nil {
+		return ""
+	}
+	response, ok := operation.Responses.StatusCodeResponses[200]
+	if !ok || response.Schema == nil {
+		return ""
+	}
+	return getSchemaJSON(r.getSchema(schemaAtBase{schema: response.Schema, basePath: r.basePath}, 0))
+}
+
+// enter follows the $refs for the schema and marks them as being visited. ok is false if a ref is
+// already being visited (i.e. the schema is recursive) or can't be resolved. The returned keys must
+// be passed to leave once the schema has been walked
+func (r *schemaResolver) enter(s schemaAtBase) (resolved schemaAtBase, keys []string, ok bool) {
+	for s.schema.Ref.String() != "" {
+		refURL := s.schema.Ref.GetURL()
+		basePath := s.basePath
+		if refURL.Path != "" {
+			if filepath.IsAbs(refURL.Path) || s.basePath == "" {
+				basePath = filepath.FromSlash(refURL.Path)
+			} else {
+				basePath = filepath.Join(filepath.Dir(s.basePath), filepath.FromSlash(refURL.Path))
+			}
+		}
+		key := basePath + "#" + refURL.Fragment
+		if r.visiting[key] {
+			r.leave(keys)
+			return schemaAtBase{}, nil, false
+		}
+
+		target, exists := r.resolved[key]
+		if !exists {
+			var err error
+			target, err = r.resolveRef(basePath, refURL.Fragment)
+			if err != nil {
+				r.refErrors[key] = err
+				r.leave(keys)
+				return schemaAtBase{}, nil, false
+			}
+			r.resolved[key] = target
+		}
+		r.visiting[key] = true
+		keys = append(keys, key)
+		s = schemaAtBase{schema: target, basePath: basePath}
+	}
+	return s, keys, true
+}
+
+// resolveRef resolves the fragment of a $ref against the doc at path. The docs are loaded here rather than by the
+// swagger library as its resolution cache depends on the order that docs were loaded in
+func (r *schemaResolver) resolveRef(path string, fragment string) (*spec.Schema, error) {
+	root := r.root
+	if path != r.basePath {
+		var err error
+		if root, err = loadRefDoc(path); err != nil {
+			return nil, err
+		}
+	}
+	ref, err := spec.NewRef("#" + fragment)
+	if err != nil {
+		return nil, err
+	}
+	return spec.ResolveRef(root, &ref)
+}
+
+var refDocs = map[string]*spec.Swagger{}
+var refDocsLock sync.Mutex
+
+func loadRefDoc(path string) (*spec.Swagger, error) {
+	refDocsLock.Lock()
+	defer refDocsLock.Unlock()
+	if doc, exists := refDocs[path]; exists {
+		return doc, nil
+	}
+	// loads.Spec handles both JSON and YAML docs
+	document, err := loads.Spec(path)
+	if err != nil {
+		return nil, fmt.Errorf("Error loading %q: %s", path, err)
+	}
+	doc := document.Spec()
+	refDocs[path] = doc
+	return doc, nil
+}
+
+// getRefError returns an error listing the $refs that couldn't be resolved (or nil if they all resolved)
+func (r *schemaResolver) getRefError() error {
+	if len(r.refErrors) == 0 {
+		return nil
+	}
+	keys := []string{}
+	for key := range r.refErrors {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	messages := []string{}
+	for _, key := range keys {
+		messages = append(messages, fmt.Sprintf("%s: %s", key, r.refErrors[key]))
+	}
+	return fmt.Errorf("Error resolving schema $refs:\n%s", strings.Join(messages, "\n"))
+}
+
+func (r *schemaResolver) leave(keys []string) {
+	for _, key := range keys {
+		delete(r.visiting, key)
+	}
+}
+
+// getAllOf returns the schemas that the schema includes with allOf (depth-first) followed by the schema itself.
+// Recursive allOf refs are skipped. The keys of the refs entered are added to keys
+func (r *schemaResolver) getAllOf(s schemaAtBase, keys *[]string) []schemaAtBase {
+	result := []schemaAtBase{}
+	for i := range s.schema.AllOf {
+		allOf, allOfKeys, ok := r.enter(schemaAtBase{schema: &s.schema.AllOf[i], basePath: s.basePath})
+		if !ok {
+			continue
+		}
+		*keys = append(*keys, allOfKeys...)
+		result = append(result, r.getAllOf(allOf, keys)...)
+	}
+	return append(result, s)
+}
+
+func (r *schemaResolver) getSchema(s schemaAtBase, depth int) *Schema {
+	if s.schema == nil {
+		return nil
+	}
+	// Descriptions alongside a $ref are more specific than the description of the referenced schema
+	result := &Schema{
+		ReadOnly:    s.schema.ReadOnly,
+		Description: s.schema.Description,
+	}
+	resolved, keys, ok := r.enter(s)
+	defer func() { r.leave(keys) }()
+	if !ok || depth > maxSchemaDepth {
+		return result
+	}
+
+	schema := resolved.schema
+	result.ReadOnly = result.ReadOnly || schema.ReadOnly
+	if result.Description == "" {
+		result.Description = schema.Description
+	}
+	allOf := r.getAllOf(resolved, &keys)
+	result.Type = getSchemaType(allOf)
+	result.Enum = schema.Enum
+	result.Default = schema.Default
+
+	switch result.Type {
+	case "object":
+		properties := getSchemaProperties(allOf)
+		if len(properties) > 0 {
+			result.Properties = map[string]*Schema{}
+			for _, name := range getSortedSchemaPropertyNames(properties) {
+				result.Properties[name] = r.getSchema(properties[name], depth+1)
+			}
+		}
+		result.Required = getSchemaRequired(allOf)
+		if schema.AdditionalProperties != nil {
+			if schema.AdditionalProperties.Schema != nil {
+				result.AdditionalProperties = r.getSchema(schemaAtBase{schema: schema.AdditionalProperties.Schema, basePath: resolved.basePath}, depth+1)
+			} else if schema.AdditionalProperties.Allows {
+				result.AdditionalProperties = &Schema{}
+			}
+		}
+		if result.Properties == nil && result.AdditionalProperties == nil {
+			// an object with no defined properties accepts anything
+			result.AdditionalProperties = &Schema{}
+		}
+	case "array":
+		if schema.Items != nil && schema.Items.Schema != nil {
+			result.Items = r.getSchema(schemaAtBase{schema: schema.Items.Schema, basePath: resolved.basePath}, depth+1)
+		}
+	}
+	return result
+}
+
+// getSchemaType returns the type of the schema (the last of allOf), falling back to the types of the schemas it includes
+func getSchemaType(allOf []schemaAtBase) string {
+	schema := allOf[len(allOf)-1].schema
+	if len(schema.Type) > 0 {
+		return schema.Type[0]
+	}
+	for _, allOfSchema := range allOf {
+		if len(allOfSchema.schema.Type) > 0 {
+			return allOfSchema.schema.Type[0]
+		}
+	}
+	for _, allOfSchema := range allOf {
+		if len(allOfSchema.schema.Properties) > 0 {
+			return "object"
+		}
+	}
+	if len(schema.AllOf) > 0 {
+		return "object"
+	}
+	return ""
+}
+
+// getSchemaProperties returns the properties for the schemas, with later schemas taking precedence
+func getSchemaProperties(allOf []schemaAtBase) map[string]schemaAtBase {
+	properties := map[string]schemaAtBase{}
+	for _, allOfSchema := range allOf {
+		for name := range allOfSchema.schema.Properties {
+			property := allOfSchema.schema.Properties[name]
+			properties[name] = schemaAtBase{schema: &property, basePath: allOfSchema.basePath}
+		}
+	}
+	return properties
+}
+
+func getSortedSchemaPropertyNames(properties map[string]schemaAtBase) []string {
+	names := make([]string, 0, len(properties))
+	for name := range properties {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func getSchemaRequired(allOf []schemaAtBase) []string {
+	required := []string{}
+	for _, allOfSchema := range allOf {
+		required = append(required, allOfSchema.schema.Required...)
+	}
+	if len(required) == 0 {
+		return nil
+	}
+	return required
+}
+
+// GetPropertySchema returns the schema for the property at the path (property names, with "[]" for array items)
+// or nil if the schema doesn't define it
+func (s *Schema) GetPropertySchema(path []string) *Schema {
+	schema := s
+	for _, segment := range path {
+		if schema == nil {
+			return nil
+		}
+		if segment == "[]" {
+			schema = schema.Items
+			continue
+		}
+		if property, ok := getSchemaProperty(schema, segment); ok {
+			schema = property
+		} else {
+			schema = schema.AdditionalProperties
+		}
+	}
+	return schema
+}
+
+// GetSkeleton returns a compact JSON skeleton for the schema (as per GetBodySkeleton). Read-only properties are omitted
+func (s *Schema) GetSkeleton() string {
+	skeleton := s.getSkeletonValue()
+	if skeleton == nil {
+		return "{}"
+	}
+	buf, err := json.Marshal(skeleton)
+	if err != nil {
+		return "{}"
+	}
+	return string(buf)
+}
+
+func (s *Schema) getSkeletonValue() interface{} {
+	if s == nil {
+		return nil
+	}
+	if s.Default != nil {
+		return s.Default
+	}
+	if len(s.Enum) > 0 {
+		return s.Enum[0]
+	}
+
+	switch s.Type {
+	case "object":
+		result := map[string]interface{}{}
+		for name, property := range s.Properties {
+			if property == nil || property.ReadOnly {
+				continue
+			}
+			if value := property.getSkeletonValue(); value != nil {
+				result[name] = value
+			}
+		}
+		return result
+	case "array":
+		if value := s.Items.getSkeletonValue(); value != nil {
+			return []interface{}{value}
+		}
+		return []interface{}{}
+	case "string":
+		return ""
+	case "integer", "number":
+		return 0
+	case "boolean":
+		return false
+	}
+	return nil
+}
+
+// minSchemaDefinitionSize is the size (in bytes of JSON) above which SchemaTable shares sub-schemas
+const minSchemaDefinitionSize = 100
+
+var schemaDefinitions = map[string]string{}
+var parsedSchemaDefinitions = map[string]*Schema{}
+var schemaDescriptions = map[string]string{}
+var unparsedSchemaDescriptions = [][]byte{}
+var schemaDefinitionsLock sync.Mutex
+
+// RegisterSchemaDefinitions registers shared schema definitions (as built by SchemaTable) for use by ParseSchema
+func RegisterSchemaDefinitions(definitions map[string]string) {
+	schemaDefinitionsLock.Lock()
+	defer schemaDefinitionsLock.Unlock()
+	for key, definition := range definitions {
+		schemaDefinitions[key] = definition
+	}
+}
+
+// RegisterSchemaDescriptions registers the JSON for a map of shared descriptions (as built by SchemaTable) for use by ParseSchema.
+// The descriptions aren't parsed until a schema that references them is parsed
+func RegisterSchemaDescriptions(descriptionsJSON []byte) {
+	schemaDefinitionsLock.Lock()
+	defer schemaDefinitionsLock.Unlock()
+	unparsedSchemaDescriptions = append(unparsedSchemaDescriptions, descriptionsJSON)
+}
+
+func getSchemaDefinition(key string) (*Schema, error) {
+	schemaDefinitionsLock.Lock()
+	parsedSchema, parsed := parsedSchemaDefinitions[key]
+	definition, exists := schemaDefinitions[key]
+	schemaDefinitionsLock.Unlock()
+	if parsed {
+		return parsedSchema, nil
+	}
+	if !exists {
+		return nil, fmt.Errorf("Schema definition not found: %q", key)
+	}
+
+	// definitions only reference smaller definitions so this terminates
+	parsedSchema, err := ParseSchema(definition)
+	if err != nil {
+		return nil, err
+	}
+	schemaDefinitionsLock.Lock()
+	parsedSchemaDefinitions[key] = parsedSchema
+	schemaDefinitionsLock.Unlock()
+	return parsedSchema, nil
+}
+
+func getSchemaDescription(key string) (string, error) {
+	schemaDefinitionsLock.Lock()
+	defer schemaDefinitionsLock.Unlock()
+	for _, descriptionsJSON := range unparsedSchemaDescriptions {
+		descriptions := map[string]string{}
+		if err := json.Unmarshal(descriptionsJSON, &descriptions); err != nil {
+			return "", fmt.Errorf("Error parsing schema descriptions: %s", err)
+		}
+		for descriptionKey, description := range descriptions {
+			schemaDescriptions[descriptionKey] = description
+		}
+	}
+	unparsedSchemaDescriptions = [][]byte{}
+
+	description, exists := schemaDescriptions[key]
+	if !exists {
+		return "", fmt.Errorf("Schema description not found: %q", key)
+	}
+	return description, nil
+}
+
+// resolveSchema replaces references to shared schema definitions and descriptions with their values
+func resolveSchema(schema *Schema) (*Schema, error) {
+	if schema == nil {
+		return nil, nil
+	}
+	if schema.Ref != "" {
+		return getSchemaDefinition(schema.Ref)
+	}
+	var err error
+	if schema.DescriptionRef != "" {
+		if schema.Description, err = getSchemaDescription(schema.DescriptionRef); err != nil {
+			return nil, err
+		}
+		schema.DescriptionRef = ""
+	}
+	for name, property := range schema.Properties {
+		if schema.Properties[name], err = resolveSchema(property); err != nil {
+			return nil, err
+		}
+	}
+	if schema.Items, err = resolveSchema(schema.Items); err != nil {
+		return nil, err
+	}
+	if schema.AdditionalProperties, err = resolveSchema(schema.AdditionalProperties); err != nil {
+		return nil, err
+	}
+	return schema, nil
+}
+
+// SchemaTable de-duplicates schemas when generating code so that definitions that are shared
+// between resource types (e.g. systemData) are only emitted once. Descriptions are held separately
+// in the table as the same descriptions are repeated across API versions and they are only needed
+// when displaying a property (so they can be registered with RegisterSchemaDescriptions)
+type SchemaTable struct {
+	Definitions  map[string]string
+	Descriptions map[string]string
+}
+
+// NewSchemaTable creates a new SchemaTable
+func NewSchemaTable() *SchemaTable {
+	return &SchemaTable{
+		Definitions:  map[string]string{},
+		Descriptions: map[string]string{},
+	}
+}
+
+// Add returns the schema JSON with descriptions and larger schemas replaced with references to the table
+func (t *SchemaTable) Add(schemaJSON string) string {
+	if schemaJSON == "" {
+		return ""
+	}
+	var schema Schema
+	if err := json.Unmarshal([]byte(schemaJSON), &schema); err != nil {
+		panic(fmt.Errorf("Error parsing schema: %s", err))
+	}
+	return getSchemaJSON(t.addSchema(&schema))
+}
+
+func (t *SchemaTable) addChildren(schema *Schema) {
+	if schema.Description != "" {
+		schema.DescriptionRef = getSchemaHash(schema.Description)
+		if existing, exists := t.Descriptions[schema.DescriptionRef]; exists && existing != schema.Description {
+			panic(fmt.Errorf("Schema description hash collision for %q", schema.DescriptionRef))
+		}
+		t.Descriptions[schema.DescriptionRef] = schema.Description
+		schema.Description = ""
+	}
+	names := make([]string, 0, len(schema.Properties))
+	for name := range schema.Properties {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		schema.Properties[name] = t.addSchema(schema.Properties[name])
+	}
+	schema.Items = t.addSchema(schema.Items)
+	schema.AdditionalProperties = t.addSchema(schema.AdditionalProperties)
+}
+
+func (t *SchemaTable) addSchema(schema *Schema) *Schema {
+	if schema == nil {
+		return nil
+	}
+	t.addChildren(schema)
+	schemaJSON := getSchemaJSON(schema)
+	if len(schemaJSON) < minSchemaDefinitionSize {
+		return schema
+	}
+	key := getSchemaHash(schemaJSON)
+	if existing, exists := t.Definitions[key]; exists && existing != schemaJSON {
+		panic(fmt.Errorf("Schema definition hash collision for %q", key))
+	}
+	t.Definitions[key] = schemaJSON
+	return &Schema{Ref: key}
+}
+
+func getSchemaHash(value string) string {
+	hash := fnv.New64a()
+	_, _ = hash.Write([]byte(value))
+	return fmt.Sprintf("%x", hash.Sum64())
+}
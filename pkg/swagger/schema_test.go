@@ -0,0 +1,47 @@
+package swagger
+
+import (
+	"encoding/json"
+	"testing"
+
+	"gotest.tools/assert"
+)
+
+func Test_SchemaSkeleton(t *testing.T) {
+	schema, err := ParseSchema(testWidgetSchema)
+	assert.NilError(t, err)
+
+	assert.Equal(t, schema.GetSkeleton(), `{"location":"","properties":{"count":0,"enabled":false,"rules":[{"name":""}]},"tags":{}}`)
+
+	var nilSchema *Schema
+	assert.Equal(t, nilSchema.GetSkeleton(), "{}")
+}
+
+func Test_GetPropertySchema(t *testing.T) {
+	schema, err := ParseSchema(testWidgetSchema)
+	assert.NilError(t, err)
+
+	assert.Equal(t, schema.GetPropertySchema([]string{}), schema)
+	assert.Equal(t, schema.GetPropertySchema([]string{"Properties", "provisioningState"}).ReadOnly, true)
+	assert.Equal(t, schema.GetPropertySchema([]string{"properties", "rules", "[]", "name"}).Type, "string")
+	assert.Equal(t, schema.GetPropertySchema([]string{"tags", "env"}).Type, "string")
+	assert.Assert(t, schema.GetPropertySchema([]string{"properties", "colour"}) == nil)
+	assert.Assert(t, schema.GetPropertySchema([]string{"location", "name"}) == nil)
+}
+
+func Test_SchemaTable(t *testing.T) {
+	schemaJSON := `{"t":"object","p":{"systemData":{"t":"object","d":"Metadata pertaining to creation and last modification of the resource.","p":{"createdBy":{"t":"string","d":"The identity that created the resource."}}}}}`
+
+	table := NewSchemaTable()
+	tableJSON := table.Add(schemaJSON)
+	assert.Equal(t, len(table.Definitions), 1)
+	assert.Assert(t, tableJSON != schemaJSON)
+
+	descriptionsJSON, err := json.Marshal(table.Descriptions)
+	assert.NilError(t, err)
+	RegisterSchemaDefinitions(table.Definitions)
+	RegisterSchemaDescriptions(descriptionsJSON)
+	schema, err := ParseSchema(tableJSON)
+	assert.NilError(t, err)
+	assert.Equal(t, getSchemaJSON(schema), schemaJSON)
+}
@@ -0,0 +1,30 @@
+package swagger
+
+import (
+	"encoding/json"
+
+	"github.com/go-openapi/loads"
+	"github.com/go-openapi/spec"
+)
+
+// GetBodySkeleton returns a compact JSON skeleton for the body parameter of the operation
+// (or "" if the operation has no body). Read-only properties are omitted
+func GetBodySkeleton(doc *loads.Document, operation *spec.Operation) string {
+	return newSchemaResolver(doc).getBodySkeleton(operation)
+}
+
+func (r *schemaResolver) getBodySkeleton(operation *spec.Operation) string {
+	body := r.getBodySchemaAtBase(operation)
+	if body == nil {
+		return ""
+	}
+	skeleton := r.getSchema(*body, 0).getSkeletonValue()
+	if skeleton == nil {
+		return ""
+	}
+	buf, err := json.Marshal(skeleton)
+	if err != nil {
+		return ""
+	}
+	return string(buf)
+}
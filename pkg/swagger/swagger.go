@@ -2,6 +2,8 @@ package swagger
 
 import (
 	"fmt"
+	"io/ioutil"
+	"path/filepath"
 	"regexp"
 	"sort"
 	"strings"
@@ -76,6 +78,54 @@ func MergeSwaggerPaths(currentPaths []*Path, config *Config, newPaths []Path, va
 	return resultPaths, nil
 }
 
+// LoadDoc loads the swagger doc at the specified path and expands the $refs other than schemas
+func LoadDoc(path string) (*loads.Document, error) {
+	document, err := loads.Spec(path)
+	if err != nil {
+		return nil, fmt.Errorf("Error opening Swagger %q: %s", path, err)
+	}
+
+	// Schemas are left unexpanded as they are resolved when walked (see schemaResolver)
+	document, err = document.Expanded(&spec.ExpandOptions{RelativeBase: path, SkipSchemas: true})
+	if err != nil {
+		return nil, fmt.Errorf("Error expanding Swagger %q: %s", path, err)
+	}
+	return document, nil
+}
+
+// LoadResourceTypesFromDirectory loads the swagger docs (*.json, *.yaml, *.yml) in a directory
+// and returns the merged ResourceTypes. Docs are loaded in filename order
+func LoadResourceTypesFromDirectory(directory string, config *Config) ([]ResourceType, error) {
+	fileInfos, err := ioutil.ReadDir(directory)
+	if err != nil {
+		return []ResourceType{}, fmt.Errorf("Error reading swagger directory %q: %s", directory, err)
+	}
+
+	var paths []*Path
+	for _, fileInfo := range fileInfos {
+		if fileInfo.IsDir() {
+			continue
+		}
+		switch strings.ToLower(filepath.Ext(fileInfo.Name())) {
+		case ".json", ".yaml", ".yml":
+		default:
+			continue
+		}
+		doc, err := LoadDoc(filepath.Join(directory, fileInfo.Name()))
+		if err != nil {
+			return []ResourceType{}, err
+		}
+		paths, err = MergeSwaggerDoc(paths, config, doc, true, "")
+		if err != nil {
+			return []ResourceType{}, fmt.Errorf("Error merging Swagger %q: %s", fileInfo.Name(), err)
+		}
+	}
+	if len(paths) == 0 {
+		return []ResourceType{}, fmt.Errorf("No swagger paths found in %q", directory)
+	}
+	return ConvertToSwaggerResourceTypes(paths), nil
+}
+
 // ConvertToSwaggerResourceTypes converts the Path array to an array of SwaggerResourceTypes for use with the Swagger expander
 func ConvertToSwaggerResourceTypes(paths []*Path) []ResourceType {
 	resourceTypes := []ResourceType{}
@@ -90,12 +140,13 @@ func ConvertToSwaggerResourceTypes(paths []*Path) []ResourceType {
 
 func convertToSwaggerResourceType(path *Path) ResourceType {
 	resourceType := ResourceType{
-		Display:      path.Name,
-		Endpoint:     endpoints.MustGetEndpointInfoFromURL(path.Operations.Get.Endpoint.TemplateURL, path.Operations.Get.Endpoint.APIVersion),
-		Children:     ConvertToSwaggerResourceTypes(path.Children),
-		SubResources: ConvertToSwaggerResourceTypes(path.SubPaths),
-		FixedContent: path.FixedContent,
-		SubPathRegex: path.SubPathRegex,
+		Display:        path.Name,
+		Endpoint:       endpoints.MustGetEndpointInfoFromURL(path.Operations.Get.Endpoint.TemplateURL, path.Operations.Get.Endpoint.APIVersion),
+		ResponseSchema: path.Operations.Get.ResponseSchema,
+		Children:       ConvertToSwaggerResourceTypes(path.Children),
+		SubResources:   ConvertToSwaggerResourceTypes(path.SubPaths),
+		FixedContent:   path.FixedContent,
+		SubPathRegex:   path.SubPathRegex,
 	}
 	if path.Operations.Get.Verb != "" {
 		resourceType.Verb = path.Operations.Get.Verb
@@ -108,11 +159,39 @@ func convertToSwaggerResourceType(path *Path) ResourceType {
 	}
 	if path.Operations.Put.Permitted {
 		resourceType.PutEndpoint = endpoints.MustGetEndpointInfoFromURL(path.Operations.Put.Endpoint.TemplateURL, path.Operations.Put.Endpoint.APIVersion)
+		resourceType.PutBodySchema = path.Operations.Put.BodySchema
 	}
+	resourceType.PostActions = path.GetPostActions()
 
 	return resourceType
 }
 
+// GetPostActions returns the POST operations on the path. These are the child paths
+// that only permit POST, e.g. /.../storageAccounts/{accountName}/listKeys
+func (path *Path) GetPostActions() []PostAction {
+	actions := []PostAction{}
+	childPaths := append(append([]*Path{}, path.Children...), path.SubPaths...)
+	for _, childPath := range childPaths {
+		if childPath.Operations.Get.Permitted || !childPath.Operations.Post.Permitted {
+			continue
+		}
+		endpoint := childPath.Operations.Post.Endpoint
+		lastSegment := endpoint.URLSegments[len(endpoint.URLSegments)-1]
+		if lastSegment.Match == "" {
+			continue // need a literal action name (and a value for the template segment that we won't have)
+		}
+		actions = append(actions, PostAction{
+			Name:         lastSegment.Match,
+			Endpoint:     endpoint,
+			BodySkeleton: childPath.Operations.Post.BodySkeleton,
+		})
+	}
+	sort.Slice(actions, func(i, j int) bool {
+		return strings.Compare(actions[i].Name, actions[j].Name) < 0
+	})
+	return actions
+}
+
 func getSortedPaths(paths []Path) []Path {
 	sort.Slice(paths, func(i, j int) bool {
 		return strings.Compare(paths[i].Endpoint.TemplateURL, paths[j].Endpoint.TemplateURL) < 0
@@ -177,6 +256,7 @@ func GetPathsFromSwagger(doc *loads.Document, config *Config, pathPrefix string)
 	}
 
 	spec := doc.Analyzer
+	schemaResolver := newSchemaResolver(doc)
 
 	swaggerPaths := spec.AllPaths()
 	paths := make([]Path, len(swaggerPaths))
@@ -226,6 +306,7 @@ func GetPathsFromSwagger(doc *loads.Document, config *Config, pathPrefix string)
 		}
 		if getOperation != nil {
 			path.Operations.Get.Permitted = true
+			path.Operations.Get.ResponseSchema = schemaResolver.getResponseSchema(getOperation)
 			if getVerb != "get" {
 				path.Operations.Get.Verb = getVerb
 			}
@@ -254,10 +335,12 @@ func GetPathsFromSwagger(doc *loads.Document, config *Config, pathPrefix string)
 		if swaggerPathItem.Post != nil && getVerb != "post" {
 			path.Operations.Post.Permitted = true
 			path.Operations.Post.Endpoint = path.Endpoint
+			path.Operations.Post.BodySkeleton = schemaResolver.getBodySkeleton(swaggerPathItem.Post)
 		}
 		if swaggerPathItem.Put != nil && getVerb != "put" {
 			path.Operations.Put.Permitted = true
 			path.Operations.Put.Endpoint = path.Endpoint
+			path.Operations.Put.BodySchema = schemaResolver.getBodySchema(swaggerPathItem.Put)
 		}
 		if override.PutPath != "" {
 			path.Operations.Put.Permitted = true
@@ -268,6 +351,9 @@ func GetPathsFromSwagger(doc *loads.Document, config *Config, pathPrefix string)
 		pathIndex++
 	}
 
+	if err := schemaResolver.getRefError(); err != nil {
+		return []Path{}, fmt.Errorf("Error loading schemas from %q: %s", doc.SpecFilePath(), err)
+	}
 	return paths, nil
 }
 
@@ -310,6 +396,9 @@ func copyOperationFrom(from PathOperation, to *PathOperation) {
 	to.Permitted = from.Permitted
 	to.Endpoint = from.Endpoint
 	to.Verb = from.Verb
+	to.BodySkeleton = from.BodySkeleton
+	to.BodySchema = from.BodySchema
+	to.ResponseSchema = from.ResponseSchema
 }
 func countNameSegments(endpoint *endpoints.EndpointInfo) int {
 	count := 0
@@ -1,13 +1,17 @@
 package swagger
 
 import (
+	"context"
 	"encoding/json"
+	"io/ioutil"
+	"path/filepath"
 	"testing"
 
 	"gotest.tools/assert"
 	is "gotest.tools/assert/cmp"
 
 	"github.com/go-openapi/loads"
+	"github.com/lawrencegripper/azbrowse/pkg/endpoints"
 )
 
 func Test_Simple_PreOrderedSpec(t *testing.T) {
@@ -432,3 +436,191 @@ func Test_AdditionalPaths(t *testing.T) {
 	assert.Assert(t, is.Len(path.SubPaths, 0))
 
 }
+
+func Test_LoadResourceTypesFromDirectory(t *testing.T) {
+
+	// Test loading and merging multiple specs from a directory
+
+	directory := t.TempDir()
+	ordersSpec := `{
+	"swagger": "2.0",
+	"info": { "title": "Orders", "version": "1.0" },
+	"paths": {
+		"/orders": { "get": {} },
+		"/orders/{orderId}": { "get": {}, "put": {}, "delete": {} }
+	}
+}`
+	customersSpec := `{
+	"swagger": "2.0",
+	"info": { "title": "Customers", "version": "1.0" },
+	"paths": {
+		"/customers": { "get": {} },
+		"/customers/{customerId}": { "get": {} }
+	}
+}`
+	assert.NilError(t, ioutil.WriteFile(filepath.Join(directory, "orders.json"), []byte(ordersSpec), 0600))
+	assert.NilError(t, ioutil.WriteFile(filepath.Join(directory, "customers.json"), []byte(customersSpec), 0600))
+	assert.NilError(t, ioutil.WriteFile(filepath.Join(directory, "readme.md"), []byte("not a spec"), 0600))
+
+	config := Config{SuppressAPIVersion: true}
+	resourceTypes, err := LoadResourceTypesFromDirectory(directory, &config)
+	assert.NilError(t, err)
+
+	assert.Assert(t, is.Len(resourceTypes, 2))
+
+	// /customers
+	resourceType := resourceTypes[0]
+	assert.Equal(t, resourceType.Endpoint.TemplateURL, "/customers")
+	assert.Equal(t, resourceType.Endpoint.APIVersion, "")
+	assert.Assert(t, is.Len(resourceType.SubResources, 1))
+	assert.Equal(t, resourceType.SubResources[0].Endpoint.TemplateURL, "/customers/{customerId}")
+
+	// /orders
+	resourceType = resourceTypes[1]
+	assert.Equal(t, resourceType.Endpoint.TemplateURL, "/orders")
+	assert.Assert(t, is.Len(resourceType.SubResources, 1))
+	assert.Equal(t, resourceType.SubResources[0].PutEndpoint.TemplateURL, "/orders/{orderId}")
+	assert.Equal(t, resourceType.SubResources[0].DeleteEndpoint.TemplateURL, "/orders/{orderId}")
+}
+
+func Test_LoadResourceTypesFromDirectory_Empty(t *testing.T) {
+	_, err := LoadResourceTypesFromDirectory(t.TempDir(), &Config{})
+	assert.ErrorContains(t, err, "No swagger paths found")
+}
+
+func Test_PostActions(t *testing.T) {
+
+	// Test that POST-only child paths are converted to PostActions with a body skeleton
+
+	specJSON := `{
+	"swagger": "2.0",
+	"info": { "title": "Test", "version": "2021-01-01" },
+	"paths": {
+		"/widgets/{widgetName}": { "get": {}, "put": {} },
+		"/widgets/{widgetName}/listKeys": { "post": {} },
+		"/widgets/{widgetName}/regenerateKey": {
+			"post": {
+				"parameters": [
+					{
+						"name": "parameters",
+						"in": "body",
+						"schema": {
+							"type": "object",
+							"properties": {
+								"keyName": { "type": "string", "enum": [ "key1", "key2" ] },
+								"expiry": { "type": "integer" },
+								"lastRotated": { "type": "string", "readOnly": true },
+								"tags": { "type": "array", "items": { "type": "string" } }
+							}
+						}
+					}
+				]
+			}
+		}
+	}
+}`
+	doc, err := loads.Analyzed(json.RawMessage(specJSON), "")
+	assert.NilError(t, err)
+
+	var paths []*Path
+	paths, err = MergeSwaggerDoc(paths, &Config{}, doc, false, "")
+	assert.NilError(t, err)
+
+	resourceTypes := ConvertToSwaggerResourceTypes(paths)
+	assert.Assert(t, is.Len(resourceTypes, 1))
+
+	actions := resourceTypes[0].PostActions
+	assert.Assert(t, is.Len(actions, 2))
+	assert.Equal(t, actions[0].Name, "listKeys")
+	assert.Equal(t, actions[0].Endpoint.TemplateURL, "/widgets/{widgetName}/listKeys")
+	assert.Equal(t, actions[0].Endpoint.APIVersion, "2021-01-01")
+	assert.Equal(t, actions[0].BodySkeleton, "")
+	assert.Equal(t, actions[1].Name, "regenerateKey")
+	assert.Equal(t, actions[1].BodySkeleton, `{"expiry":0,"keyName":"key1","tags":[""]}`)
+}
+
+func Test_GetCreatableSubResourceType(t *testing.T) {
+	resourceType := ResourceType{
+		Display:  "orders",
+		Endpoint: endpoints.MustGetEndpointInfoFromURL("/stores/{storeId}/orders", ""),
+		SubResources: []ResourceType{
+			{
+				Display:  "{orderId}",
+				Endpoint: endpoints.MustGetEndpointInfoFromURL("/stores/{storeId}/orders/{orderId}", ""),
+			},
+		},
+	}
+	assert.Assert(t, resourceType.GetCreatableSubResourceType() == nil)
+
+	resourceType.SubResources[0].PutEndpoint = endpoints.MustGetEndpointInfoFromURL("/stores/{store}/orders/{orderId}", "")
+	subResourceType := resourceType.GetCreatableSubResourceType()
+	assert.Assert(t, subResourceType != nil)
+	assert.Equal(t, subResourceType.Display, "{orderId}")
+
+	resourceType.SubResources[0].PutEndpoint = endpoints.MustGetEndpointInfoFromURL("/stores/{storeId}/archive/{orderId}", "")
+	assert.Assert(t, resourceType.GetCreatableSubResourceType() == nil)
+}
+
+func Test_GetResourceTypeForURL(t *testing.T) {
+	resourceTypes := []ResourceType{
+		{
+			Display:  "{widgetName}",
+			Endpoint: endpoints.MustGetEndpointInfoFromURL("/widgets/{widgetName}", ""),
+			SubResources: []ResourceType{
+				{
+					Display:  "{childName}",
+					Endpoint: endpoints.MustGetEndpointInfoFromURL("/widgets/{widgetName}/{childName}", ""),
+				},
+			},
+			Children: []ResourceType{
+				{
+					Display:  "config",
+					Endpoint: endpoints.MustGetEndpointInfoFromURL("/widgets/{widgetName}/config", ""),
+				},
+				{
+					Display:  "settings",
+					Endpoint: endpoints.MustGetEndpointInfoFromURL("/widgets/{widgetName}/config/settings", ""),
+				},
+			},
+		},
+	}
+	ctx := context.Background()
+	matcher := NewResourceTypeMatcher(resourceTypes)
+	emptyMatcher := NewResourceTypeMatcher([]ResourceType{})
+
+	// the ResourceTypeMatcher gives the same results as walking the resource types
+	getResourceTypeFuncs := map[string]func(url string, resourceTypes []ResourceType) *ResourceType{
+		"walk": func(url string, resourceTypes []ResourceType) *ResourceType {
+			return GetResourceTypeForURL(ctx, url, resourceTypes)
+		},
+		"matcher": func(url string, resourceTypes []ResourceType) *ResourceType {
+			if len(resourceTypes) == 0 {
+				return emptyMatcher.GetResourceTypeForURL(ctx, url)
+			}
+			return matcher.GetResourceTypeForURL(ctx, url)
+		},
+	}
+	for name, getResourceType := range getResourceTypeFuncs {
+		t.Run(name, func(t *testing.T) {
+			resourceType := getResourceType("/WIDGETS/w1?api-version=2021-01-01", resourceTypes)
+			assert.Assert(t, resourceType != nil)
+			assert.Equal(t, resourceType.Display, "{widgetName}")
+
+			// SubResources are tested before Children
+			resourceType = getResourceType("/widgets/w1/config", resourceTypes)
+			assert.Assert(t, resourceType != nil)
+			assert.Equal(t, resourceType.Display, "{childName}")
+
+			resourceType = getResourceType("/widgets/w1/config/settings", resourceTypes)
+			assert.Assert(t, resourceType != nil)
+			assert.Equal(t, resourceType.Display, "settings")
+
+			assert.Assert(t, getResourceType("/widgets/w1/config/settings/other", resourceTypes) == nil)
+			assert.Assert(t, getResourceType("/widgets/w1", []ResourceType{}) == nil)
+
+			// the returned ResourceType is a copy
+			resourceType.Display = "changed"
+			assert.Equal(t, resourceTypes[0].Children[1].Display, "settings")
+		})
+	}
+}
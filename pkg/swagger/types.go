@@ -3,6 +3,7 @@ package swagger
 import (
 	"context"
 	"regexp"
+	"strings"
 
 	"github.com/lawrencegripper/azbrowse/internal/pkg/tracing"
 	"github.com/lawrencegripper/azbrowse/pkg/endpoints"
@@ -13,9 +14,12 @@ type ResourceType struct {
 	Display              string
 	Endpoint             *endpoints.EndpointInfo
 	Verb                 string
+	ResponseSchema       string // ResponseSchema is the compact JSON Schema (with descriptions) for the GET response (see GetResponseSchema)
 	DeleteEndpoint       *endpoints.EndpointInfo
 	PatchEndpoint        *endpoints.EndpointInfo
 	PutEndpoint          *endpoints.EndpointInfo
+	PutBodySchema        string         // PutBodySchema is the compact JSON Schema for the PUT body (see GetBodySchema)
+	PostActions          []PostAction   // PostActions are the POST operations on the resource (e.g. listKeys, restart)
 	Children             []ResourceType // Children are auto-loaded (must be able to build the URL => no additional template URL values)
 	SubResources         []ResourceType // SubResources are not auto-loaded (these come from the request to the endpoint)
 	FixedContent         string
@@ -23,6 +27,13 @@ type ResourceType struct {
 	subPathRegexInstance *regexp.Regexp
 }
 
+// PostAction holds information about a POST operation on a resource
+type PostAction struct {
+	Name         string
+	Endpoint     *endpoints.EndpointInfo
+	BodySkeleton string // JSON skeleton built from the body parameter schema (empty if the operation has no body)
+}
+
 // PerformSubPathReplace performs any configured SubPathRegex replacement
 func (r ResourceType) PerformSubPathReplace(url string) (string, error) {
 
@@ -39,12 +50,33 @@ func (r ResourceType) PerformSubPathReplace(url string) (string, error) {
 	return r.subPathRegexInstance.ReplaceAllString(url, r.SubPathRegex.Replace), nil
 }
 
+// GetCreatableSubResourceType returns the SubResource that can be created beneath the ResourceType, i.e. a SubResource
+// with a PUT endpoint of the form <endpoint>/{name}. Returns nil if there isn't one
+func (r ResourceType) GetCreatableSubResourceType() *ResourceType {
+	for _, subResource := range r.SubResources {
+		if subResource.PutEndpoint == nil {
+			continue
+		}
+		segments := subResource.PutEndpoint.URLSegments
+		if len(segments) != len(r.Endpoint.URLSegments)+1 || segments[len(segments)-1].Name == "" {
+			continue
+		}
+		if !r.Endpoint.Match(subResource.PutEndpoint.TemplateURL[:strings.LastIndex(subResource.PutEndpoint.TemplateURL, "/")]).IsMatch {
+			continue
+		}
+		result := subResource
+		return &result
+	}
+	return nil
+}
+
 // GetSubResourceTypeForURL gets the SubResource matching the URL
 func (r ResourceType) GetSubResourceTypeForURL(ctx context.Context, url string) *ResourceType {
 	return GetResourceTypeForURL(ctx, url, r.SubResources)
 }
 
-// GetResourceTypeForURL Gets the resource type matching the url
+// GetResourceTypeForURL Gets the resource type matching the url. This walks the resource types so use a
+// ResourceTypeMatcher for large sets of resource types that are searched repeatedly
 func GetResourceTypeForURL(ctx context.Context, url string, resourceTypes []ResourceType) *ResourceType {
 	span, _ := tracing.StartSpanFromContext(ctx, "getResourceTypeForURL:"+url)
 	defer span.Finish()
@@ -66,6 +98,45 @@ func getResourceTypeForURLInner(url string, resourceTypes []ResourceType) *Resou
 	return nil
 }
 
+// ResourceTypeMatcher finds the resource type matching a url using an index of the resource types (including
+// their SubResources and Children). The index is built when the ResourceTypeMatcher is created, so create it
+// along with the set of resource types (e.g. when creating an API set) rather than for each lookup
+type ResourceTypeMatcher struct {
+	matcher *endpoints.Matcher
+}
+
+// NewResourceTypeMatcher creates a ResourceTypeMatcher for the resource types
+func NewResourceTypeMatcher(resourceTypes []ResourceType) *ResourceTypeMatcher {
+	matcher := endpoints.NewMatcher()
+	addResourceTypesToMatcher(matcher, resourceTypes)
+	return &ResourceTypeMatcher{matcher: matcher}
+}
+
+// GetResourceTypeForURL Gets the resource type matching the url (the same result as the GetResourceTypeForURL func)
+func (m *ResourceTypeMatcher) GetResourceTypeForURL(ctx context.Context, url string) *ResourceType {
+	span, _ := tracing.StartSpanFromContext(ctx, "getResourceTypeForURL:"+url)
+	defer span.Finish()
+	value, matchResult := m.matcher.Match(url)
+	if !matchResult.IsMatch {
+		return nil
+	}
+	result := *value.(*ResourceType)
+	return &result
+}
+
+// addResourceTypesToMatcher adds the resource types (and their SubResources and Children) in the order they should be
+// tested, so that the first match is the same as walking the resource types depth-first
+func addResourceTypesToMatcher(matcher *endpoints.Matcher, resourceTypes []ResourceType) {
+	for i := range resourceTypes {
+		resourceType := &resourceTypes[i]
+		if resourceType.Endpoint != nil {
+			matcher.Add(resourceType.Endpoint, resourceType)
+		}
+		addResourceTypesToMatcher(matcher, resourceType.SubResources)
+		addResourceTypesToMatcher(matcher, resourceType.Children)
+	}
+}
+
 /////////////////////////////////////////////////////////////////////////////
 // Path models
 
@@ -98,9 +169,12 @@ type PathOperations struct {
 
 // PathOperation represents an operation on the path (GET, PUT, ...)
 type PathOperation struct {
-	Permitted bool                    // true if the operation is permitted for the path
-	Verb      string                  // Empty unless the Verb is overridden for the operation
-	Endpoint  *endpoints.EndpointInfo // nil unless the endpoint is overridden for the operation
+	Permitted      bool                    // true if the operation is permitted for the path
+	Verb           string                  // Empty unless the Verb is overridden for the operation
+	Endpoint       *endpoints.EndpointInfo // nil unless the endpoint is overridden for the operation
+	BodySkeleton   string                  // JSON skeleton for the body parameter (currently only set for POST)
+	BodySchema     string                  // compact JSON Schema for the body parameter (currently only set for PUT)
+	ResponseSchema string                  // compact JSON Schema for the 200 response (currently only set for GET)
 }
 
 /*
@@ -0,0 +1,266 @@
+package swagger
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+const readOnlyMessage = "read-only property will be ignored"
+
+// ValidationError describes a problem found when validating content against a Schema
+type ValidationError struct {
+	Path    string // Path is the JSON path of the property, e.g. properties.sku.name
+	Line    int    // Line is the 1-based line of the property in the content
+	Message string
+	value   interface{}
+}
+
+func (e ValidationError) String() string {
+	if e.Path == "" {
+		return fmt.Sprintf("line %d: %s", e.Line, e.Message)
+	}
+	return fmt.Sprintf("line %d: %s: %s", e.Line, e.Path, e.Message)
+}
+
+// ValidateContent validates JSON content against the schema. Unknown properties, type mismatches,
+// missing required properties and read-only properties are reported
+func ValidateContent(schema *Schema, content string) ([]ValidationError, error) {
+	decoder := json.NewDecoder(strings.NewReader(content))
+	decoder.UseNumber()
+	var value interface{}
+	if err := decoder.Decode(&value); err != nil {
+		return nil, fmt.Errorf("Error parsing content: %s", err)
+	}
+	lines, err := getJSONPathLines(content)
+	if err != nil {
+		return nil, err
+	}
+
+	validationErrors := []ValidationError{}
+	validateValue(schema, value, "", &validationErrors)
+	for i := range validationErrors {
+		validationErrors[i].Line = lines[validationErrors[i].Path]
+	}
+	sort.SliceStable(validationErrors, func(i, j int) bool {
+		return validationErrors[i].Line < validationErrors[j].Line
+	})
+	return validationErrors, nil
+}
+
+// ValidateUpdate validates updated JSON content against the schema, ignoring problems that were already
+// present in the original content (e.g. properties returned by a GET that aren't part of the PUT body)
+func ValidateUpdate(schema *Schema, originalContent string, updatedContent string) ([]ValidationError, error) {
+	originalErrors, err := ValidateContent(schema, originalContent)
+	if err != nil {
+		return nil, err
+	}
+	updatedErrors, err := ValidateContent(schema, updatedContent)
+	if err != nil {
+		return nil, err
+	}
+
+	originalValues := map[string]interface{}{}
+	for _, originalError := range originalErrors {
+		originalValues[originalError.Path+"|"+originalError.Message] = originalError.value
+	}
+	validationErrors := []ValidationError{}
+	for _, updatedError := range updatedErrors {
+		originalValue, existing := originalValues[updatedError.Path+"|"+updatedError.Message]
+		if existing && (updatedError.Message != readOnlyMessage || reflect.DeepEqual(originalValue, updatedError.value)) {
+			continue // unchanged since the original content
+		}
+		validationErrors = append(validationErrors, updatedError)
+	}
+	return validationErrors, nil
+}
+
+// ValidateEditedContent validates content from the editor against the schema JSON (e.g. a PutBodySchema).
+// Invalid JSON is reported as a single error at the line of the syntax error. If originalContent is set
+// then problems already present in it are ignored (see ValidateUpdate)
+func ValidateEditedContent(schemaJSON string, originalContent string, updatedContent string) []ValidationError {
+	var value interface{}
+	if err := json.Unmarshal([]byte(updatedContent), &value); err != nil {
+		return []ValidationError{{Line: getJSONErrorLine(updatedContent, err), Message: fmt.Sprintf("invalid JSON: %s", err)}}
+	}
+	if schemaJSON == "" {
+		return nil
+	}
+	schema, err := ParseSchema(schemaJSON)
+	if err != nil {
+		return nil
+	}
+	var validationErrors []ValidationError
+	if originalContent == "" {
+		validationErrors, err = ValidateContent(schema, updatedContent)
+	} else {
+		validationErrors, err = ValidateUpdate(schema, originalContent, updatedContent)
+	}
+	if err != nil {
+		return nil
+	}
+	return validationErrors
+}
+
+func getJSONErrorLine(content string, err error) int {
+	if syntaxErr, ok := err.(*json.SyntaxError); ok && syntaxErr.Offset <= int64(len(content)) {
+		return strings.Count(content[:syntaxErr.Offset], "\n") + 1
+	}
+	return 1
+}
+
+func joinJSONPath(path string, name string) string {
+	if path == "" {
+		return name
+	}
+	return path + "." + name
+}
+
+func getJSONType(value interface{}) string {
+	switch typedValue := value.(type) {
+	case map[string]interface{}:
+		return "object"
+	case []interface{}:
+		return "array"
+	case string:
+		return "string"
+	case bool:
+		return "boolean"
+	case json.Number:
+		if strings.ContainsAny(typedValue.String(), ".eE") {
+			return "number"
+		}
+		return "integer"
+	}
+	return "null"
+}
+
+func isJSONTypeMatch(schemaType string, valueType string) bool {
+	return schemaType == "" ||
+		schemaType == valueType ||
+		valueType == "null" ||
+		(schemaType == "number" && valueType == "integer")
+}
+
+// getSchemaProperty finds the schema for the property, falling back to a case-insensitive match as ARM property names are case-insensitive
+func getSchemaProperty(schema *Schema, name string) (*Schema, bool) {
+	if property, ok := schema.Properties[name]; ok {
+		return property, true
+	}
+	for propertyName, property := range schema.Properties {
+		if strings.EqualFold(propertyName, name) {
+			return property, true
+		}
+	}
+	return nil, false
+}
+
+func hasProperty(value map[string]interface{}, name string) bool {
+	for propertyName := range value {
+		if strings.EqualFold(propertyName, name) {
+			return true
+		}
+	}
+	return false
+}
+
+func validateValue(schema *Schema, value interface{}, path string, validationErrors *[]ValidationError) {
+	if schema == nil {
+		return
+	}
+	if schema.ReadOnly {
+		*validationErrors = append(*validationErrors, ValidationError{Path: path, Message: readOnlyMessage, value: value})
+		return
+	}
+
+	valueType := getJSONType(value)
+	if !isJSONTypeMatch(schema.Type, valueType) {
+		*validationErrors = append(*validationErrors, ValidationError{
+			Path:    path,
+			Message: fmt.Sprintf("expected %s but got %s", schema.Type, valueType),
+			value:   value,
+		})
+		return
+	}
+
+	switch typedValue := value.(type) {
+	case map[string]interface{}:
+		if schema.Properties == nil && schema.AdditionalProperties == nil {
+			return
+		}
+		names := make([]string, 0, len(typedValue))
+		for name := range typedValue {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			propertyPath := joinJSONPath(path, name)
+			if property, ok := getSchemaProperty(schema, name); ok {
+				validateValue(property, typedValue[name], propertyPath, validationErrors)
+			} else if schema.AdditionalProperties != nil {
+				validateValue(schema.AdditionalProperties, typedValue[name], propertyPath, validationErrors)
+			} else {
+				*validationErrors = append(*validationErrors, ValidationError{Path: propertyPath, Message: "unknown property", value: typedValue[name]})
+			}
+		}
+		for _, name := range schema.Required {
+			if !hasProperty(typedValue, name) {
+				*validationErrors = append(*validationErrors, ValidationError{Path: path, Message: fmt.Sprintf("missing required property %q", name)})
+			}
+		}
+	case []interface{}:
+		for i, item := range typedValue {
+			validateValue(schema.Items, item, fmt.Sprintf("%s[%d]", path, i), validationErrors)
+		}
+	}
+}
+
+// getJSONPathLines returns the 1-based line number for each JSON path in the content
+func getJSONPathLines(content string) (map[string]int, error) {
+	decoder := json.NewDecoder(strings.NewReader(content))
+	lines := map[string]int{}
+	getLine := func() int {
+		return strings.Count(content[:decoder.InputOffset()], "\n") + 1
+	}
+
+	var walk func(path string) error
+	walk = func(path string) error {
+		token, err := decoder.Token()
+		if err != nil {
+			return fmt.Errorf("Error parsing content: %s", err)
+		}
+		if _, ok := lines[path]; !ok {
+			lines[path] = getLine()
+		}
+		switch token {
+		case json.Delim('{'):
+			for decoder.More() {
+				keyToken, err := decoder.Token()
+				if err != nil {
+					return fmt.Errorf("Error parsing content: %s", err)
+				}
+				propertyPath := joinJSONPath(path, keyToken.(string))
+				lines[propertyPath] = getLine()
+				if err = walk(propertyPath); err != nil {
+					return err
+				}
+			}
+			_, err = decoder.Token() // closing '}'
+		case json.Delim('['):
+			for i := 0; decoder.More(); i++ {
+				if err = walk(fmt.Sprintf("%s[%d]", path, i)); err != nil {
+					return err
+				}
+			}
+			_, err = decoder.Token() // closing ']'
+		}
+		return err
+	}
+	lines[""] = 1
+	if err := walk(""); err != nil {
+		return nil, err
+	}
+	return lines, nil
+}
@@ -0,0 +1,321 @@
+package swagger
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+
+	"github.com/go-openapi/loads"
+	"gotest.tools/assert"
+	is "gotest.tools/assert/cmp"
+)
+
+const testWidgetSchema = `{
+	"t": "object",
+	"q": ["location"],
+	"p": {
+		"id": { "t": "string", "r": true },
+		"location": { "t": "string" },
+		"tags": { "t": "object", "a": { "t": "string" } },
+		"properties": {
+			"t": "object",
+			"p": {
+				"count": { "t": "integer" },
+				"enabled": { "t": "boolean" },
+				"provisioningState": { "t": "string", "r": true },
+				"rules": { "t": "array", "i": { "t": "object", "p": { "name": { "t": "string" } } } }
+			}
+		}
+	}
+}`
+
+func Test_PutBodySchema(t *testing.T) {
+
+	specJSON := `{
+	"swagger": "2.0",
+	"info": { "title": "Test", "version": "2021-01-01" },
+	"paths": {
+		"/widgets/{widgetName}": {
+			"get": {},
+			"put": {
+				"parameters": [
+					{
+						"name": "parameters",
+						"in": "body",
+						"schema": {
+							"required": [ "location" ],
+							"allOf": [ { "properties": { "id": { "type": "string", "readOnly": true } } } ],
+							"properties": {
+								"location": { "type": "string" },
+								"tags": { "type": "object", "additionalProperties": { "type": "string" } }
+							}
+						}
+					}
+				]
+			}
+		}
+	}
+}`
+	doc, err := loads.Analyzed(json.RawMessage(specJSON), "")
+	assert.NilError(t, err)
+
+	var paths []*Path
+	paths, err = MergeSwaggerDoc(paths, &Config{}, doc, false, "")
+	assert.NilError(t, err)
+
+	resourceTypes := ConvertToSwaggerResourceTypes(paths)
+	assert.Assert(t, is.Len(resourceTypes, 1))
+
+	schema, err := ParseSchema(resourceTypes[0].PutBodySchema)
+	assert.NilError(t, err)
+	assert.Equal(t, schema.Type, "object")
+	assert.DeepEqual(t, schema.Required, []string{"location"})
+	assert.Equal(t, schema.Properties["id"].ReadOnly, true)
+	assert.Equal(t, schema.Properties["location"].Type, "string")
+	assert.Equal(t, schema.Properties["tags"].AdditionalProperties.Type, "string")
+}
+
+func Test_PutBodySchema_RecursiveIsDeterministic(t *testing.T) {
+
+	// Recursive refs (directly and via another file) must be cut at the same point each time
+	// so that the generated code (and the validation errors) don't change between code-gen runs
+
+	directory := t.TempDir()
+	specJSON := `{
+	"swagger": "2.0",
+	"info": { "title": "Test", "version": "2021-01-01" },
+	"paths": {
+		"/widgets/{widgetName}": {
+			"get": {
+				"responses": { "200": { "description": "OK", "schema": { "$ref": "./definitions.json#/definitions/Widget" } } }
+			},
+			"put": {
+				"parameters": [
+					{ "name": "parameters", "in": "body", "schema": { "$ref": "./definitions.json#/definitions/Widget" } }
+				]
+			}
+		}
+	}
+}`
+	definitionsJSON := `{
+	"swagger": "2.0",
+	"info": { "title": "Definitions", "version": "2021-01-01" },
+	"paths": {},
+	"definitions": {
+		"Widget": {
+			"description": "A widget",
+			"allOf": [ { "$ref": "#/definitions/Resource" } ],
+			"properties": {
+				"parent": { "$ref": "#/definitions/Widget" },
+				"children": { "type": "array", "items": { "$ref": "#/definitions/Widget" } },
+				"rules": { "type": "array", "items": { "$ref": "#/definitions/Rule" } }
+			}
+		},
+		"Rule": {
+			"properties": {
+				"name": { "type": "string" },
+				"target": { "$ref": "#/definitions/Widget" },
+				"next": { "$ref": "#/definitions/Rule" }
+			}
+		},
+		"Resource": {
+			"properties": {
+				"id": { "type": "string", "readOnly": true },
+				"owner": { "$ref": "#/definitions/Widget" }
+			}
+		}
+	}
+}`
+	specPath := filepath.Join(directory, "widgets.json")
+	assert.NilError(t, ioutil.WriteFile(specPath, []byte(specJSON), 0600))
+	assert.NilError(t, ioutil.WriteFile(filepath.Join(directory, "definitions.json"), []byte(definitionsJSON), 0600))
+
+	getSchemas := func() (string, string) {
+		doc, err := LoadDoc(specPath)
+		assert.NilError(t, err)
+		var paths []*Path
+		paths, err = MergeSwaggerDoc(paths, &Config{}, doc, false, "")
+		assert.NilError(t, err)
+		resourceTypes := ConvertToSwaggerResourceTypes(paths)
+		assert.Assert(t, is.Len(resourceTypes, 1))
+		return resourceTypes[0].PutBodySchema, resourceTypes[0].ResponseSchema
+	}
+
+	putBodySchema, responseSchema := getSchemas()
+	for i := 0; i < 10; i++ {
+		nextPutBodySchema, nextResponseSchema := getSchemas()
+		assert.Equal(t, nextPutBodySchema, putBodySchema)
+		assert.Equal(t, nextResponseSchema, responseSchema)
+	}
+
+	schema, err := ParseSchema(putBodySchema)
+	assert.NilError(t, err)
+	assert.Equal(t, schema.Type, "object")
+	assert.Equal(t, schema.Properties["id"].ReadOnly, true)
+	assert.Equal(t, schema.Properties["rules"].Items.Properties["name"].Type, "string")
+	assert.Equal(t, schema.Properties["rules"].Items.Properties["next"].Type, "")
+	// the recursive refs are cut where Widget repeats
+	assert.Equal(t, schema.Properties["parent"].Type, "")
+	assert.Equal(t, schema.Properties["owner"].Type, "")
+	assert.Equal(t, schema.Properties["children"].Items.Type, "")
+	assert.Equal(t, schema.Properties["rules"].Items.Properties["target"].Type, "")
+}
+
+func Test_PutBodySchema_CrossFileRefs(t *testing.T) {
+	directory := t.TempDir()
+	specJSON := `{
+	"swagger": "2.0",
+	"info": { "title": "Test", "version": "2021-01-01" },
+	"paths": {
+		"/widgets/{widgetName}": {
+			"get": {},
+			"put": {
+				"parameters": [
+					{ "name": "parameters", "in": "body", "schema": { "$ref": "./%s#/definitions/Widget" } }
+				]
+			}
+		}
+	}
+}`
+	definitionsYAML := `swagger: "2.0"
+info:
+  title: Definitions
+  version: "2021-01-01"
+paths: {}
+definitions:
+  Widget:
+    required: [location]
+    properties:
+      location:
+        type: string
+`
+	assert.NilError(t, ioutil.WriteFile(filepath.Join(directory, "definitions.yaml"), []byte(definitionsYAML), 0600))
+
+	loadPaths := func(refFile string) ([]*Path, error) {
+		specPath := filepath.Join(directory, "widgets.json")
+		assert.NilError(t, ioutil.WriteFile(specPath, []byte(fmt.Sprintf(specJSON, refFile)), 0600))
+		doc, err := LoadDoc(specPath)
+		assert.NilError(t, err)
+		return MergeSwaggerDoc(nil, &Config{}, doc, false, "")
+	}
+
+	// YAML docs can be referenced
+	paths, err := loadPaths("definitions.yaml")
+	assert.NilError(t, err)
+	resourceTypes := ConvertToSwaggerResourceTypes(paths)
+	assert.Assert(t, is.Len(resourceTypes, 1))
+	schema, err := ParseSchema(resourceTypes[0].PutBodySchema)
+	assert.NilError(t, err)
+	assert.Equal(t, schema.Properties["location"].Type, "string")
+	assert.DeepEqual(t, schema.Required, []string{"location"})
+
+	// refs that can't be resolved are reported
+	_, err = loadPaths("missing.json")
+	assert.ErrorContains(t, err, "missing.json#/definitions/Widget")
+}
+
+func Test_ValidateContent(t *testing.T) {
+	schema, err := ParseSchema(testWidgetSchema)
+	assert.NilError(t, err)
+
+	content := `{
+  "id": "/widgets/w1",
+  "tags": {
+    "env": 1
+  },
+  "properties": {
+    "count": 1.5,
+    "enabled": "yes",
+    "colour": "blue",
+    "rules": [
+      {
+        "name": "r1"
+      },
+      {
+        "nmae": "r2"
+      }
+    ]
+  }
+}`
+	validationErrors, err := ValidateContent(schema, content)
+	assert.NilError(t, err)
+
+	messages := []string{}
+	for _, validationError := range validationErrors {
+		messages = append(messages, validationError.String())
+	}
+	assert.DeepEqual(t, messages, []string{
+		`line 1: missing required property "location"`,
+		"line 2: id: read-only property will be ignored",
+		"line 4: tags.env: expected string but got integer",
+		"line 7: properties.count: expected integer but got number",
+		"line 8: properties.enabled: expected boolean but got string",
+		"line 9: properties.colour: unknown property",
+		"line 15: properties.rules[1].nmae: unknown property",
+	})
+}
+
+func Test_ValidateContent_InvalidJSON(t *testing.T) {
+	schema, err := ParseSchema(testWidgetSchema)
+	assert.NilError(t, err)
+
+	_, err = ValidateContent(schema, `{"location": `)
+	assert.Assert(t, err != nil)
+}
+
+func Test_ValidateUpdate(t *testing.T) {
+	schema, err := ParseSchema(testWidgetSchema)
+	assert.NilError(t, err)
+
+	original := `{
+  "id": "/widgets/w1",
+  "location": "westeurope",
+  "etag": "abc",
+  "properties": {
+    "provisioningState": "Succeeded",
+    "count": 1
+  }
+}`
+	updated := `{
+  "id": "/widgets/w1",
+  "location": "westeurope",
+  "etag": "abc",
+  "properties": {
+    "provisioningState": "Failed",
+    "Count": 2,
+    "enabeld": true
+  }
+}`
+	validationErrors, err := ValidateUpdate(schema, original, updated)
+	assert.NilError(t, err)
+
+	messages := []string{}
+	for _, validationError := range validationErrors {
+		messages = append(messages, validationError.String())
+	}
+	assert.DeepEqual(t, messages, []string{
+		"line 6: properties.provisioningState: read-only property will be ignored",
+		"line 8: properties.enabeld: unknown property",
+	})
+}
+
+func Test_ValidateEditedContent(t *testing.T) {
+	validationErrors := ValidateEditedContent(testWidgetSchema, "", "{\n  \"location\": \"westeurope\",\n  \"colour\": \n}")
+	assert.Assert(t, is.Len(validationErrors, 1))
+	assert.Equal(t, validationErrors[0].Line, 4)
+
+	validationErrors = ValidateEditedContent(testWidgetSchema, "", "{\n  \"colour\": \"blue\"\n}")
+	messages := []string{}
+	for _, validationError := range validationErrors {
+		messages = append(messages, validationError.String())
+	}
+	assert.DeepEqual(t, messages, []string{
+		`line 1: missing required property "location"`,
+		"line 2: colour: unknown property",
+	})
+
+	validationErrors = ValidateEditedContent("", "", `{"colour": "blue"}`)
+	assert.Assert(t, is.Len(validationErrors, 0))
+}